@@ -158,6 +158,7 @@ var allTests = []func(t *testing.T, sb integration.Sandbox){
 	testHostnameLookup,
 	testHostnameSpecifying,
 	testPushByDigest,
+	testPushAllowPartial,
 	testBasicInlineCacheImportExport,
 	testExportBusyboxLocal,
 	testBridgeNetworking,
@@ -1126,6 +1127,80 @@ func testPushByDigest(t *testing.T, sb integration.Sandbox) {
 	require.Greater(t, desc.Size, int64(0))
 }
 
+func testPushAllowPartial(t *testing.T, sb integration.Sandbox) {
+	workers.CheckFeatureCompat(t, sb, workers.FeatureDirectPush)
+	requiresLinux(t)
+	c, err := New(sb.Context(), sb.Address())
+	require.NoError(t, err)
+	defer c.Close()
+
+	registry, err := sb.NewRegistry()
+	if errors.Is(err, integration.ErrRequirements) {
+		t.Skip(err.Error())
+	}
+	require.NoError(t, err)
+
+	st := llb.Scratch().File(llb.Mkfile("foo", 0600, []byte("data")))
+
+	def, err := st.Marshal(sb.Context())
+	require.NoError(t, err)
+
+	name := registry + "/foo/bar:latest"
+	// nothing listens on this port
+	unreachable := "localhost:1/foo/bar:latest"
+
+	_, err = c.Solve(sb.Context(), def, SolveOpt{
+		Exports: []ExportEntry{
+			{
+				Type: ExporterImage,
+				Attrs: map[string]string{
+					"name": name + "," + unreachable,
+					"push": "true",
+				},
+			},
+		},
+	}, nil)
+	require.ErrorContains(t, err, "failed to push "+unreachable)
+
+	resp, err := c.Solve(sb.Context(), def, SolveOpt{
+		Exports: []ExportEntry{
+			{
+				Type: ExporterImage,
+				Attrs: map[string]string{
+					"name":               name + "," + unreachable,
+					"push":               "true",
+					"push-allow-partial": "true",
+				},
+			},
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	dt, err := base64.StdEncoding.DecodeString(resp.ExporterResponse[exptypes.ExporterImagePushResultsKey])
+	require.NoError(t, err)
+	var results map[string]exptypes.PushResult
+	require.NoError(t, json.Unmarshal(dt, &results))
+	require.Empty(t, results[name].Error)
+	require.Contains(t, results[unreachable].Error, "failed to push "+unreachable)
+
+	desc, _, err := contentutil.ProviderFromRef(name)
+	require.NoError(t, err)
+	require.Equal(t, resp.ExporterResponse[exptypes.ExporterImageDigestKey], desc.Digest.String())
+
+	_, err = c.Solve(sb.Context(), def, SolveOpt{
+		Exports: []ExportEntry{
+			{
+				Type: ExporterImage,
+				Attrs: map[string]string{
+					"name":               name,
+					"push-allow-partial": "true",
+				},
+			},
+		},
+	}, nil)
+	require.ErrorContains(t, err, `requires "push"`)
+}
+
 func testSecurityMode(t *testing.T, sb integration.Sandbox) {
 	integration.SkipOnPlatform(t, "windows")
 	workers.CheckFeatureCompat(t, sb, workers.FeatureSecurityMode)
@@ -6,4 +6,8 @@ const (
 	ExporterTar    = "tar"
 	ExporterOCI    = "oci"
 	ExporterDocker = "docker"
+
+	ExporterSquashFS = "squashfs"
+	ExporterEROFS    = "erofs"
+	ExporterExt4     = "ext4"
 )
@@ -3,6 +3,7 @@ package client
 import (
 	"time"
 
+	"github.com/moby/buildkit/solver/errdefs"
 	"github.com/moby/buildkit/solver/pb"
 	digest "github.com/opencontainers/go-digest"
 )
@@ -16,6 +17,9 @@ type Vertex struct {
 	Cached        bool              `json:"cached,omitempty"`
 	Error         string            `json:"error,omitempty"`
 	ProgressGroup *pb.ProgressGroup `json:"progressGroup,omitempty"`
+	// ExitReason is set if the vertex failed because a process exited with
+	// a known reason, e.g. it was OOM killed or terminated by a signal.
+	ExitReason *errdefs.ExitReason `json:"exitReason,omitempty"`
 }
 
 type VertexStatus struct {
@@ -47,11 +51,28 @@ type VertexWarning struct {
 	Range      []*pb.Range    `json:"range,omitempty"`
 }
 
+// VertexStats is a point-in-time sample of the resource usage of a running
+// vertex. Counters are cumulative since the process was started.
+type VertexStats struct {
+	ID              string        `json:"id"`
+	Vertex          digest.Digest `json:"vertex,omitempty"`
+	Timestamp       time.Time     `json:"timestamp"`
+	CPUNanos        uint64        `json:"cpuNanos,omitempty"`
+	MemoryBytes     uint64        `json:"memoryBytes,omitempty"`
+	MemoryPeakBytes uint64        `json:"memoryPeakBytes,omitempty"`
+	IOReadBytes     uint64        `json:"ioReadBytes,omitempty"`
+	IOWriteBytes    uint64        `json:"ioWriteBytes,omitempty"`
+	PIDs            uint64        `json:"pids,omitempty"`
+	NetRxBytes      int64         `json:"netRxBytes,omitempty"`
+	NetTxBytes      int64         `json:"netTxBytes,omitempty"`
+}
+
 type SolveStatus struct {
 	Vertexes []*Vertex        `json:"vertexes,omitempty"`
 	Statuses []*VertexStatus  `json:"statuses,omitempty"`
 	Logs     []*VertexLog     `json:"logs,omitempty"`
 	Warnings []*VertexWarning `json:"warnings,omitempty"`
+	Stats    []*VertexStats   `json:"stats,omitempty"`
 }
 
 type SolveResponse struct {
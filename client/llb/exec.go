@@ -265,12 +265,32 @@ func (e *ExecOp) Marshal(ctx context.Context, c *Constraints) (digest.Digest, []
 		return "", nil, nil, nil, err
 	}
 
+	resources, err := getResourceLimits(e.base)(ctx, c)
+	if err != nil {
+		return "", nil, nil, nil, err
+	}
+
+	accessLog, err := getNetworkAccessLog(e.base)(ctx, c)
+	if err != nil {
+		return "", nil, nil, nil, err
+	}
+
 	peo := &pb.ExecOp{
 		Meta:     meta,
 		Network:  network,
 		Security: security,
 	}
 
+	if resources != nil && (resources.Memory != 0 || resources.NanoCPUs != 0 || resources.Pids != 0) {
+		peo.ResourceLimits = resources
+		addCap(&e.constraints, pb.CapExecResourceLimits)
+	}
+
+	if accessLog {
+		peo.NetworkAccessLog = true
+		addCap(&e.constraints, pb.CapExecNetworkAccessLog)
+	}
+
 	if network != NetModeSandbox {
 		addCap(&e.constraints, pb.CapExecMetaNetwork)
 	}
@@ -687,6 +707,33 @@ func WithCgroupParent(cp string) RunOption {
 	})
 }
 
+// NetworkAccessLog requests recording the DNS lookups and connections made by
+// the container.
+var NetworkAccessLog = runOptionFunc(func(ei *ExecInfo) {
+	ei.State = ei.State.WithNetworkAccessLog()
+})
+
+// MemoryLimit sets the memory limit in bytes for the container.
+func MemoryLimit(bytes int64) RunOption {
+	return runOptionFunc(func(ei *ExecInfo) {
+		ei.State = ei.State.WithMemoryLimit(bytes)
+	})
+}
+
+// CPULimit sets the number of CPUs the container can use.
+func CPULimit(cpus float64) RunOption {
+	return runOptionFunc(func(ei *ExecInfo) {
+		ei.State = ei.State.WithCPULimit(cpus)
+	})
+}
+
+// PIDsLimit sets the maximum number of processes in the container.
+func PIDsLimit(pids int64) RunOption {
+	return runOptionFunc(func(ei *ExecInfo) {
+		ei.State = ei.State.WithPIDsLimit(pids)
+	})
+}
+
 func With(so ...StateOption) RunOption {
 	return runOptionFunc(func(ei *ExecInfo) {
 		ei.State = ei.State.With(so...)
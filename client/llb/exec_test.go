@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/moby/buildkit/solver/pb"
+	digest "github.com/opencontainers/go-digest"
 	"github.com/stretchr/testify/require"
 )
 
@@ -70,3 +71,57 @@ func TestExecOpMarshalConsistency(t *testing.T) {
 		prevDef = def.Def
 	}
 }
+
+func TestExecResourceLimits(t *testing.T) {
+	t.Parallel()
+
+	st := Image("foo").WithCPULimit(1.5).Run(
+		Shlex("args"),
+		MemoryLimit(4<<30),
+		PIDsLimit(100),
+	).Root()
+	def, err := st.Marshal(context.TODO())
+	require.NoError(t, err)
+
+	m, arr := parseDef(t, def.Def)
+	dgst, idx := last(t, arr)
+	require.Equal(t, 0, idx)
+
+	exec := m[dgst].GetExec()
+	require.NotNil(t, exec)
+	require.Equal(t, int64(4<<30), exec.ResourceLimits.Memory)
+	require.Equal(t, int64(1.5e9), exec.ResourceLimits.NanoCPUs)
+	require.Equal(t, int64(100), exec.ResourceLimits.Pids)
+	_, ok := def.Metadata[digest.Digest(dgst)].Caps[pb.CapExecResourceLimits]
+	require.True(t, ok)
+
+	st = Image("foo").Run(Shlex("args")).Root()
+	def, err = st.Marshal(context.TODO())
+	require.NoError(t, err)
+	m, arr = parseDef(t, def.Def)
+	dgst, _ = last(t, arr)
+	require.Nil(t, m[dgst].GetExec().ResourceLimits)
+}
+
+func TestExecNetworkAccessLog(t *testing.T) {
+	t.Parallel()
+
+	st := Image("foo").Run(Shlex("args"), NetworkAccessLog).Root()
+	def, err := st.Marshal(context.TODO())
+	require.NoError(t, err)
+
+	m, arr := parseDef(t, def.Def)
+	dgst, _ := last(t, arr)
+	require.True(t, m[dgst].GetExec().NetworkAccessLog)
+	_, ok := def.Metadata[digest.Digest(dgst)].Caps[pb.CapExecNetworkAccessLog]
+	require.True(t, ok)
+
+	st = Image("foo").Run(Shlex("args")).Root()
+	def, err = st.Marshal(context.TODO())
+	require.NoError(t, err)
+	m, arr = parseDef(t, def.Def)
+	dgst, _ = last(t, arr)
+	require.False(t, m[dgst].GetExec().NetworkAccessLog)
+	_, ok = def.Metadata[digest.Digest(dgst)].Caps[pb.CapExecNetworkAccessLog]
+	require.False(t, ok)
+}
@@ -570,6 +570,7 @@ type CopyInfo struct {
 	IncludePatterns                []string
 	ExcludePatterns                []string
 	AttemptUnpack                  bool
+	UnpackOpt                      *UnpackOpt
 	CreateDestPath                 bool
 	AllowWildcard                  bool
 	AllowEmptyWildcard             bool
@@ -578,6 +579,29 @@ type CopyInfo struct {
 	AlwaysReplaceExistingDestPaths bool
 }
 
+// UnpackOpt configures how archives are unpacked if AttemptUnpack is set.
+type UnpackOpt struct {
+	// Zip also unpacks zip archives, which are copied as files otherwise.
+	Zip bool
+	// StripComponents removes this number of leading path components from
+	// the archive entries. Entries with fewer components are skipped.
+	StripComponents int
+	// IncludePatterns only unpacks the archive entries matching at least one
+	// of the patterns, after StripComponents is applied.
+	IncludePatterns []string
+}
+
+func (mi *UnpackOpt) marshal() *pb.UnpackOpt {
+	if mi == nil {
+		return nil
+	}
+	return &pb.UnpackOpt{
+		Zip:             mi.Zip,
+		StripComponents: int32(mi.StripComponents),
+		IncludePatterns: mi.IncludePatterns,
+	}
+}
+
 func (mi *CopyInfo) SetCopyOption(mi2 *CopyInfo) {
 	*mi2 = *mi
 }
@@ -608,6 +632,7 @@ func (a *fileActionCopy) toProtoAction(ctx context.Context, parent string, base
 		FollowSymlink:                    a.info.FollowSymlinks,
 		DirCopyContents:                  a.info.CopyDirContentsOnly,
 		AttemptUnpackDockerCompatibility: a.info.AttemptUnpack,
+		Unpack:                           a.info.UnpackOpt.marshal(),
 		CreateDestPath:                   a.info.CreateDestPath,
 		Timestamp:                        marshalTime(a.info.CreatedTime),
 		AlwaysReplaceExistingDestPaths:   a.info.AlwaysReplaceExistingDestPaths,
@@ -653,6 +678,9 @@ func (a *fileActionCopy) addCaps(f *FileOp) {
 	if a.info.Mode.ModeStr != "" {
 		addCap(&f.constraints, pb.CapFileCopyModeStringFormat)
 	}
+	if a.info.UnpackOpt != nil {
+		addCap(&f.constraints, pb.CapFileCopyUnpackOpt)
+	}
 }
 
 type CreatedTime time.Time
@@ -385,6 +385,35 @@ func TestFileCopy(t *testing.T) {
 	require.Equal(t, int64(-1), copy.Timestamp)
 }
 
+func TestFileCopyUnpackOpt(t *testing.T) {
+	t.Parallel()
+
+	st := Scratch().File(Copy(Image("bar"), "a.zip", "/out/", &CopyInfo{
+		AttemptUnpack: true,
+		UnpackOpt: &UnpackOpt{
+			Zip:             true,
+			StripComponents: 1,
+			IncludePatterns: []string{"bin/*"},
+		},
+	}))
+	def, err := st.Marshal(context.TODO())
+	require.NoError(t, err)
+
+	m, arr := parseDef(t, def.Def)
+	dgst, idx := last(t, arr)
+	require.Equal(t, 0, idx)
+
+	f := m[dgst].GetFile()
+	require.NotNil(t, f)
+	copy := f.Actions[0].Action.(*pb.FileAction_Copy).Copy
+	require.True(t, copy.AttemptUnpackDockerCompatibility)
+	require.Equal(t, &pb.UnpackOpt{
+		Zip:             true,
+		StripComponents: 1,
+		IncludePatterns: []string{"bin/*"},
+	}, copy.Unpack)
+}
+
 func TestFileCopyFromAction(t *testing.T) {
 	t.Parallel()
 
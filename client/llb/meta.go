@@ -27,6 +27,8 @@ var (
 	keyCgroupParent   = contextKeyT("llb.exec.cgroup.parent")
 	keyUser           = contextKeyT("llb.exec.user")
 	keyValidExitCodes = contextKeyT("llb.exec.validexitcodes")
+	keyResources      = contextKeyT("llb.exec.resources")
+	keyAccessLog      = contextKeyT("llb.exec.accesslog")
 
 	keyPlatform = contextKeyT("llb.platform")
 	keyNetwork  = contextKeyT("llb.network")
@@ -324,6 +326,55 @@ func getCgroupParent(s State) func(context.Context, *Constraints) (string, error
 	}
 }
 
+func networkAccessLog(v bool) StateOption {
+	return func(s State) State {
+		return s.WithValue(keyAccessLog, v)
+	}
+}
+
+func getNetworkAccessLog(s State) func(context.Context, *Constraints) (bool, error) {
+	return func(ctx context.Context, c *Constraints) (bool, error) {
+		v, err := s.getValue(keyAccessLog)(ctx, c)
+		if err != nil {
+			return false, err
+		}
+		if v != nil {
+			return v.(bool), nil
+		}
+		return false, nil
+	}
+}
+
+func resourceLimits(fn func(*pb.ResourceLimits)) StateOption {
+	return func(s State) State {
+		return s.withValue(keyResources, func(ctx context.Context, c *Constraints) (any, error) {
+			v, err := getResourceLimits(s)(ctx, c)
+			if err != nil {
+				return nil, err
+			}
+			rl := &pb.ResourceLimits{}
+			if v != nil {
+				rl = v.CloneVT()
+			}
+			fn(rl)
+			return rl, nil
+		})
+	}
+}
+
+func getResourceLimits(s State) func(context.Context, *Constraints) (*pb.ResourceLimits, error) {
+	return func(ctx context.Context, c *Constraints) (*pb.ResourceLimits, error) {
+		v, err := s.getValue(keyResources)(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		if v != nil {
+			return v.(*pb.ResourceLimits), nil
+		}
+		return nil, nil
+	}
+}
+
 // Network returns a [StateOption] which sets the network mode used for containers created by [State.Run].
 // This is the equivalent of [State.Network]
 // See [State.With] for where to use this.
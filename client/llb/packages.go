@@ -0,0 +1,166 @@
+package llb
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"maps"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/pkg/errors"
+)
+
+// GoModules returns a state with the modules listed in a go.sum file in the
+// GOMODCACHE layout. Every module is fetched by a separate source and
+// verified against its go.sum hashes, so that changing a dependency only
+// fetches that module. The go.sum file is usually read from a local or git
+// source by the frontend.
+func GoModules(gosum []byte, opts ...PackageOption) (State, error) {
+	pi := &PackageInfo{}
+	for _, o := range opts {
+		o.SetPackageOption(pi)
+	}
+
+	type sums struct {
+		sum, modSum string
+	}
+	modules := map[string]*sums{}
+	s := bufio.NewScanner(bytes.NewReader(gosum))
+	for i := 1; s.Scan(); i++ {
+		f := strings.Fields(s.Text())
+		if len(f) == 0 {
+			continue
+		}
+		if len(f) != 3 {
+			return State{}, errors.Errorf("malformed go.sum line %d: %q", i, s.Text())
+		}
+		version, isMod := strings.CutSuffix(f[1], "/go.mod")
+		key := f[0] + "@" + version
+		m, ok := modules[key]
+		if !ok {
+			m = &sums{}
+			modules[key] = m
+		}
+		if isMod {
+			m.modSum = f[2]
+		} else {
+			m.sum = f[2]
+		}
+	}
+	if err := s.Err(); err != nil {
+		return State{}, err
+	}
+
+	addCap(&pi.Constraints, pb.CapSourceGoMod)
+	states := make([]State, 0, len(modules))
+	for _, key := range slices.Sorted(maps.Keys(modules)) {
+		m := modules[key]
+		attrs := map[string]string{}
+		if m.sum != "" {
+			attrs[pb.AttrGoModSum] = m.sum
+		}
+		if m.modSum != "" {
+			attrs[pb.AttrGoModModSum] = m.modSum
+		}
+		if pi.Proxy != "" {
+			attrs[pb.AttrGoModProxy] = pi.Proxy
+		}
+		states = append(states, NewState(NewSource("gomod://"+key, attrs, pi.Constraints).Output()))
+	}
+	return Merge(states), nil
+}
+
+// NPMPackages returns a state with the packages listed in a package-lock.json
+// file (lockfileVersion 2 or later) in the npm cache layout, for use with
+// npm ci --offline. Every package is fetched by a separate source and
+// verified against its integrity, so that changing a dependency only fetches
+// that package.
+func NPMPackages(lock []byte, opts ...PackageOption) (State, error) {
+	pi := &PackageInfo{}
+	for _, o := range opts {
+		o.SetPackageOption(pi)
+	}
+
+	var lf struct {
+		LockfileVersion int `json:"lockfileVersion"`
+		Packages        map[string]struct {
+			Name      string `json:"name"`
+			Version   string `json:"version"`
+			Resolved  string `json:"resolved"`
+			Integrity string `json:"integrity"`
+			Link      bool   `json:"link"`
+		} `json:"packages"`
+	}
+	if err := json.Unmarshal(lock, &lf); err != nil {
+		return State{}, errors.Wrap(err, "failed to parse package-lock.json")
+	}
+	if lf.LockfileVersion < 2 {
+		return State{}, errors.Errorf("unsupported package-lock.json lockfileVersion %d", lf.LockfileVersion)
+	}
+
+	addCap(&pi.Constraints, pb.CapSourceNPM)
+	var states []State
+	seen := map[string]struct{}{}
+	for _, p := range slices.Sorted(maps.Keys(lf.Packages)) {
+		pkg := lf.Packages[p]
+		// the root package and workspace links aren't fetched
+		if p == "" || pkg.Link || pkg.Resolved == "" {
+			continue
+		}
+		if _, ok := seen[pkg.Resolved]; ok {
+			continue
+		}
+		seen[pkg.Resolved] = struct{}{}
+		// git+, file: and other non-registry dependencies can't be fetched
+		// by their integrity
+		if u, err := url.Parse(pkg.Resolved); err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+			return State{}, errors.Errorf("unsupported resolved URL %q for %s, only registry packages are supported", pkg.Resolved, p)
+		}
+		if pkg.Integrity == "" {
+			return State{}, errors.Errorf("missing integrity for %s", p)
+		}
+		name := pkg.Name
+		if name == "" {
+			_, name, _ = strings.Cut(path.Join("/", p), "/node_modules/")
+			if i := strings.LastIndex(name, "/node_modules/"); i >= 0 {
+				name = name[i+len("/node_modules/"):]
+			}
+		}
+		attrs := map[string]string{
+			pb.AttrNPMURL:       pkg.Resolved,
+			pb.AttrNPMIntegrity: pkg.Integrity,
+		}
+		if pi.Proxy != "" {
+			attrs[pb.AttrNPMRegistry] = pi.Proxy
+		}
+		states = append(states, NewState(NewSource("npm://"+name+"@"+pkg.Version, attrs, pi.Constraints).Output()))
+	}
+	return Merge(states), nil
+}
+
+type PackageOption interface {
+	SetPackageOption(*PackageInfo)
+}
+
+type packageOptionFunc func(*PackageInfo)
+
+func (fn packageOptionFunc) SetPackageOption(pi *PackageInfo) {
+	fn(pi)
+}
+
+// PackageProxy sets the Go module proxy for GoModules, or the registry that
+// replaces https://registry.npmjs.org/ in the package URLs for NPMPackages.
+func PackageProxy(url string) PackageOption {
+	return packageOptionFunc(func(pi *PackageInfo) {
+		pi.Proxy = url
+	})
+}
+
+type PackageInfo struct {
+	constraintsWrapper
+	Proxy string
+}
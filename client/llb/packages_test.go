@@ -0,0 +1,112 @@
+package llb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoModules(t *testing.T) {
+	t.Parallel()
+
+	gosum := []byte(`github.com/pkg/errors v0.9.1 h1:FEBLx1zS214owpjy7qsBeixbURkuhQAwrK5UwLGTwt4=
+github.com/pkg/errors v0.9.1/go.mod h1:bwawxfHBFNV+L2hUp1rHADufV3IMtnDRdf1r5NINEl0=
+golang.org/x/mod v0.24.0/go.mod h1:IXM97Txy2VM4PJ3gI61r1YEk/gAj6zAHN3AdZt6S9Ww=
+
+`)
+	st, err := GoModules(gosum, PackageProxy("https://goproxy.example"))
+	require.NoError(t, err)
+
+	sources := packageSources(t, st)
+	require.Len(t, sources, 2)
+
+	src := sources["gomod://github.com/pkg/errors@v0.9.1"]
+	require.NotNil(t, src)
+	require.Equal(t, map[string]string{
+		pb.AttrGoModSum:    "h1:FEBLx1zS214owpjy7qsBeixbURkuhQAwrK5UwLGTwt4=",
+		pb.AttrGoModModSum: "h1:bwawxfHBFNV+L2hUp1rHADufV3IMtnDRdf1r5NINEl0=",
+		pb.AttrGoModProxy:  "https://goproxy.example",
+	}, src.Attrs)
+
+	src = sources["gomod://golang.org/x/mod@v0.24.0"]
+	require.NotNil(t, src)
+	require.Equal(t, map[string]string{
+		pb.AttrGoModModSum: "h1:IXM97Txy2VM4PJ3gI61r1YEk/gAj6zAHN3AdZt6S9Ww=",
+		pb.AttrGoModProxy:  "https://goproxy.example",
+	}, src.Attrs)
+
+	_, err = GoModules([]byte("github.com/pkg/errors v0.9.1\n"))
+	require.ErrorContains(t, err, "malformed go.sum line 1")
+}
+
+func TestNPMPackages(t *testing.T) {
+	t.Parallel()
+
+	lock := []byte(`{
+  "name": "app",
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "app", "version": "1.0.0"},
+    "node_modules/lodash": {
+      "version": "4.17.21",
+      "resolved": "https://registry.npmjs.org/lodash/-/lodash-4.17.21.tgz",
+      "integrity": "sha512-v2kDEe57lecTulaDIuNTPy3Ry4gLGJ6Z1O3vE1krgXZNrsQ+LFTGHVxVjcXPs17LhbZVGedAJv8XZ1tvj5FvSg=="
+    },
+    "node_modules/@babel/core": {
+      "version": "7.0.0",
+      "resolved": "https://registry.npmjs.org/@babel/core/-/core-7.0.0.tgz",
+      "integrity": "sha1-aaaaaaaaaaaaaaaaaaaaaaaaaaa="
+    },
+    "node_modules/foo/node_modules/lodash": {
+      "version": "4.17.21",
+      "resolved": "https://registry.npmjs.org/lodash/-/lodash-4.17.21.tgz",
+      "integrity": "sha512-v2kDEe57lecTulaDIuNTPy3Ry4gLGJ6Z1O3vE1krgXZNrsQ+LFTGHVxVjcXPs17LhbZVGedAJv8XZ1tvj5FvSg=="
+    },
+    "node_modules/workspace": {
+      "resolved": "packages/workspace",
+      "link": true
+    }
+  }
+}`)
+	st, err := NPMPackages(lock, PackageProxy("https://npm.example/"))
+	require.NoError(t, err)
+
+	sources := packageSources(t, st)
+	require.Len(t, sources, 2)
+
+	src := sources["npm://lodash@4.17.21"]
+	require.NotNil(t, src)
+	require.Equal(t, "https://registry.npmjs.org/lodash/-/lodash-4.17.21.tgz", src.Attrs[pb.AttrNPMURL])
+	require.Equal(t, "https://npm.example/", src.Attrs[pb.AttrNPMRegistry])
+
+	src = sources["npm://@babel/core@7.0.0"]
+	require.NotNil(t, src)
+	require.Equal(t, "sha1-aaaaaaaaaaaaaaaaaaaaaaaaaaa=", src.Attrs[pb.AttrNPMIntegrity])
+
+	_, err = NPMPackages([]byte(`{"lockfileVersion": 1, "dependencies": {}}`))
+	require.ErrorContains(t, err, "unsupported package-lock.json lockfileVersion 1")
+
+	_, err = NPMPackages([]byte(`{"lockfileVersion": 2, "packages": {"node_modules/a": {"version": "1.0.0", "resolved": "https://registry.npmjs.org/a/-/a-1.0.0.tgz"}}}`))
+	require.ErrorContains(t, err, "missing integrity")
+
+	for _, resolved := range []string{"git+ssh://git@github.com/a/a.git#abc", "file:../a"} {
+		_, err = NPMPackages([]byte(`{"lockfileVersion": 2, "packages": {"node_modules/a": {"version": "1.0.0", "resolved": "` + resolved + `", "integrity": "sha512-abc"}}}`))
+		require.ErrorContains(t, err, "unsupported resolved URL")
+	}
+}
+
+func packageSources(t *testing.T, st State) map[string]*pb.SourceOp {
+	def, err := st.Marshal(context.TODO())
+	require.NoError(t, err)
+
+	_, ops := parseDef(t, def.Def)
+	sources := map[string]*pb.SourceOp{}
+	for _, op := range ops {
+		if src := op.GetSource(); src != nil {
+			sources[src.Identifier] = src
+		}
+	}
+	return sources
+}
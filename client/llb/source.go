@@ -591,6 +591,31 @@ type OCILayoutInfo struct {
 	layerLimit *int
 }
 
+// OCIArtifact returns a state with the blobs of an OCI artifact, for example
+// a Helm chart or a WASM module, as files. The files are named by the
+// org.opencontainers.image.title annotation of the blobs, or by the encoded
+// digest if it isn't set. If ref points to an index, the manifest is selected
+// by the platform of the state.
+func OCIArtifact(ref string, opts ...OCIArtifactOption) State {
+	ai := &OCIArtifactInfo{}
+	for _, o := range opts {
+		o.SetOCIArtifactOption(ai)
+	}
+
+	addCap(&ai.Constraints, pb.CapSourceOCIArtifact)
+
+	source := NewSource("oci-artifact://"+ref, map[string]string{}, ai.Constraints)
+	return NewState(source.Output())
+}
+
+type OCIArtifactOption interface {
+	SetOCIArtifactOption(*OCIArtifactInfo)
+}
+
+type OCIArtifactInfo struct {
+	constraintsWrapper
+}
+
 type DiffType string
 
 const (
@@ -653,6 +678,10 @@ func HTTP(url string, opts ...HTTPOption) State {
 		hi.Header.setAttrs(attrs)
 		addCap(&hi.Constraints, pb.CapSourceHTTPHeader)
 	}
+	if len(hi.Mirrors) > 0 {
+		attrs[pb.AttrHTTPMirrors] = strings.Join(hi.Mirrors, "\n")
+		addCap(&hi.Constraints, pb.CapSourceHTTPMirrors)
+	}
 
 	addCap(&hi.Constraints, pb.CapSourceHTTP)
 	source := NewSource(url, attrs, hi.Constraints)
@@ -668,6 +697,7 @@ type HTTPInfo struct {
 	GID              int
 	AuthHeaderSecret string
 	Header           *HTTPHeader
+	Mirrors          []string
 }
 
 type HTTPOption interface {
@@ -713,6 +743,15 @@ func Header(header HTTPHeader) HTTPOption {
 	})
 }
 
+// Mirrors returns an [HTTPOption] that sets URLs to fetch the same file from
+// if the source URL can't be fetched. They are tried in order. Use [Checksum]
+// to verify the content served by the mirrors.
+func Mirrors(urls ...string) HTTPOption {
+	return httpOptionFunc(func(hi *HTTPInfo) {
+		hi.Mirrors = append(hi.Mirrors, urls...)
+	})
+}
+
 type HTTPHeader struct {
 	Accept    string
 	UserAgent string
@@ -733,7 +772,7 @@ func (hh *HTTPHeader) attr(name string) string {
 }
 
 func platformSpecificSource(id string) bool {
-	return strings.HasPrefix(id, "docker-image://") || strings.HasPrefix(id, "oci-layout://")
+	return strings.HasPrefix(id, "docker-image://") || strings.HasPrefix(id, "oci-layout://") || strings.HasPrefix(id, "oci-artifact://")
 }
 
 func addCap(c *Constraints, id apicaps.CapID) {
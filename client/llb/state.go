@@ -486,6 +486,38 @@ func (s State) WithCgroupParent(cp string) State {
 	return cgroupParent(cp)(s)
 }
 
+// WithNetworkAccessLog requests recording the DNS lookups and connections made
+// by any containers created from this state. The log is only recorded if the
+// worker has the network access log enabled.
+func (s State) WithNetworkAccessLog() State {
+	return networkAccessLog(true)(s)
+}
+
+// WithMemoryLimit sets the memory limit in bytes for any containers created from this state.
+// Resource limits are Linux specific and only applies to containers created from this state such as via `[State.Run]`
+func (s State) WithMemoryLimit(bytes int64) State {
+	return resourceLimits(func(rl *pb.ResourceLimits) {
+		rl.Memory = bytes
+	})(s)
+}
+
+// WithCPULimit sets the number of CPUs any containers created from this state can use.
+// Fractional values are allowed, e.g. 1.5 allows using one and a half CPUs.
+// Resource limits are Linux specific and only applies to containers created from this state such as via `[State.Run]`
+func (s State) WithCPULimit(cpus float64) State {
+	return resourceLimits(func(rl *pb.ResourceLimits) {
+		rl.NanoCPUs = int64(cpus * 1e9)
+	})(s)
+}
+
+// WithPIDsLimit sets the maximum number of processes for any containers created from this state.
+// Resource limits are Linux specific and only applies to containers created from this state such as via `[State.Run]`
+func (s State) WithPIDsLimit(pids int64) State {
+	return resourceLimits(func(rl *pb.ResourceLimits) {
+		rl.Pids = pids
+	})(s)
+}
+
 func (s State) isFileOpCopyInput() {}
 
 type output struct {
@@ -530,6 +562,8 @@ type ConstraintsOpt interface {
 	ImageOption
 	GitOption
 	OCILayoutOption
+	OCIArtifactOption
+	PackageOption
 }
 
 type constraintsOptFunc func(m *Constraints)
@@ -550,6 +584,14 @@ func (fn constraintsOptFunc) SetOCILayoutOption(oi *OCILayoutInfo) {
 	oi.applyConstraints(fn)
 }
 
+func (fn constraintsOptFunc) SetOCIArtifactOption(ai *OCIArtifactInfo) {
+	ai.applyConstraints(fn)
+}
+
+func (fn constraintsOptFunc) SetPackageOption(pi *PackageInfo) {
+	pi.applyConstraints(fn)
+}
+
 func (fn constraintsOptFunc) SetHTTPOption(hi *HTTPInfo) {
 	hi.applyConstraints(fn)
 }
@@ -231,6 +231,13 @@ func insertDesc(index *ocispecs.Index, in ocispecs.Descriptor, name *NameOrTag)
 			}
 		}
 		index.Manifests = manifests
+	} else {
+		// unnamed descriptors, e.g. referrers, are only added once
+		for _, m := range index.Manifests {
+			if m.Digest == desc.Digest && m.Annotations[ocispecs.AnnotationRefName] == "" {
+				return nil
+			}
+		}
 	}
 	index.Manifests = append(index.Manifests, desc)
 	return nil
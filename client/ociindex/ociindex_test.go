@@ -127,6 +127,14 @@ func TestAddDescriptor(t *testing.T) {
 	// store.Put also sets defaults for MediaType and SchemaVersion
 	assert.Equal(t, ocispecs.MediaTypeImageIndex, readIdx.MediaType)
 	assert.Equal(t, 2, readIdx.SchemaVersion)
+
+	// adding the same unnamed descriptor again is a no-op
+	err = store.Put(three)
+	require.NoError(t, err)
+
+	readIdx, err = store.Read()
+	require.NoError(t, err)
+	assert.Len(t, readIdx.Manifests, 3)
 }
 
 func TestAddDescriptorWithTag(t *testing.T) {
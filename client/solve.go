@@ -8,6 +8,7 @@ import (
 	"maps"
 	"os"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -159,7 +160,7 @@ func (c *Client) solve(ctx context.Context, def *llb.Definition, runGateway runG
 			switch ex.Type {
 			case ExporterLocal:
 				supportDir = true
-			case ExporterTar:
+			case ExporterTar, ExporterSquashFS, ExporterEROFS, ExporterExt4:
 				supportFile = true
 			case ExporterOCI, ExporterDocker:
 				supportFile = ex.Output != nil
@@ -187,7 +188,14 @@ func (c *Client) solve(ctx context.Context, def *llb.Definition, runGateway runG
 				if ex.OutputDir == "" {
 					return nil, errors.Errorf("output directory is required for %s exporter", ex.Type)
 				}
-				syncTargets = append(syncTargets, filesync.WithFSSyncDir(exID, ex.OutputDir))
+				if ex.Attrs["mode"] == "sync" {
+					// only allow the exporter to remove files if the user
+					// asked for it
+					prune, _ := strconv.ParseBool(ex.Attrs["prune"])
+					syncTargets = append(syncTargets, filesync.WithFSSyncDirSync(exID, ex.OutputDir, prune))
+				} else {
+					syncTargets = append(syncTargets, filesync.WithFSSyncDir(exID, ex.OutputDir))
+				}
 			}
 			if supportStore {
 				store := ex.OutputStore
@@ -384,6 +392,24 @@ func (c *Client) solve(ctx context.Context, def *llb.Definition, runGateway runG
 			}
 		}
 	}
+	if referrersDt := res.ExporterResponse[exptypes.ExporterImageReferrersKey]; referrersDt != "" {
+		referrersDt, err := base64.StdEncoding.DecodeString(referrersDt)
+		if err != nil {
+			return nil, err
+		}
+		var referrers []ocispecs.Descriptor
+		if err = json.Unmarshal(referrersDt, &referrers); err != nil {
+			return nil, err
+		}
+		for _, storePath := range storesToUpdate {
+			idx := ociindex.NewStoreIndex(storePath)
+			for _, desc := range referrers {
+				if err := idx.Put(desc); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
 	return res, nil
 }
 
@@ -27,6 +27,7 @@ func NewSolveStatus(resp *controlapi.StatusResponse) *SolveStatus {
 			Error:         v.Error,
 			Cached:        v.Cached,
 			ProgressGroup: v.ProgressGroup,
+			ExitReason:    v.ExitReason,
 		})
 	}
 	for _, v := range resp.Statuses {
@@ -60,6 +61,21 @@ func NewSolveStatus(resp *controlapi.StatusResponse) *SolveStatus {
 			Range:      v.Ranges,
 		})
 	}
+	for _, v := range resp.Stats {
+		s.Stats = append(s.Stats, &VertexStats{
+			ID:              v.ID,
+			Vertex:          digest.Digest(v.Vertex),
+			Timestamp:       v.Timestamp.AsTime(),
+			CPUNanos:        v.CpuNanos,
+			MemoryBytes:     v.MemoryBytes,
+			MemoryPeakBytes: v.MemoryPeakBytes,
+			IOReadBytes:     v.IoReadBytes,
+			IOWriteBytes:    v.IoWriteBytes,
+			PIDs:            v.Pids,
+			NetRxBytes:      v.NetRxBytes,
+			NetTxBytes:      v.NetTxBytes,
+		})
+	}
 	return s
 }
 
@@ -78,6 +94,7 @@ func (ss *SolveStatus) Marshal() (out []*controlapi.StatusResponse) {
 				Error:         v.Error,
 				Cached:        v.Cached,
 				ProgressGroup: v.ProgressGroup,
+				ExitReason:    v.ExitReason,
 			})
 		}
 		for _, v := range ss.Statuses {
@@ -120,6 +137,21 @@ func (ss *SolveStatus) Marshal() (out []*controlapi.StatusResponse) {
 				Url:    v.URL,
 			})
 		}
+		for _, v := range ss.Stats {
+			sr.Stats = append(sr.Stats, &controlapi.VertexStats{
+				ID:              v.ID,
+				Vertex:          string(v.Vertex),
+				Timestamp:       timestamppb.New(v.Timestamp),
+				CpuNanos:        v.CPUNanos,
+				MemoryBytes:     v.MemoryBytes,
+				MemoryPeakBytes: v.MemoryPeakBytes,
+				IoReadBytes:     v.IOReadBytes,
+				IoWriteBytes:    v.IOWriteBytes,
+				Pids:            v.PIDs,
+				NetRxBytes:      v.NetRxBytes,
+				NetTxBytes:      v.NetTxBytes,
+			})
+		}
 		out = append(out, &sr)
 		if !retry {
 			break
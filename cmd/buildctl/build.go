@@ -6,7 +6,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -111,6 +114,22 @@ var buildCommand = cli.Command{
 			Name:  "registry-auth-tlscontext",
 			Usage: "Overwrite TLS configuration when authenticating with registries, e.g. --registry-auth-tlscontext host=https://myserver:2376,insecure=false,ca=/path/to/my/ca.crt,cert=/path/to/my/cert.crt,key=/path/to/my/key.crt",
 		},
+		cli.StringFlag{
+			Name:  "signing-key",
+			Usage: "Sign attestations and images with a PEM encoded private key kept on the client, or \"ephemeral\" to generate one",
+		},
+		cli.StringFlag{
+			Name:  "on-error",
+			Usage: "Action when a build step fails: shell opens an interactive shell in the failed step, keep keeps the failed step's container until interrupted",
+		},
+		cli.BoolFlag{
+			Name:  "check-reproducible",
+			Usage: "Build a second time with cache disabled and fail if the exported images differ",
+		},
+		cli.StringFlag{
+			Name:  "reproducibility-report",
+			Usage: "Write the result of --check-reproducible as JSON to a file",
+		},
 		cli.StringFlag{
 			Name:  "debug-json-cache-metrics",
 			Usage: "Where to output json cache metrics, use 'stdout' or 'stderr' for standard (error) output.",
@@ -124,20 +143,31 @@ func read(r io.Reader, clicontext *cli.Context) (*llb.Definition, error) {
 		return nil, errors.Wrap(err, "failed to parse input")
 	}
 	if clicontext.Bool("no-cache") {
-		for _, dt := range def.Def {
-			var op pb.Op
-			if err := op.UnmarshalVT(dt); err != nil {
-				return nil, errors.Wrap(err, "failed to parse llb proto op")
-			}
-			dgst := digest.FromBytes(dt)
-			c := llb.Constraints{Metadata: def.Metadata[dgst]}
-			llb.IgnoreCache(&c)
-			def.Metadata[dgst] = c.Metadata
+		if err := ignoreCache(def); err != nil {
+			return nil, err
 		}
 	}
 	return def, nil
 }
 
+// maxReproducibleWarnings is the number of differences found by
+// --check-reproducible that are printed, the report file has all of them.
+const maxReproducibleWarnings = 10
+
+func ignoreCache(def *llb.Definition) error {
+	for _, dt := range def.Def {
+		var op pb.Op
+		if err := op.UnmarshalVT(dt); err != nil {
+			return errors.Wrap(err, "failed to parse llb proto op")
+		}
+		dgst := digest.FromBytes(dt)
+		c := llb.Constraints{Metadata: def.Metadata[dgst]}
+		llb.IgnoreCache(&c)
+		def.Metadata[dgst] = c.Metadata
+	}
+	return nil
+}
+
 func openTraceFile(clicontext *cli.Context) (*os.File, error) {
 	if traceFileName := clicontext.String("trace"); traceFileName != "" {
 		return os.OpenFile(traceFileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
@@ -213,10 +243,39 @@ func buildAction(clicontext *cli.Context) error {
 		attachable = append(attachable, secretProvider)
 	}
 
+	if key := clicontext.String("signing-key"); key != "" {
+		sp, err := build.ParseSigningKey(key, os.Stderr)
+		if err != nil {
+			return err
+		}
+		attachable = append(attachable, sp)
+	}
+
 	if err := build.ValidateAllow(clicontext.StringSlice("allow")); err != nil {
 		return err
 	}
 
+	onError, err := build.ParseOnError(clicontext.String("on-error"))
+	if err != nil {
+		return err
+	}
+
+	breakpoints, err := build.ParseBreakpoints(clicontext.StringSlice("break"), clicontext.StringSlice("break-after"))
+	if err != nil {
+		return err
+	}
+
+	progressMode := clicontext.String("progress")
+	if onError == build.OnErrorShell || len(breakpoints) > 0 {
+		switch progressMode {
+		case "auto":
+			// tty progress would redraw over the interactive shell
+			progressMode = "plain"
+		case "tty":
+			return errors.Errorf("interactive debugging can't be used with tty progress")
+		}
+	}
+
 	exports, err := build.ParseOutput(clicontext.StringSlice("output"))
 	if err != nil {
 		return err
@@ -293,6 +352,42 @@ func buildAction(clicontext *cli.Context) error {
 		solveOpt.FrontendAttrs["no-cache"] = ""
 	}
 
+	var reproducibleOpt *client.SolveOpt
+	var reproducibleDef *llb.Definition
+	var reproducibleDir string
+	if clicontext.Bool("check-reproducible") {
+		if _, ok := solveOpt.FrontendAttrs["requestid"]; ok {
+			return errors.New("--check-reproducible can't be used with frontend subrequests")
+		}
+		reproducibleDir, err = os.MkdirTemp("", "buildctl-reproducible-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(reproducibleDir)
+
+		// both builds are exported as OCI layouts with the options of the
+		// image export so that the layers can be compared
+		opt := solveOpt
+		opt.Ref = identity.NewID()
+		opt.Exports = []client.ExportEntry{build.ReproducibleExport(exports, filepath.Join(reproducibleDir, "b"))}
+		opt.CacheExports = nil
+		opt.FrontendAttrs = maps.Clone(solveOpt.FrontendAttrs)
+		if def != nil {
+			reproducibleDef = &llb.Definition{
+				Def:      def.Def,
+				Metadata: maps.Clone(def.Metadata),
+				Source:   def.Source,
+			}
+			if err := ignoreCache(reproducibleDef); err != nil {
+				return err
+			}
+		} else {
+			opt.FrontendAttrs["no-cache"] = ""
+		}
+		reproducibleOpt = &opt
+		solveOpt.Exports = append(slices.Clone(solveOpt.Exports), build.ReproducibleExport(exports, filepath.Join(reproducibleDir, "a")))
+	}
+
 	refFile := clicontext.String("ref-file")
 	if refFile != "" {
 		defer func() {
@@ -301,7 +396,7 @@ func buildAction(clicontext *cli.Context) error {
 	}
 
 	// not using shared context to not disrupt display but let is finish reporting errors
-	pw, err := progresswriter.NewPrinter(context.TODO(), os.Stderr, clicontext.String("progress"))
+	pw, err := progresswriter.NewPrinter(context.TODO(), os.Stderr, progressMode)
 	if err != nil {
 		return err
 	}
@@ -351,17 +446,29 @@ func buildAction(clicontext *cli.Context) error {
 	}
 
 	var subMetadata map[string][]byte
+	var reproducibleReport *build.ReproducibilityReport
+	var reproducibleStatus chan *client.SolveStatus
+	if reproducibleOpt != nil {
+		reproducibleStatus = mw.WithPrefix("reproducibility check", true).Status()
+	}
 
 	eg.Go(func() error {
 		defer func() {
 			for _, w := range writers {
 				close(w.Status())
 			}
+			if reproducibleStatus != nil {
+				close(reproducibleStatus)
+			}
 		}()
 
 		sreq := gateway.SolveRequest{
 			Frontend:    solveOpt.Frontend,
 			FrontendOpt: solveOpt.FrontendAttrs,
+			// failed steps are only reported with their state when the
+			// result is evaluated as part of the solve request. With
+			// breakpoints the debugger evaluates the result step by step.
+			Evaluate: onError != "" && len(breakpoints) == 0,
 		}
 
 		sreq.CacheImports = make([]frontend.CacheOptionsEntry, len(solveOpt.CacheImports))
@@ -383,7 +490,15 @@ func buildAction(clicontext *cli.Context) error {
 				}
 			}
 			res, err := c.Solve(ctx, sreq)
+			if err == nil && len(breakpoints) > 0 {
+				err = build.NewDebugger(c, breakpoints).Run(ctx, res)
+			}
 			if err != nil {
+				if onError != "" {
+					if err1 := build.DebugOnError(ctx, c, err, onError); err1 != nil {
+						bklog.G(ctx).Warnf("failed to debug failed step: %v", err1)
+					}
+				}
 				return nil, err
 			}
 			if isSubRequest && res != nil {
@@ -405,6 +520,28 @@ func buildAction(clicontext *cli.Context) error {
 			}
 		}
 
+		if reproducibleOpt != nil {
+			// the status channel is closed by Solve
+			ch := reproducibleStatus
+			reproducibleStatus = nil
+			if _, err := c.Solve(ctx, reproducibleDef, *reproducibleOpt, ch); err != nil {
+				return errors.Wrap(err, "failed to rebuild for reproducibility check")
+			}
+			reproducibleReport, err = build.CheckReproducible(ctx, filepath.Join(reproducibleDir, "a"), filepath.Join(reproducibleDir, "b"))
+			if err != nil {
+				return errors.Wrap(err, "failed to compare build results")
+			}
+			if reportFile := clicontext.String("reproducibility-report"); reportFile != "" {
+				dt, err := json.MarshalIndent(reproducibleReport, "", "  ")
+				if err != nil {
+					return err
+				}
+				if err := os.WriteFile(reportFile, dt, 0644); err != nil {
+					return err
+				}
+			}
+		}
+
 		return nil
 	})
 
@@ -429,6 +566,17 @@ func buildAction(clicontext *cli.Context) error {
 
 	meg.Wait()
 
+	if reproducibleReport != nil && !reproducibleReport.Reproducible {
+		for i, d := range reproducibleReport.Differences {
+			if i == maxReproducibleWarnings {
+				fmt.Fprintf(os.Stderr, "WARNING: %d more differences\n", len(reproducibleReport.Differences)-i)
+				break
+			}
+			fmt.Fprintf(os.Stderr, "WARNING: %s\n", d)
+		}
+		return errors.Errorf("build is not reproducible: %s", reproducibleReport.Differences[0])
+	}
+
 	return nil
 }
 
@@ -0,0 +1,407 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/moby/buildkit/client/llb"
+	gateway "github.com/moby/buildkit/frontend/gateway/client"
+	"github.com/moby/buildkit/solver/pb"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+)
+
+// Breakpoint pauses a build before or after a matching vertex.
+type Breakpoint struct {
+	// Filename and Line match vertices by their source location, e.g.
+	// Dockerfile:42.
+	Filename string
+	Line     int32
+	// Digest matches a vertex by its LLB digest.
+	Digest digest.Digest
+	// After pauses after the vertex has been solved instead of before.
+	After bool
+}
+
+// ParseBreakpoints parses --break and --break-after
+func ParseBreakpoints(before, after []string) ([]Breakpoint, error) {
+	var bps []Breakpoint
+	for _, v := range before {
+		bp, err := parseBreakpoint(v)
+		if err != nil {
+			return nil, err
+		}
+		bps = append(bps, *bp)
+	}
+	for _, v := range after {
+		bp, err := parseBreakpoint(v)
+		if err != nil {
+			return nil, err
+		}
+		bp.After = true
+		bps = append(bps, *bp)
+	}
+	return bps, nil
+}
+
+func parseBreakpoint(v string) (*Breakpoint, error) {
+	if dgst, err := digest.Parse(v); err == nil {
+		return &Breakpoint{Digest: dgst}, nil
+	}
+	idx := strings.LastIndex(v, ":")
+	if idx <= 0 {
+		return nil, errors.Errorf("invalid breakpoint %q, expected <file>:<line> or a vertex digest", v)
+	}
+	line, err := strconv.ParseInt(v[idx+1:], 10, 32)
+	if err != nil || line <= 0 {
+		return nil, errors.Errorf("invalid line in breakpoint %q", v)
+	}
+	return &Breakpoint{Filename: v[:idx], Line: int32(line)}, nil
+}
+
+// Debugger steps through the LLB graph of a build result and pauses on
+// breakpoints so that the filesystem of the build can be inspected. The
+// debugger runs on the client: the frontend returns the result unevaluated
+// and the debugger solves it one vertex at a time, so the steps after a
+// breakpoint only run once the build is continued. Steps that the frontend
+// evaluated itself, e.g. to read files, have already run.
+type Debugger struct {
+	c           gateway.Client
+	breakpoints []Breakpoint
+	in          io.Reader
+	out         io.Writer
+
+	def    *llb.Definition
+	ops    map[digest.Digest]*pb.Op
+	step   bool
+	paused map[digest.Digest]struct{}
+}
+
+func NewDebugger(c gateway.Client, breakpoints []Breakpoint) *Debugger {
+	return &Debugger{
+		c:           c,
+		breakpoints: breakpoints,
+		in:          os.Stdin,
+		out:         os.Stderr,
+	}
+}
+
+// Run walks the vertices of res in the order they would be solved and pauses
+// on every vertex that matches a breakpoint. The refs of a multi-platform
+// result are walked one after another, vertices shared between platforms
+// only pause once.
+func (d *Debugger) Run(ctx context.Context, res *gateway.Result) error {
+	d.paused = map[digest.Digest]struct{}{}
+	if res.Ref != nil {
+		return d.run(ctx, res.Ref)
+	}
+	for _, k := range slices.Sorted(maps.Keys(res.Refs)) {
+		if res.Refs[k] == nil {
+			continue
+		}
+		fmt.Fprintf(d.out, "\nDebugging %s\n", k)
+		if err := d.run(ctx, res.Refs[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Debugger) run(ctx context.Context, ref gateway.Reference) error {
+	st, err := ref.ToState()
+	if err != nil {
+		return err
+	}
+	def, err := st.Marshal(ctx)
+	if err != nil {
+		return err
+	}
+	if len(def.Def) == 0 {
+		return nil
+	}
+	d.def = def
+	d.ops = make(map[digest.Digest]*pb.Op, len(def.Def))
+	for _, dt := range def.Def {
+		var op pb.Op
+		if err := op.UnmarshalVT(dt); err != nil {
+			return errors.Wrap(err, "failed to parse llb proto op")
+		}
+		d.ops[digest.FromBytes(dt)] = &op
+	}
+
+	var terminal pb.Op
+	if err := terminal.UnmarshalVT(def.Def[len(def.Def)-1]); err != nil {
+		return errors.Wrap(err, "failed to parse llb proto op")
+	}
+	var order []digest.Digest
+	for _, inp := range terminal.Inputs {
+		order = d.walk(digest.Digest(inp.Digest), order, map[digest.Digest]struct{}{})
+	}
+
+	for _, dgst := range order {
+		if _, ok := d.paused[dgst]; ok {
+			continue
+		}
+		d.paused[dgst] = struct{}{}
+		if d.step || d.matches(dgst, false) {
+			if err := d.pause(ctx, dgst, false); err != nil {
+				return err
+			}
+		}
+		if d.matches(dgst, true) {
+			if err := d.pause(ctx, dgst, true); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (d *Debugger) walk(dgst digest.Digest, order []digest.Digest, visited map[digest.Digest]struct{}) []digest.Digest {
+	if _, ok := visited[dgst]; ok {
+		return order
+	}
+	visited[dgst] = struct{}{}
+	op, ok := d.ops[dgst]
+	if !ok {
+		return order
+	}
+	for _, inp := range op.Inputs {
+		order = d.walk(digest.Digest(inp.Digest), order, visited)
+	}
+	return append(order, dgst)
+}
+
+func (d *Debugger) matches(dgst digest.Digest, after bool) bool {
+	for _, bp := range d.breakpoints {
+		if bp.After != after {
+			continue
+		}
+		if bp.Digest != "" {
+			if bp.Digest == dgst {
+				return true
+			}
+			continue
+		}
+		for _, loc := range d.locations(dgst) {
+			if loc.filename != bp.Filename && filepath.Base(loc.filename) != bp.Filename {
+				continue
+			}
+			if bp.Line >= loc.start && bp.Line <= loc.end {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type sourceLocation struct {
+	filename   string
+	start, end int32
+}
+
+func (l sourceLocation) String() string {
+	if l.start == l.end {
+		return fmt.Sprintf("%s:%d", l.filename, l.start)
+	}
+	return fmt.Sprintf("%s:%d-%d", l.filename, l.start, l.end)
+}
+
+func (d *Debugger) locations(dgst digest.Digest) []sourceLocation {
+	if d.def.Source == nil {
+		return nil
+	}
+	locs, ok := d.def.Source.Locations[dgst.String()]
+	if !ok {
+		return nil
+	}
+	var out []sourceLocation
+	for _, loc := range locs.Locations {
+		if loc.SourceIndex < 0 || int(loc.SourceIndex) >= len(d.def.Source.Infos) {
+			continue
+		}
+		filename := d.def.Source.Infos[loc.SourceIndex].Filename
+		for _, r := range loc.Ranges {
+			out = append(out, sourceLocation{filename: filename, start: r.Start.Line, end: r.End.Line})
+		}
+	}
+	return out
+}
+
+func (d *Debugger) name(dgst digest.Digest) string {
+	if name, ok := d.def.Metadata[dgst].Description["llb.customname"]; ok {
+		return name
+	}
+	return dgst.String()
+}
+
+func (d *Debugger) pause(ctx context.Context, dgst digest.Digest, after bool) error {
+	d.step = false
+
+	// solve everything up to the breakpoint so that the build is actually
+	// paused at this point
+	var refs []gateway.Reference
+	if after {
+		ref, err := d.solve(ctx, dgst, 0)
+		if err != nil {
+			return err
+		}
+		refs = append(refs, ref)
+	} else {
+		for _, inp := range d.ops[dgst].Inputs {
+			ref, err := d.solve(ctx, digest.Digest(inp.Digest), inp.Index)
+			if err != nil {
+				return err
+			}
+			refs = append(refs, ref)
+		}
+	}
+
+	where := "before"
+	if after {
+		where = "after"
+	}
+	fmt.Fprintf(d.out, "\nPaused %s %s\n", where, d.name(dgst))
+	for _, loc := range d.locations(dgst) {
+		fmt.Fprintf(d.out, "  at %s\n", loc)
+	}
+
+	for {
+		fmt.Fprint(d.out, "(c)ontinue, (s)tep, s(h)ell, (a)bort> ")
+		line, err := d.readLine()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		switch line {
+		case "c", "continue":
+			return nil
+		case "s", "step":
+			d.step = true
+			return nil
+		case "h", "shell":
+			if err := d.shell(ctx, dgst, refs, after); err != nil {
+				fmt.Fprintf(d.out, "shell failed: %v\n", err)
+			}
+		case "a", "abort":
+			return errors.Errorf("build aborted at %s", d.name(dgst))
+		}
+	}
+}
+
+// readLine reads a command from the prompt. Stdin is read a byte at a time
+// instead of through a buffered reader so that nothing typed after the
+// command is consumed before the shell takes over the terminal.
+func (d *Debugger) readLine() (string, error) {
+	var line []byte
+	b := make([]byte, 1)
+	for {
+		n, err := d.in.Read(b)
+		if n > 0 {
+			if b[0] == '\n' {
+				return strings.TrimSpace(string(line)), nil
+			}
+			line = append(line, b[0])
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) && len(line) > 0 {
+				return strings.TrimSpace(string(line)), nil
+			}
+			return "", err
+		}
+	}
+}
+
+// solve evaluates output index of vertex dgst.
+func (d *Debugger) solve(ctx context.Context, dgst digest.Digest, index int64) (gateway.Reference, error) {
+	terminal, err := (&pb.Op{Inputs: []*pb.Input{{Digest: string(dgst), Index: index}}}).MarshalVT()
+	if err != nil {
+		return nil, err
+	}
+	sub := &llb.Definition{
+		Metadata: map[digest.Digest]llb.OpMetadata{},
+		Source:   d.def.Source,
+	}
+	for _, dt := range d.def.Def[:len(d.def.Def)-1] {
+		opDgst := digest.FromBytes(dt)
+		sub.Def = append(sub.Def, dt)
+		if md, ok := d.def.Metadata[opDgst]; ok {
+			sub.Metadata[opDgst] = md
+		}
+	}
+	sub.Def = append(sub.Def, terminal)
+
+	res, err := d.c.Solve(ctx, gateway.SolveRequest{
+		Definition: sub.ToPB(),
+		Evaluate:   true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	// a definition always solves to a single ref
+	return res.Ref, nil
+}
+
+func (d *Debugger) shell(ctx context.Context, dgst digest.Digest, refs []gateway.Reference, after bool) error {
+	op := d.ops[dgst]
+	var meta *pb.Meta
+	var security pb.SecurityMode
+	var mounts []gateway.Mount
+	req := gateway.NewContainerRequest{
+		Platform:    op.Platform,
+		Constraints: op.Constraints,
+	}
+
+	if exec := op.GetExec(); exec != nil {
+		meta = exec.Meta
+		security = exec.Security
+		req.Hostname = exec.Meta.Hostname
+		req.NetMode = exec.Network
+		req.ExtraHosts = exec.Meta.ExtraHosts
+		if !after {
+			for _, mnt := range exec.Mounts {
+				m := gateway.Mount{
+					Selector:  mnt.Selector,
+					Dest:      mnt.Dest,
+					Readonly:  mnt.Readonly,
+					MountType: mnt.MountType,
+					CacheOpt:  mnt.CacheOpt,
+					SecretOpt: mnt.SecretOpt,
+					SSHOpt:    mnt.SSHOpt,
+				}
+				if mnt.Input >= 0 && int(mnt.Input) < len(refs) {
+					m.Ref = refs[mnt.Input]
+				}
+				mounts = append(mounts, m)
+			}
+		}
+	}
+	if mounts == nil {
+		if len(refs) == 0 || refs[0] == nil {
+			return errors.New("no filesystem to inspect")
+		}
+		mounts = []gateway.Mount{{
+			Dest:      "/",
+			Ref:       refs[0],
+			MountType: pb.MountType_BIND,
+		}}
+	}
+	req.Mounts = mounts
+
+	ctr, err := d.c.NewContainer(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer ctr.Release(context.WithoutCancel(ctx))
+
+	return runShell(ctx, ctr, meta, security)
+}
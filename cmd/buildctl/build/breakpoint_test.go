@@ -0,0 +1,155 @@
+package build
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/moby/buildkit/client/llb"
+	gateway "github.com/moby/buildkit/frontend/gateway/client"
+	"github.com/moby/buildkit/solver/pb"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBreakpoints(t *testing.T) {
+	dgst := "sha256:2f2a3b2e1e6c1e5b5e6a9a2d1e5b7e8c9f0a1b2c3d4e5f60718293a4b5c6d7e8"
+	bps, err := ParseBreakpoints([]string{"Dockerfile:42", dgst}, []string{"sub/dir/Dockerfile.dev:7"})
+	require.NoError(t, err)
+	require.Equal(t, []Breakpoint{
+		{Filename: "Dockerfile", Line: 42},
+		{Digest: "sha256:2f2a3b2e1e6c1e5b5e6a9a2d1e5b7e8c9f0a1b2c3d4e5f60718293a4b5c6d7e8"},
+		{Filename: "sub/dir/Dockerfile.dev", Line: 7, After: true},
+	}, bps)
+
+	_, err = ParseBreakpoints([]string{"Dockerfile"}, nil)
+	require.ErrorContains(t, err, "invalid breakpoint")
+
+	_, err = ParseBreakpoints(nil, []string{"Dockerfile:0"})
+	require.ErrorContains(t, err, "invalid line")
+}
+
+func TestDebuggerBreakpoints(t *testing.T) {
+	ctx := context.TODO()
+	sm := llb.NewSourceMap(nil, "Dockerfile", "", nil)
+	mkdir := llb.Scratch().File(llb.Mkdir("/a", 0755), sm.Location(lines(1)), llb.WithCustomName("mkdir a"))
+	mkfile := mkdir.File(llb.Mkfile("/a/b", 0644, nil), sm.Location(lines(2)), llb.WithCustomName("mkfile b"))
+	res := &gateway.Result{Ref: &testRef{st: mkfile}}
+
+	// pausing before a step only solves its inputs
+	c := &testClient{}
+	out, err := runDebugger(ctx, c, res, "c\n", Breakpoint{Filename: "Dockerfile", Line: 2})
+	require.NoError(t, err)
+	require.Equal(t, 1, strings.Count(out, "Paused"))
+	require.Contains(t, out, "Paused before mkfile b\n  at Dockerfile:2\n")
+	require.Equal(t, []digest.Digest{headDigest(ctx, t, mkdir)}, c.solved)
+
+	// pausing after a step solves the step, stepping pauses on the next one
+	c = &testClient{}
+	out, err = runDebugger(ctx, c, res, "s\nc\n", Breakpoint{Filename: "Dockerfile", Line: 1, After: true})
+	require.NoError(t, err)
+	require.Equal(t, 2, strings.Count(out, "Paused"))
+	require.Less(t, strings.Index(out, "Paused after mkdir a"), strings.Index(out, "Paused before mkfile b"))
+	require.Equal(t, []digest.Digest{headDigest(ctx, t, mkdir), headDigest(ctx, t, mkdir)}, c.solved)
+
+	// breakpoints can be set by digest
+	c = &testClient{}
+	out, err = runDebugger(ctx, c, res, "c\n", Breakpoint{Digest: headDigest(ctx, t, mkfile)})
+	require.NoError(t, err)
+	require.Contains(t, out, "Paused before mkfile b")
+
+	// steps that don't match are not paused on
+	c = &testClient{}
+	out, err = runDebugger(ctx, c, res, "", Breakpoint{Filename: "Dockerfile.other", Line: 1})
+	require.NoError(t, err)
+	require.NotContains(t, out, "Paused")
+	require.Empty(t, c.solved)
+
+	_, err = runDebugger(ctx, &testClient{}, res, "a\n", Breakpoint{Filename: "Dockerfile", Line: 1})
+	require.ErrorContains(t, err, "build aborted at mkdir a")
+}
+
+func TestDebuggerMultiPlatform(t *testing.T) {
+	ctx := context.TODO()
+	sm := llb.NewSourceMap(nil, "Dockerfile", "", nil)
+	mkdir := llb.Scratch().File(llb.Mkdir("/a", 0755), sm.Location(lines(1)), llb.WithCustomName("mkdir a"))
+	amd64 := mkdir.File(llb.Mkfile("/a/amd64", 0644, nil), sm.Location(lines(2)), llb.WithCustomName("mkfile amd64"))
+	arm64 := mkdir.File(llb.Mkfile("/a/arm64", 0644, nil), sm.Location(lines(2)), llb.WithCustomName("mkfile arm64"))
+	res := &gateway.Result{Refs: map[string]gateway.Reference{
+		"linux/amd64": &testRef{st: amd64},
+		"linux/arm64": &testRef{st: arm64},
+	}}
+
+	// the shared step only pauses once
+	c := &testClient{}
+	out, err := runDebugger(ctx, c, res, "c\nc\nc\n", Breakpoint{Filename: "Dockerfile", Line: 1}, Breakpoint{Filename: "Dockerfile", Line: 2})
+	require.NoError(t, err)
+	require.Equal(t, 1, strings.Count(out, "Paused before mkdir a"))
+	require.Contains(t, out, "Paused before mkfile amd64")
+	require.Contains(t, out, "Paused before mkfile arm64")
+	require.Less(t, strings.Index(out, "Debugging linux/amd64"), strings.Index(out, "Debugging linux/arm64"))
+}
+
+func TestDebuggerReadLine(t *testing.T) {
+	// input typed after the command is left for the shell
+	in := strings.NewReader(" h \nls -l\n")
+	d := &Debugger{in: in}
+	line, err := d.readLine()
+	require.NoError(t, err)
+	require.Equal(t, "h", line)
+	require.Equal(t, len("ls -l\n"), in.Len())
+
+	line, err = d.readLine()
+	require.NoError(t, err)
+	require.Equal(t, "ls -l", line)
+
+	_, err = d.readLine()
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func runDebugger(ctx context.Context, c gateway.Client, res *gateway.Result, in string, bps ...Breakpoint) (string, error) {
+	out := &bytes.Buffer{}
+	d := NewDebugger(c, bps)
+	d.in = strings.NewReader(in)
+	d.out = out
+	err := d.Run(ctx, res)
+	return out.String(), err
+}
+
+func lines(l int32) []*pb.Range {
+	return []*pb.Range{{Start: &pb.Position{Line: l}, End: &pb.Position{Line: l}}}
+}
+
+func headDigest(ctx context.Context, t *testing.T, st llb.State) digest.Digest {
+	def, err := st.Marshal(ctx)
+	require.NoError(t, err)
+	var terminal pb.Op
+	require.NoError(t, terminal.UnmarshalVT(def.Def[len(def.Def)-1]))
+	return digest.Digest(terminal.Inputs[0].Digest)
+}
+
+// testClient records the vertices solved by the debugger.
+type testClient struct {
+	gateway.Client
+	solved []digest.Digest
+}
+
+func (c *testClient) Solve(ctx context.Context, req gateway.SolveRequest) (*gateway.Result, error) {
+	var terminal pb.Op
+	if err := terminal.UnmarshalVT(req.Definition.Def[len(req.Definition.Def)-1]); err != nil {
+		return nil, err
+	}
+	c.solved = append(c.solved, digest.Digest(terminal.Inputs[0].Digest))
+	return &gateway.Result{Ref: &testRef{}}, nil
+}
+
+type testRef struct {
+	gateway.Reference
+	st llb.State
+}
+
+func (r *testRef) ToState() (llb.State, error) {
+	return r.st, nil
+}
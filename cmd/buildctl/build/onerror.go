@@ -0,0 +1,144 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/containerd/console"
+	gateway "github.com/moby/buildkit/frontend/gateway/client"
+	"github.com/moby/buildkit/solver/errdefs"
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/pkg/errors"
+)
+
+const (
+	// OnErrorShell starts an interactive shell in the container of the
+	// failed step.
+	OnErrorShell = "shell"
+	// OnErrorKeep keeps the container of the failed step around until the
+	// build is interrupted.
+	OnErrorKeep = "keep"
+)
+
+// ParseOnError parses --on-error
+func ParseOnError(v string) (string, error) {
+	switch v {
+	case "", OnErrorShell, OnErrorKeep:
+		return v, nil
+	default:
+		return "", errors.Errorf("invalid --on-error value %q, expected %q or %q", v, OnErrorShell, OnErrorKeep)
+	}
+}
+
+// DebugOnError opens a container with the env, working directory and mounts
+// of the failed exec step in solveErr. It does nothing if solveErr didn't
+// come from an exec step.
+func DebugOnError(ctx context.Context, c gateway.Client, solveErr error, mode string) error {
+	var se *errdefs.SolveError
+	if !errors.As(solveErr, &se) || se.Op == nil {
+		return nil
+	}
+	opExec, ok := se.Op.Op.(*pb.Op_Exec)
+	if !ok {
+		return nil
+	}
+	exec := opExec.Exec
+	if len(se.MountIDs) != len(exec.Mounts) {
+		return errors.Errorf("failed step has %d mounts but %d results", len(exec.Mounts), len(se.MountIDs))
+	}
+
+	var mounts []gateway.Mount
+	for i, mnt := range exec.Mounts {
+		mounts = append(mounts, gateway.Mount{
+			Selector:  mnt.Selector,
+			Dest:      mnt.Dest,
+			ResultID:  se.MountIDs[i],
+			Readonly:  mnt.Readonly,
+			MountType: mnt.MountType,
+			CacheOpt:  mnt.CacheOpt,
+			SecretOpt: mnt.SecretOpt,
+			SSHOpt:    mnt.SSHOpt,
+		})
+	}
+
+	ctr, err := c.NewContainer(ctx, gateway.NewContainerRequest{
+		Mounts:      mounts,
+		Hostname:    exec.Meta.Hostname,
+		NetMode:     exec.Network,
+		ExtraHosts:  exec.Meta.ExtraHosts,
+		Platform:    se.Op.Platform,
+		Constraints: se.Op.Constraints,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to create container for failed step")
+	}
+	defer ctr.Release(context.WithoutCancel(ctx))
+
+	if mode == OnErrorKeep {
+		fmt.Fprintf(os.Stderr, "keeping container of failed step %q, interrupt to release it\n", exec.Meta.Args)
+		<-ctx.Done()
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "starting shell in failed step %q\n", exec.Meta.Args)
+	return runShell(ctx, ctr, exec.Meta, exec.Security)
+}
+
+// openConsole returns the terminal the interactive shell is attached to.
+var openConsole = func() (console.Console, error) {
+	return console.ConsoleFromFile(os.Stdin)
+}
+
+// runShell starts an interactive shell in ctr with the env, user and working
+// directory from meta and waits for it to exit.
+func runShell(ctx context.Context, ctr gateway.Container, meta *pb.Meta, security pb.SecurityMode) error {
+	con, err := openConsole()
+	if err != nil {
+		return errors.New("interactive shell requires a terminal")
+	}
+	if err := con.SetRaw(); err != nil {
+		return err
+	}
+	defer con.Reset()
+
+	req := gateway.StartRequest{
+		Args:         []string{"/bin/sh"},
+		Tty:          true,
+		Stdin:        io.NopCloser(con),
+		Stdout:       nopWriteCloser{con},
+		Stderr:       nopWriteCloser{con},
+		SecurityMode: security,
+	}
+	if meta != nil {
+		req.Env = meta.Env
+		req.User = meta.User
+		req.Cwd = meta.Cwd
+	}
+	proc, err := ctr.Start(ctx, req)
+	if err != nil {
+		return errors.Wrap(err, "failed to start shell")
+	}
+
+	stop := watchResize(ctx, con, proc)
+	defer stop()
+
+	return proc.Wait()
+}
+
+func resize(ctx context.Context, con console.Console, proc gateway.ContainerProcess) {
+	size, err := con.Size()
+	if err != nil {
+		return
+	}
+	proc.Resize(ctx, gateway.WinSize{Rows: uint32(size.Height), Cols: uint32(size.Width)})
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,177 @@
+package build
+
+import (
+	"context"
+	"testing"
+
+	"github.com/containerd/console"
+	gateway "github.com/moby/buildkit/frontend/gateway/client"
+	"github.com/moby/buildkit/solver/errdefs"
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOnError(t *testing.T) {
+	for _, v := range []string{"", OnErrorShell, OnErrorKeep} {
+		mode, err := ParseOnError(v)
+		require.NoError(t, err)
+		require.Equal(t, v, mode)
+	}
+
+	_, err := ParseOnError("debug")
+	require.ErrorContains(t, err, `invalid --on-error value "debug"`)
+}
+
+func TestDebugOnError(t *testing.T) {
+	oldOpenConsole := openConsole
+	openConsole = func() (console.Console, error) {
+		return &testConsole{}, nil
+	}
+	t.Cleanup(func() {
+		openConsole = oldOpenConsole
+	})
+
+	platform := &pb.Platform{OS: "linux", Architecture: "arm64"}
+	solveErr := errors.Wrap(&errdefs.SolveError{
+		Solve: &errdefs.Solve{
+			MountIDs: []string{"rootfs-result", "cache-result"},
+			Op: &pb.Op{
+				Op: &pb.Op_Exec{
+					Exec: &pb.ExecOp{
+						Meta: &pb.Meta{
+							Args:     []string{"/bin/sh", "-c", "make"},
+							Env:      []string{"PATH=/usr/bin:/bin", "GOFLAGS=-mod=vendor"},
+							Cwd:      "/src",
+							User:     "builder",
+							Hostname: "failed-step",
+						},
+						Mounts: []*pb.Mount{
+							{Dest: "/", Output: 0},
+							{Dest: "/root/.cache", Output: -1, MountType: pb.MountType_CACHE, CacheOpt: &pb.CacheOpt{ID: "gocache"}},
+						},
+						Network:  pb.NetMode_NONE,
+						Security: pb.SecurityMode_INSECURE,
+					},
+				},
+				Platform: platform,
+			},
+		},
+		Err: errors.New("process did not complete successfully: exit code: 2"),
+	}, "failed to solve")
+
+	c := &testContainerClient{}
+	require.NoError(t, DebugOnError(context.TODO(), c, solveErr, OnErrorShell))
+
+	require.Len(t, c.containers, 1)
+	ctrReq := c.containers[0].req
+	require.Equal(t, []gateway.Mount{
+		{Dest: "/", ResultID: "rootfs-result"},
+		{Dest: "/root/.cache", ResultID: "cache-result", MountType: pb.MountType_CACHE, CacheOpt: &pb.CacheOpt{ID: "gocache"}},
+	}, ctrReq.Mounts)
+	require.Equal(t, "failed-step", ctrReq.Hostname)
+	require.Equal(t, pb.NetMode_NONE, ctrReq.NetMode)
+	require.Equal(t, platform, ctrReq.Platform)
+	require.True(t, c.containers[0].released)
+
+	require.Len(t, c.containers[0].started, 1)
+	startReq := c.containers[0].started[0]
+	require.Equal(t, []string{"/bin/sh"}, startReq.Args)
+	require.Equal(t, []string{"PATH=/usr/bin:/bin", "GOFLAGS=-mod=vendor"}, startReq.Env)
+	require.Equal(t, "/src", startReq.Cwd)
+	require.Equal(t, "builder", startReq.User)
+	require.Equal(t, pb.SecurityMode_INSECURE, startReq.SecurityMode)
+	require.True(t, startReq.Tty)
+}
+
+func TestDebugOnErrorMountMismatch(t *testing.T) {
+	solveErr := &errdefs.SolveError{
+		Solve: &errdefs.Solve{
+			Op: &pb.Op{
+				Op: &pb.Op_Exec{
+					Exec: &pb.ExecOp{
+						Meta:   &pb.Meta{Args: []string{"true"}},
+						Mounts: []*pb.Mount{{Dest: "/"}},
+					},
+				},
+			},
+		},
+		Err: errors.New("failed"),
+	}
+
+	c := &testContainerClient{}
+	err := DebugOnError(context.TODO(), c, solveErr, OnErrorShell)
+	require.ErrorContains(t, err, "failed step has 1 mounts but 0 results")
+	require.Empty(t, c.containers)
+}
+
+func TestDebugOnErrorNotExec(t *testing.T) {
+	solveErr := &errdefs.SolveError{
+		Solve: &errdefs.Solve{
+			Op: &pb.Op{Op: &pb.Op_File{File: &pb.FileOp{}}},
+		},
+		Err: errors.New("failed"),
+	}
+
+	c := &testContainerClient{}
+	require.NoError(t, DebugOnError(context.TODO(), c, solveErr, OnErrorShell))
+	require.NoError(t, DebugOnError(context.TODO(), c, errors.New("failed"), OnErrorShell))
+	require.Empty(t, c.containers)
+}
+
+// testContainerClient records the containers created for failed steps.
+type testContainerClient struct {
+	gateway.Client
+	containers []*testContainer
+}
+
+func (c *testContainerClient) NewContainer(ctx context.Context, req gateway.NewContainerRequest) (gateway.Container, error) {
+	ctr := &testContainer{req: req}
+	c.containers = append(c.containers, ctr)
+	return ctr, nil
+}
+
+type testContainer struct {
+	req      gateway.NewContainerRequest
+	started  []gateway.StartRequest
+	released bool
+}
+
+func (c *testContainer) Start(ctx context.Context, req gateway.StartRequest) (gateway.ContainerProcess, error) {
+	c.started = append(c.started, req)
+	return &testProcess{}, nil
+}
+
+func (c *testContainer) Release(ctx context.Context) error {
+	c.released = true
+	return nil
+}
+
+type testProcess struct {
+	gateway.ContainerProcess
+}
+
+func (p *testProcess) Wait() error {
+	return nil
+}
+
+func (p *testProcess) Resize(ctx context.Context, size gateway.WinSize) error {
+	return nil
+}
+
+// testConsole stands in for the terminal of the interactive shell.
+type testConsole struct {
+	console.Console
+}
+
+func (c *testConsole) SetRaw() error {
+	return nil
+}
+
+func (c *testConsole) Reset() error {
+	return nil
+}
+
+func (c *testConsole) Size() (console.WinSize, error) {
+	return console.WinSize{Height: 24, Width: 80}, nil
+}
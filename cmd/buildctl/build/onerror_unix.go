@@ -0,0 +1,34 @@
+//go:build !windows
+
+package build
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/containerd/console"
+	gateway "github.com/moby/buildkit/frontend/gateway/client"
+)
+
+func watchResize(ctx context.Context, con console.Console, proc gateway.ContainerProcess) func() {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGWINCH)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ch:
+				resize(ctx, con, proc)
+			case <-done:
+				return
+			}
+		}
+	}()
+	resize(ctx, con, proc)
+	return func() {
+		signal.Stop(ch)
+		close(done)
+	}
+}
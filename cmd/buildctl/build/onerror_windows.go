@@ -0,0 +1,13 @@
+package build
+
+import (
+	"context"
+
+	"github.com/containerd/console"
+	gateway "github.com/moby/buildkit/frontend/gateway/client"
+)
+
+func watchResize(ctx context.Context, con console.Console, proc gateway.ContainerProcess) func() {
+	resize(ctx, con, proc)
+	return func() {}
+}
@@ -0,0 +1,595 @@
+package build
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/core/images"
+	"github.com/containerd/containerd/v2/pkg/archive/compression"
+	contentlocal "github.com/containerd/containerd/v2/plugins/content/local"
+	"github.com/containerd/platforms"
+	"github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/util/attestation"
+	"github.com/moby/buildkit/util/imageutil"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+// maxDifferences is the number of differences recorded by CheckReproducible
+// before it stops comparing the images.
+const maxDifferences = 100
+
+// reproducibleAttrs are the exporter options that change the layers or the
+// config of an exported image. They are copied from the image export of the
+// build to the exports that are compared.
+var reproducibleAttrs = []string{
+	"compression",
+	"compression-level",
+	"force-compression",
+	"oci-mediatypes",
+	"rewrite-timestamp",
+	"squash",
+	"max-layers",
+}
+
+// ReproducibleExport returns an export that writes the build result as an OCI
+// layout to dir so that it can be compared with CheckReproducible. The
+// options that affect the image are copied from the first image, oci or
+// docker export in exports.
+func ReproducibleExport(exports []client.ExportEntry, dir string) client.ExportEntry {
+	attrs := map[string]string{}
+	for _, e := range exports {
+		switch e.Type {
+		case client.ExporterImage, client.ExporterOCI, client.ExporterDocker:
+		default:
+			continue
+		}
+		for k, v := range e.Attrs {
+			if slices.Contains(reproducibleAttrs, k) || strings.HasPrefix(k, "annotation") {
+				attrs[k] = v
+			}
+		}
+		break
+	}
+	return client.ExportEntry{
+		Type:  client.ExporterOCI,
+		Attrs: attrs,
+		Output: func(map[string]string) (io.WriteCloser, error) {
+			return newLayoutWriter(dir)
+		},
+	}
+}
+
+// layoutWriter unpacks the OCI layout tarball written to it into a directory.
+type layoutWriter struct {
+	*io.PipeWriter
+	done chan error
+}
+
+func newLayoutWriter(dir string) (*layoutWriter, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	pr, pw := io.Pipe()
+	w := &layoutWriter{PipeWriter: pw, done: make(chan error, 1)}
+	go func() {
+		err := unpackLayout(pr, dir)
+		pr.CloseWithError(err)
+		w.done <- err
+	}()
+	return w, nil
+}
+
+func (w *layoutWriter) Close() error {
+	if err := w.PipeWriter.Close(); err != nil {
+		return err
+	}
+	return <-w.done
+}
+
+// unpackLayout writes the index and the blobs of the OCI layout tarball r to
+// dir. Other files are skipped.
+func unpackLayout(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			// drain the padding after the end of the archive
+			_, err = io.Copy(io.Discard, r)
+			return err
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to read exported OCI layout")
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := path.Clean(hdr.Name)
+		if name != ocispecs.ImageIndexFile {
+			alg, enc, ok := strings.Cut(strings.TrimPrefix(name, ocispecs.ImageBlobsDir+"/"), "/")
+			if !ok || !strings.HasPrefix(name, ocispecs.ImageBlobsDir+"/") {
+				continue
+			}
+			if err := digest.NewDigestFromEncoded(digest.Algorithm(alg), enc).Validate(); err != nil {
+				return errors.Wrapf(err, "invalid blob %s in exported OCI layout", hdr.Name)
+			}
+		}
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+			return err
+		}
+		f, err := os.OpenFile(p, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(f, tr)
+		if err1 := f.Close(); err == nil {
+			err = err1
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// ReproducibilityReport is the result of comparing two builds of the same
+// definition.
+type ReproducibilityReport struct {
+	Reproducible bool         `json:"reproducible"`
+	Differences  []Difference `json:"differences,omitempty"`
+	// Truncated is set if comparing stopped after maxDifferences.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Difference is a difference between the images exported by two builds of
+// the same definition.
+type Difference struct {
+	// Platform is the platform of the image, empty for single platform
+	// builds.
+	Platform string `json:"platform,omitempty"`
+	// Layer is the index of the layer the difference was found in, or -1
+	// for differences of the manifest or the config.
+	Layer int `json:"layer"`
+	// Path is the name of the layer entry that differs.
+	Path   string `json:"path,omitempty"`
+	Reason string `json:"reason"`
+	// Detail describes differences that are not about a single entry.
+	Detail string `json:"detail,omitempty"`
+	A      *Entry `json:"a,omitempty"`
+	B      *Entry `json:"b,omitempty"`
+}
+
+func (d Difference) String() string {
+	var s []string
+	if d.Platform != "" {
+		s = append(s, d.Platform)
+	}
+	if d.Layer >= 0 {
+		s = append(s, fmt.Sprintf("layer %d", d.Layer))
+	}
+	if d.Path != "" {
+		s = append(s, d.Path)
+	}
+	out := strings.Join(s, " ")
+	if out != "" {
+		out += ": "
+	}
+	out += d.Reason + " differs"
+	if d.Detail != "" {
+		return out + " (" + d.Detail + ")"
+	}
+	if d.A != nil || d.B != nil {
+		return fmt.Sprintf("%s (%s, %s)", out, d.A, d.B)
+	}
+	return out
+}
+
+// Entry is the tar header of a layer entry and the digest of its content.
+type Entry struct {
+	Typeflag   string            `json:"typeflag"`
+	Mode       int64             `json:"mode"`
+	UID        int               `json:"uid"`
+	GID        int               `json:"gid"`
+	Uname      string            `json:"uname,omitempty"`
+	Gname      string            `json:"gname,omitempty"`
+	Size       int64             `json:"size"`
+	ModTime    time.Time         `json:"modTime"`
+	Linkname   string            `json:"linkname,omitempty"`
+	Devmajor   int64             `json:"devmajor,omitempty"`
+	Devminor   int64             `json:"devminor,omitempty"`
+	PAXRecords map[string]string `json:"paxRecords,omitempty"`
+	Digest     digest.Digest     `json:"digest,omitempty"`
+
+	index int
+}
+
+func (e *Entry) String() string {
+	if e == nil {
+		return "missing"
+	}
+	s := fmt.Sprintf("type=%s mode=%o uid=%d gid=%d size=%d mtime=%s", e.Typeflag, e.Mode, e.UID, e.GID, e.Size, e.ModTime.UTC().Format(time.RFC3339Nano))
+	if e.Linkname != "" {
+		s += " link=" + e.Linkname
+	}
+	return s
+}
+
+// CheckReproducible compares the images in the OCI layouts in dirA and dirB,
+// written by exports from ReproducibleExport. Layers are compared entry by
+// entry: the tar headers, the content of files and the whiteouts, and in
+// which layer each entry is. Attestations are not compared.
+func CheckReproducible(ctx context.Context, dirA, dirB string) (*ReproducibilityReport, error) {
+	a, err := loadLayout(ctx, dirA)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load first build")
+	}
+	b, err := loadLayout(ctx, dirB)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load second build")
+	}
+
+	c := &comparer{a: a.store, b: b.store}
+	names := map[string]struct{}{}
+	for k := range a.manifests {
+		names[k] = struct{}{}
+	}
+	for k := range b.manifests {
+		names[k] = struct{}{}
+	}
+	for _, p := range slices.Sorted(maps.Keys(names)) {
+		if c.done() {
+			break
+		}
+		c.platform = p
+		mfstA, okA := a.manifests[p]
+		mfstB, okB := b.manifests[p]
+		if !okA {
+			c.add(Difference{Layer: -1, Reason: "platform", Detail: "only built by the second build"})
+			continue
+		}
+		if !okB {
+			c.add(Difference{Layer: -1, Reason: "platform", Detail: "only built by the first build"})
+			continue
+		}
+		if err := c.compareManifest(ctx, mfstA, mfstB); err != nil {
+			return nil, err
+		}
+	}
+
+	return &ReproducibilityReport{
+		Reproducible: len(c.diffs) == 0,
+		Differences:  c.diffs,
+		Truncated:    c.done(),
+	}, nil
+}
+
+type layout struct {
+	store     content.Store
+	manifests map[string]ocispecs.Descriptor
+}
+
+// loadLayout returns the image manifests of the OCI layout in dir by
+// platform. Single platform images use an empty platform.
+func loadLayout(ctx context.Context, dir string) (*layout, error) {
+	dt, err := os.ReadFile(filepath.Join(dir, ocispecs.ImageIndexFile))
+	if err != nil {
+		return nil, err
+	}
+	var idx ocispecs.Index
+	if err := json.Unmarshal(dt, &idx); err != nil {
+		return nil, errors.Wrap(err, "failed to parse index")
+	}
+	if len(idx.Manifests) != 1 {
+		return nil, errors.Errorf("expected a single image in the OCI layout, got %d", len(idx.Manifests))
+	}
+	store, err := contentlocal.NewStore(dir)
+	if err != nil {
+		return nil, err
+	}
+	l := &layout{store: store, manifests: map[string]ocispecs.Descriptor{}}
+
+	desc := idx.Manifests[0]
+	if images.IsManifestType(desc.MediaType) {
+		l.manifests[""] = desc
+		return l, nil
+	}
+	if !images.IsIndexType(desc.MediaType) {
+		return nil, errors.Errorf("unsupported media type %s", desc.MediaType)
+	}
+	var imgIdx ocispecs.Index
+	if err := imageutil.ReadJSON(ctx, store, desc, &imgIdx); err != nil {
+		return nil, errors.Wrap(err, "failed to read image index")
+	}
+	for _, d := range imgIdx.Manifests {
+		if d.Annotations[attestation.DockerAnnotationReferenceType] == attestation.DockerAnnotationReferenceTypeDefault {
+			continue
+		}
+		if !images.IsManifestType(d.MediaType) {
+			continue
+		}
+		var p string
+		if d.Platform != nil {
+			p = platforms.Format(*d.Platform)
+		}
+		l.manifests[p] = d
+	}
+	return l, nil
+}
+
+type comparer struct {
+	a, b     content.Provider
+	platform string
+	diffs    []Difference
+}
+
+func (c *comparer) done() bool {
+	return len(c.diffs) >= maxDifferences
+}
+
+func (c *comparer) add(d Difference) {
+	if c.done() {
+		return
+	}
+	d.Platform = c.platform
+	c.diffs = append(c.diffs, d)
+}
+
+func (c *comparer) compareManifest(ctx context.Context, descA, descB ocispecs.Descriptor) error {
+	if descA.Digest == descB.Digest {
+		return nil
+	}
+	var mfstA, mfstB ocispecs.Manifest
+	if err := imageutil.ReadJSON(ctx, c.a, descA, &mfstA); err != nil {
+		return errors.Wrap(err, "failed to read image manifest")
+	}
+	if err := imageutil.ReadJSON(ctx, c.b, descB, &mfstB); err != nil {
+		return errors.Wrap(err, "failed to read image manifest")
+	}
+
+	if mfstA.Config.Digest != mfstB.Config.Digest {
+		fields, err := c.jsonFields(ctx, mfstA.Config, mfstB.Config)
+		if err != nil {
+			return errors.Wrap(err, "failed to read image config")
+		}
+		c.add(Difference{Layer: -1, Reason: "config", Detail: strings.Join(fields, ", ")})
+	}
+	if !maps.Equal(mfstA.Annotations, mfstB.Annotations) {
+		c.add(Difference{Layer: -1, Reason: "manifest annotations"})
+	}
+	if len(mfstA.Layers) != len(mfstB.Layers) {
+		c.add(Difference{Layer: -1, Reason: "layer count", Detail: fmt.Sprintf("%d and %d", len(mfstA.Layers), len(mfstB.Layers))})
+	}
+
+	same := len(mfstA.Layers) == len(mfstB.Layers)
+	for i := range min(len(mfstA.Layers), len(mfstB.Layers)) {
+		if mfstA.Layers[i].Digest != mfstB.Layers[i].Digest {
+			same = false
+		}
+	}
+	if same {
+		return nil
+	}
+
+	// read the headers of all layers to find entries that moved to another
+	// layer
+	layersA, err := readLayers(ctx, c.a, mfstA.Layers)
+	if err != nil {
+		return err
+	}
+	layersB, err := readLayers(ctx, c.b, mfstB.Layers)
+	if err != nil {
+		return err
+	}
+	for i := range max(len(layersA), len(layersB)) {
+		if c.done() {
+			return nil
+		}
+		if i < len(layersA) && i < len(layersB) && mfstA.Layers[i].Digest == mfstB.Layers[i].Digest {
+			continue
+		}
+		var la, lb map[string]*Entry
+		if i < len(layersA) {
+			la = layersA[i]
+		}
+		if i < len(layersB) {
+			lb = layersB[i]
+		}
+		before := len(c.diffs)
+		c.compareLayer(i, la, lb, layersA, layersB)
+		if len(c.diffs) == before && la != nil && lb != nil {
+			// the same entries in the same order, but a different blob
+			c.add(Difference{Layer: i, Reason: "layer blob", Detail: fmt.Sprintf("%s and %s, e.g. compression or tar padding", mfstA.Layers[i].Digest, mfstB.Layers[i].Digest)})
+		}
+	}
+	return nil
+}
+
+func (c *comparer) compareLayer(i int, la, lb map[string]*Entry, layersA, layersB []map[string]*Entry) {
+	names := map[string]struct{}{}
+	for k := range la {
+		names[k] = struct{}{}
+	}
+	for k := range lb {
+		names[k] = struct{}{}
+	}
+	ordered := true
+	for _, name := range slices.Sorted(maps.Keys(names)) {
+		if c.done() {
+			return
+		}
+		ea, eb := la[name], lb[name]
+		if ea == nil || eb == nil {
+			reason := "presence"
+			if strings.HasPrefix(path.Base(name), ".wh.") {
+				reason = "whiteout"
+			}
+			d := Difference{Layer: i, Path: name, Reason: reason, A: ea, B: eb}
+			if ea == nil {
+				if j := findLayer(layersA, name, i); j >= 0 {
+					d.Reason = "layer"
+					d.Detail = fmt.Sprintf("in layer %d of the first build", j)
+				}
+			} else if j := findLayer(layersB, name, i); j >= 0 {
+				d.Reason = "layer"
+				d.Detail = fmt.Sprintf("in layer %d of the second build", j)
+			}
+			c.add(d)
+			continue
+		}
+		if reason := compareEntry(ea, eb); reason != "" {
+			c.add(Difference{Layer: i, Path: name, Reason: reason, A: ea, B: eb})
+		}
+		if ea.index != eb.index {
+			ordered = false
+		}
+	}
+	if !ordered && len(la) == len(lb) {
+		c.add(Difference{Layer: i, Reason: "entry order"})
+	}
+}
+
+// findLayer returns the index of another layer than skip that has an entry
+// name, or -1.
+func findLayer(layers []map[string]*Entry, name string, skip int) int {
+	for j, l := range layers {
+		if _, ok := l[name]; ok && j != skip {
+			return j
+		}
+	}
+	return -1
+}
+
+func compareEntry(a, b *Entry) string {
+	switch {
+	case a.Typeflag != b.Typeflag:
+		return "type"
+	case a.Mode != b.Mode:
+		return "mode"
+	case a.UID != b.UID || a.GID != b.GID || a.Uname != b.Uname || a.Gname != b.Gname:
+		return "owner"
+	case a.Linkname != b.Linkname:
+		return "link target"
+	case a.Devmajor != b.Devmajor || a.Devminor != b.Devminor:
+		return "device"
+	case !maps.Equal(a.PAXRecords, b.PAXRecords):
+		return "pax records"
+	case a.Size != b.Size:
+		return "size"
+	case !a.ModTime.Equal(b.ModTime):
+		return "modification time"
+	case a.Digest != b.Digest:
+		return "content"
+	}
+	return ""
+}
+
+// jsonFields returns the top-level fields that differ between the JSON blobs
+// a and b.
+func (c *comparer) jsonFields(ctx context.Context, a, b ocispecs.Descriptor) ([]string, error) {
+	var fa, fb map[string]json.RawMessage
+	if err := imageutil.ReadJSON(ctx, c.a, a, &fa); err != nil {
+		return nil, err
+	}
+	if err := imageutil.ReadJSON(ctx, c.b, b, &fb); err != nil {
+		return nil, err
+	}
+	keys := map[string]struct{}{}
+	for k := range fa {
+		keys[k] = struct{}{}
+	}
+	for k := range fb {
+		keys[k] = struct{}{}
+	}
+	var fields []string
+	for _, k := range slices.Sorted(maps.Keys(keys)) {
+		if !bytes.Equal(fa[k], fb[k]) {
+			fields = append(fields, k)
+		}
+	}
+	return fields, nil
+}
+
+func readLayers(ctx context.Context, provider content.Provider, layers []ocispecs.Descriptor) ([]map[string]*Entry, error) {
+	out := make([]map[string]*Entry, len(layers))
+	for i, l := range layers {
+		entries, err := readLayer(ctx, provider, l)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read layer %s", l.Digest)
+		}
+		out[i] = entries
+	}
+	return out, nil
+}
+
+// readLayer returns the entries of the layer desc by name.
+func readLayer(ctx context.Context, provider content.Provider, desc ocispecs.Descriptor) (map[string]*Entry, error) {
+	ra, err := provider.ReaderAt(ctx, desc)
+	if err != nil {
+		return nil, err
+	}
+	defer ra.Close()
+
+	r, err := compression.DecompressStream(content.NewReader(ra))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	entries := map[string]*Entry{}
+	tr := tar.NewReader(r)
+	for i := 0; ; i++ {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		e := &Entry{
+			Typeflag: string(hdr.Typeflag),
+			Mode:     hdr.Mode,
+			UID:      hdr.Uid,
+			GID:      hdr.Gid,
+			Uname:    hdr.Uname,
+			Gname:    hdr.Gname,
+			Size:     hdr.Size,
+			ModTime:  hdr.ModTime,
+			Linkname: hdr.Linkname,
+			Devmajor: hdr.Devmajor,
+			Devminor: hdr.Devminor,
+			index:    i,
+		}
+		for k, v := range hdr.PAXRecords {
+			// records that are also decoded into header fields are
+			// compared through them
+			switch k {
+			case "path", "linkpath", "size", "uid", "gid", "uname", "gname", "mtime", "atime", "ctime":
+				continue
+			}
+			if e.PAXRecords == nil {
+				e.PAXRecords = map[string]string{}
+			}
+			e.PAXRecords[k] = v
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			e.Digest, err = digest.Canonical.FromReader(tr)
+			if err != nil {
+				return nil, err
+			}
+		}
+		entries[path.Clean("/"+hdr.Name)] = e
+	}
+}
@@ -0,0 +1,386 @@
+package build
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/containerd/containerd/v2/core/content"
+	contentlocal "github.com/containerd/containerd/v2/plugins/content/local"
+	"github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/util/attestation"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+)
+
+type testEntry struct {
+	name    string
+	mode    int64
+	data    string
+	modTime int64
+}
+
+type testImage struct {
+	created string
+	layers  [][]testEntry
+	gzip    bool
+}
+
+func TestCheckReproducible(t *testing.T) {
+	ctx := context.TODO()
+	base := testImage{
+		created: "2024-01-01T00:00:00Z",
+		layers: [][]testEntry{
+			{{name: "etc/", mode: 0755}, {name: "etc/passwd", mode: 0644, data: "root:x:0:0"}},
+			{{name: "bin/", mode: 0755}, {name: "bin/sh", mode: 0755, data: "elf"}},
+		},
+	}
+	with := func(fn func(img *testImage)) testImage {
+		img := base
+		img.layers = make([][]testEntry, len(base.layers))
+		for i, l := range base.layers {
+			img.layers[i] = append([]testEntry{}, l...)
+		}
+		fn(&img)
+		return img
+	}
+
+	tcs := []struct {
+		name   string
+		b      testImage
+		layer  int
+		path   string
+		reason string
+		detail string
+	}{
+		{name: "same", b: base},
+		{
+			name:   "content",
+			b:      with(func(img *testImage) { img.layers[0][1].data = "root:x:0:1" }),
+			layer:  0,
+			path:   "/etc/passwd",
+			reason: "content",
+		},
+		{
+			name:   "mtime",
+			b:      with(func(img *testImage) { img.layers[1][1].modTime = 10 }),
+			layer:  1,
+			path:   "/bin/sh",
+			reason: "modification time",
+		},
+		{
+			name:   "mode",
+			b:      with(func(img *testImage) { img.layers[1][1].mode = 0700 }),
+			layer:  1,
+			path:   "/bin/sh",
+			reason: "mode",
+		},
+		{
+			name: "whiteout",
+			b: with(func(img *testImage) {
+				img.layers[1] = append(img.layers[1], testEntry{name: "etc/.wh.passwd", mode: 0644})
+			}),
+			layer:  1,
+			path:   "/etc/.wh.passwd",
+			reason: "whiteout",
+		},
+		{
+			name: "entry order",
+			b: with(func(img *testImage) {
+				img.layers[1][0], img.layers[1][1] = img.layers[1][1], img.layers[1][0]
+			}),
+			layer:  1,
+			reason: "entry order",
+		},
+		{
+			// the file is added by the first layer instead of the second
+			name: "layer boundary",
+			b: with(func(img *testImage) {
+				img.layers[0] = append(img.layers[0], img.layers[1][1])
+				img.layers[1] = img.layers[1][:1]
+			}),
+			layer:  0,
+			path:   "/bin/sh",
+			reason: "layer",
+			detail: "in layer 1 of the first build",
+		},
+		{
+			name:   "layer count",
+			b:      with(func(img *testImage) { img.layers = img.layers[:1] }),
+			layer:  -1,
+			reason: "layer count",
+			detail: "2 and 1",
+		},
+		{
+			name:   "config",
+			b:      with(func(img *testImage) { img.created = "2024-01-02T00:00:00Z" }),
+			layer:  -1,
+			reason: "config",
+			detail: "created",
+		},
+		{
+			// the same tar entries in a differently compressed blob
+			name:   "compression",
+			b:      with(func(img *testImage) { img.gzip = true }),
+			layer:  0,
+			reason: "layer blob",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			dirA := writeLayout(t, map[string]testImage{"": base})
+			dirB := writeLayout(t, map[string]testImage{"": tc.b})
+			report, err := CheckReproducible(ctx, dirA, dirB)
+			require.NoError(t, err)
+			if tc.reason == "" {
+				require.True(t, report.Reproducible)
+				require.Empty(t, report.Differences)
+				return
+			}
+			require.False(t, report.Reproducible)
+			require.NotEmpty(t, report.Differences)
+			d := report.Differences[0]
+			require.Equal(t, tc.layer, d.Layer)
+			require.Equal(t, tc.path, d.Path)
+			require.Equal(t, tc.reason, d.Reason)
+			if tc.detail != "" {
+				require.Equal(t, tc.detail, d.Detail)
+			}
+			require.Contains(t, d.String(), tc.reason+" differs")
+
+			// the report is written as an artifact
+			_, err = json.Marshal(report)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCheckReproducibleMultiPlatform(t *testing.T) {
+	ctx := context.TODO()
+	img := testImage{
+		created: "2024-01-01T00:00:00Z",
+		layers:  [][]testEntry{{{name: "a", mode: 0644, data: "a"}}},
+	}
+	changed := testImage{
+		created: img.created,
+		layers:  [][]testEntry{{{name: "a", mode: 0644, data: "b"}}},
+	}
+
+	// attestations differ between builds and are not compared
+	dirA := writeLayout(t, map[string]testImage{"linux/amd64": img, "linux/arm64": img})
+	dirB := writeLayout(t, map[string]testImage{"linux/amd64": img, "linux/arm64": img})
+	report, err := CheckReproducible(ctx, dirA, dirB)
+	require.NoError(t, err)
+	require.True(t, report.Reproducible)
+
+	dirB = writeLayout(t, map[string]testImage{"linux/amd64": img, "linux/arm64": changed})
+	report, err = CheckReproducible(ctx, dirA, dirB)
+	require.NoError(t, err)
+	require.Len(t, report.Differences, 1)
+	require.Equal(t, "linux/arm64", report.Differences[0].Platform)
+	require.Equal(t, "/a", report.Differences[0].Path)
+	require.Equal(t, "content", report.Differences[0].Reason)
+	require.True(t, strings.HasPrefix(report.Differences[0].String(), "linux/arm64 layer 0 /a: content differs"))
+
+	dirB = writeLayout(t, map[string]testImage{"linux/amd64": img})
+	report, err = CheckReproducible(ctx, dirA, dirB)
+	require.NoError(t, err)
+	require.Len(t, report.Differences, 1)
+	require.Equal(t, "platform", report.Differences[0].Reason)
+}
+
+func TestCheckReproducibleMaxDifferences(t *testing.T) {
+	var a, b []testEntry
+	for i := range maxDifferences + 5 {
+		name := strings.Repeat("f", i+1)
+		a = append(a, testEntry{name: name, mode: 0644, data: "a"})
+		b = append(b, testEntry{name: name, mode: 0644, data: "b"})
+	}
+	dirA := writeLayout(t, map[string]testImage{"": {layers: [][]testEntry{a}}})
+	dirB := writeLayout(t, map[string]testImage{"": {layers: [][]testEntry{b}}})
+	report, err := CheckReproducible(context.TODO(), dirA, dirB)
+	require.NoError(t, err)
+	require.Len(t, report.Differences, maxDifferences)
+	require.True(t, report.Truncated)
+}
+
+func TestReproducibleExport(t *testing.T) {
+	exports := []client.ExportEntry{
+		{Type: client.ExporterLocal, Attrs: map[string]string{"compression": "zstd"}},
+		{Type: client.ExporterImage, Attrs: map[string]string{
+			"name":                   "example.com/foo",
+			"push":                   "true",
+			"rewrite-timestamp":      "true",
+			"compression":            "estargz",
+			"annotation.foo":         "bar",
+			"annotation-index.title": "baz",
+		}},
+	}
+	dir := t.TempDir()
+	e := ReproducibleExport(exports, dir)
+	require.Equal(t, client.ExporterOCI, e.Type)
+	require.Equal(t, map[string]string{
+		"rewrite-timestamp":      "true",
+		"compression":            "estargz",
+		"annotation.foo":         "bar",
+		"annotation-index.title": "baz",
+	}, e.Attrs)
+
+	// the exported tarball is unpacked into dir
+	blob := []byte("blob")
+	dgst := digest.FromBytes(blob)
+	w, err := e.Output(nil)
+	require.NoError(t, err)
+	tw := tar.NewWriter(w)
+	for name, dt := range map[string][]byte{
+		ocispecs.ImageIndexFile:          []byte("{}"),
+		ocispecs.ImageLayoutFile:         []byte("{}"),
+		"blobs/sha256/" + dgst.Encoded(): blob,
+		"../escape":                      []byte("x"),
+		"blobs/../../escape":             []byte("x"),
+		"manifest.json":                  []byte("[]"),
+	} {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(dt)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write(dt)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, w.Close())
+
+	dt, err := os.ReadFile(filepath.Join(dir, "blobs", "sha256", dgst.Encoded()))
+	require.NoError(t, err)
+	require.Equal(t, blob, dt)
+	_, err = os.Stat(filepath.Join(dir, ocispecs.ImageIndexFile))
+	require.NoError(t, err)
+	for _, name := range []string{ocispecs.ImageLayoutFile, "manifest.json", "../escape", "escape"} {
+		_, err = os.Stat(filepath.Join(dir, name))
+		require.ErrorIs(t, err, os.ErrNotExist, name)
+	}
+
+	// blobs must be named by their digest
+	w, err = newLayoutWriter(t.TempDir())
+	require.NoError(t, err)
+	tw = tar.NewWriter(w)
+	// writes fail once the unpacking has stopped, the error is returned by Close
+	tw.WriteHeader(&tar.Header{Name: "blobs/sha256/abc", Mode: 0644, Typeflag: tar.TypeReg})
+	tw.Close()
+	require.ErrorContains(t, w.Close(), "invalid blob blobs/sha256/abc")
+}
+
+// writeLayout writes an OCI layout with an image for every platform, or a
+// single platform image for the empty platform. Multi-platform images get an
+// attestation manifest for every platform that differs between calls.
+func writeLayout(t *testing.T, imgs map[string]testImage) string {
+	ctx := context.TODO()
+	dir := t.TempDir()
+	store, err := contentlocal.NewStore(dir)
+	require.NoError(t, err)
+
+	writeBlob := func(mediaType string, dt []byte) ocispecs.Descriptor {
+		desc := ocispecs.Descriptor{MediaType: mediaType, Digest: digest.FromBytes(dt), Size: int64(len(dt))}
+		require.NoError(t, content.WriteBlob(ctx, store, desc.Digest.String(), bytes.NewReader(dt), desc))
+		return desc
+	}
+	writeJSON := func(mediaType string, v any) ocispecs.Descriptor {
+		dt, err := json.Marshal(v)
+		require.NoError(t, err)
+		return writeBlob(mediaType, dt)
+	}
+
+	var manifests []ocispecs.Descriptor
+	for p, img := range imgs {
+		mfst := ocispecs.Manifest{
+			Versioned: specs.Versioned{SchemaVersion: 2},
+			MediaType: ocispecs.MediaTypeImageManifest,
+			Config: writeJSON(ocispecs.MediaTypeImageConfig, map[string]any{
+				"created": img.created,
+				"rootfs":  map[string]any{"type": "layers"},
+			}),
+		}
+		for _, l := range img.layers {
+			mediaType := ocispecs.MediaTypeImageLayer
+			dt := layerTar(t, l)
+			if img.gzip {
+				mediaType = ocispecs.MediaTypeImageLayerGzip
+				buf := &bytes.Buffer{}
+				gz := gzip.NewWriter(buf)
+				_, err := gz.Write(dt)
+				require.NoError(t, err)
+				require.NoError(t, gz.Close())
+				dt = buf.Bytes()
+			}
+			mfst.Layers = append(mfst.Layers, writeBlob(mediaType, dt))
+		}
+		desc := writeJSON(ocispecs.MediaTypeImageManifest, mfst)
+		if p == "" {
+			manifests = append(manifests, desc)
+			continue
+		}
+		platform := ocispecs.Platform{OS: "linux", Architecture: strings.TrimPrefix(p, "linux/")}
+		desc.Platform = &platform
+		manifests = append(manifests, desc)
+
+		att := writeJSON(ocispecs.MediaTypeImageManifest, ocispecs.Manifest{
+			Versioned: specs.Versioned{SchemaVersion: 2},
+			MediaType: ocispecs.MediaTypeImageManifest,
+			Config:    writeBlob(ocispecs.MediaTypeImageConfig, []byte(t.Name()+dir)),
+		})
+		att.Platform = &ocispecs.Platform{OS: "unknown", Architecture: "unknown"}
+		att.Annotations = map[string]string{
+			attestation.DockerAnnotationReferenceType:   attestation.DockerAnnotationReferenceTypeDefault,
+			attestation.DockerAnnotationReferenceDigest: desc.Digest.String(),
+		}
+		manifests = append(manifests, att)
+	}
+
+	top := manifests[0]
+	if len(manifests) > 1 || top.Platform != nil {
+		top = writeJSON(ocispecs.MediaTypeImageIndex, ocispecs.Index{
+			Versioned: specs.Versioned{SchemaVersion: 2},
+			MediaType: ocispecs.MediaTypeImageIndex,
+			Manifests: manifests,
+		})
+	}
+	dt, err := json.Marshal(ocispecs.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispecs.MediaTypeImageIndex,
+		Manifests: []ocispecs.Descriptor{top},
+	})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ocispecs.ImageIndexFile), dt, 0644))
+	return dir
+}
+
+func layerTar(t *testing.T, entries []testEntry) []byte {
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	for _, e := range entries {
+		hdr := &tar.Header{
+			Name:    e.name,
+			Mode:    e.mode,
+			ModTime: time.Unix(e.modTime, 0),
+			Format:  tar.FormatPAX,
+		}
+		if strings.HasSuffix(e.name, "/") {
+			hdr.Typeflag = tar.TypeDir
+		} else {
+			hdr.Typeflag = tar.TypeReg
+			hdr.Size = int64(len(e.data))
+		}
+		require.NoError(t, tw.WriteHeader(hdr))
+		_, err := io.WriteString(tw, e.data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	return buf.Bytes()
+}
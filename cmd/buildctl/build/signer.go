@@ -0,0 +1,33 @@
+package build
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/session/signer/signerprovider"
+)
+
+// SigningKeyEphemeral generates a one-off signing key for the build.
+const SigningKeyEphemeral = "ephemeral"
+
+// ParseSigningKey parses --signing-key. For an ephemeral key the PEM encoded
+// public key is written to w so that the signatures can be verified.
+func ParseSigningKey(v string, w io.Writer) (session.Attachable, error) {
+	if v != SigningKeyEphemeral {
+		return signerprovider.FromKeyFile(v)
+	}
+	sp, pub, err := signerprovider.NewEphemeral()
+	if err != nil {
+		return nil, err
+	}
+	dt, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	if err := pem.Encode(w, &pem.Block{Type: "PUBLIC KEY", Bytes: dt}); err != nil {
+		return nil, err
+	}
+	return sp, nil
+}
@@ -18,5 +18,28 @@ var debugCommand = cli.Command{
 		debug.CtlCommand,
 		debug.GetCommand,
 		debug.HistoriesCommand,
+		debug.DiffImageCommand,
+		debugBuildCommand,
 	},
 }
+
+var debugBuildCommand = func() cli.Command {
+	cmd := buildCommand
+	cmd.Aliases = nil
+	cmd.Usage = "build with breakpoints"
+	cmd.UsageText = `
+	To pause a Dockerfile build before the instruction on line 42:
+	  $ buildctl debug build --frontend dockerfile.v0 --local context=. --local dockerfile=. --break Dockerfile:42
+	`
+	cmd.Flags = append(append([]cli.Flag{}, buildCommand.Flags...),
+		cli.StringSliceFlag{
+			Name:  "break",
+			Usage: "Pause before the step at a source location or vertex digest, e.g. --break Dockerfile:42",
+		},
+		cli.StringSliceFlag{
+			Name:  "break-after",
+			Usage: "Pause after the step at a source location or vertex digest, e.g. --break-after Dockerfile:42",
+		},
+	)
+	return cmd
+}()
@@ -0,0 +1,209 @@
+package debug
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/core/content/proxy"
+	"github.com/containerd/platforms"
+	"github.com/docker/cli/cli/config"
+	bccommon "github.com/moby/buildkit/cmd/buildctl/common"
+	"github.com/moby/buildkit/util/appcontext"
+	"github.com/moby/buildkit/util/contentutil"
+	"github.com/moby/buildkit/util/imagediff"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+)
+
+var DiffImageCommand = cli.Command{
+	Name:      "diff-image",
+	Usage:     "show the differences between two images",
+	ArgsUsage: "<ref-a> <ref-b>",
+	UsageText: `
+	Images are resolved from a registry, or from the content store of buildkitd
+	if given as a digest:
+	  $ buildctl debug diff-image docker.io/library/alpine:3.20 sha256:3f2c...
+	`,
+	Action: diffImage,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "platform",
+			Usage: "Platform of the images to compare, defaults to the platform of the client",
+		},
+		cli.StringFlag{
+			Name:  "format",
+			Usage: "Output format: text or json",
+			Value: "text",
+		},
+	},
+}
+
+func diffImage(clicontext *cli.Context) error {
+	args := clicontext.Args()
+	if len(args) != 2 {
+		return errors.Errorf("two image references must be specified")
+	}
+	format := clicontext.String("format")
+	if format != "text" && format != "json" {
+		return errors.Errorf("invalid format %q", format)
+	}
+
+	platform := platforms.DefaultSpec()
+	if v := clicontext.String("platform"); v != "" {
+		p, err := platforms.Parse(v)
+		if err != nil {
+			return errors.Wrapf(err, "invalid platform %q", v)
+		}
+		platform = p
+	}
+
+	ctx := appcontext.Context()
+
+	var store content.Store
+	resolve := func(ref string) (imagediff.Image, error) {
+		if dgst, err := digest.Parse(ref); err == nil {
+			if store == nil {
+				c, err := bccommon.ResolveClient(clicontext)
+				if err != nil {
+					return imagediff.Image{}, err
+				}
+				store = proxy.NewContentStore(c.ContentClient())
+			}
+			desc, err := descriptorFromStore(ctx, store, dgst)
+			if err != nil {
+				return imagediff.Image{}, err
+			}
+			return imagediff.Image{Provider: store, Desc: desc}, nil
+		}
+
+		dockerConfig := config.LoadDefaultConfigFile(os.Stderr)
+		desc, provider, err := contentutil.ProviderFromRef(ref, contentutil.WithCredentials(
+			func(host string) (string, string, error) {
+				ac, err := dockerConfig.GetAuthConfig(host)
+				if err != nil {
+					return "", "", err
+				}
+				return ac.Username, ac.Password, nil
+			}))
+		if err != nil {
+			return imagediff.Image{}, err
+		}
+		return imagediff.Image{Provider: provider, Desc: desc}, nil
+	}
+
+	a, err := resolve(args[0])
+	if err != nil {
+		return errors.Wrapf(err, "failed to resolve %s", args[0])
+	}
+	b, err := resolve(args[1])
+	if err != nil {
+		return errors.Wrapf(err, "failed to resolve %s", args[1])
+	}
+
+	res, err := imagediff.Diff(ctx, a, b, platforms.Only(platform))
+	if err != nil {
+		return err
+	}
+
+	if format == "json" {
+		enc := json.NewEncoder(clicontext.App.Writer)
+		enc.SetIndent("", "  ")
+		return enc.Encode(res)
+	}
+	return printImageDiff(clicontext.App.Writer, res)
+}
+
+// descriptorFromStore returns the descriptor of an index or manifest blob in
+// the content store, reading the media type from the blob.
+func descriptorFromStore(ctx context.Context, store content.Store, dgst digest.Digest) (ocispecs.Descriptor, error) {
+	info, err := store.Info(ctx, dgst)
+	if err != nil {
+		return ocispecs.Descriptor{}, err
+	}
+	desc := ocispecs.Descriptor{Digest: dgst, Size: info.Size}
+	dt, err := content.ReadBlob(ctx, store, desc)
+	if err != nil {
+		return ocispecs.Descriptor{}, err
+	}
+	var mt struct {
+		MediaType string `json:"mediaType"`
+	}
+	if err := json.Unmarshal(dt, &mt); err != nil {
+		return ocispecs.Descriptor{}, errors.Wrapf(err, "%s is not an image index or manifest", dgst)
+	}
+	if mt.MediaType == "" {
+		return ocispecs.Descriptor{}, errors.Errorf("%s has no media type", dgst)
+	}
+	desc.MediaType = mt.MediaType
+	return desc, nil
+}
+
+func printImageDiff(w io.Writer, res *imagediff.Result) error {
+	if res.Empty() {
+		_, err := fmt.Fprintf(w, "no differences between %s and %s\n", res.ManifestA, res.ManifestB)
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 1, 8, 1, '\t', 0)
+	fmt.Fprintf(tw, "--- %s\n+++ %s\n", res.ManifestA, res.ManifestB)
+
+	if len(res.Config) > 0 {
+		fmt.Fprintln(tw, "\nCONFIG\tA\tB")
+		for _, c := range res.Config {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", c.Field, jsonValue(c.A), jsonValue(c.B))
+		}
+	}
+	if len(res.Layers) > 0 {
+		fmt.Fprintln(tw, "\nLAYER\tA\tB")
+		for _, l := range res.Layers {
+			fmt.Fprintf(tw, "%d\t%s\t%s\n", l.Index, layerValue(l.A), layerValue(l.B))
+		}
+	}
+	if len(res.Files) > 0 {
+		fmt.Fprintln(tw, "\nFILE\tCHANGE\tA\tB")
+		for _, f := range res.Files {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", f.Path, f.Change, fileValue(f.A), fileValue(f.B))
+		}
+	}
+	if len(res.Attestations) > 0 {
+		fmt.Fprintln(tw, "\nATTESTATION\tCHANGE")
+		for _, a := range res.Attestations {
+			fmt.Fprintf(tw, "%s\t%s\n", a.PredicateType, a.Change)
+		}
+	}
+	return tw.Flush()
+}
+
+func jsonValue(v any) string {
+	if v == nil {
+		return "-"
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	dt, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(dt)
+}
+
+func layerValue(desc *ocispecs.Descriptor) string {
+	if desc == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%s (%d bytes)", desc.Digest, desc.Size)
+}
+
+func fileValue(f *imagediff.File) string {
+	if f == nil {
+		return "-"
+	}
+	return f.String()
+}
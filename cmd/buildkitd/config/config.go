@@ -25,6 +25,8 @@ type Config struct {
 
 	CDI CDIConfig `toml:"cdi"`
 
+	Attestation AttestationConfig `toml:"attestation"`
+
 	Workers struct {
 		OCI        OCIConfig        `toml:"oci"`
 		Containerd ContainerdConfig `toml:"containerd"`
@@ -32,6 +34,12 @@ type Config struct {
 
 	Registries map[string]resolverconfig.RegistryConfig `toml:"registry"`
 
+	// HTTP configures the http source per URL host.
+	HTTP map[string]HTTPConfig `toml:"http"`
+
+	// Sources configures external source providers per source scheme.
+	Sources map[string]SourceConfig `toml:"source"`
+
 	DNS *DNSConfig `toml:"dns"`
 
 	History *HistoryConfig `toml:"history"`
@@ -76,6 +84,24 @@ type OTELConfig struct {
 	SocketPath string `toml:"socketPath"`
 }
 
+type HTTPConfig struct {
+	// Mirrors are URL prefixes that replace the scheme and host of the URLs
+	// of the host. They are tried in order before the URL itself.
+	Mirrors []string `toml:"mirrors"`
+}
+
+type SourceConfig struct {
+	// Address of the provider implementing the source protocol, in the form
+	// of unix://<path> or tcp://<host>:<port>.
+	Address string `toml:"address"`
+}
+
+type AttestationConfig struct {
+	// SigningKey is the path to a PEM encoded private key used to sign
+	// attestations when the client doesn't provide a signer.
+	SigningKey string `toml:"signingKey"`
+}
+
 type CDIConfig struct {
 	Disabled    *bool    `toml:"disabled"`
 	SpecDirs    []string `toml:"specDirs"`
@@ -99,6 +125,10 @@ type NetworkConfig struct {
 	CNIPoolSize   int    `toml:"cniPoolSize"`
 	BridgeName    string `toml:"bridgeName"`
 	BridgeSubnet  string `toml:"bridgeSubnet"`
+	// NetworkAccessLog allows recording the DNS lookups and connections of
+	// exec steps that request it in the provenance. Only supported by the OCI
+	// worker with cni and bridge network modes.
+	NetworkAccessLog bool `toml:"networkAccessLog"`
 }
 
 type OCIConfig struct {
@@ -118,6 +148,9 @@ type OCIConfig struct {
 	ProxySnapshotterPath string `toml:"proxySnapshotterPath"`
 	DefaultCgroupParent  string `toml:"defaultCgroupParent"`
 
+	// DefaultResourceLimits are applied to build containers that don't set their own limits.
+	DefaultResourceLimits ResourceLimitsConfig `toml:"defaultResourceLimits"`
+
 	// StargzSnapshotterConfig is configuration for stargz snapshotter.
 	// We use a generic map[string]interface{} in order to remove the dependency
 	// on stargz snapshotter's config pkg from our config.
@@ -156,9 +189,21 @@ type ContainerdConfig struct {
 
 	DefaultCgroupParent string `toml:"defaultCgroupParent"`
 
+	// DefaultResourceLimits are applied to build containers that don't set their own limits.
+	DefaultResourceLimits ResourceLimitsConfig `toml:"defaultResourceLimits"`
+
 	Rootless bool `toml:"rootless"`
 }
 
+type ResourceLimitsConfig struct {
+	// Memory is the memory limit, e.g. "4GB".
+	Memory string `toml:"memory"`
+	// CPUs is the number of CPUs a container can use. Fractional values are allowed.
+	CPUs float64 `toml:"cpus"`
+	// PIDs is the maximum number of processes in a container.
+	PIDs int64 `toml:"pids"`
+}
+
 type ContainerdRuntime struct {
 	Name    string         `toml:"name"`
 	Path    string         `toml:"path"`
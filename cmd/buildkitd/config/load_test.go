@@ -72,6 +72,12 @@ tlsconfigdir=["/etc/buildkitd/myregistry"]
 key="key.pem"
 cert="cert.pem"
 
+[http."downloads.example.com"]
+mirrors=["https://mirror.example.com/downloads"]
+
+[source."s3"]
+address="unix:///run/buildkit/s3-source.sock"
+
 [dns]
 nameservers=["1.1.1.1","8.8.8.8"]
 options=["edns0"]
@@ -144,6 +150,9 @@ searchDomains=["example.com"]
 	require.Equal(t, "key.pem", cfg.Registries["docker.io"].KeyPairs[0].Key)
 	require.Equal(t, "cert.pem", cfg.Registries["docker.io"].KeyPairs[0].Certificate)
 
+	require.Equal(t, []string{"https://mirror.example.com/downloads"}, cfg.HTTP["downloads.example.com"].Mirrors)
+	require.Equal(t, "unix:///run/buildkit/s3-source.sock", cfg.Sources["s3"].Address)
+
 	require.NotNil(t, cfg.DNS)
 	require.Equal(t, []string{"1.1.1.1", "8.8.8.8"}, cfg.DNS.Nameservers)
 	require.Equal(t, []string{"example.com"}, cfg.DNS.SearchDomains)
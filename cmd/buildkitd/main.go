@@ -32,6 +32,7 @@ import (
 	"github.com/moby/buildkit/cmd/buildkitd/config"
 	"github.com/moby/buildkit/control"
 	"github.com/moby/buildkit/executor/oci"
+	"github.com/moby/buildkit/exporter/attestation"
 	"github.com/moby/buildkit/frontend"
 	dockerfile "github.com/moby/buildkit/frontend/dockerfile/builder"
 	"github.com/moby/buildkit/frontend/gateway"
@@ -90,9 +91,10 @@ func init() {
 var propagators = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
 
 type workerInitializerOpt struct {
-	config         *config.Config
-	sessionManager *session.Manager
-	traceSocket    string
+	config            *config.Config
+	sessionManager    *session.Manager
+	traceSocket       string
+	attestationSigner attestation.Signer
 }
 
 type workerInitializer struct {
@@ -810,10 +812,16 @@ func newController(ctx context.Context, c *cli.Context, cfg *config.Config) (*co
 		}
 	}
 
+	attestationSigner, err := attestationSignerFromConfig(cfg.Attestation)
+	if err != nil {
+		return nil, err
+	}
+
 	wc, err := newWorkerController(c, workerInitializerOpt{
-		config:         cfg,
-		sessionManager: sessionManager,
-		traceSocket:    traceSocket,
+		config:            cfg,
+		sessionManager:    sessionManager,
+		traceSocket:       traceSocket,
+		attestationSigner: attestationSigner,
 	})
 	if err != nil {
 		return nil, err
@@ -892,6 +900,24 @@ func resolverFunc(cfg *config.Config) docker.RegistryHosts {
 	return resolver.NewRegistryConfig(cfg.Registries)
 }
 
+func httpMirrors(cfg *config.Config) map[string][]string {
+	m := make(map[string][]string, len(cfg.HTTP))
+	for host, c := range cfg.HTTP {
+		if len(c.Mirrors) > 0 {
+			m[host] = c.Mirrors
+		}
+	}
+	return m
+}
+
+func sourceProviders(cfg *config.Config) map[string]string {
+	m := make(map[string]string, len(cfg.Sources))
+	for scheme, c := range cfg.Sources {
+		m[scheme] = c.Address
+	}
+	return m
+}
+
 func newWorkerController(c *cli.Context, wiOpt workerInitializerOpt) (*worker.Controller, error) {
 	wc := &worker.Controller{}
 	nWorkers := 0
@@ -329,6 +329,11 @@ func containerdWorkerInitializer(c *cli.Context, common workerInitializerOpt) ([
 		}
 	}
 
+	resourceLimits, err := resourceLimitsFromConfig(cfg.DefaultResourceLimits)
+	if err != nil {
+		return nil, err
+	}
+
 	workerOpts := containerd.WorkerOptions{
 		Root:            common.config.Root,
 		Address:         cfg.Address,
@@ -345,6 +350,7 @@ func containerdWorkerInitializer(c *cli.Context, common workerInitializerOpt) ([
 		TraceSocket:     common.traceSocket,
 		Runtime:         runtime,
 		CDIManager:      cdiManager,
+		ResourceLimits:  resourceLimits,
 	}
 
 	opt, err := containerd.NewWorkerOpt(workerOpts, ctd.WithTimeout(60*time.Second))
@@ -353,7 +359,10 @@ func containerdWorkerInitializer(c *cli.Context, common workerInitializerOpt) ([
 	}
 	opt.GCPolicy = getGCPolicy(cfg.GCConfig, common.config.Root)
 	opt.BuildkitVersion = getBuildkitVersion()
+	opt.AttestationSigner = common.attestationSigner
 	opt.RegistryHosts = resolverFunc(common.config)
+	opt.HTTPMirrors = httpMirrors(common.config)
+	opt.SourceProviders = sourceProviders(common.config)
 
 	if platformsStr := cfg.Platforms; len(platformsStr) != 0 {
 		platforms, err := parsePlatforms(platformsStr)
@@ -311,6 +311,7 @@ func ociWorkerInitializer(c *cli.Context, common workerInitializerOpt) ([]worker
 			PoolSize:     common.config.Workers.OCI.CNIPoolSize,
 			BridgeName:   common.config.Workers.OCI.BridgeName,
 			BridgeSubnet: common.config.Workers.OCI.BridgeSubnet,
+			AccessLog:    common.config.Workers.OCI.NetworkAccessLog,
 		},
 	}
 
@@ -319,13 +320,21 @@ func ociWorkerInitializer(c *cli.Context, common workerInitializerOpt) ([]worker
 		parallelismSem = semaphore.NewWeighted(int64(cfg.MaxParallelism))
 	}
 
-	opt, err := runc.NewWorkerOpt(common.config.Root, snFactory, cfg.Rootless, processMode, cfg.Labels, idmapping, nc, dns, cfg.Binary, cfg.ApparmorProfile, cfg.SELinux, parallelismSem, common.traceSocket, cfg.DefaultCgroupParent, cdiManager)
+	resourceLimits, err := resourceLimitsFromConfig(cfg.DefaultResourceLimits)
+	if err != nil {
+		return nil, err
+	}
+
+	opt, err := runc.NewWorkerOpt(common.config.Root, snFactory, cfg.Rootless, processMode, cfg.Labels, idmapping, nc, dns, cfg.Binary, cfg.ApparmorProfile, cfg.SELinux, parallelismSem, common.traceSocket, cfg.DefaultCgroupParent, resourceLimits, cdiManager)
 	if err != nil {
 		return nil, err
 	}
 	opt.GCPolicy = getGCPolicy(cfg.GCConfig, common.config.Root)
 	opt.BuildkitVersion = getBuildkitVersion()
+	opt.AttestationSigner = common.attestationSigner
 	opt.RegistryHosts = hosts
+	opt.HTTPMirrors = httpMirrors(common.config)
+	opt.SourceProviders = sourceProviders(common.config)
 
 	if platformsStr := cfg.Platforms; len(platformsStr) != 0 {
 		platforms, err := parsePlatforms(platformsStr)
@@ -4,7 +4,11 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/docker/go-units"
 	"github.com/moby/buildkit/cmd/buildkitd/config"
+	"github.com/moby/buildkit/exporter/attestation"
+	"github.com/moby/buildkit/session/signer"
+	"github.com/moby/buildkit/solver/pb"
 	"github.com/moby/buildkit/util/disk"
 	"github.com/pkg/errors"
 )
@@ -66,3 +70,35 @@ func stringToGCConfig(in string) (config.GCConfig, error) {
 	cfg.GCMaxUsedSpace = config.DiskSpace{Bytes: max * 1e6}
 	return cfg, nil
 }
+
+func resourceLimitsFromConfig(cfg config.ResourceLimitsConfig) (*pb.ResourceLimits, error) {
+	rl := &pb.ResourceLimits{
+		NanoCPUs: int64(cfg.CPUs * 1e9),
+		Pids:     cfg.PIDs,
+	}
+	if cfg.Memory != "" {
+		v, err := units.RAMInBytes(cfg.Memory)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse memory limit %q", cfg.Memory)
+		}
+		rl.Memory = v
+	}
+	if rl.Memory < 0 || rl.NanoCPUs < 0 || rl.Pids < 0 {
+		return nil, errors.Errorf("invalid negative resource limits")
+	}
+	if rl.Memory == 0 && rl.NanoCPUs == 0 && rl.Pids == 0 {
+		return nil, nil
+	}
+	return rl, nil
+}
+
+func attestationSignerFromConfig(cfg config.AttestationConfig) (attestation.Signer, error) {
+	if cfg.SigningKey == "" {
+		return nil, nil
+	}
+	key, err := signer.LoadKey(cfg.SigningKey)
+	if err != nil {
+		return nil, err
+	}
+	return attestation.NewKeySigner(key)
+}
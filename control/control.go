@@ -27,6 +27,7 @@ import (
 	"github.com/moby/buildkit/exporter/util/epoch"
 	"github.com/moby/buildkit/frontend"
 	"github.com/moby/buildkit/frontend/attestations"
+	"github.com/moby/buildkit/frontend/attestations/sbom"
 	"github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/session/grpchijack"
 	containerdsnapshot "github.com/moby/buildkit/snapshot/containerd"
@@ -477,35 +478,40 @@ func (c *Controller) Solve(ctx context.Context, req *controlapi.SolveRequest) (*
 
 	var procs []llbsolver.Processor
 
+	useCache := true
+	if v, ok := req.FrontendAttrs["no-cache"]; ok && v == "" {
+		// disable cache if cache is disabled for all stages
+		useCache = false
+	}
+	resolveMode := llb.ResolveModeDefault.String()
+	if v, ok := req.FrontendAttrs["image-resolve-mode"]; ok {
+		resolveMode = v
+	}
+
 	if attrs, ok := attests["sbom"]; ok {
-		var ref reference.Named
-		params := make(map[string]string)
-		for k, v := range attrs {
-			if k == "generator" {
-				if v == "" {
-					return nil, errors.Errorf("sbom generator cannot be empty")
-				}
-				ref, err = reference.ParseNormalizedNamed(v)
-				if err != nil {
-					return nil, errors.Wrapf(err, "failed to parse sbom generator %s", v)
-				}
-				ref = reference.TagNameOnly(ref)
-			} else {
-				params[k] = v
-			}
+		generator, params, err := parseGenerator("sbom", attrs, sbom.BuiltinGenerator)
+		if err != nil {
+			return nil, err
+		}
+		sbomEpoch, err := epoch.ParseBuildArgsTime(req.FrontendAttrs)
+		if err != nil {
+			return nil, err
 		}
+		procs = append(procs, proc.SBOMProcessor(generator, useCache, resolveMode, params, sbomEpoch))
+	}
 
-		useCache := true
-		if v, ok := req.FrontendAttrs["no-cache"]; ok && v == "" {
-			// disable cache if cache is disabled for all stages
-			useCache = false
+	if attrs, ok := attests["vuln"]; ok {
+		if _, ok := attests["sbom"]; !ok {
+			return nil, errors.Errorf("vuln attestation requires sbom attestation")
 		}
-		resolveMode := llb.ResolveModeDefault.String()
-		if v, ok := req.FrontendAttrs["image-resolve-mode"]; ok {
-			resolveMode = v
+		generator, params, err := parseGenerator("vuln", attrs, "")
+		if err != nil {
+			return nil, err
 		}
-
-		procs = append(procs, proc.SBOMProcessor(ref.String(), useCache, resolveMode, params))
+		if generator == "" {
+			return nil, errors.Errorf("vuln generator cannot be empty")
+		}
+		procs = append(procs, proc.VulnProcessor(generator, useCache, resolveMode, params))
 	}
 
 	if attrs, ok := attests["provenance"]; ok {
@@ -543,6 +549,33 @@ func (c *Controller) Solve(ctx context.Context, req *controlapi.SolveRequest) (*
 	}, nil
 }
 
+// parseGenerator splits the attrs of an attestation into the generator image
+// and the parameters passed to the generator. builtin is the name of a
+// generator that is not an image, if the attestation has one.
+func parseGenerator(name string, attrs map[string]string, builtin string) (string, map[string]string, error) {
+	var generator string
+	params := make(map[string]string)
+	for k, v := range attrs {
+		if k != "generator" {
+			params[k] = v
+			continue
+		}
+		if v == "" {
+			return "", nil, errors.Errorf("%s generator cannot be empty", name)
+		}
+		if builtin != "" && v == builtin {
+			generator = v
+			continue
+		}
+		ref, err := reference.ParseNormalizedNamed(v)
+		if err != nil {
+			return "", nil, errors.Wrapf(err, "failed to parse %s generator %s", name, v)
+		}
+		generator = reference.TagNameOnly(ref).String()
+	}
+	return generator, params, nil
+}
+
 func (c *Controller) Status(req *controlapi.StatusRequest, stream controlapi.Control_StatusServer) error {
 	if err := sendTimestampHeader(stream); err != nil {
 		return err
@@ -146,3 +146,25 @@ func TestParseCacheExportIgnoreError(t *testing.T) {
 		})
 	}
 }
+
+func TestParseGenerator(t *testing.T) {
+	generator, params, err := parseGenerator("sbom", map[string]string{"generator": "builtin", "format": "cyclonedx"}, "builtin")
+	require.NoError(t, err)
+	require.Equal(t, "builtin", generator)
+	require.Equal(t, map[string]string{"format": "cyclonedx"}, params)
+
+	generator, params, err = parseGenerator("vuln", map[string]string{"generator": "example/scanner", "predicate-type": "https://openvex.dev/ns/v0.2.0"}, "")
+	require.NoError(t, err)
+	require.Equal(t, "docker.io/example/scanner:latest", generator)
+	require.Equal(t, map[string]string{"predicate-type": "https://openvex.dev/ns/v0.2.0"}, params)
+
+	generator, _, err = parseGenerator("sbom", map[string]string{}, "builtin")
+	require.NoError(t, err)
+	require.Empty(t, generator)
+
+	_, _, err = parseGenerator("vuln", map[string]string{"generator": ""}, "")
+	require.ErrorContains(t, err, "vuln generator cannot be empty")
+
+	_, _, err = parseGenerator("vuln", map[string]string{"generator": "Invalid:ref:"}, "")
+	require.ErrorContains(t, err, "failed to parse vuln generator")
+}
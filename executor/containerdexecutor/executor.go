@@ -33,6 +33,7 @@ type containerdExecutor struct {
 	root             string
 	networkProviders map[pb.NetMode]network.Provider
 	cgroupParent     string
+	resourceLimits   *pb.ResourceLimits
 	dnsConfig        *oci.DNSConfig
 	running          map[string]*containerState
 	mu               sync.Mutex
@@ -67,6 +68,7 @@ type ExecutorOptions struct {
 	Client           *ctd.Client
 	Root             string
 	CgroupParent     string
+	ResourceLimits   *pb.ResourceLimits
 	NetworkProviders map[pb.NetMode]network.Provider
 	DNSConfig        *oci.DNSConfig
 	ApparmorProfile  string
@@ -88,6 +90,7 @@ func New(executorOpts ExecutorOptions) executor.Executor {
 		root:             executorOpts.Root,
 		networkProviders: executorOpts.NetworkProviders,
 		cgroupParent:     executorOpts.CgroupParent,
+		resourceLimits:   executorOpts.ResourceLimits,
 		dnsConfig:        executorOpts.DNSConfig,
 		running:          make(map[string]*containerState),
 		apparmorProfile:  executorOpts.ApparmorProfile,
@@ -158,7 +161,7 @@ func (w *containerdExecutor) Run(ctx context.Context, id string, root executor.M
 		return nil, err
 	}
 
-	namespace, err := provider.New(ctx, meta.Hostname)
+	namespace, err := provider.New(network.WithAccessLog(ctx, meta.NetworkAccessLog), meta.Hostname)
 	if err != nil {
 		return nil, err
 	}
@@ -433,6 +436,7 @@ func (w *containerdExecutor) runProcess(ctx context.Context, p ctd.Process, resi
 			if status.ExitCode() == gatewayapi.UnknownExitStatus && status.Error() != nil {
 				exitErr.Err = errors.Wrap(status.Error(), "failure waiting for process")
 			}
+			exitErr.Reason = executor.ExitReason(ctx, exitErr.ExitCode)
 			select {
 			case <-ctx.Done():
 				exitErr.Err = errors.Wrap(context.Cause(ctx), exitErr.Error())
@@ -150,6 +150,7 @@ func (w *containerdExecutor) createOCISpec(ctx context.Context, id, resolvConf,
 	// }
 
 	processMode := oci.ProcessSandbox // FIXME(AkihiroSuda)
+	meta.ResourceLimits = oci.MergeResourceLimits(w.resourceLimits, meta.ResourceLimits)
 	spec, cleanup, err := oci.GenerateSpec(ctx, meta, mounts, id, resolvConf, hostsFile, namespace, w.cgroupParent, processMode, nil, w.apparmorProfile, w.selinux, w.traceSocket, w.cdiManager, opts...)
 	if err != nil {
 		releaseAll()
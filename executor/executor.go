@@ -24,9 +24,13 @@ type Meta struct {
 	Ulimit         []*pb.Ulimit
 	CDIDevices     []*pb.CDIDevice
 	CgroupParent   string
+	ResourceLimits *pb.ResourceLimits
 	NetMode        pb.NetMode
 	SecurityMode   pb.SecurityMode
 	ValidExitCodes []int
+	// NetworkAccessLog requests recording the network access of the process
+	// if the network provider supports it.
+	NetworkAccessLog bool
 
 	RemoveMountStubsRecursive bool
 }
@@ -58,6 +62,9 @@ type ProcessInfo struct {
 	Stdout, Stderr io.WriteCloser
 	Resize         <-chan WinSize
 	Signal         <-chan syscall.Signal
+	// Stats is an optional callback receiving resource usage samples while
+	// the container started by Run is running.
+	Stats func(*resourcestypes.Sample)
 }
 
 type Executor interface {
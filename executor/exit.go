@@ -0,0 +1,30 @@
+package executor
+
+import (
+	"context"
+	"errors"
+
+	"github.com/moby/buildkit/solver/errdefs"
+)
+
+// ExitReason returns why a process exited with exitCode based on the state
+// of ctx and the exit status reported by the runtime. Returns nil if the
+// reason is not known.
+func ExitReason(ctx context.Context, exitCode uint32) *errdefs.ExitReason {
+	select {
+	case <-ctx.Done():
+		if errors.Is(context.Cause(ctx), context.DeadlineExceeded) {
+			return &errdefs.ExitReason{Type: errdefs.ExitReasonType_TIMEOUT}
+		}
+		return &errdefs.ExitReason{Type: errdefs.ExitReasonType_CANCELLED}
+	default:
+	}
+	if sig, name, ok := exitSignal(exitCode); ok {
+		return &errdefs.ExitReason{
+			Type:       errdefs.ExitReasonType_SIGNALED,
+			Signal:     int32(sig),
+			SignalName: name,
+		}
+	}
+	return nil
+}
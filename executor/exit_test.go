@@ -0,0 +1,57 @@
+package executor
+
+import (
+	"context"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/moby/buildkit/solver/errdefs"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExitReason(t *testing.T) {
+	t.Parallel()
+
+	require.Nil(t, ExitReason(context.TODO(), 0))
+	require.Nil(t, ExitReason(context.TODO(), 1))
+	// unknown exit status
+	require.Nil(t, ExitReason(context.TODO(), 255))
+
+	for _, tc := range []struct {
+		exitCode uint32
+		sig      syscall.Signal
+		name     string
+	}{
+		// crashes are reported with the signal from the wait status
+		{exitCode: 139, sig: syscall.SIGSEGV, name: "SIGSEGV"},
+		{exitCode: 128 + uint32(syscall.SIGBUS), sig: syscall.SIGBUS, name: "SIGBUS"},
+		// e.g. killed by the OOM killer outside of the container cgroup
+		{exitCode: 137, sig: syscall.SIGKILL, name: "SIGKILL"},
+	} {
+		r := ExitReason(context.TODO(), tc.exitCode)
+		if runtime.GOOS == "windows" {
+			require.Nil(t, r)
+			continue
+		}
+		require.NotNil(t, r)
+		require.Equal(t, errdefs.ExitReasonType_SIGNALED, r.Type)
+		require.Equal(t, int32(tc.sig), r.Signal)
+		require.Equal(t, tc.name, r.SignalName)
+	}
+
+	ctx, cancel := context.WithCancelCause(context.TODO())
+	cancel(errors.WithStack(context.Canceled))
+	r := ExitReason(ctx, 137)
+	require.NotNil(t, r)
+	require.Equal(t, errdefs.ExitReasonType_CANCELLED, r.Type)
+
+	ctx, cancel2 := context.WithTimeoutCause(context.TODO(), time.Nanosecond, errors.WithStack(context.DeadlineExceeded))
+	defer cancel2()
+	<-ctx.Done()
+	r = ExitReason(ctx, 0)
+	require.NotNil(t, r)
+	require.Equal(t, errdefs.ExitReasonType_TIMEOUT, r.Type)
+}
@@ -0,0 +1,21 @@
+//go:build !windows
+
+package executor
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// exitSignal returns the signal that terminated a process from its exit
+// status. runc and the containerd shims take the status from wait(2) and
+// report a process terminated by a signal, e.g. SIGSEGV or a kill by the OOM
+// killer, with the exit code 128+signal.
+func exitSignal(exitCode uint32) (syscall.Signal, string, bool) {
+	if exitCode <= 128 || exitCode > 128+64 {
+		return 0, "", false
+	}
+	sig := syscall.Signal(exitCode - 128)
+	return sig, unix.SignalName(sig), true
+}
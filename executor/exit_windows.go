@@ -0,0 +1,9 @@
+package executor
+
+import "syscall"
+
+// exitSignal always returns false as Windows processes are not terminated by
+// signals.
+func exitSignal(uint32) (syscall.Signal, string, bool) {
+	return 0, "", false
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/moby/buildkit/executor"
 	"github.com/moby/buildkit/snapshot"
 	"github.com/moby/buildkit/solver/llbsolver/cdidevices"
+	"github.com/moby/buildkit/solver/pb"
 	"github.com/moby/buildkit/util/network"
 	rootlessmountopts "github.com/moby/buildkit/util/rootless/mountopts"
 	"github.com/moby/buildkit/util/system"
@@ -57,6 +58,30 @@ func (pm ProcessMode) String() string {
 	}
 }
 
+// MergeResourceLimits returns resource limits of an exec with any unset
+// values taken from the defaults. Returns nil if no limits are set.
+func MergeResourceLimits(defaults, rl *pb.ResourceLimits) *pb.ResourceLimits {
+	out := &pb.ResourceLimits{}
+	if defaults != nil {
+		out = defaults.CloneVT()
+	}
+	if rl != nil {
+		if rl.Memory != 0 {
+			out.Memory = rl.Memory
+		}
+		if rl.NanoCPUs != 0 {
+			out.NanoCPUs = rl.NanoCPUs
+		}
+		if rl.Pids != 0 {
+			out.Pids = rl.Pids
+		}
+	}
+	if out.Memory == 0 && out.NanoCPUs == 0 && out.Pids == 0 {
+		return nil
+	}
+	return out
+}
+
 // Ideally we don't have to import whole containerd just for the default spec
 
 // GenerateSpec generates spec using containerd functionality.
@@ -112,6 +137,12 @@ func GenerateSpec(ctx context.Context, meta executor.Meta, mounts []executor.Mou
 		return nil, nil, err
 	}
 
+	resourceOpts, err := generateResourceLimitOpts(meta.ResourceLimits)
+	if err != nil {
+		return nil, nil, err
+	}
+	opts = append(opts, resourceOpts...)
+
 	hostname := defaultHostname
 	if meta.Hostname != "" {
 		hostname = meta.Hostname
@@ -140,7 +171,6 @@ func GenerateSpec(ctx context.Context, meta executor.Meta, mounts []executor.Mou
 	}
 
 	var s *oci.Spec
-	var err error
 
 	if runtime.GOOS == "darwin" && runtime.GOARCH == "arm64" {
 		// note: if we use the WithPlatform option instead of calling this other funciton,
@@ -49,6 +49,13 @@ func generateRlimitOpts(ulimits []*pb.Ulimit) ([]oci.SpecOpts, error) {
 	return nil, errors.New("no support for POSIXRlimit on FreeBSD")
 }
 
+func generateResourceLimitOpts(rl *pb.ResourceLimits) ([]oci.SpecOpts, error) {
+	if rl == nil {
+		return nil, nil
+	}
+	return nil, errors.New("no support for resource limits on FreeBSD")
+}
+
 // tracing is not implemented on FreeBSD
 func getTracingSocketMount(_ string) *specs.Mount {
 	return nil
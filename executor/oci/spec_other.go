@@ -146,6 +146,34 @@ func generateRlimitOpts(ulimits []*pb.Ulimit) ([]oci.SpecOpts, error) {
 	}, nil
 }
 
+// cpuPeriod is the default CFS scheduler period used for CPU limits.
+const cpuPeriod = 100000
+
+// minNanoCPUs is the smallest CPU limit. It maps to the minimum CFS quota of
+// 1000µs, smaller quotas are rejected or treated as no limit by the kernel.
+const minNanoCPUs = 1e7
+
+func generateResourceLimitOpts(rl *pb.ResourceLimits) ([]oci.SpecOpts, error) {
+	if rl == nil {
+		return nil, nil
+	}
+	var opts []oci.SpecOpts
+	if rl.Memory > 0 {
+		opts = append(opts, oci.WithMemoryLimit(uint64(rl.Memory)))
+	}
+	if rl.NanoCPUs > 0 {
+		if rl.NanoCPUs < minNanoCPUs {
+			return nil, errors.Errorf("cpu limit %g is below the minimum of 0.01", float64(rl.NanoCPUs)/1e9)
+		}
+		quota := rl.NanoCPUs * cpuPeriod / 1e9
+		opts = append(opts, oci.WithCPUCFS(quota, cpuPeriod))
+	}
+	if rl.Pids > 0 {
+		opts = append(opts, oci.WithPidsLimit(rl.Pids))
+	}
+	return opts, nil
+}
+
 // genereateCDIOptions creates the OCI runtime spec options for injecting CDI
 // devices.
 func generateCDIOpts(manager *cdidevices.Manager, devs []*pb.CDIDevice) ([]oci.SpecOpts, error) {
@@ -0,0 +1,82 @@
+//go:build linux || darwin
+
+package oci
+
+import (
+	"context"
+	"testing"
+
+	"github.com/containerd/containerd/v2/core/containers"
+	"github.com/moby/buildkit/solver/pb"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateResourceLimitOpts(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name   string
+		rl     *pb.ResourceLimits
+		memory int64
+		quota  int64
+		pids   int64
+		err    string
+	}{
+		{
+			name: "none",
+		},
+		{
+			name:   "all",
+			rl:     &pb.ResourceLimits{Memory: 512 << 20, NanoCPUs: 1.5e9, Pids: 100},
+			memory: 512 << 20,
+			quota:  150000,
+			pids:   100,
+		},
+		{
+			name:  "min cpus",
+			rl:    &pb.ResourceLimits{NanoCPUs: 1e7},
+			quota: 1000,
+		},
+		{
+			name: "below min cpus",
+			rl:   &pb.ResourceLimits{NanoCPUs: 9999},
+			err:  "cpu limit 9.999e-06 is below the minimum of 0.01",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			opts, err := generateResourceLimitOpts(tc.rl)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+
+			// the default spec has the resources set
+			s := &specs.Spec{Linux: &specs.Linux{Resources: &specs.LinuxResources{
+				CPU:    &specs.LinuxCPU{},
+				Memory: &specs.LinuxMemory{},
+			}}}
+			for _, opt := range opts {
+				require.NoError(t, opt(context.TODO(), nil, &containers.Container{}, s))
+			}
+			res := s.Linux.Resources
+			if tc.memory != 0 {
+				require.Equal(t, tc.memory, *res.Memory.Limit)
+			} else {
+				require.Nil(t, res.Memory.Limit)
+			}
+			if tc.quota != 0 {
+				require.Equal(t, tc.quota, *res.CPU.Quota)
+				require.Equal(t, uint64(cpuPeriod), *res.CPU.Period)
+			} else {
+				require.Nil(t, res.CPU.Quota)
+			}
+			if tc.pids != 0 {
+				require.Equal(t, tc.pids, res.Pids.Limit)
+			} else {
+				require.Nil(t, res.Pids)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package oci
+
+import (
+	"testing"
+
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeResourceLimits(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name     string
+		defaults *pb.ResourceLimits
+		rl       *pb.ResourceLimits
+		expected *pb.ResourceLimits
+	}{
+		{
+			name: "none",
+		},
+		{
+			name:     "empty",
+			defaults: &pb.ResourceLimits{},
+			rl:       &pb.ResourceLimits{},
+		},
+		{
+			name:     "defaults",
+			defaults: &pb.ResourceLimits{Memory: 1 << 30, NanoCPUs: 2e9},
+			expected: &pb.ResourceLimits{Memory: 1 << 30, NanoCPUs: 2e9},
+		},
+		{
+			name:     "exec",
+			rl:       &pb.ResourceLimits{Pids: 100},
+			expected: &pb.ResourceLimits{Pids: 100},
+		},
+		{
+			name:     "override",
+			defaults: &pb.ResourceLimits{Memory: 1 << 30, NanoCPUs: 2e9, Pids: 1000},
+			rl:       &pb.ResourceLimits{NanoCPUs: 5e8},
+			expected: &pb.ResourceLimits{Memory: 1 << 30, NanoCPUs: 5e8, Pids: 1000},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var defaults *pb.ResourceLimits
+			if tc.defaults != nil {
+				defaults = tc.defaults.CloneVT()
+			}
+			out := MergeResourceLimits(tc.defaults, tc.rl)
+			if tc.expected == nil {
+				require.Nil(t, out)
+				return
+			}
+			require.True(t, tc.expected.EqualVT(out), "expected %v, got %v", tc.expected, out)
+			// the defaults are not modified
+			require.True(t, defaults.EqualVT(tc.defaults))
+		})
+	}
+}
@@ -87,6 +87,13 @@ func generateRlimitOpts(ulimits []*pb.Ulimit) ([]oci.SpecOpts, error) {
 	return nil, errors.New("no support for POSIXRlimit on Windows")
 }
 
+func generateResourceLimitOpts(rl *pb.ResourceLimits) ([]oci.SpecOpts, error) {
+	if rl == nil {
+		return nil, nil
+	}
+	return nil, errors.New("no support for resource limits on Windows")
+}
+
 func getTracingSocketMount(socket string) *specs.Mount {
 	return &specs.Mount{
 		Destination: filepath.FromSlash(tracingSocketPath),
@@ -30,14 +30,17 @@ type cgroupRecord struct {
 	once         sync.Once
 	ns           string
 	sampler      *Sub[*resourcestypes.Sample]
+	liveSampler  *Sub[*resourcestypes.Sample]
 	closeSampler func() error
 	samples      []*resourcestypes.Sample
 	err          error
 	done         chan struct{}
 	monitor      *Monitor
 	netSampler   NetworkSampler
+	onSample     func(*resourcestypes.Sample)
 	startCPUStat *procfs.CPUStat
 	sysCPUStat   *resourcestypes.SysCPUStat
+	netAccess    *resourcestypes.NetworkAccessLog
 }
 
 func (r *cgroupRecord) Wait() error {
@@ -52,6 +55,9 @@ func (r *cgroupRecord) Start() {
 	}
 	s := NewSampler(2*time.Second, 10, r.sample)
 	r.sampler = s.Record()
+	if r.onSample != nil {
+		r.liveSampler = s.Subscribe(r.onSample)
+	}
 	r.closeSampler = s.Close
 }
 
@@ -75,9 +81,15 @@ func (r *cgroupRecord) close() {
 			delete(r.monitor.records, r.ns)
 			r.monitor.mu.Unlock()
 		}()
+		if l, ok := r.netSampler.(NetworkAccessLogger); ok {
+			r.netAccess = l.AccessLog()
+		}
 		if r.sampler == nil {
 			return
 		}
+		if r.liveSampler != nil {
+			r.liveSampler.Close(false)
+		}
 		s, err := r.sampler.Close(true)
 		if err != nil {
 			r.err = err
@@ -147,8 +159,9 @@ func (r *cgroupRecord) Samples() (*resourcestypes.Samples, error) {
 		return nil, r.err
 	}
 	return &resourcestypes.Samples{
-		Samples:    r.samples,
-		SysCPUStat: r.sysCPUStat,
+		Samples:       r.samples,
+		SysCPUStat:    r.sysCPUStat,
+		NetworkAccess: r.netAccess,
 	}, nil
 }
 
@@ -184,8 +197,17 @@ type NetworkSampler interface {
 	Sample() (*resourcestypes.NetworkSample, error)
 }
 
+// NetworkAccessLogger is implemented by network samplers that record the DNS
+// lookups and connections made from the namespace.
+type NetworkAccessLogger interface {
+	AccessLog() *resourcestypes.NetworkAccessLog
+}
+
 type RecordOpt struct {
 	NetworkSampler NetworkSampler
+	// OnSample is called with every sample while the record is running so
+	// that resource usage can be streamed before the process exits.
+	OnSample func(*resourcestypes.Sample)
 }
 
 func (m *Monitor) RecordNamespace(ns string, opt RecordOpt) (resourcestypes.Recorder, error) {
@@ -203,6 +225,7 @@ func (m *Monitor) RecordNamespace(ns string, opt RecordOpt) (resourcestypes.Reco
 		done:       make(chan struct{}),
 		monitor:    m,
 		netSampler: opt.NetworkSampler,
+		onSample:   opt.OnSample,
 	}
 	m.mu.Lock()
 	m.records[ns] = r
@@ -27,12 +27,18 @@ type Sub[T WithTimestamp] struct {
 	last     time.Time
 	samples  []T
 	err      error
+	handler  func(T)
 }
 
 func (s *Sub[T]) Close(captureLast bool) ([]T, error) {
 	s.sampler.mu.Lock()
 	delete(s.sampler.subs, s)
 
+	if s.handler != nil {
+		s.sampler.mu.Unlock()
+		return nil, nil
+	}
+
 	if s.err != nil {
 		s.sampler.mu.Unlock()
 		return nil, s.err
@@ -71,10 +77,22 @@ func NewSampler[T WithTimestamp](minInterval time.Duration, maxSamples int, cb f
 }
 
 func (s *Sampler[T]) Record() *Sub[T] {
+	return s.subscribe(nil)
+}
+
+// Subscribe registers a subscription that calls handler for every sample
+// taken at the minimum interval instead of accumulating them. Samples are not
+// returned on Close.
+func (s *Sampler[T]) Subscribe(handler func(T)) *Sub[T] {
+	return s.subscribe(handler)
+}
+
+func (s *Sampler[T]) subscribe(handler func(T)) *Sub[T] {
 	ss := &Sub[T]{
 		interval: s.minInterval,
 		first:    time.Now(),
 		sampler:  s,
+		handler:  handler,
 	}
 	s.mu.Lock()
 	s.subs[ss] = struct{}{}
@@ -110,11 +128,18 @@ func (s *Sampler[T]) run() {
 				continue
 			}
 			value, err := s.callback(tm)
+			var handlers []func(T)
 			s.mu.Lock()
 			for _, ss := range active {
 				if _, found := s.subs[ss]; !found {
 					continue // skip if Close() was called while the lock was released
 				}
+				if ss.handler != nil {
+					if err == nil {
+						handlers = append(handlers, ss.handler)
+					}
+					continue
+				}
 				if err != nil {
 					ss.err = err
 				} else {
@@ -127,6 +152,9 @@ func (s *Sampler[T]) run() {
 				}
 			}
 			s.mu.Unlock()
+			for _, h := range handlers {
+				h(value)
+			}
 		}
 	}
 }
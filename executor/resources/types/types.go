@@ -14,8 +14,9 @@ type Recorder interface {
 }
 
 type Samples struct {
-	Samples    []*Sample   `json:"samples,omitempty"`
-	SysCPUStat *SysCPUStat `json:"sysCPUStat,omitempty"`
+	Samples       []*Sample         `json:"samples,omitempty"`
+	SysCPUStat    *SysCPUStat       `json:"sysCPUStat,omitempty"`
+	NetworkAccess *NetworkAccessLog `json:"networkAccess,omitempty"`
 }
 
 // Sample represents a wrapper for sampled data of cgroupv2 controllers
@@ -44,6 +45,30 @@ type NetworkSample struct {
 	TxDropped int64 `json:"txDropped,omitempty"`
 }
 
+// NetworkAccessLog records the network access of a process. It is only
+// available for network providers that support capturing it.
+type NetworkAccessLog struct {
+	DNS         []DNSLookup         `json:"dns,omitempty"`
+	Connections []NetworkConnection `json:"connections,omitempty"`
+	// Truncated is set if records were dropped because of the size limit.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// DNSLookup is a name resolved over DNS and the addresses it resolved to.
+type DNSLookup struct {
+	Name      string   `json:"name"`
+	Addresses []string `json:"addresses,omitempty"`
+}
+
+// NetworkConnection is an outgoing TCP connection or UDP flow.
+type NetworkConnection struct {
+	Protocol string `json:"protocol"`
+	// Address is the destination IP:port.
+	Address string `json:"address"`
+	// Hosts are the names that resolved to the destination IP.
+	Hosts []string `json:"hosts,omitempty"`
+}
+
 // CPUStat represents the sampling state of the cgroupv2 CPU controller
 type CPUStat struct {
 	UsageNanos     *uint64   `json:"usageNanos,omitempty"`
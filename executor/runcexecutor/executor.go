@@ -47,6 +47,8 @@ type Opt struct {
 	Rootless bool
 	// DefaultCgroupParent is the cgroup-parent name for executor
 	DefaultCgroupParent string
+	// DefaultResourceLimits are applied to containers that don't set their own limits
+	DefaultResourceLimits *pb.ResourceLimits
 	// ProcessMode
 	ProcessMode     oci.ProcessMode
 	IdentityMapping *user.IdentityMapping
@@ -67,6 +69,7 @@ type runcExecutor struct {
 	runc             *runc.Runc
 	root             string
 	cgroupParent     string
+	resourceLimits   *pb.ResourceLimits
 	rootless         bool
 	networkProviders map[pb.NetMode]network.Provider
 	processMode      oci.ProcessMode
@@ -135,6 +138,7 @@ func New(opt Opt, networkProviders map[pb.NetMode]network.Provider) (executor.Ex
 		runc:             runtime,
 		root:             root,
 		cgroupParent:     opt.DefaultCgroupParent,
+		resourceLimits:   opt.DefaultResourceLimits,
 		rootless:         opt.Rootless,
 		networkProviders: networkProviders,
 		processMode:      opt.ProcessMode,
@@ -180,7 +184,7 @@ func (w *runcExecutor) Run(ctx context.Context, id string, root executor.Mount,
 	if !ok {
 		return nil, errors.Errorf("unknown network mode %s", meta.NetMode)
 	}
-	namespace, err := provider.New(ctx, meta.Hostname)
+	namespace, err := provider.New(network.WithAccessLog(ctx, meta.NetworkAccessLog), meta.Hostname)
 	if err != nil {
 		return nil, err
 	}
@@ -268,6 +272,8 @@ func (w *runcExecutor) Run(ctx context.Context, id string, root executor.Mount,
 		}
 	}
 
+	meta.ResourceLimits = oci.MergeResourceLimits(w.resourceLimits, meta.ResourceLimits)
+
 	spec, cleanup, err := oci.GenerateSpec(ctx, meta, mounts, id, resolvConf, hostsFile, namespace, w.cgroupParent, w.processMode, w.idmap, w.apparmorProfile, w.selinux, w.tracingSocket, w.cdiManager, opts...)
 	if err != nil {
 		return nil, err
@@ -307,6 +313,7 @@ func (w *runcExecutor) Run(ctx context.Context, id string, root executor.Mount,
 	if cgroupPath != "" {
 		rec, err = w.resmon.RecordNamespace(cgroupPath, resources.RecordOpt{
 			NetworkSampler: namespace,
+			OnSample:       process.Stats,
 		})
 		if err != nil {
 			return nil, err
@@ -363,6 +370,7 @@ func exitError(ctx context.Context, cgroupPath string, err error, validExitCodes
 			exitErr = &gatewayapi.ExitError{ExitCode: uint32(runcExitError.Status)}
 		}
 
+		exitErr.Reason = executor.ExitReason(ctx, exitErr.ExitCode)
 		detectOOM(ctx, cgroupPath, exitErr)
 	}
 
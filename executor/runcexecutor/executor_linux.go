@@ -14,6 +14,7 @@ import (
 	runc "github.com/containerd/go-runc"
 	"github.com/moby/buildkit/executor"
 	gatewayapi "github.com/moby/buildkit/frontend/gateway/pb"
+	"github.com/moby/buildkit/solver/errdefs"
 	"github.com/moby/buildkit/util/bklog"
 	"github.com/moby/sys/signal"
 	"github.com/opencontainers/runtime-spec/specs-go"
@@ -192,6 +193,7 @@ func detectOOM(ctx context.Context, ns string, gwErr *gatewayapi.ExitError) {
 	}
 	if count > 0 {
 		gwErr.Err = syscall.ENOMEM
+		gwErr.Reason = &errdefs.ExitReason{Type: errdefs.ExitReasonType_OOM_KILLED}
 	}
 }
 
@@ -0,0 +1,92 @@
+package attestation
+
+import (
+	"context"
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/session/signer"
+	"github.com/pkg/errors"
+	"github.com/secure-systems-lab/go-securesystemslib/dsse"
+)
+
+// Signer signs payloads on behalf of the exporter. See signer.SignPayload
+// for the supported payload types.
+type Signer interface {
+	Sign(ctx context.Context, payloadType string, payload []byte) (sig []byte, keyID string, err error)
+}
+
+// NewKeySigner returns a signer using a private key available to the daemon.
+func NewKeySigner(key crypto.Signer) (Signer, error) {
+	keyID, err := signer.KeyID(key.Public())
+	if err != nil {
+		return nil, err
+	}
+	return &keySigner{key: key, keyID: keyID}, nil
+}
+
+type keySigner struct {
+	key   crypto.Signer
+	keyID string
+}
+
+func (s *keySigner) Sign(ctx context.Context, payloadType string, payload []byte) ([]byte, string, error) {
+	sig, err := signer.SignPayload(s.key, payloadType, payload)
+	if err != nil {
+		return nil, "", err
+	}
+	return sig, s.keyID, nil
+}
+
+// NewSessionSigner returns a signer that forwards signing requests to the
+// client of the session so private keys never leave the client. If the
+// client doesn't provide a signer, fallback is used instead.
+func NewSessionSigner(sm *session.Manager, g session.Group, fallback Signer) Signer {
+	return &sessionSigner{sm: sm, g: g, fallback: fallback}
+}
+
+type sessionSigner struct {
+	sm       *session.Manager
+	g        session.Group
+	fallback Signer
+}
+
+func (s *sessionSigner) Sign(ctx context.Context, payloadType string, payload []byte) (sig []byte, keyID string, err error) {
+	err = s.sm.Any(ctx, s.g, func(ctx context.Context, _ string, c session.Caller) error {
+		sig, keyID, err = signer.Sign(ctx, c, payloadType, payload)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, signer.ErrNotSupported) && s.fallback != nil {
+			return s.fallback.Sign(ctx, payloadType, payload)
+		}
+		if errors.Is(err, signer.ErrNotSupported) {
+			return nil, "", errors.New("no attestation signer available: client did not provide a signer and no signing key is configured on the daemon")
+		}
+		return nil, "", err
+	}
+	return sig, keyID, nil
+}
+
+// SignStatement wraps the in-toto statement in a DSSE envelope signed by s.
+func SignStatement(ctx context.Context, s Signer, statement intoto.Statement) (*dsse.Envelope, error) {
+	payload, err := json.Marshal(statement)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal attestation")
+	}
+	sig, keyID, err := s.Sign(ctx, intoto.PayloadType, payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign attestation")
+	}
+	return &dsse.Envelope{
+		PayloadType: intoto.PayloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures: []dsse.Signature{{
+			KeyID: keyID,
+			Sig:   base64.StdEncoding.EncodeToString(sig),
+		}},
+	}, nil
+}
@@ -15,6 +15,8 @@ import (
 	"github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/snapshot"
 	"github.com/moby/buildkit/solver/result"
+	attestationTypes "github.com/moby/buildkit/util/attestation"
+	"github.com/moby/buildkit/util/sbomutil"
 	"github.com/pkg/errors"
 	"golang.org/x/sync/errgroup"
 )
@@ -145,15 +147,28 @@ func unbundle(root string, bundle exporter.Attestation) ([]exporter.Attestation,
 		if _, err := dec.Token(); !errors.Is(err, io.EOF) {
 			return nil, errors.New("in-toto statement is not a single JSON object")
 		}
-		if bundle.InToto.PredicateType != "" && stmt.PredicateType != bundle.InToto.PredicateType {
-			return nil, errors.Errorf("bundle entry %s does not match required predicate type %s", stmt.PredicateType, bundle.InToto.PredicateType)
-		}
-
 		predicate, err := json.Marshal(stmt.Predicate)
 		if err != nil {
 			return nil, err
 		}
 
+		if bundle.InToto.PredicateType == "" && string(bundle.Metadata[result.AttestationReasonKey]) == result.AttestationReasonVuln && !attestationTypes.IsVulnPredicate(stmt.PredicateType) {
+			// vulnerability scanners may only produce other statements if
+			// the predicate type was set explicitly
+			return nil, errors.Errorf("bundle entry %s is not a vulnerability report", stmt.PredicateType)
+		}
+		if bundle.InToto.PredicateType != "" && stmt.PredicateType != bundle.InToto.PredicateType {
+			if !sbomutil.IsSBOMPredicate(stmt.PredicateType) || !sbomutil.IsSBOMPredicate(bundle.InToto.PredicateType) {
+				return nil, errors.Errorf("bundle entry %s does not match required predicate type %s", stmt.PredicateType, bundle.InToto.PredicateType)
+			}
+			// scanner produced a different sbom format than requested
+			predicate, err = sbomutil.Convert(predicate, stmt.PredicateType, bundle.InToto.PredicateType)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to convert bundle entry %s", entry.Name())
+			}
+			stmt.PredicateType = bundle.InToto.PredicateType
+		}
+
 		subjects := make([]result.InTotoSubject, len(stmt.Subject))
 		for i, subject := range stmt.Subject {
 			subjects[i] = result.InTotoSubject{
@@ -0,0 +1,61 @@
+package attestation
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+	"github.com/moby/buildkit/exporter"
+	gatewaypb "github.com/moby/buildkit/frontend/gateway/pb"
+	"github.com/moby/buildkit/solver/result"
+	attestationTypes "github.com/moby/buildkit/util/attestation"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnbundleVuln(t *testing.T) {
+	write := func(t *testing.T, predicateTypes ...string) string {
+		dir := t.TempDir()
+		for i, pt := range predicateTypes {
+			dt, err := json.Marshal(intoto.Statement{
+				StatementHeader: intoto.StatementHeader{
+					Type:          intoto.StatementInTotoV01,
+					PredicateType: pt,
+				},
+				Predicate: map[string]any{},
+			})
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile(filepath.Join(dir, strconv.Itoa(i)+".json"), dt, 0600))
+		}
+		return dir
+	}
+	bundle := func(predicateType string) exporter.Attestation {
+		return exporter.Attestation{
+			Kind: gatewaypb.AttestationKind_Bundle,
+			Metadata: map[string][]byte{
+				result.AttestationReasonKey: []byte(result.AttestationReasonVuln),
+			},
+			InToto: result.InTotoAttestation{PredicateType: predicateType},
+		}
+	}
+
+	// vulnerability reports and OpenVEX documents are accepted by default
+	atts, err := unbundle(write(t, attestationTypes.PredicateTypeVulns, attestationTypes.PredicateTypeOpenVEX), bundle(""))
+	require.NoError(t, err)
+	require.Len(t, atts, 2)
+	require.Equal(t, attestationTypes.PredicateTypeVulns, atts[0].InToto.PredicateType)
+	require.Equal(t, attestationTypes.PredicateTypeOpenVEX, atts[1].InToto.PredicateType)
+
+	_, err = unbundle(write(t, attestationTypes.PredicateTypeVulns, intoto.PredicateSPDX), bundle(""))
+	require.ErrorContains(t, err, "is not a vulnerability report")
+
+	// other statements require an explicit predicate type
+	atts, err = unbundle(write(t, "https://example.com/report"), bundle("https://example.com/report"))
+	require.NoError(t, err)
+	require.Len(t, atts, 1)
+
+	_, err = unbundle(write(t, attestationTypes.PredicateTypeVulns), bundle("https://example.com/report"))
+	require.ErrorContains(t, err, "does not match required predicate type")
+}
@@ -3,6 +3,7 @@ package containerimage
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io/fs"
 	"path/filepath"
@@ -30,7 +31,8 @@ var intotoPlatform = ocispecs.Platform{
 	OS:           "unknown",
 }
 
-// supplementSBOM modifies SPDX attestations to include the file layers
+// supplementSBOM modifies SPDX and CycloneDX attestations to include the
+// file layers
 func supplementSBOM(ctx context.Context, s session.Group, target cache.ImmutableRef, targetRemote *solver.Remote, att exporter.Attestation) (exporter.Attestation, error) {
 	if target == nil {
 		return att, nil
@@ -38,7 +40,7 @@ func supplementSBOM(ctx context.Context, s session.Group, target cache.Immutable
 	if att.Kind != gatewaypb.AttestationKind_InToto {
 		return att, nil
 	}
-	if att.InToto.PredicateType != intoto.PredicateSPDX {
+	if att.InToto.PredicateType != intoto.PredicateSPDX && att.InToto.PredicateType != intoto.PredicateCycloneDX {
 		return att, nil
 	}
 	name, ok := att.Metadata[result.AttestationSBOMCore]
@@ -54,17 +56,41 @@ func supplementSBOM(ctx context.Context, s session.Group, target cache.Immutable
 		return att, err
 	}
 
-	doc, err := decodeSPDX(content)
+	layers, err := newFileLayerFinder(target, targetRemote)
 	if err != nil {
-		// ignore decoding error
-		return att, nil
+		return att, err
 	}
+	defer layers.release(context.WithoutCancel(ctx))
 
-	layers, err := newFileLayerFinder(target, targetRemote)
+	if att.InToto.PredicateType == intoto.PredicateCycloneDX {
+		content, err = supplementCycloneDX(ctx, s, &layers, content)
+	} else {
+		content, err = supplementSPDX(ctx, s, &layers, content)
+	}
 	if err != nil {
 		return att, err
 	}
-	defer layers.release(context.WithoutCancel(ctx))
+	if content == nil {
+		return att, nil
+	}
+
+	return exporter.Attestation{
+		Kind:        att.Kind,
+		Path:        att.Path,
+		ContentFunc: func(context.Context) ([]byte, error) { return content, nil },
+		InToto:      att.InToto,
+	}, nil
+}
+
+// supplementSPDX adds the layer of every file to its comment. Returns nil if
+// the document can't be decoded.
+func supplementSPDX(ctx context.Context, s session.Group, layers *fileLayerFinder, content []byte) ([]byte, error) {
+	doc, err := decodeSPDX(content)
+	if err != nil {
+		// ignore decoding error
+		return nil, nil
+	}
+
 	modifyFile := func(f *spdx.File) error {
 		if f == nil {
 			// Skip over nil entries - this is likely a bug in the SPDX parser,
@@ -91,13 +117,13 @@ func supplementSBOM(ctx context.Context, s session.Group, target cache.Immutable
 	}
 	for _, f := range doc.Files {
 		if err := modifyFile(f); err != nil {
-			return att, err
+			return nil, err
 		}
 	}
 	for _, p := range doc.Packages {
 		for _, f := range p.Files {
 			if err := modifyFile(f); err != nil {
-				return att, err
+				return nil, err
 			}
 		}
 	}
@@ -110,17 +136,70 @@ func supplementSBOM(ctx context.Context, s session.Group, target cache.Immutable
 		Creator:     "buildkit-" + version.Version,
 	})
 
-	content, err = encodeSPDX(doc)
-	if err != nil {
-		return att, err
+	return encodeSPDX(doc)
+}
+
+// cycloneDXLayerProperty is the property added to file components of a
+// CycloneDX BOM with the digest of the layer that contains the file.
+const cycloneDXLayerProperty = "buildkit:layerID"
+
+// supplementCycloneDX adds the layer of every file component as a property.
+// The BOM is modified as generic JSON so that fields produced by the scanner
+// are kept as is. Returns nil if the BOM can't be decoded.
+func supplementCycloneDX(ctx context.Context, s session.Group, layers *fileLayerFinder, content []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(content))
+	dec.UseNumber()
+	var bom map[string]any
+	if err := dec.Decode(&bom); err != nil || bom["bomFormat"] != "CycloneDX" {
+		// ignore decoding error
+		return nil, nil
 	}
 
-	return exporter.Attestation{
-		Kind:        att.Kind,
-		Path:        att.Path,
-		ContentFunc: func(context.Context) ([]byte, error) { return content, nil },
-		InToto:      att.InToto,
-	}, nil
+	var modifyComponents func(v any) error
+	modifyComponents = func(v any) error {
+		components, _ := v.([]any)
+		for _, c := range components {
+			component, ok := c.(map[string]any)
+			if !ok {
+				continue
+			}
+			if err := modifyComponents(component["components"]); err != nil {
+				return err
+			}
+			name, _ := component["name"].(string)
+			if component["type"] != "file" || name == "" {
+				continue
+			}
+			properties, _ := component["properties"].([]any)
+			if slices.ContainsFunc(properties, func(p any) bool {
+				m, _ := p.(map[string]any)
+				return m["name"] == cycloneDXLayerProperty
+			}) {
+				continue
+			}
+			desc, err := layers.find(ctx, s, name)
+			if err != nil {
+				if !errors.Is(err, fs.ErrNotExist) {
+					return err
+				}
+				continue
+			}
+			component["properties"] = append(properties, map[string]any{
+				"name":  cycloneDXLayerProperty,
+				"value": desc.Digest.String(),
+			})
+		}
+		return nil
+	}
+	if err := modifyComponents(bom["components"]); err != nil {
+		return nil, err
+	}
+
+	dt, err := json.Marshal(bom)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to encode cyclonedx")
+	}
+	return dt, nil
 }
 
 func decodeSPDX(dt []byte) (s *spdx.Document, err error) {
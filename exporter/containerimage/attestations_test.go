@@ -0,0 +1,81 @@
+package containerimage
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupplementSBOM(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+
+	layer := ocispecs.Descriptor{MediaType: ocispecs.MediaTypeImageLayerGzip, Digest: digest.FromString("layer")}
+	newLayers := func() *fileLayerFinder {
+		return &fileLayerFinder{cache: map[string]ocispecs.Descriptor{"/bin/sh": layer}}
+	}
+
+	t.Run("spdx", func(t *testing.T) {
+		dt, err := supplementSPDX(ctx, nil, newLayers(), []byte(`{"spdxVersion":"SPDX-2.3","dataLicense":"CC0-1.0","SPDXID":"SPDXRef-DOCUMENT","name":"sbom","documentNamespace":"https://example.com/sbom","creationInfo":{"creators":["Tool: scanner"],"created":"2024-01-01T00:00:00Z"},`+
+			`"files":[{"fileName":"/bin/sh","SPDXID":"SPDXRef-File-0","checksums":[{"algorithm":"SHA1","checksumValue":"da39a3ee5e6b4b0d3255bfef95601890afd80709"}]},{"fileName":"/missing","SPDXID":"SPDXRef-File-1","checksums":[{"algorithm":"SHA1","checksumValue":"da39a3ee5e6b4b0d3255bfef95601890afd80709"}]}]}`))
+		require.NoError(t, err)
+		var doc struct {
+			CreationInfo struct {
+				Creators []string `json:"creators"`
+			} `json:"creationInfo"`
+			Files []struct {
+				FileName string `json:"fileName"`
+				Comment  string `json:"comment"`
+			} `json:"files"`
+		}
+		require.NoError(t, json.Unmarshal(dt, &doc))
+		require.Len(t, doc.Files, 2)
+		require.Equal(t, "layerID: "+layer.Digest.String(), doc.Files[0].Comment)
+		require.Empty(t, doc.Files[1].Comment)
+		require.Len(t, doc.CreationInfo.Creators, 2)
+
+		dt, err = supplementSPDX(ctx, nil, newLayers(), []byte(`not json`))
+		require.NoError(t, err)
+		require.Nil(t, dt)
+	})
+
+	t.Run("cyclonedx", func(t *testing.T) {
+		dt, err := supplementCycloneDX(ctx, nil, newLayers(), []byte(`{"bomFormat":"CycloneDX","specVersion":"1.5","version":1,"x-scanner":{"large":12345678901234567890},`+
+			`"components":[`+
+			`{"type":"library","name":"musl","components":[{"type":"file","name":"/bin/sh"}]},`+
+			`{"type":"file","name":"/bin/sh","properties":[{"name":"other","value":"1"}]},`+
+			`{"type":"file","name":"/missing"},`+
+			`{"type":"file","name":"/bin/sh","properties":[{"name":"buildkit:layerID","value":"kept"}]}]}`))
+		require.NoError(t, err)
+
+		var bom struct {
+			XScanner   json.RawMessage `json:"x-scanner"`
+			Components []struct {
+				Name       string `json:"name"`
+				Components []struct {
+					Properties []map[string]string `json:"properties"`
+				} `json:"components"`
+				Properties []map[string]string `json:"properties"`
+			} `json:"components"`
+		}
+		require.NoError(t, json.Unmarshal(dt, &bom))
+		// fields unknown to buildkit are kept as is
+		require.JSONEq(t, `{"large":12345678901234567890}`, string(bom.XScanner))
+		require.Len(t, bom.Components, 4)
+
+		prop := map[string]string{"name": cycloneDXLayerProperty, "value": layer.Digest.String()}
+		require.Empty(t, bom.Components[0].Properties)
+		require.Equal(t, []map[string]string{prop}, bom.Components[0].Components[0].Properties)
+		require.Equal(t, []map[string]string{{"name": "other", "value": "1"}, prop}, bom.Components[1].Properties)
+		require.Empty(t, bom.Components[2].Properties)
+		require.Equal(t, []map[string]string{{"name": cycloneDXLayerProperty, "value": "kept"}}, bom.Components[3].Properties)
+
+		dt, err = supplementCycloneDX(ctx, nil, newLayers(), []byte(`{"spdxVersion":"SPDX-2.3"}`))
+		require.NoError(t, err)
+		require.Nil(t, dt)
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/containerd/containerd/v2/core/content"
 	"github.com/containerd/containerd/v2/core/images"
@@ -20,13 +21,16 @@ import (
 	"github.com/containerd/containerd/v2/pkg/rootfs"
 	cerrdefs "github.com/containerd/errdefs"
 	"github.com/containerd/platforms"
+	"github.com/hashicorp/go-multierror"
 	"github.com/moby/buildkit/cache"
 	cacheconfig "github.com/moby/buildkit/cache/config"
 	"github.com/moby/buildkit/client"
 	"github.com/moby/buildkit/exporter"
+	"github.com/moby/buildkit/exporter/attestation"
 	"github.com/moby/buildkit/exporter/containerimage/exptypes"
 	"github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/snapshot"
+	"github.com/moby/buildkit/util/bklog"
 	"github.com/moby/buildkit/util/compression"
 	"github.com/moby/buildkit/util/contentutil"
 	"github.com/moby/buildkit/util/errutil"
@@ -53,6 +57,9 @@ type Opt struct {
 	Images         images.Store
 	RegistryHosts  docker.RegistryHosts
 	LeaseManager   leases.Manager
+	// AttestationSigner is used to sign attestations if the client doesn't
+	// provide a signer.
+	AttestationSigner attestation.Signer
 }
 
 type imageExporter struct {
@@ -171,6 +178,26 @@ func (e *imageExporter) Resolve(ctx context.Context, id int, opt map[string]stri
 				return nil, errors.Wrapf(err, "non-bool value specified for %s", k)
 			}
 			i.nameCanonical = b
+		case exptypes.OptKeySign:
+			if v == "" {
+				i.sign = true
+				continue
+			}
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, errors.Wrapf(err, "non-bool value specified for %s", k)
+			}
+			i.sign = b
+		case exptypes.OptKeyPushAllowPartial:
+			if v == "" {
+				i.pushAllowPartial = true
+				continue
+			}
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, errors.Wrapf(err, "non-bool value specified for %s", k)
+			}
+			i.pushAllowPartial = b
 		default:
 			if i.meta == nil {
 				i.meta = make(map[string][]byte)
@@ -178,6 +205,17 @@ func (e *imageExporter) Resolve(ctx context.Context, id int, opt map[string]stri
 			i.meta[k] = []byte(v)
 		}
 	}
+	if i.sign && !i.push {
+		return nil, errors.Errorf("exporter option %q requires %q", exptypes.OptKeySign, exptypes.OptKeyPush)
+	}
+	if i.pushAllowPartial && !i.push {
+		return nil, errors.Errorf("exporter option %q requires %q", exptypes.OptKeyPushAllowPartial, exptypes.OptKeyPush)
+	}
+	// referrers are only kept by pushing them, the image store has no way to
+	// find them from the image
+	if i.opts.AttestationStorage == exptypes.AttestationStorageReferrers && !i.push {
+		return nil, errors.Errorf("exporter option %s=%s requires %q", exptypes.OptKeyAttestationStorage, exptypes.AttestationStorageReferrers, exptypes.OptKeyPush)
+	}
 	return i, nil
 }
 
@@ -189,11 +227,13 @@ type imageExporterInstance struct {
 	opts                 ImageCommitOpts
 	push                 bool
 	pushByDigest         bool
+	pushAllowPartial     bool
 	unpack               bool
 	store                bool
 	storeAllowIncomplete bool
 	insecure             bool
 	nameCanonical        bool
+	sign                 bool
 	danglingPrefix       string
 	danglingEmptyOnly    bool
 	meta                 map[string][]byte
@@ -243,7 +283,11 @@ func (e *imageExporterInstance) Export(ctx context.Context, src *exporter.Source
 		}
 	}()
 
-	desc, err := e.opt.ImageWriter.Commit(ctx, src, sessionID, inlineCache, &opts)
+	if opts.SignAttestations {
+		opts.AttestationSigner = attestation.NewSessionSigner(e.opt.SessionManager, session.NewGroup(sessionID), e.opt.AttestationSigner)
+	}
+
+	desc, referrers, err := e.opt.ImageWriter.Commit(ctx, src, sessionID, inlineCache, &opts)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -354,15 +398,25 @@ func (e *imageExporterInstance) Export(ctx context.Context, src *exporter.Source
 					}
 				}
 			}
-			if e.push {
-				err = e.pushImage(ctx, src, sessionID, targetName, desc.Digest)
+		}
+		if e.push {
+			results, err := e.pushImages(ctx, src, sessionID, targetNames, *desc, referrers)
+			if err != nil {
+				return nil, nil, err
+			}
+			// the signature of the first name that was signed
+			for _, targetName := range targetNames {
+				if r := results[targetName]; r.Signature != "" {
+					resp[exptypes.ExporterImageSignatureKey] = r.Signature.String()
+					break
+				}
+			}
+			if len(results) > 1 {
+				dtresults, err := json.Marshal(results)
 				if err != nil {
-					var statusErr remoteserrors.ErrUnexpectedStatus
-					if errors.As(err, &statusErr) {
-						err = errutil.WithDetails(err)
-					}
-					return nil, nil, errors.Wrapf(err, "failed to push %v", targetName)
+					return nil, nil, err
 				}
+				resp[exptypes.ExporterImagePushResultsKey] = base64.StdEncoding.EncodeToString(dtresults)
 			}
 		}
 		resp[exptypes.ExporterImageNameKey] = e.opts.ImageName
@@ -383,7 +437,80 @@ func (e *imageExporterInstance) Export(ctx context.Context, src *exporter.Source
 	return resp, nil, nil
 }
 
-func (e *imageExporterInstance) pushImage(ctx context.Context, src *exporter.Source, sessionID string, targetName string, dgst digest.Digest) error {
+// pushImages pushes the image to all targetNames concurrently and waits for
+// all pushes to finish. The export fails if any of the pushes failed, unless
+// push-allow-partial is set and some pushes succeeded. Then the result of
+// every push is returned and each failed push is reported as a warning of
+// the export.
+func (e *imageExporterInstance) pushImages(ctx context.Context, src *exporter.Source, sessionID string, targetNames []string, desc ocispecs.Descriptor, referrers []ocispecs.Descriptor) (map[string]exptypes.PushResult, error) {
+	var mu sync.Mutex
+	signatures := map[string]digest.Digest{}
+	errs := push.Multi(ctx, targetNames, func(ctx context.Context, targetName string, pushed []string) error {
+		err := e.pushImage(ctx, src, sessionID, targetName, desc.Digest, pushed)
+		if err != nil {
+			var statusErr remoteserrors.ErrUnexpectedStatus
+			if errors.As(err, &statusErr) {
+				err = errutil.WithDetails(err)
+			}
+			return errors.Wrapf(err, "failed to push %v", targetName)
+		}
+		if len(referrers) > 0 {
+			if err := push.PushReferrers(ctx, e.opt.SessionManager, sessionID, e.opt.ImageWriter.ContentStore(), referrers, targetName, e.insecure, e.opt.RegistryHosts); err != nil {
+				return errors.Wrapf(err, "failed to push attestations for %v", targetName)
+			}
+		}
+		if e.sign {
+			sigDesc, err := e.pushSignature(ctx, sessionID, targetName, desc)
+			if err != nil {
+				return errors.Wrapf(err, "failed to push signature for %v", targetName)
+			}
+			mu.Lock()
+			signatures[targetName] = sigDesc.Digest
+			mu.Unlock()
+		}
+		return nil
+	})
+
+	results := make(map[string]exptypes.PushResult, len(targetNames))
+	var failed error
+	for i, err := range errs {
+		r := exptypes.PushResult{Signature: signatures[targetNames[i]]}
+		if err != nil {
+			r.Error = err.Error()
+			failed = multierror.Append(failed, err)
+		}
+		results[targetNames[i]] = r
+	}
+	if len(targetNames) == 1 {
+		return results, errs[0]
+	}
+	if failed == nil {
+		return results, nil
+	}
+	if merr, ok := failed.(*multierror.Error); !e.pushAllowPartial || (ok && len(merr.Errors) == len(targetNames)) {
+		return nil, failed
+	}
+	for i, err := range errs {
+		if err != nil {
+			warnPushFailed(ctx, targetNames[i], err)
+		}
+	}
+	return results, nil
+}
+
+// warnPushFailed reports a push that failed while others succeeded, as the
+// export doesn't fail in that case with push-allow-partial.
+func warnPushFailed(ctx context.Context, targetName string, err error) {
+	bklog.G(ctx).Warn(err)
+	pw, _, _ := progress.NewFromContext(ctx)
+	pw.Write("push "+targetName, client.VertexWarning{
+		Level: 1,
+		Short: []byte(err.Error()),
+	})
+	pw.Close()
+}
+
+func (e *imageExporterInstance) pushImage(ctx context.Context, src *exporter.Source, sessionID string, targetName string, dgst digest.Digest, pushed []string) error {
 	var refs []cache.ImmutableRef
 	if src.Ref != nil {
 		refs = append(refs, src.Ref)
@@ -397,6 +524,7 @@ func (e *imageExporterInstance) pushImage(ctx context.Context, src *exporter.Sou
 
 	annotations := map[digest.Digest]map[string]string{}
 	mprovider := contentutil.NewMultiProvider(e.opt.ImageWriter.ContentStore())
+	var layers []digest.Digest
 	for _, ref := range refs {
 		remotes, err := ref.GetRemotes(ctx, false, e.opts.RefCfg, false, session.NewGroup(sessionID))
 		if err != nil {
@@ -406,9 +534,16 @@ func (e *imageExporterInstance) pushImage(ctx context.Context, src *exporter.Sou
 		for _, desc := range remote.Descriptors {
 			mprovider.Add(desc.Digest, remote.Provider)
 			addAnnotations(annotations, desc)
+			layers = append(layers, desc.Digest)
 		}
 	}
-	return push.Push(ctx, e.opt.SessionManager, sessionID, mprovider, e.opt.ImageWriter.ContentStore(), dgst, targetName, e.insecure, e.opt.RegistryHosts, e.pushByDigest, annotations)
+
+	var provider content.Provider = mprovider
+	if len(pushed) > 0 {
+		push.AddPushedSources(annotations, layers, pushed)
+		provider = push.WithPushedSources(ctx, e.opt.SessionManager, sessionID, mprovider, e.opt.ImageWriter.ContentStore(), pushed, e.insecure, e.opt.RegistryHosts)
+	}
+	return push.Push(ctx, e.opt.SessionManager, sessionID, provider, e.opt.ImageWriter.ContentStore(), dgst, targetName, e.insecure, e.opt.RegistryHosts, e.pushByDigest, annotations)
 }
 
 func (e *imageExporterInstance) unpackImage(ctx context.Context, img images.Image, src *exporter.Source, s session.Group) (err0 error) {
@@ -548,3 +683,17 @@ func (d *descriptorReference) Descriptor() ocispecs.Descriptor {
 func (d *descriptorReference) Release() error {
 	return d.release(context.TODO())
 }
+
+// pushSignature signs the pushed image and pushes the signature manifest to
+// the repository of targetName, referring to the image with its subject.
+func (e *imageExporterInstance) pushSignature(ctx context.Context, sessionID string, targetName string, desc ocispecs.Descriptor) (*ocispecs.Descriptor, error) {
+	signer := attestation.NewSessionSigner(e.opt.SessionManager, session.NewGroup(sessionID), e.opt.AttestationSigner)
+	sigDesc, err := e.opt.ImageWriter.commitSignatureManifest(ctx, signer, targetName, desc)
+	if err != nil {
+		return nil, err
+	}
+	if err := push.PushReferrers(ctx, e.opt.SessionManager, sessionID, e.opt.ImageWriter.ContentStore(), []ocispecs.Descriptor{*sigDesc}, targetName, e.insecure, e.opt.RegistryHosts); err != nil {
+		return nil, err
+	}
+	return sigDesc, nil
+}
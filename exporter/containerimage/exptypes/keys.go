@@ -69,8 +69,8 @@ var (
 	OptKeySourceDateEpoch ImageExporterOptKey = ImageExporterOptKey(commonexptypes.OptKeySourceDateEpoch)
 
 	// Compression type for newly created and cached layers.
-	// estargz should be used with OptKeyOCITypes set to true.
-	// Value: string <uncompressed|gzip|estargz|zstd>
+	// estargz and zstd:chunked should be used with OptKeyOCITypes set to true.
+	// Value: string <uncompressed|gzip|estargz|zstd|zstd:chunked>
 	OptKeyLayerCompression ImageExporterOptKey = "compression"
 
 	// Force compression on all (including existing) layers.
@@ -79,10 +79,46 @@ var (
 
 	// Compression level
 	// Value: int (0-9) for gzip and estargz
-	// Value: int (0-22) for zstd
+	// Value: int (0-22) for zstd and zstd:chunked
 	OptKeyCompressionLevel ImageExporterOptKey = "compression-level"
 
 	// Rewrite timestamps in layers to match SOURCE_DATE_EPOCH
 	// Value: bool <true|false>
 	OptKeyRewriteTimestamp ImageExporterOptKey = "rewrite-timestamp"
+
+	// Merge all layers of the image into a single layer.
+	// Value: bool <true|false>
+	OptKeySquash ImageExporterOptKey = "squash"
+
+	// Maximum number of layers in the image. Adjacent small layers on top of
+	// the base image are merged until the limit is met.
+	// Value: int
+	OptKeyMaxLayers ImageExporterOptKey = "max-layers"
+
+	// Sign attestations and store them as DSSE envelopes. The signing key is
+	// provided by the client session or configured on the daemon.
+	// Value: bool <true|false>
+	OptKeySignAttestations ImageExporterOptKey = "sign-attestations"
+
+	// Where to store attestation manifests. "index" adds them to the image
+	// index, "referrers" attaches them to each platform manifest with the
+	// subject field and pushes them using the OCI referrers API.
+	// Value: string <index|referrers>
+	OptKeyAttestationStorage ImageExporterOptKey = "attestation-storage"
+
+	// Sign the pushed image manifest with a simple signing payload and push
+	// the signature as an OCI referrer artifact. Requires push.
+	// Value: bool <true|false>
+	OptKeySign ImageExporterOptKey = "sign"
+
+	// Don't fail the export when the image is pushed to some of the names
+	// but not to others. The failed pushes are reported as warnings and in
+	// the push results. Requires push.
+	// Value: bool <true|false>
+	OptKeyPushAllowPartial ImageExporterOptKey = "push-allow-partial"
+)
+
+const (
+	AttestationStorageIndex     = "index"
+	AttestationStorageReferrers = "referrers"
 )
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/moby/buildkit/solver/result"
+	digest "github.com/opencontainers/go-digest"
 	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
@@ -14,7 +15,10 @@ const (
 	ExporterImageConfigKey       = "containerimage.config"
 	ExporterImageConfigDigestKey = "containerimage.config.digest"
 	ExporterImageDescriptorKey   = "containerimage.descriptor"
+	ExporterImageSignatureKey    = "containerimage.signature.digest"
+	ExporterImageReferrersKey    = "containerimage.referrers"
 	ExporterImageBaseConfigKey   = "containerimage.base.config"
+	ExporterImagePushResultsKey  = "containerimage.push.results"
 	ExporterPlatformsKey         = "refs.platforms"
 )
 
@@ -34,6 +38,13 @@ type Platform struct {
 	Platform ocispecs.Platform
 }
 
+// PushResult is the result of pushing an image to one of the names of the
+// image exporter.
+type PushResult struct {
+	Signature digest.Digest `json:"signature,omitempty"`
+	Error     string        `json:"error,omitempty"`
+}
+
 type InlineCacheEntry struct {
 	Data []byte
 }
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	cacheconfig "github.com/moby/buildkit/cache/config"
+	"github.com/moby/buildkit/exporter/attestation"
 	"github.com/moby/buildkit/exporter/containerimage/exptypes"
 	"github.com/moby/buildkit/exporter/util/epoch"
 	"github.com/moby/buildkit/util/bklog"
@@ -23,6 +24,16 @@ type ImageCommitOpts struct {
 
 	ForceInlineAttestations bool // force inline attestations to be attached
 	RewriteTimestamp        bool // rewrite timestamps in layers to match the epoch
+	SignAttestations        bool // wrap attestations in signed DSSE envelopes
+	Squash                  bool // merge all layers into one
+	MaxLayers               int  // merge layers above the base image to fit the limit
+
+	// AttestationStorage is exptypes.AttestationStorageIndex (default) or
+	// exptypes.AttestationStorageReferrers.
+	AttestationStorage string
+
+	// AttestationSigner signs attestations when SignAttestations is set.
+	AttestationSigner attestation.Signer
 }
 
 func (c *ImageCommitOpts) Load(ctx context.Context, opt map[string]string) (map[string]string, error) {
@@ -58,6 +69,23 @@ func (c *ImageCommitOpts) Load(ctx context.Context, opt map[string]string) (map[
 			err = parseBool(&c.RefCfg.PreferNonDistributable, k, v)
 		case exptypes.OptKeyRewriteTimestamp:
 			err = parseBool(&c.RewriteTimestamp, k, v)
+		case exptypes.OptKeySignAttestations:
+			err = parseBool(&c.SignAttestations, k, v)
+		case exptypes.OptKeySquash:
+			err = parseBool(&c.Squash, k, v)
+		case exptypes.OptKeyMaxLayers:
+			if c.MaxLayers, err = strconv.Atoi(v); err != nil {
+				err = errors.Wrapf(err, "non-int value specified for %s", k)
+			} else if c.MaxLayers < 1 {
+				err = errors.Errorf("invalid value %d for %s, must be at least 1", c.MaxLayers, k)
+			}
+		case exptypes.OptKeyAttestationStorage:
+			switch v {
+			case exptypes.AttestationStorageIndex, exptypes.AttestationStorageReferrers:
+				c.AttestationStorage = v
+			default:
+				err = errors.Errorf("invalid value %q for %s, expected %s or %s", v, k, exptypes.AttestationStorageIndex, exptypes.AttestationStorageReferrers)
+			}
 		default:
 			rest[k] = v
 		}
@@ -70,6 +98,9 @@ func (c *ImageCommitOpts) Load(ctx context.Context, opt map[string]string) (map[
 	if c.RefCfg.Compression.Type.OnlySupportOCITypes() {
 		c.EnableOCITypes(ctx, c.RefCfg.Compression.Type.String())
 	}
+	if c.AttestationStorage == exptypes.AttestationStorageReferrers {
+		c.OCIArtifact = true
+	}
 	if c.OCIArtifact && !c.OCITypes {
 		c.EnableOCITypes(ctx, "oci-artifact")
 	}
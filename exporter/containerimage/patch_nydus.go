@@ -23,6 +23,10 @@ func patchImageLayers(ctx context.Context, remote *solver.Remote, history []ocis
 		return remote, history, nil
 	}
 
+	if opts.Squash || opts.MaxLayers > 0 {
+		return nil, nil, errors.New("squash and max-layers are not supported with nydus compression")
+	}
+
 	desc, err := cache.MergeNydus(ctx, ref, opts.RefCfg.Compression, sg)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "merge nydus layer")
@@ -0,0 +1,114 @@
+package containerimage
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/distribution/reference"
+	"github.com/moby/buildkit/exporter/attestation"
+	attestationTypes "github.com/moby/buildkit/util/attestation"
+	"github.com/moby/buildkit/util/progress"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+type simpleSigning struct {
+	Critical simpleSigningCritical `json:"critical"`
+	Optional map[string]any        `json:"optional"`
+}
+
+type simpleSigningCritical struct {
+	Identity struct {
+		DockerReference string `json:"docker-reference"`
+	} `json:"identity"`
+	Image struct {
+		DockerManifestDigest digest.Digest `json:"docker-manifest-digest"`
+	} `json:"image"`
+	Type string `json:"type"`
+}
+
+func simpleSigningPayload(name string, dgst digest.Digest) ([]byte, error) {
+	parsed, err := reference.ParseNormalizedNamed(name)
+	if err != nil {
+		return nil, err
+	}
+	var p simpleSigning
+	p.Critical.Identity.DockerReference = reference.TrimNamed(parsed).String()
+	p.Critical.Image.DockerManifestDigest = dgst
+	p.Critical.Type = attestationTypes.SimpleSigningType
+	return json.Marshal(p)
+}
+
+// commitSignatureManifest signs target for the repository of name and writes
+// the signature as an OCI artifact manifest with target as its subject.
+func (ic *ImageWriter) commitSignatureManifest(ctx context.Context, s attestation.Signer, name string, target ocispecs.Descriptor) (*ocispecs.Descriptor, error) {
+	payload, err := simpleSigningPayload(name, target.Digest)
+	if err != nil {
+		return nil, err
+	}
+	sig, _, err := s.Sign(ctx, attestationTypes.MediaTypeSimpleSigning, payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign image")
+	}
+
+	layerDesc := ocispecs.Descriptor{
+		MediaType: attestationTypes.MediaTypeSimpleSigning,
+		Digest:    digest.FromBytes(payload),
+		Size:      int64(len(payload)),
+		Annotations: map[string]string{
+			attestationTypes.AnnotationSignature: base64.StdEncoding.EncodeToString(sig),
+		},
+	}
+	if err := content.WriteBlob(ctx, ic.opt.ContentStore, layerDesc.Digest.String(), bytes.NewReader(payload), layerDesc); err != nil {
+		return nil, errors.Wrapf(err, "error writing signature payload blob %s", layerDesc.Digest)
+	}
+
+	configDesc := ocispecs.DescriptorEmptyJSON
+	if err := content.WriteBlob(ctx, ic.opt.ContentStore, configDesc.Digest.String(), bytes.NewReader(configDesc.Data), configDesc); err != nil {
+		return nil, errors.Wrap(err, "error writing config blob")
+	}
+	configDesc.Data = nil
+
+	subject := ocispecs.Descriptor{
+		MediaType: target.MediaType,
+		Digest:    target.Digest,
+		Size:      target.Size,
+	}
+	mfst := ocispecs.Manifest{
+		MediaType: ocispecs.MediaTypeImageManifest,
+		Versioned: specs.Versioned{
+			SchemaVersion: 2,
+		},
+		ArtifactType: attestationTypes.ArtifactTypeSignature,
+		Config:       configDesc,
+		Layers:       []ocispecs.Descriptor{layerDesc},
+		Subject:      &subject,
+	}
+	mfstJSON, err := json.MarshalIndent(mfst, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal manifest")
+	}
+	mfstDesc := ocispecs.Descriptor{
+		MediaType:    ocispecs.MediaTypeImageManifest,
+		ArtifactType: attestationTypes.ArtifactTypeSignature,
+		Digest:       digest.FromBytes(mfstJSON),
+		Size:         int64(len(mfstJSON)),
+	}
+
+	labels := map[string]string{
+		"containerd.io/gc.ref.content.0": configDesc.Digest.String(),
+		"containerd.io/gc.ref.content.1": layerDesc.Digest.String(),
+	}
+	done := progress.OneOff(ctx, "exporting signature manifest "+mfstDesc.Digest.String())
+	if err := content.WriteBlob(ctx, ic.opt.ContentStore, mfstDesc.Digest.String(), bytes.NewReader(mfstJSON), mfstDesc, content.WithLabels(labels)); err != nil {
+		return nil, done(errors.Wrapf(err, "error writing manifest blob %s", mfstDesc.Digest))
+	}
+	done(nil)
+
+	return &mfstDesc, nil
+}
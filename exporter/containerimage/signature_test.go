@@ -0,0 +1,129 @@
+package containerimage
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"maps"
+	"sync"
+	"testing"
+
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/plugins/content/local"
+	"github.com/moby/buildkit/exporter/attestation"
+	"github.com/moby/buildkit/session/signer"
+	attestationTypes "github.com/moby/buildkit/util/attestation"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitSignatureManifest(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+
+	store, err := local.NewLabeledStore(t.TempDir(), &labelStore{labels: map[digest.Digest]map[string]string{}})
+	require.NoError(t, err)
+	ic, err := NewImageWriter(WriterOpt{ContentStore: store})
+	require.NoError(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	s, err := attestation.NewKeySigner(key)
+	require.NoError(t, err)
+
+	target := ocispecs.Descriptor{
+		MediaType: ocispecs.MediaTypeImageIndex,
+		Digest:    digest.FromString("index"),
+		Size:      123,
+		Annotations: map[string]string{
+			"foo": "bar",
+		},
+	}
+	desc, err := ic.commitSignatureManifest(ctx, s, "foo:latest", target)
+	require.NoError(t, err)
+	require.Equal(t, ocispecs.MediaTypeImageManifest, desc.MediaType)
+	require.Equal(t, attestationTypes.ArtifactTypeSignature, desc.ArtifactType)
+
+	dt, err := content.ReadBlob(ctx, store, *desc)
+	require.NoError(t, err)
+	require.Equal(t, desc.Digest, digest.FromBytes(dt))
+	var mfst ocispecs.Manifest
+	require.NoError(t, json.Unmarshal(dt, &mfst))
+	require.Equal(t, 2, mfst.SchemaVersion)
+	require.Equal(t, ocispecs.MediaTypeImageManifest, mfst.MediaType)
+	require.Equal(t, attestationTypes.ArtifactTypeSignature, mfst.ArtifactType)
+	require.Equal(t, ocispecs.DescriptorEmptyJSON.Digest, mfst.Config.Digest)
+	require.Equal(t, ocispecs.MediaTypeEmptyJSON, mfst.Config.MediaType)
+
+	// the subject refers to the signed image without its annotations
+	require.Equal(t, &ocispecs.Descriptor{
+		MediaType: target.MediaType,
+		Digest:    target.Digest,
+		Size:      target.Size,
+	}, mfst.Subject)
+
+	require.Len(t, mfst.Layers, 1)
+	layer := mfst.Layers[0]
+	require.Equal(t, attestationTypes.MediaTypeSimpleSigning, layer.MediaType)
+	payload, err := content.ReadBlob(ctx, store, layer)
+	require.NoError(t, err)
+
+	var p simpleSigning
+	require.NoError(t, json.Unmarshal(payload, &p))
+	require.Equal(t, "docker.io/library/foo", p.Critical.Identity.DockerReference)
+	require.Equal(t, target.Digest, p.Critical.Image.DockerManifestDigest)
+	require.Equal(t, attestationTypes.SimpleSigningType, p.Critical.Type)
+
+	sig, err := base64.StdEncoding.DecodeString(layer.Annotations[attestationTypes.AnnotationSignature])
+	require.NoError(t, err)
+	require.NoError(t, signer.VerifyWithKey(key.Public(), payload, sig))
+
+	// the manifest keeps its blobs from being garbage collected
+	info, err := store.Info(ctx, desc.Digest)
+	require.NoError(t, err)
+	require.Equal(t, mfst.Config.Digest.String(), info.Labels["containerd.io/gc.ref.content.0"])
+	require.Equal(t, layer.Digest.String(), info.Labels["containerd.io/gc.ref.content.1"])
+
+	_, err = ic.commitSignatureManifest(ctx, s, "Invalid Name", target)
+	require.Error(t, err)
+}
+
+type labelStore struct {
+	mu     sync.Mutex
+	labels map[digest.Digest]map[string]string
+}
+
+func (s *labelStore) Get(dgst digest.Digest) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return maps.Clone(s.labels[dgst]), nil
+}
+
+func (s *labelStore) Set(dgst digest.Digest, labels map[string]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.labels[dgst] = maps.Clone(labels)
+	return nil
+}
+
+func (s *labelStore) Update(dgst digest.Digest, update map[string]string) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	labels := s.labels[dgst]
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	for k, v := range update {
+		if v == "" {
+			delete(labels, k)
+		} else {
+			labels[k] = v
+		}
+	}
+	s.labels[dgst] = labels
+	return maps.Clone(labels), nil
+}
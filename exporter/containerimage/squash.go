@@ -0,0 +1,211 @@
+package containerimage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/pkg/labels"
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/moby/buildkit/identity"
+	"github.com/moby/buildkit/solver"
+	"github.com/moby/buildkit/util/compression"
+	"github.com/moby/buildkit/util/contentutil"
+	"github.com/moby/buildkit/util/converter/tarmerge"
+	"github.com/moby/buildkit/util/iohelper"
+	"github.com/moby/buildkit/util/progress"
+	dockerspec "github.com/moby/docker-image-spec/specs-go/v1"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+// layerRange is a half-open range of layer indexes that are merged into one
+// layer.
+type layerRange struct {
+	start, end int
+}
+
+// squashLayers merges the layers of the image for the squash and max-layers
+// options. History items of merged layers, except the last one in each range,
+// are marked as empty layers so that the history still matches the layers.
+func (ic *ImageWriter) squashLayers(ctx context.Context, opts *ImageCommitOpts, remote *solver.Remote, history []ocispecs.History, baseImg *dockerspec.DockerOCIImage) (*solver.Remote, []ocispecs.History, error) {
+	if (!opts.Squash && opts.MaxLayers == 0) || len(remote.Descriptors) < 2 {
+		return remote, history, nil
+	}
+	cs := contentutil.NewStoreWithProvider(ic.opt.ContentStore, remote.Provider)
+
+	var ranges []layerRange
+	if opts.Squash {
+		ranges = []layerRange{{start: 0, end: len(remote.Descriptors)}}
+	} else {
+		base, err := baseLayers(ctx, cs, remote.Descriptors, baseImg)
+		if err != nil {
+			return nil, nil, err
+		}
+		if base >= opts.MaxLayers && len(remote.Descriptors) > opts.MaxLayers {
+			return nil, nil, errors.Errorf("max-layers %d does not leave room for new layers on top of %d base image layers", opts.MaxLayers, base)
+		}
+		ranges = layerRanges(remote.Descriptors, base, opts.MaxLayers)
+	}
+
+	descs := make([]ocispecs.Descriptor, 0, len(ranges))
+	last := make(map[int]struct{}, len(ranges))
+	for _, r := range ranges {
+		last[r.end-1] = struct{}{}
+		if r.end-r.start == 1 {
+			descs = append(descs, remote.Descriptors[r.start])
+			continue
+		}
+		done := progress.OneOff(ctx, fmt.Sprintf("merging layers %d-%d", r.start+1, r.end))
+		desc, err := mergeLayers(ctx, cs, remote.Descriptors[r.start:r.end], opts.RefCfg.Compression, r.start > 0)
+		if err := done(err); err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to merge layers %d-%d", r.start+1, r.end)
+		}
+		descs = append(descs, compression.ConvertAllLayerMediaTypes(ctx, opts.OCITypes, *desc)...)
+	}
+
+	return &solver.Remote{
+		Provider:    cs,
+		Descriptors: descs,
+	}, squashHistory(history, last), nil
+}
+
+// squashHistory returns a copy of history where the items of layers that are
+// not the last layer of a merged range are marked as empty layers.
+func squashHistory(history []ocispecs.History, last map[int]struct{}) []ocispecs.History {
+	history = append([]ocispecs.History(nil), history...)
+	var layerIndex int
+	for i, h := range history {
+		if h.EmptyLayer {
+			continue
+		}
+		if _, ok := last[layerIndex]; !ok {
+			history[i].EmptyLayer = true
+		}
+		layerIndex++
+	}
+	return history
+}
+
+// baseLayers returns the number of layers at the bottom of descs that are the
+// same as the layers of the base image.
+func baseLayers(ctx context.Context, cs content.Store, descs []ocispecs.Descriptor, baseImg *dockerspec.DockerOCIImage) (int, error) {
+	if baseImg == nil {
+		return 0, nil
+	}
+	for i, desc := range descs {
+		if i >= len(baseImg.RootFS.DiffIDs) {
+			return i, nil
+		}
+		diffID := digest.Digest(desc.Annotations[labels.LabelUncompressed])
+		if diffID == "" {
+			info, err := cs.Info(ctx, desc.Digest)
+			if err != nil {
+				return 0, err
+			}
+			diffID = digest.Digest(info.Labels[labels.LabelUncompressed])
+		}
+		if diffID != baseImg.RootFS.DiffIDs[i] {
+			return i, nil
+		}
+	}
+	return len(descs), nil
+}
+
+// layerRanges keeps the base layers as they are and merges the adjacent
+// layers with the smallest combined size until there are at most maxLayers
+// layers.
+func layerRanges(descs []ocispecs.Descriptor, base int, maxLayers int) []layerRange {
+	ranges := make([]layerRange, 0, len(descs))
+	sizes := make([]int64, 0, len(descs))
+	for i, desc := range descs {
+		ranges = append(ranges, layerRange{start: i, end: i + 1})
+		sizes = append(sizes, desc.Size)
+	}
+	for len(ranges) > maxLayers {
+		best := -1
+		for i := base; i < len(ranges)-1; i++ {
+			if best == -1 || sizes[i]+sizes[i+1] < sizes[best]+sizes[best+1] {
+				best = i
+			}
+		}
+		if best == -1 {
+			break
+		}
+		ranges[best].end = ranges[best+1].end
+		sizes[best] += sizes[best+1]
+		ranges = append(ranges[:best+1], ranges[best+2:]...)
+		sizes = append(sizes[:best+1], sizes[best+2:]...)
+	}
+	return ranges
+}
+
+// mergeLayers writes a new layer blob with the combined changes of descs. If
+// keepWhiteouts is set, the new layer keeps whiteouts for files in the layers
+// below descs.
+func mergeLayers(ctx context.Context, cs content.Store, descs []ocispecs.Descriptor, comp compression.Config, keepWhiteouts bool) (*ocispecs.Descriptor, error) {
+	layers := make([]tarmerge.Layer, len(descs))
+	for i, desc := range descs {
+		layers[i] = func() (io.ReadCloser, error) {
+			c, err := compression.FromMediaType(desc.MediaType)
+			if err != nil {
+				return nil, err
+			}
+			return c.Decompress(ctx, cs, desc)
+		}
+	}
+
+	ref := fmt.Sprintf("merge-%s-%s", descs[len(descs)-1].Digest, identity.NewID())
+	w, err := cs.Writer(ctx, content.WithRef(ref))
+	if err != nil {
+		return nil, err
+	}
+	defer w.Close()
+	if err := w.Truncate(0); err != nil { // Old written data possibly remains
+		return nil, err
+	}
+
+	compressorFunc, finalize := comp.Type.Compress(ctx, comp)
+	zw, err := compressorFunc(&iohelper.NopWriteCloser{Writer: w}, comp.Type.MediaType())
+	if err != nil {
+		return nil, err
+	}
+	diffID := digest.Canonical.Digester()
+	if err := tarmerge.Merge(io.MultiWriter(zw, diffID.Hash()), layers, keepWhiteouts); err != nil {
+		zw.Close()
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	labelz := map[string]string{labels.LabelUncompressed: diffID.Digest().String()}
+	if err := w.Commit(ctx, 0, "", content.WithLabels(labelz)); err != nil && !cerrdefs.IsAlreadyExists(err) {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	info, err := cs.Info(ctx, w.Digest())
+	if err != nil {
+		return nil, err
+	}
+
+	desc := &ocispecs.Descriptor{
+		MediaType:   comp.Type.MediaType(),
+		Digest:      info.Digest,
+		Size:        info.Size,
+		Annotations: labelz,
+	}
+	if finalize != nil {
+		a, err := finalize(ctx, cs)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed finalize compression")
+		}
+		maps.Copy(desc.Annotations, a)
+	}
+	return desc, nil
+}
@@ -0,0 +1,156 @@
+package containerimage
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/pkg/labels"
+	"github.com/containerd/containerd/v2/plugins/content/local"
+	"github.com/moby/buildkit/exporter/containerimage/exptypes"
+	dockerspec "github.com/moby/docker-image-spec/specs-go/v1"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLayerRanges(t *testing.T) {
+	t.Parallel()
+
+	layers := func(sizes ...int64) []ocispecs.Descriptor {
+		descs := make([]ocispecs.Descriptor, len(sizes))
+		for i, size := range sizes {
+			descs[i] = ocispecs.Descriptor{Size: size}
+		}
+		return descs
+	}
+
+	for _, tc := range []struct {
+		name      string
+		descs     []ocispecs.Descriptor
+		base      int
+		maxLayers int
+		expected  []layerRange
+	}{
+		{
+			name:      "fits",
+			descs:     layers(1, 2, 3),
+			maxLayers: 3,
+			expected:  []layerRange{{0, 1}, {1, 2}, {2, 3}},
+		},
+		{
+			name:      "smallest",
+			descs:     layers(10, 1, 2, 10),
+			maxLayers: 3,
+			expected:  []layerRange{{0, 1}, {1, 3}, {3, 4}},
+		},
+		{
+			name:      "repeated",
+			descs:     layers(5, 1, 1, 5, 1),
+			maxLayers: 2,
+			expected:  []layerRange{{0, 3}, {3, 5}},
+		},
+		{
+			name:      "base",
+			descs:     layers(1, 1, 10, 20, 30),
+			base:      2,
+			maxLayers: 3,
+			expected:  []layerRange{{0, 1}, {1, 2}, {2, 5}},
+		},
+		{
+			name:      "base only",
+			descs:     layers(1, 1, 1),
+			base:      3,
+			maxLayers: 2,
+			expected:  []layerRange{{0, 1}, {1, 2}, {2, 3}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, layerRanges(tc.descs, tc.base, tc.maxLayers))
+		})
+	}
+}
+
+func TestBaseLayers(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+
+	store, err := local.NewLabeledStore(t.TempDir(), &labelStore{labels: map[digest.Digest]map[string]string{}})
+	require.NoError(t, err)
+
+	// the diffID of the last layer is only available from the content store
+	blob := []byte("layer2")
+	diffIDs := []digest.Digest{digest.FromString("diff0"), digest.FromString("diff1"), digest.FromString("diff2")}
+	err = content.WriteBlob(ctx, store, "layer2", strings.NewReader(string(blob)), ocispecs.Descriptor{
+		Digest: digest.FromBytes(blob),
+		Size:   int64(len(blob)),
+	}, content.WithLabels(map[string]string{labels.LabelUncompressed: diffIDs[2].String()}))
+	require.NoError(t, err)
+
+	descs := []ocispecs.Descriptor{
+		{Digest: digest.FromString("layer0"), Annotations: map[string]string{labels.LabelUncompressed: diffIDs[0].String()}},
+		{Digest: digest.FromString("layer1"), Annotations: map[string]string{labels.LabelUncompressed: diffIDs[1].String()}},
+		{Digest: digest.FromBytes(blob), Size: int64(len(blob))},
+	}
+
+	baseImg := func(diffIDs ...digest.Digest) *dockerspec.DockerOCIImage {
+		img := &dockerspec.DockerOCIImage{}
+		img.RootFS.DiffIDs = diffIDs
+		return img
+	}
+
+	n, err := baseLayers(ctx, store, descs, nil)
+	require.NoError(t, err)
+	require.Equal(t, 0, n)
+
+	n, err = baseLayers(ctx, store, descs, baseImg(diffIDs[:2]...))
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	n, err = baseLayers(ctx, store, descs, baseImg(diffIDs...))
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+
+	n, err = baseLayers(ctx, store, descs, baseImg(diffIDs[0], digest.FromString("other"), diffIDs[2]))
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+}
+
+func TestSquashHistory(t *testing.T) {
+	t.Parallel()
+
+	history := []ocispecs.History{
+		{CreatedBy: "layer0"},
+		{CreatedBy: "env", EmptyLayer: true},
+		{CreatedBy: "layer1"},
+		{CreatedBy: "layer2"},
+		{CreatedBy: "cmd", EmptyLayer: true},
+		{CreatedBy: "layer3"},
+	}
+	// layers 1-2 are merged
+	last := map[int]struct{}{0: {}, 2: {}, 3: {}}
+
+	squashed := squashHistory(history, last)
+	require.Equal(t, []ocispecs.History{
+		{CreatedBy: "layer0"},
+		{CreatedBy: "env", EmptyLayer: true},
+		{CreatedBy: "layer1", EmptyLayer: true},
+		{CreatedBy: "layer2"},
+		{CreatedBy: "cmd", EmptyLayer: true},
+		{CreatedBy: "layer3"},
+	}, squashed)
+
+	// the original history isn't modified
+	require.False(t, history[2].EmptyLayer)
+}
+
+func TestSquashInlineCache(t *testing.T) {
+	t.Parallel()
+
+	ic := &ImageWriter{}
+	for _, opts := range []*ImageCommitOpts{{Squash: true}, {MaxLayers: 2}} {
+		_, _, err := ic.commitDistributionManifest(context.TODO(), opts, nil, nil, nil, nil, &exptypes.InlineCacheEntry{}, nil, nil, nil)
+		require.ErrorContains(t, err, "inline cache is not supported")
+	}
+}
@@ -46,8 +46,6 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
-const attestationManifestArtifactType = "application/vnd.docker.attestation.manifest.v1+json"
-
 type WriterOpt struct {
 	Snapshotter  snapshot.Snapshotter
 	ContentStore content.Store
@@ -63,19 +61,27 @@ type ImageWriter struct {
 	opt WriterOpt
 }
 
-func (ic *ImageWriter) Commit(ctx context.Context, inp *exporter.Source, sessionID string, inlineCache exptypes.InlineCache, opts *ImageCommitOpts) (*ocispecs.Descriptor, error) {
+// Commit writes the image for inp to the content store and returns the
+// descriptor of its root manifest or index. With
+// exptypes.AttestationStorageReferrers the attestation manifests are not part
+// of the image and are returned as referrers of the platform manifests
+// instead.
+func (ic *ImageWriter) Commit(ctx context.Context, inp *exporter.Source, sessionID string, inlineCache exptypes.InlineCache, opts *ImageCommitOpts) (*ocispecs.Descriptor, []ocispecs.Descriptor, error) {
 	if _, ok := inp.Metadata[exptypes.ExporterPlatformsKey]; len(inp.Refs) > 0 && !ok {
-		return nil, errors.Errorf("unable to export multiple refs, missing platforms mapping")
+		return nil, nil, errors.Errorf("unable to export multiple refs, missing platforms mapping")
 	}
 
 	isMap := len(inp.Refs) > 0
 
 	ps, err := exptypes.ParsePlatforms(inp.Metadata)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	if !isMap {
+	referrersStorage := opts.AttestationStorage == exptypes.AttestationStorageReferrers
+	var referrers []ocispecs.Descriptor
+
+	if !isMap && !referrersStorage {
 		// enable index if we need to include attestations
 		for _, p := range ps.Platforms {
 			if atts, ok := inp.Attestations[p.ID]; ok {
@@ -95,7 +101,7 @@ func (ic *ImageWriter) Commit(ctx context.Context, inp *exporter.Source, session
 	}
 	if opts.Epoch == nil {
 		if tm, ok, err := epoch.ParseSource(inp); err != nil {
-			return nil, err
+			return nil, nil, err
 		} else if ok {
 			opts.Epoch = tm
 		}
@@ -104,7 +110,7 @@ func (ic *ImageWriter) Commit(ctx context.Context, inp *exporter.Source, session
 	for pk, a := range opts.Annotations {
 		if pk != "" {
 			if _, ok := inp.FindRef(pk); !ok {
-				return nil, errors.Errorf("invalid annotation: no platform %s found in source", pk)
+				return nil, nil, errors.Errorf("invalid annotation: no platform %s found in source", pk)
 			}
 		}
 		if len(a.Index)+len(a.IndexDescriptor)+len(a.ManifestDescriptor) > 0 {
@@ -114,7 +120,7 @@ func (ic *ImageWriter) Commit(ctx context.Context, inp *exporter.Source, session
 
 	if !isMap {
 		if len(ps.Platforms) > 1 {
-			return nil, errors.Errorf("cannot export multiple platforms without multi-platform enabled")
+			return nil, nil, errors.Errorf("cannot export multiple platforms without multi-platform enabled")
 		}
 
 		var ref cache.ImmutableRef
@@ -133,33 +139,33 @@ func (ic *ImageWriter) Commit(ctx context.Context, inp *exporter.Source, session
 		if len(baseImgConfig) > 0 {
 			var baseImgX dockerspec.DockerOCIImage
 			if err := json.Unmarshal(baseImgConfig, &baseImgX); err != nil {
-				return nil, errors.Wrap(err, "failed to unmarshal base image config")
+				return nil, nil, errors.Wrap(err, "failed to unmarshal base image config")
 			}
 			baseImg = &baseImgX
 		}
 
 		remotes, err := ic.exportLayers(ctx, opts.RefCfg, session.NewGroup(sessionID), ref)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		remote := &remotes[0]
 		if opts.RewriteTimestamp {
 			remote, err = ic.rewriteRemoteWithEpoch(ctx, opts, remote, baseImg)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 		}
 
 		annotations := opts.Annotations.Platform(nil)
 		if len(annotations.Index) > 0 || len(annotations.IndexDescriptor) > 0 {
-			return nil, errors.Errorf("index annotations not supported for single platform export")
+			return nil, nil, errors.Errorf("index annotations not supported for single platform export")
 		}
 
 		var inlineCacheEntry *exptypes.InlineCacheEntry
 		if inlineCache != nil {
 			inlineCacheResult, err := inlineCache(ctx)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			if inlineCacheResult != nil {
 				if p != nil {
@@ -172,7 +178,7 @@ func (ic *ImageWriter) Commit(ctx context.Context, inp *exporter.Source, session
 
 		mfstDesc, configDesc, err := ic.commitDistributionManifest(ctx, opts, ref, config, remote, annotations, inlineCacheEntry, opts.Epoch, session.NewGroup(sessionID), baseImg)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		if mfstDesc.Annotations == nil {
 			mfstDesc.Annotations = make(map[string]string)
@@ -180,9 +186,19 @@ func (ic *ImageWriter) Commit(ctx context.Context, inp *exporter.Source, session
 		if len(ps.Platforms) == 1 {
 			mfstDesc.Platform = &ps.Platforms[0].Platform
 		}
+		if referrersStorage && p != nil {
+			if attestations, ok := inp.Attestations[p.ID]; ok {
+				desc, err := ic.commitAttestations(ctx, opts, sessionID, p, ref, remote, *mfstDesc, attestations)
+				if err != nil {
+					return nil, nil, err
+				}
+				referrers = append(referrers, referrerDescriptor(*desc))
+			}
+		}
+
 		mfstDesc.Annotations[exptypes.ExporterConfigDigestKey] = configDesc.Digest.String()
 
-		return mfstDesc, nil
+		return mfstDesc, referrers, nil
 	}
 
 	if len(inp.Attestations) > 0 {
@@ -194,7 +210,7 @@ func (ic *ImageWriter) Commit(ctx context.Context, inp *exporter.Source, session
 	for _, p := range ps.Platforms {
 		r, ok := inp.FindRef(p.ID)
 		if !ok {
-			return nil, errors.Errorf("failed to find ref for ID %s", p.ID)
+			return nil, nil, errors.Errorf("failed to find ref for ID %s", p.ID)
 		}
 		remotesMap[p.ID] = len(refs)
 		refs = append(refs, r)
@@ -202,14 +218,14 @@ func (ic *ImageWriter) Commit(ctx context.Context, inp *exporter.Source, session
 
 	remotes, err := ic.exportLayers(ctx, opts.RefCfg, session.NewGroup(sessionID), refs...)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	var inlineCacheResult *result.Result[*exptypes.InlineCacheEntry]
 	if inlineCache != nil {
 		inlineCacheResult, err = inlineCache(ctx)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
@@ -232,7 +248,7 @@ func (ic *ImageWriter) Commit(ctx context.Context, inp *exporter.Source, session
 	for i, p := range ps.Platforms {
 		r, ok := inp.FindRef(p.ID)
 		if !ok {
-			return nil, errors.Errorf("failed to find ref for ID %s", p.ID)
+			return nil, nil, errors.Errorf("failed to find ref for ID %s", p.ID)
 		}
 		config := exptypes.ParseKey(inp.Metadata, exptypes.ExporterImageConfigKey, &p)
 		baseImgConfig := exptypes.ParseKey(inp.Metadata, exptypes.ExporterImageBaseConfigKey, &p)
@@ -240,7 +256,7 @@ func (ic *ImageWriter) Commit(ctx context.Context, inp *exporter.Source, session
 		if len(baseImgConfig) > 0 {
 			var baseImgX dockerspec.DockerOCIImage
 			if err := json.Unmarshal(baseImgConfig, &baseImgX); err != nil {
-				return nil, errors.Wrap(err, "failed to unmarshal base image config")
+				return nil, nil, errors.Wrap(err, "failed to unmarshal base image config")
 			}
 			baseImg = &baseImgX
 		}
@@ -254,7 +270,7 @@ func (ic *ImageWriter) Commit(ctx context.Context, inp *exporter.Source, session
 		if opts.RewriteTimestamp {
 			remote, err = ic.rewriteRemoteWithEpoch(ctx, opts, remote, baseImg)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 		}
 
@@ -265,7 +281,7 @@ func (ic *ImageWriter) Commit(ctx context.Context, inp *exporter.Source, session
 
 		desc, _, err := ic.commitDistributionManifest(ctx, opts, r, config, remote, opts.Annotations.Platform(&p.Platform), inlineCacheEntry, opts.Epoch, session.NewGroup(sessionID), baseImg)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		dp := p.Platform
 		desc.Platform = &dp
@@ -274,49 +290,13 @@ func (ic *ImageWriter) Commit(ctx context.Context, inp *exporter.Source, session
 		labels[fmt.Sprintf("containerd.io/gc.ref.content.%d", i)] = desc.Digest.String()
 
 		if attestations, ok := inp.Attestations[p.ID]; ok {
-			attestations, err := attestation.Unbundle(ctx, session.NewGroup(sessionID), attestations)
+			desc, err := ic.commitAttestations(ctx, opts, sessionID, &p, r, remote, *desc, attestations)
 			if err != nil {
-				return nil, err
-			}
-
-			eg, ctx2 := errgroup.WithContext(ctx)
-			for i, att := range attestations {
-				i, att := i, att
-				eg.Go(func() error {
-					att, err := supplementSBOM(ctx2, session.NewGroup(sessionID), r, remote, att)
-					if err != nil {
-						return err
-					}
-					attestations[i] = att
-					return nil
-				})
+				return nil, nil, err
 			}
-			if err := eg.Wait(); err != nil {
-				return nil, err
-			}
-
-			var defaultSubjects []intoto.Subject
-			for _, name := range strings.Split(opts.ImageName, ",") {
-				if name == "" {
-					continue
-				}
-				pl, err := purl.RefToPURL(packageurl.TypeDocker, name, &p.Platform)
-				if err != nil {
-					return nil, err
-				}
-				defaultSubjects = append(defaultSubjects, intoto.Subject{
-					Name:   pl,
-					Digest: result.ToDigestMap(desc.Digest),
-				})
-			}
-			stmts, err := attestation.MakeInTotoStatements(ctx, session.NewGroup(sessionID), attestations, defaultSubjects)
-			if err != nil {
-				return nil, err
-			}
-
-			desc, err := ic.commitAttestationsManifest(ctx, opts, *desc, stmts, opts.OCIArtifact)
-			if err != nil {
-				return nil, err
+			if referrersStorage {
+				referrers = append(referrers, referrerDescriptor(*desc))
+				continue
 			}
 			desc.Platform = &intotoPlatform
 			attestationManifests = append(attestationManifests, *desc)
@@ -330,7 +310,7 @@ func (ic *ImageWriter) Commit(ctx context.Context, inp *exporter.Source, session
 
 	idxBytes, err := json.MarshalIndent(idx, "", "  ")
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to marshal index")
+		return nil, nil, errors.Wrap(err, "failed to marshal index")
 	}
 
 	idxDigest := digest.FromBytes(idxBytes)
@@ -343,11 +323,68 @@ func (ic *ImageWriter) Commit(ctx context.Context, inp *exporter.Source, session
 	idxDone := progress.OneOff(ctx, "exporting manifest list "+idxDigest.String())
 
 	if err := content.WriteBlob(ctx, ic.opt.ContentStore, idxDigest.String(), bytes.NewReader(idxBytes), idxDesc, content.WithLabels(labels)); err != nil {
-		return nil, idxDone(errors.Wrapf(err, "error writing manifest list blob %s", idxDigest))
+		return nil, nil, idxDone(errors.Wrapf(err, "error writing manifest list blob %s", idxDigest))
 	}
 	idxDone(nil)
 
-	return &idxDesc, nil
+	return &idxDesc, referrers, nil
+}
+
+// commitAttestations writes the attestation manifest for the platform
+// manifest target built from ref.
+func (ic *ImageWriter) commitAttestations(ctx context.Context, opts *ImageCommitOpts, sessionID string, p *exptypes.Platform, ref cache.ImmutableRef, remote *solver.Remote, target ocispecs.Descriptor, attestations []exporter.Attestation) (*ocispecs.Descriptor, error) {
+	attestations, err := attestation.Unbundle(ctx, session.NewGroup(sessionID), attestations)
+	if err != nil {
+		return nil, err
+	}
+
+	eg, ctx2 := errgroup.WithContext(ctx)
+	for i, att := range attestations {
+		i, att := i, att
+		eg.Go(func() error {
+			att, err := supplementSBOM(ctx2, session.NewGroup(sessionID), ref, remote, att)
+			if err != nil {
+				return err
+			}
+			attestations[i] = att
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	var defaultSubjects []intoto.Subject
+	for _, name := range strings.Split(opts.ImageName, ",") {
+		if name == "" {
+			continue
+		}
+		pl, err := purl.RefToPURL(packageurl.TypeDocker, name, &p.Platform)
+		if err != nil {
+			return nil, err
+		}
+		defaultSubjects = append(defaultSubjects, intoto.Subject{
+			Name:   pl,
+			Digest: result.ToDigestMap(target.Digest),
+		})
+	}
+	stmts, err := attestation.MakeInTotoStatements(ctx, session.NewGroup(sessionID), attestations, defaultSubjects)
+	if err != nil {
+		return nil, err
+	}
+
+	return ic.commitAttestationsManifest(ctx, opts, target, stmts, opts.OCIArtifact)
+}
+
+// referrerDescriptor returns the descriptor of an attestation manifest as it
+// is listed by the referrers API.
+func referrerDescriptor(desc ocispecs.Descriptor) ocispecs.Descriptor {
+	return ocispecs.Descriptor{
+		MediaType:    desc.MediaType,
+		ArtifactType: attestationTypes.ArtifactTypeAttestationManifest,
+		Digest:       desc.Digest,
+		Size:         desc.Size,
+	}
 }
 
 func (ic *ImageWriter) exportLayers(ctx context.Context, refCfg cacheconfig.RefConfig, s session.Group, refs ...cache.ImmutableRef) ([]solver.Remote, error) {
@@ -460,6 +497,11 @@ func (ic *ImageWriter) rewriteRemoteWithEpoch(ctx context.Context, opts *ImageCo
 }
 
 func (ic *ImageWriter) commitDistributionManifest(ctx context.Context, opts *ImageCommitOpts, ref cache.ImmutableRef, config []byte, remote *solver.Remote, annotations *Annotations, inlineCache *exptypes.InlineCacheEntry, epoch *time.Time, sg session.Group, baseImg *dockerspec.DockerOCIImage) (*ocispecs.Descriptor, *ocispecs.Descriptor, error) {
+	if inlineCache != nil && (opts.Squash || opts.MaxLayers > 0) {
+		// the inline cache describes the layers of the build result
+		return nil, nil, errors.New("inline cache is not supported with squash or max-layers")
+	}
+
 	if len(config) == 0 {
 		var err error
 		config, err = defaultImageConfig()
@@ -478,6 +520,11 @@ func (ic *ImageWriter) commitDistributionManifest(ctx context.Context, opts *Ima
 		return nil, nil, err
 	}
 
+	remote, history, err = ic.squashLayers(ctx, opts, remote, history, baseImg)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	config, err = patchImageConfig(config, remote.Descriptors, history, inlineCache, epoch, baseImg)
 	if err != nil {
 		return nil, nil, err
@@ -569,13 +616,28 @@ func (ic *ImageWriter) commitAttestationsManifest(ctx context.Context, opts *Ima
 	for i, statement := range statements {
 		i, statement := i, statement
 
-		data, err := json.Marshal(statement)
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to marshal attestation")
+		mediaType := intoto.PayloadType
+		var data []byte
+		if opts.SignAttestations && opts.AttestationSigner != nil {
+			env, err := attestation.SignStatement(ctx, opts.AttestationSigner, statement)
+			if err != nil {
+				return nil, err
+			}
+			data, err = json.Marshal(env)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to marshal attestation envelope")
+			}
+			mediaType = attestationTypes.MediaTypeDSSEEnvelope
+		} else {
+			var err error
+			data, err = json.Marshal(statement)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to marshal attestation")
+			}
 		}
 		digest := digest.FromBytes(data)
 		desc := ocispecs.Descriptor{
-			MediaType: intoto.PayloadType,
+			MediaType: mediaType,
 			Digest:    digest,
 			Size:      int64(len(data)),
 			Annotations: map[string]string{
@@ -616,7 +678,7 @@ func (ic *ImageWriter) commitAttestationsManifest(ctx context.Context, opts *Ima
 	}
 
 	if ociArtifact {
-		mfst.ArtifactType = attestationManifestArtifactType
+		mfst.ArtifactType = attestationTypes.ArtifactTypeAttestationManifest
 		mfst.Subject = &target
 	}
 
@@ -0,0 +1,116 @@
+package containerimage
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/plugins/content/local"
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+	slsa "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/common"
+	"github.com/moby/buildkit/session/signer"
+	"github.com/moby/buildkit/session/signer/signerprovider"
+	attestationTypes "github.com/moby/buildkit/util/attestation"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/secure-systems-lab/go-securesystemslib/dsse"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitSignedAttestationsManifest(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+
+	store, err := local.NewLabeledStore(t.TempDir(), &labelStore{labels: map[digest.Digest]map[string]string{}})
+	require.NoError(t, err)
+	ic, err := NewImageWriter(WriterOpt{ContentStore: store})
+	require.NoError(t, err)
+
+	sp, pub, err := signerprovider.NewEphemeral()
+	require.NoError(t, err)
+	require.IsType(t, &ecdsa.PublicKey{}, pub)
+	keyID, err := signer.KeyID(pub)
+	require.NoError(t, err)
+
+	target := ocispecs.Descriptor{
+		MediaType: ocispecs.MediaTypeImageManifest,
+		Digest:    digest.FromString("image"),
+		Size:      123,
+	}
+	statement := intoto.Statement{
+		StatementHeader: intoto.StatementHeader{
+			Type:          intoto.StatementInTotoV01,
+			PredicateType: "https://example.com/predicate/v1",
+			Subject: []intoto.Subject{{
+				Name:   "pkg:docker/foo@latest",
+				Digest: slsa.DigestSet{"sha256": target.Digest.Encoded()},
+			}},
+		},
+		Predicate: map[string]any{"foo": "bar"},
+	}
+
+	opts := &ImageCommitOpts{
+		OCITypes:          true,
+		SignAttestations:  true,
+		AttestationSigner: &providerSigner{sp.(signer.SignerServer)},
+	}
+	desc, err := ic.commitAttestationsManifest(ctx, opts, target, []intoto.Statement{statement}, false)
+	require.NoError(t, err)
+
+	dt, err := content.ReadBlob(ctx, store, *desc)
+	require.NoError(t, err)
+	var mfst ocispecs.Manifest
+	require.NoError(t, json.Unmarshal(dt, &mfst))
+	require.Len(t, mfst.Layers, 1)
+	layer := mfst.Layers[0]
+	require.Equal(t, attestationTypes.MediaTypeDSSEEnvelope, layer.MediaType)
+	require.Equal(t, statement.PredicateType, layer.Annotations["in-toto.io/predicate-type"])
+
+	dt, err = content.ReadBlob(ctx, store, layer)
+	require.NoError(t, err)
+	var env dsse.Envelope
+	require.NoError(t, json.Unmarshal(dt, &env))
+	require.Equal(t, intoto.PayloadType, env.PayloadType)
+
+	payload, err := base64.StdEncoding.DecodeString(env.Payload)
+	require.NoError(t, err)
+	var st intoto.Statement
+	require.NoError(t, json.Unmarshal(payload, &st))
+	require.Equal(t, statement.PredicateType, st.PredicateType)
+	require.Equal(t, statement.Subject, st.Subject)
+
+	// the signature covers the DSSE pre-authentication encoding
+	require.Len(t, env.Signatures, 1)
+	require.Equal(t, keyID, env.Signatures[0].KeyID)
+	sig, err := base64.StdEncoding.DecodeString(env.Signatures[0].Sig)
+	require.NoError(t, err)
+	require.NoError(t, signer.VerifyWithKey(pub, dsse.PAE(env.PayloadType, payload), sig))
+	require.Error(t, signer.VerifyWithKey(pub, payload, sig))
+
+	// without signing the statement is stored as is
+	opts.SignAttestations = false
+	desc, err = ic.commitAttestationsManifest(ctx, opts, target, []intoto.Statement{statement}, false)
+	require.NoError(t, err)
+	dt, err = content.ReadBlob(ctx, store, *desc)
+	require.NoError(t, err)
+	mfst = ocispecs.Manifest{}
+	require.NoError(t, json.Unmarshal(dt, &mfst))
+	require.Len(t, mfst.Layers, 1)
+	require.Equal(t, intoto.PayloadType, mfst.Layers[0].MediaType)
+}
+
+// providerSigner signs with the signer the client attaches to the session.
+type providerSigner struct {
+	sp signer.SignerServer
+}
+
+func (s *providerSigner) Sign(ctx context.Context, payloadType string, payload []byte) ([]byte, string, error) {
+	resp, err := s.sp.Sign(ctx, &signer.SignRequest{PayloadType: payloadType, Data: payload})
+	if err != nil {
+		return nil, "", err
+	}
+	return resp.Signature, resp.KeyID, nil
+}
@@ -0,0 +1,222 @@
+package fsimage
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/moby/buildkit/cache"
+	"github.com/moby/buildkit/exporter"
+	"github.com/moby/buildkit/exporter/containerimage/exptypes"
+	"github.com/moby/buildkit/exporter/local"
+	"github.com/moby/buildkit/exporter/util/epoch"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/session/filesync"
+	"github.com/moby/buildkit/util/progress"
+	"github.com/pkg/errors"
+	"github.com/tonistiigi/fsutil"
+	fstypes "github.com/tonistiigi/fsutil/types"
+)
+
+type Opt struct {
+	SessionManager *session.Manager
+	// Root is the worker state directory. The temporary files for the image
+	// are created below it instead of the system temp directory, which may
+	// be too small or in memory.
+	Root string
+	// Type is the filesystem of the image: client.ExporterSquashFS,
+	// client.ExporterEROFS or client.ExporterExt4.
+	Type string
+}
+
+type fsImageExporter struct {
+	opt Opt
+}
+
+func New(opt Opt) (exporter.Exporter, error) {
+	if _, ok := builders[opt.Type]; !ok {
+		return nil, errors.Errorf("unsupported filesystem image type %q", opt.Type)
+	}
+	if opt.Root == "" {
+		return nil, errors.New("root directory is required for filesystem image export")
+	}
+	return &fsImageExporter{opt: opt}, nil
+}
+
+func (e *fsImageExporter) Resolve(ctx context.Context, id int, opt map[string]string) (exporter.ExporterInstance, error) {
+	i := &fsImageExporterInstance{
+		fsImageExporter: e,
+		id:              id,
+		attrs:           opt,
+	}
+	rest, err := i.fsOpts.Load(opt)
+	if err != nil {
+		return nil, err
+	}
+	if err := i.opts.load(e.opt.Type, rest); err != nil {
+		return nil, err
+	}
+	return i, nil
+}
+
+type fsImageExporterInstance struct {
+	*fsImageExporter
+	id    int
+	attrs map[string]string
+
+	fsOpts local.CreateFSOpts
+	opts   Opts
+}
+
+func (e *fsImageExporterInstance) ID() int {
+	return e.id
+}
+
+func (e *fsImageExporterInstance) Name() string {
+	return "exporting to client " + e.opt.Type + " image"
+}
+
+func (e *fsImageExporterInstance) Type() string {
+	return e.opt.Type
+}
+
+func (e *fsImageExporterInstance) Attrs() map[string]string {
+	return e.attrs
+}
+
+func (e *fsImageExporterInstance) Config() *exporter.Config {
+	return exporter.NewConfig()
+}
+
+func (e *fsImageExporterInstance) Export(ctx context.Context, inp *exporter.Source, _ exptypes.InlineCache, sessionID string) (map[string]string, exporter.DescriptorReference, error) {
+	var defers []func() error
+
+	defer func() {
+		for i := len(defers) - 1; i >= 0; i-- {
+			defers[i]()
+		}
+	}()
+
+	if e.fsOpts.Epoch == nil {
+		if tm, ok, err := epoch.ParseSource(inp); err != nil {
+			return nil, nil, err
+		} else if ok {
+			e.fsOpts.Epoch = tm
+		}
+	}
+
+	now := time.Now().Truncate(time.Second)
+	isMap := len(inp.Refs) > 0
+
+	getDir := func(ctx context.Context, k string, ref cache.ImmutableRef, attestations []exporter.Attestation) (*fsutil.Dir, error) {
+		outputFS, cleanup, err := local.CreateFS(ctx, sessionID, k, ref, attestations, now, isMap, e.fsOpts)
+		if err != nil {
+			return nil, err
+		}
+		if cleanup != nil {
+			defers = append(defers, cleanup)
+		}
+
+		st := &fstypes.Stat{
+			Mode: uint32(os.ModeDir | 0755),
+			Path: strings.ReplaceAll(k, "/", "_"),
+		}
+		if e.fsOpts.Epoch != nil {
+			st.ModTime = e.fsOpts.Epoch.UnixNano()
+		}
+
+		return &fsutil.Dir{
+			FS:   outputFS,
+			Stat: st,
+		}, nil
+	}
+
+	if _, ok := inp.Metadata[exptypes.ExporterPlatformsKey]; isMap && !ok {
+		return nil, nil, errors.Errorf("unable to export multiple refs, missing platforms mapping")
+	}
+	p, err := exptypes.ParsePlatforms(inp.Metadata)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !isMap && len(p.Platforms) > 1 {
+		return nil, nil, errors.Errorf("unable to export multiple platforms without map")
+	}
+
+	var fs fsutil.FS
+
+	if len(p.Platforms) > 0 {
+		dirs := make([]fsutil.Dir, 0, len(p.Platforms))
+		for _, p := range p.Platforms {
+			r, ok := inp.FindRef(p.ID)
+			if !ok {
+				return nil, nil, errors.Errorf("failed to find ref for ID %s", p.ID)
+			}
+			d, err := getDir(ctx, p.ID, r, inp.Attestations[p.ID])
+			if err != nil {
+				return nil, nil, err
+			}
+			dirs = append(dirs, *d)
+		}
+		if isMap {
+			var err error
+			fs, err = fsutil.SubDirFS(dirs)
+			if err != nil {
+				return nil, nil, err
+			}
+		} else {
+			fs = dirs[0].FS
+		}
+	} else {
+		d, err := getDir(ctx, "", inp.Ref, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		fs = d.FS
+	}
+
+	tmpRoot := filepath.Join(e.opt.Root, "fsimage")
+	if err := os.MkdirAll(tmpRoot, 0700); err != nil {
+		return nil, nil, err
+	}
+	tmpDir, err := os.MkdirTemp(tmpRoot, "export-")
+	if err != nil {
+		return nil, nil, err
+	}
+	defers = append(defers, func() error { return os.RemoveAll(tmpDir) })
+
+	imagePath := filepath.Join(tmpDir, "image."+e.opt.Type)
+	report := progress.OneOff(ctx, "creating "+e.opt.Type+" image")
+	if err := createImage(ctx, e.opt.Type, e.opts, fs, e.fsOpts.Epoch, tmpDir, imagePath); err != nil {
+		return nil, nil, report(err)
+	}
+	report(nil)
+
+	timeoutCtx, cancel := context.WithCancelCause(ctx)
+	timeoutCtx, _ = context.WithTimeoutCause(timeoutCtx, 5*time.Second, errors.WithStack(context.DeadlineExceeded)) //nolint:govet
+	defer func() { cancel(errors.WithStack(context.Canceled)) }()
+
+	caller, err := e.opt.SessionManager.Get(timeoutCtx, sessionID, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f, err := os.Open(imagePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	w, err := filesync.CopyFileWriter(ctx, nil, e.id, caller)
+	if err != nil {
+		return nil, nil, err
+	}
+	report = progress.OneOff(ctx, "sending "+e.opt.Type+" image")
+	if _, err := io.Copy(w, f); err != nil {
+		w.Close()
+		return nil, nil, report(err)
+	}
+	return nil, nil, report(w.Close())
+}
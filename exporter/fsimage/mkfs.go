@@ -0,0 +1,314 @@
+package fsimage
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/google/uuid"
+	"github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/util/iohelper"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	"github.com/tonistiigi/fsutil"
+)
+
+const (
+	keySize        = "size"
+	keyBlockSize   = "block-size"
+	keyLabel       = "label"
+	keyCompression = "compression"
+)
+
+// Opts are the options of a filesystem image exporter.
+type Opts struct {
+	// Size of the image in bytes. Only used for ext4, where the size is
+	// calculated from the content if not set.
+	Size int64
+	// BlockSize of the filesystem in bytes.
+	BlockSize int64
+	// Label of the filesystem. Not supported for squashfs.
+	Label string
+	// Compression algorithm. Not supported for ext4.
+	Compression string
+}
+
+func (o *Opts) load(typ string, opt map[string]string) error {
+	for k, v := range opt {
+		switch k {
+		case keySize:
+			if typ != client.ExporterExt4 {
+				return errors.Errorf("%s is not supported by %s exporter", k, typ)
+			}
+			size, err := units.RAMInBytes(v)
+			if err != nil {
+				return errors.Wrapf(err, "invalid value for %s", k)
+			}
+			o.Size = size
+		case keyBlockSize:
+			size, err := units.RAMInBytes(v)
+			if err != nil {
+				return errors.Wrapf(err, "invalid value for %s", k)
+			}
+			o.BlockSize = size
+		case keyLabel:
+			if typ == client.ExporterSquashFS {
+				return errors.Errorf("%s is not supported by %s exporter", k, typ)
+			}
+			o.Label = v
+		case keyCompression:
+			if typ == client.ExporterExt4 {
+				return errors.Errorf("%s is not supported by %s exporter", k, typ)
+			}
+			o.Compression = v
+		}
+	}
+	return nil
+}
+
+// tarInfo describes the tarball the image is created from.
+type tarInfo struct {
+	path    string
+	size    int64
+	entries int
+	digest  digest.Digest
+}
+
+// mkfsCommand is a command that creates a filesystem image.
+type mkfsCommand struct {
+	name  string
+	args  []string
+	env   []string
+	stdin string
+	// versionFlag makes the tool print its version, which must be at least
+	// minVersion.
+	versionFlag string
+	minVersion  string
+}
+
+type builder func(opts Opts, src tarInfo, epoch *time.Time, out string) mkfsCommand
+
+var builders = map[string]builder{
+	client.ExporterSquashFS: squashfsCommand,
+	client.ExporterEROFS:    erofsCommand,
+	client.ExporterExt4:     ext4Command,
+}
+
+// squashfsCommand uses sqfstar from squashfs-tools 4.6 or later, which reads
+// the tarball from stdin.
+func squashfsCommand(opts Opts, src tarInfo, epoch *time.Time, out string) mkfsCommand {
+	cmd := mkfsCommand{
+		name:        "sqfstar",
+		args:        []string{"-quiet", "-no-progress"},
+		stdin:       src.path,
+		versionFlag: "-version",
+		minVersion:  "4.6",
+	}
+	if opts.Compression != "" {
+		cmd.args = append(cmd.args, "-comp", opts.Compression)
+	}
+	if opts.BlockSize != 0 {
+		cmd.args = append(cmd.args, "-b", strconv.FormatInt(opts.BlockSize, 10))
+	}
+	if epoch != nil {
+		cmd.env = append(cmd.env, "SOURCE_DATE_EPOCH="+strconv.FormatInt(epoch.Unix(), 10))
+	}
+	cmd.args = append(cmd.args, out)
+	return cmd
+}
+
+// erofsCommand uses mkfs.erofs from erofs-utils 1.7 or later for tarball
+// input.
+func erofsCommand(opts Opts, src tarInfo, epoch *time.Time, out string) mkfsCommand {
+	cmd := mkfsCommand{
+		name:        "mkfs.erofs",
+		args:        []string{"--quiet", "--tar=f"},
+		versionFlag: "-V",
+		minVersion:  "1.7",
+	}
+	if opts.Compression != "" {
+		cmd.args = append(cmd.args, "-z"+opts.Compression)
+	}
+	if opts.BlockSize != 0 {
+		cmd.args = append(cmd.args, "-b"+strconv.FormatInt(opts.BlockSize, 10))
+	}
+	if opts.Label != "" {
+		cmd.args = append(cmd.args, "-L", opts.Label)
+	}
+	if epoch != nil {
+		cmd.args = append(cmd.args, "-T"+strconv.FormatInt(epoch.Unix(), 10), "-U", imageUUID(src).String())
+		cmd.env = append(cmd.env, "SOURCE_DATE_EPOCH="+strconv.FormatInt(epoch.Unix(), 10))
+	}
+	cmd.args = append(cmd.args, out, src.path)
+	return cmd
+}
+
+// ext4Command uses mke2fs from e2fsprogs 1.47.1 or later for tarball input.
+// Older versions fail to read the tarball passed with -d, e.g. 1.47.0 in
+// Ubuntu 24.04.
+func ext4Command(opts Opts, src tarInfo, epoch *time.Time, out string) mkfsCommand {
+	cmd := mkfsCommand{
+		name:        "mke2fs",
+		args:        []string{"-q", "-F", "-t", "ext4", "-d", src.path},
+		versionFlag: "-V",
+		minVersion:  "1.47.1",
+	}
+	// reserve inodes for all entries and the ones created by mke2fs
+	cmd.args = append(cmd.args, "-N", strconv.Itoa(src.entries+64))
+	if opts.BlockSize != 0 {
+		cmd.args = append(cmd.args, "-b", strconv.FormatInt(opts.BlockSize, 10))
+	}
+	if opts.Label != "" {
+		cmd.args = append(cmd.args, "-L", opts.Label)
+	}
+	extended := "root_owner=0:0"
+	if epoch != nil {
+		id := imageUUID(src).String()
+		cmd.args = append(cmd.args, "-U", id)
+		extended += ",hash_seed=" + id
+		cmd.env = append(cmd.env, "E2FSPROGS_FAKE_TIME="+strconv.FormatInt(epoch.Unix(), 10))
+	}
+	cmd.args = append(cmd.args, "-E", extended)
+
+	size := opts.Size
+	if size == 0 {
+		size = ext4Size(src)
+	}
+	cmd.args = append(cmd.args, out, fmt.Sprintf("%dk", size/1024))
+	return cmd
+}
+
+// ext4Size estimates the size of an ext4 image for the tarball, leaving room
+// for the metadata and the journal.
+func ext4Size(src tarInfo) int64 {
+	const mib = 1 << 20
+	size := src.size*3/2 + int64(src.entries)*4096 + 32*mib
+	return (size + mib - 1) / mib * mib
+}
+
+// imageUUID returns a filesystem UUID derived from the content so that the
+// image is reproducible.
+func imageUUID(src tarInfo) uuid.UUID {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(src.digest))
+}
+
+// createImage writes fs as a tarball to dir and converts it to a filesystem
+// image at out. The tools can't create all image types from a stream, so the
+// full tarball and the image are both stored in dir until the export is
+// done.
+func createImage(ctx context.Context, typ string, opts Opts, fs fsutil.FS, epoch *time.Time, dir string, out string) error {
+	// check the tool before writing the tarball
+	path, err := builders[typ](opts, tarInfo{}, epoch, out).lookPath(ctx)
+	if err != nil {
+		return err
+	}
+	src, err := writeTarball(ctx, fs, filepath.Join(dir, "rootfs.tar"))
+	if err != nil {
+		return err
+	}
+	cmd := builders[typ](opts, *src, epoch, out)
+	return cmd.run(ctx, path)
+}
+
+func writeTarball(ctx context.Context, fs fsutil.FS, p string) (*tarInfo, error) {
+	f, err := os.Create(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	dgstr := digest.Canonical.Digester()
+	if err := fsutil.WriteTar(ctx, fs, &iohelper.NopWriteCloser{Writer: io.MultiWriter(f, dgstr.Hash())}); err != nil {
+		return nil, errors.Wrap(err, "failed to write tarball")
+	}
+	info := &tarInfo{path: p, digest: dgstr.Digest()}
+	if info.size, err = f.Seek(0, io.SeekCurrent); err != nil {
+		return nil, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	tr := tar.NewReader(f)
+	for {
+		if _, err := tr.Next(); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		info.entries++
+	}
+	return info, f.Close()
+}
+
+var versionRegexp = regexp.MustCompile(`\d+(\.\d+)+`)
+
+// lookPath returns the path of the tool, failing if it isn't installed or is
+// older than the minimum version.
+func (c mkfsCommand) lookPath(ctx context.Context) (string, error) {
+	path, err := exec.LookPath(c.name)
+	if err != nil {
+		return "", errors.Wrapf(err, "%s is required to create the image", c.name)
+	}
+	if c.minVersion == "" {
+		return path, nil
+	}
+	// the tools print the version and may exit with an error
+	out, _ := exec.CommandContext(ctx, path, c.versionFlag).CombinedOutput()
+	version := versionRegexp.FindString(string(out))
+	if version == "" {
+		return "", errors.Errorf("failed to detect the version of %s: %s", c.name, strings.TrimSpace(string(out)))
+	}
+	if compareVersions(version, c.minVersion) < 0 {
+		return "", errors.Errorf("%s %s is older than %s, which is required to create the image", c.name, version, c.minVersion)
+	}
+	return path, nil
+}
+
+// compareVersions compares dot separated version numbers.
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			return x - y
+		}
+	}
+	return 0
+}
+
+func (c mkfsCommand) run(ctx context.Context, path string) error {
+	cmd := exec.CommandContext(ctx, path, c.args...)
+	cmd.Env = append(os.Environ(), c.env...)
+	if c.stdin != "" {
+		f, err := os.Open(c.stdin)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		cmd.Stdin = f
+	}
+	var stderr bytes.Buffer
+	cmd.Stdout = &stderr
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return errors.Wrapf(err, "%s failed: %s", c.name, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package fsimage
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moby/buildkit/client"
+	"github.com/stretchr/testify/require"
+	"github.com/tonistiigi/fsutil"
+)
+
+func TestLoadOpts(t *testing.T) {
+	var o Opts
+	require.NoError(t, o.load(client.ExporterExt4, map[string]string{
+		"size":       "1GiB",
+		"block-size": "4k",
+		"label":      "rootfs",
+	}))
+	require.Equal(t, Opts{Size: 1 << 30, BlockSize: 4096, Label: "rootfs"}, o)
+
+	o = Opts{}
+	require.NoError(t, o.load(client.ExporterSquashFS, map[string]string{"compression": "zstd"}))
+	require.Equal(t, "zstd", o.Compression)
+
+	require.ErrorContains(t, (&Opts{}).load(client.ExporterSquashFS, map[string]string{"size": "1G"}), "size is not supported by squashfs exporter")
+	require.ErrorContains(t, (&Opts{}).load(client.ExporterSquashFS, map[string]string{"label": "x"}), "label is not supported")
+	require.ErrorContains(t, (&Opts{}).load(client.ExporterExt4, map[string]string{"compression": "gzip"}), "compression is not supported")
+	require.ErrorContains(t, (&Opts{}).load(client.ExporterEROFS, map[string]string{"block-size": "big"}), "invalid value for block-size")
+}
+
+func TestCommands(t *testing.T) {
+	src := tarInfo{path: "/tmp/rootfs.tar", size: 10 << 20, entries: 100, digest: "sha256:0000000000000000000000000000000000000000000000000000000000000000"}
+	epoch := time.Unix(1700000000, 0)
+	id := imageUUID(src).String()
+
+	cmd := squashfsCommand(Opts{Compression: "zstd"}, src, &epoch, "/tmp/out")
+	require.Equal(t, "sqfstar", cmd.name)
+	require.Equal(t, []string{"-quiet", "-no-progress", "-comp", "zstd", "/tmp/out"}, cmd.args)
+	require.Equal(t, []string{"SOURCE_DATE_EPOCH=1700000000"}, cmd.env)
+	require.Equal(t, src.path, cmd.stdin)
+
+	cmd = erofsCommand(Opts{Compression: "lz4hc", Label: "root"}, src, &epoch, "/tmp/out")
+	require.Equal(t, "mkfs.erofs", cmd.name)
+	require.Equal(t, []string{"--quiet", "--tar=f", "-zlz4hc", "-L", "root", "-T1700000000", "-U", id, "/tmp/out", src.path}, cmd.args)
+
+	cmd = ext4Command(Opts{}, src, nil, "/tmp/out")
+	require.Equal(t, "mke2fs", cmd.name)
+	require.Equal(t, []string{"-q", "-F", "-t", "ext4", "-d", src.path, "-N", "164", "-E", "root_owner=0:0", "/tmp/out", "49152k"}, cmd.args)
+	require.Empty(t, cmd.env)
+
+	cmd = ext4Command(Opts{Size: 1 << 30}, src, &epoch, "/tmp/out")
+	require.Equal(t, []string{"-q", "-F", "-t", "ext4", "-d", src.path, "-N", "164", "-U", id, "-E", "root_owner=0:0,hash_seed=" + id, "/tmp/out", "1048576k"}, cmd.args)
+	require.Equal(t, []string{"E2FSPROGS_FAKE_TIME=1700000000"}, cmd.env)
+
+	// the UUID only depends on the content
+	require.Equal(t, id, imageUUID(src).String())
+	require.NotEqual(t, id, imageUUID(tarInfo{digest: "sha256:1111111111111111111111111111111111111111111111111111111111111111"}).String())
+}
+
+func TestWriteTarball(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "src/etc"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "src/etc/hosts"), []byte("localhost"), 0644))
+
+	fs, err := fsutil.NewFS(filepath.Join(dir, "src"))
+	require.NoError(t, err)
+	info, err := writeTarball(context.TODO(), fs, filepath.Join(dir, "rootfs.tar"))
+	require.NoError(t, err)
+	require.Equal(t, 2, info.entries)
+
+	st, err := os.Stat(info.path)
+	require.NoError(t, err)
+	require.Equal(t, st.Size(), info.size)
+
+	info2, err := writeTarball(context.TODO(), fs, filepath.Join(dir, "rootfs2.tar"))
+	require.NoError(t, err)
+	require.Equal(t, info.digest, info2.digest)
+}
+
+// TestCreateImage creates an image of each type from files owned by the user
+// running the test and checks the content with the userspace tools, so that
+// neither step requires root or mounting the image.
+func TestCreateImage(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "src/etc"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "src/etc/hosts"), []byte("127.0.0.1 localhost\n"), 0644))
+	fs, err := fsutil.NewFS(filepath.Join(dir, "src"))
+	require.NoError(t, err)
+	epoch := time.Unix(1700000000, 0)
+
+	for _, tc := range []struct {
+		typ      string
+		tools    map[string]string // tool name to minimum version
+		version  string            // flag that prints the version
+		validate func(t *testing.T, image string) string
+	}{
+		{
+			typ:     client.ExporterSquashFS,
+			tools:   map[string]string{"sqfstar": "4.6", "unsquashfs": "4.6"},
+			version: "-version",
+			validate: func(t *testing.T, image string) string {
+				return runTool(t, "unsquashfs", "-cat", image, "etc/hosts")
+			},
+		},
+		{
+			typ:     client.ExporterEROFS,
+			tools:   map[string]string{"mkfs.erofs": "1.7", "fsck.erofs": "1.7"},
+			version: "-V",
+			validate: func(t *testing.T, image string) string {
+				out := filepath.Join(t.TempDir(), "out")
+				runTool(t, "fsck.erofs", "--extract="+out, image)
+				dt, err := os.ReadFile(filepath.Join(out, "etc/hosts"))
+				require.NoError(t, err)
+				return string(dt)
+			},
+		},
+		{
+			typ:     client.ExporterExt4,
+			tools:   map[string]string{"mke2fs": "1.47.1", "e2fsck": "1.47.1", "debugfs": "1.47.1"},
+			version: "-V",
+			validate: func(t *testing.T, image string) string {
+				runTool(t, "e2fsck", "-f", "-n", image)
+				return runTool(t, "debugfs", "-R", "cat /etc/hosts", image)
+			},
+		},
+	} {
+		t.Run(tc.typ, func(t *testing.T) {
+			for name, minVersion := range tc.tools {
+				requireTool(t, name, tc.version, minVersion)
+			}
+			tmpDir := t.TempDir()
+			image := filepath.Join(tmpDir, "image."+tc.typ)
+			require.NoError(t, createImage(context.TODO(), tc.typ, Opts{}, fs, &epoch, tmpDir, image))
+			require.Equal(t, "127.0.0.1 localhost\n", tc.validate(t, image))
+
+			// images are reproducible with an epoch
+			tmpDir2 := t.TempDir()
+			image2 := filepath.Join(tmpDir2, "image."+tc.typ)
+			require.NoError(t, createImage(context.TODO(), tc.typ, Opts{}, fs, &epoch, tmpDir2, image2))
+			dt, err := os.ReadFile(image)
+			require.NoError(t, err)
+			dt2, err := os.ReadFile(image2)
+			require.NoError(t, err)
+			require.Equal(t, dt, dt2)
+		})
+	}
+}
+
+// requireTool skips the test if the tool is missing or older than minVersion.
+func requireTool(t *testing.T, name, versionFlag, minVersion string) {
+	t.Helper()
+	_, err := mkfsCommand{name: name, versionFlag: versionFlag, minVersion: minVersion}.lookPath(context.TODO())
+	if err != nil {
+		t.Skip(err)
+	}
+}
+
+func TestToolVersion(t *testing.T) {
+	dir := t.TempDir()
+	tool := filepath.Join(dir, "mke2fs")
+	require.NoError(t, os.WriteFile(tool, []byte("#!/bin/sh\necho 'mke2fs 1.47.0 (5-Feb-2023)' >&2\nexit 1\n"), 0755))
+	t.Setenv("PATH", dir)
+
+	// the tool is checked before the tarball is written
+	err := createImage(context.TODO(), client.ExporterExt4, Opts{}, nil, nil, dir, filepath.Join(dir, "image.ext4"))
+	require.ErrorContains(t, err, "mke2fs 1.47.0 is older than 1.47.1")
+	_, err = os.Stat(filepath.Join(dir, "rootfs.tar"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	require.NoError(t, os.WriteFile(tool, []byte("#!/bin/sh\necho 'mke2fs 1.47.2 (1-Jan-2025)'\n"), 0755))
+	p, err := ext4Command(Opts{}, tarInfo{}, nil, "").lookPath(context.TODO())
+	require.NoError(t, err)
+	require.Equal(t, tool, p)
+
+	require.NoError(t, os.WriteFile(tool, []byte("#!/bin/sh\necho unknown\n"), 0755))
+	_, err = ext4Command(Opts{}, tarInfo{}, nil, "").lookPath(context.TODO())
+	require.ErrorContains(t, err, "failed to detect the version of mke2fs")
+
+	_, err = squashfsCommand(Opts{}, tarInfo{}, nil, "").lookPath(context.TODO())
+	require.ErrorContains(t, err, "sqfstar is required to create the image")
+
+	require.Negative(t, compareVersions("1.47.0", "1.47.1"))
+	require.Positive(t, compareVersions("1.47.10", "1.47.9"))
+	require.Zero(t, compareVersions("4.6", "4.6.0"))
+}
+
+func runTool(t *testing.T, name string, args ...string) string {
+	t.Helper()
+	out, err := exec.Command(name, args...).Output()
+	require.NoError(t, err, "%s %s", name, strings.Join(args, " "))
+	return string(out)
+}
@@ -3,6 +3,7 @@ package local
 import (
 	"context"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -22,6 +23,22 @@ import (
 	"golang.org/x/time/rate"
 )
 
+const (
+	// keyMode is an exporter option for how files are written to the
+	// destination directory.
+	keyMode = "mode"
+	// modeCopy copies all files to the destination directory, leaving other
+	// files in it untouched.
+	modeCopy = "copy"
+	// modeSync only transfers changed files and removes files from the
+	// destination directory that are not part of the result.
+	modeSync = "sync"
+	// keyPrune allows modeSync to remove files from a destination directory
+	// that is not empty. The client enforces it, the exporter only validates
+	// the value.
+	keyPrune = "prune"
+)
+
 type Opt struct {
 	SessionManager *session.Manager
 }
@@ -42,11 +59,27 @@ func (e *localExporter) Resolve(ctx context.Context, id int, opt map[string]stri
 		attrs:         opt,
 		localExporter: e,
 	}
-	_, err := i.opts.Load(opt)
+	rest, err := i.opts.Load(opt)
 	if err != nil {
 		return nil, err
 	}
 
+	switch v := rest[keyMode]; v {
+	case "", modeCopy:
+	case modeSync:
+		i.sync = true
+	default:
+		return nil, errors.Errorf("invalid value %q for %s, expected %s or %s", v, keyMode, modeCopy, modeSync)
+	}
+	if v, ok := rest[keyPrune]; ok {
+		if _, err := strconv.ParseBool(v); err != nil {
+			return nil, errors.Wrapf(err, "non-bool value specified for %s", keyPrune)
+		}
+		if !i.sync {
+			return nil, errors.Errorf("%s requires %s=%s", keyPrune, keyMode, modeSync)
+		}
+	}
+
 	return i, nil
 }
 
@@ -56,6 +89,7 @@ type localExporterInstance struct {
 	attrs map[string]string
 
 	opts CreateFSOpts
+	sync bool
 }
 
 func (e *localExporterInstance) ID() int {
@@ -112,6 +146,10 @@ func (e *localExporterInstance) Export(ctx context.Context, inp *exporter.Source
 
 	now := time.Now().Truncate(time.Second)
 
+	if e.sync {
+		return nil, nil, e.syncToCaller(ctx, inp, p, isMap, now, sessionID, caller)
+	}
+
 	visitedPath := map[string]string{}
 	var visitedMu sync.Mutex
 
@@ -189,6 +227,72 @@ func (e *localExporterInstance) Export(ctx context.Context, inp *exporter.Source
 	return nil, nil, nil
 }
 
+// syncToCaller sends the result for all platforms in a single transfer so
+// that the client can remove the files that are not part of any of them.
+func (e *localExporterInstance) syncToCaller(ctx context.Context, inp *exporter.Source, p exptypes.Platforms, isMap bool, now time.Time, sessionID string, caller session.Caller) error {
+	var cleanups []func() error
+	defer func() {
+		for i := len(cleanups) - 1; i >= 0; i-- {
+			cleanups[i]()
+		}
+	}()
+
+	getDir := func(k string, ref cache.ImmutableRef, attestations []exporter.Attestation) (fsutil.Dir, error) {
+		outputFS, cleanup, err := CreateFS(ctx, sessionID, k, ref, attestations, now, isMap, e.opts)
+		if err != nil {
+			return fsutil.Dir{}, err
+		}
+		if cleanup != nil {
+			cleanups = append(cleanups, cleanup)
+		}
+		st := &fstypes.Stat{
+			Mode: uint32(os.ModeDir | 0755),
+			Path: strings.ReplaceAll(k, "/", "_"),
+		}
+		if e.opts.Epoch != nil {
+			st.ModTime = e.opts.Epoch.UnixNano()
+		}
+		return fsutil.Dir{FS: outputFS, Stat: st}, nil
+	}
+
+	var dirs []fsutil.Dir
+	if len(p.Platforms) > 0 {
+		for _, p := range p.Platforms {
+			r, ok := inp.FindRef(p.ID)
+			if !ok {
+				return errors.Errorf("failed to find ref for ID %s", p.ID)
+			}
+			d, err := getDir(p.ID, r, inp.Attestations[p.ID])
+			if err != nil {
+				return err
+			}
+			dirs = append(dirs, d)
+		}
+	} else {
+		d, err := getDir("", inp.Ref, nil)
+		if err != nil {
+			return err
+		}
+		dirs = append(dirs, d)
+	}
+
+	var outputFS fsutil.FS
+	switch {
+	case e.opts.UsePlatformSplit(isMap):
+		var err error
+		if outputFS, err = fsutil.SubDirFS(dirs); err != nil {
+			return err
+		}
+	case len(dirs) > 1:
+		return errors.Errorf("%s=%s requires platform-split when exporting multiple platforms", keyMode, modeSync)
+	default:
+		outputFS = dirs[0].FS
+	}
+
+	progress := NewProgressHandler(ctx, "syncing files")
+	return filesync.SyncToCaller(ctx, outputFS, e.id, caller, progress)
+}
+
 func NewProgressHandler(ctx context.Context, id string) func(int, bool) {
 	limiter := rate.NewLimiter(rate.Every(100*time.Millisecond), 1)
 	pw, _, _ := progress.NewFromContext(ctx)
@@ -17,6 +17,7 @@ import (
 	cacheconfig "github.com/moby/buildkit/cache/config"
 	"github.com/moby/buildkit/client"
 	"github.com/moby/buildkit/exporter"
+	"github.com/moby/buildkit/exporter/attestation"
 	"github.com/moby/buildkit/exporter/containerimage"
 	"github.com/moby/buildkit/exporter/containerimage/exptypes"
 	"github.com/moby/buildkit/session"
@@ -49,6 +50,9 @@ type Opt struct {
 	ImageWriter    *containerimage.ImageWriter
 	Variant        ExporterVariant
 	LeaseManager   leases.Manager
+	// AttestationSigner is used to sign attestations if the client doesn't
+	// provide a signer.
+	AttestationSigner attestation.Signer
 }
 
 type imageExporter struct {
@@ -78,6 +82,9 @@ func (e *imageExporter) Resolve(ctx context.Context, id int, opt map[string]stri
 	if err != nil {
 		return nil, err
 	}
+	if e.opt.Variant == VariantDocker && i.opts.AttestationStorage == exptypes.AttestationStorageReferrers {
+		return nil, errors.Errorf("%s=%s is not supported by the docker exporter", exptypes.OptKeyAttestationStorage, exptypes.AttestationStorageReferrers)
+	}
 
 	for k, v := range opt {
 		switch k {
@@ -159,7 +166,11 @@ func (e *imageExporterInstance) Export(ctx context.Context, src *exporter.Source
 		}
 	}()
 
-	desc, err := e.opt.ImageWriter.Commit(ctx, src, sessionID, inlineCache, &opts)
+	if opts.SignAttestations {
+		opts.AttestationSigner = attestation.NewSessionSigner(e.opt.SessionManager, session.NewGroup(sessionID), e.opt.AttestationSigner)
+	}
+
+	desc, referrers, err := e.opt.ImageWriter.Commit(ctx, src, sessionID, inlineCache, &opts)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -207,7 +218,20 @@ func (e *imageExporterInstance) Export(ctx context.Context, src *exporter.Source
 		resp[exptypes.ExporterImageNameKey] = strings.Join(names, ",")
 	}
 
+	if len(referrers) > 0 {
+		dtreferrers, err := json.Marshal(referrers)
+		if err != nil {
+			return nil, nil, err
+		}
+		resp[exptypes.ExporterImageReferrersKey] = base64.StdEncoding.EncodeToString(dtreferrers)
+	}
+
 	expOpts := []archiveexporter.ExportOpt{archiveexporter.WithManifest(*desc, names...)}
+	for _, r := range referrers {
+		// referrers are listed in index.json without a name, as allowed by
+		// the OCI image layout
+		expOpts = append(expOpts, archiveexporter.WithManifest(r))
+	}
 	switch e.opt.Variant {
 	case VariantOCI:
 		expOpts = append(expOpts, archiveexporter.WithAllPlatforms(), archiveexporter.WithSkipDockerManifest())
@@ -290,6 +314,11 @@ func (e *imageExporterInstance) Export(ctx context.Context, src *exporter.Source
 		if err != nil {
 			return nil, nil, err
 		}
+		for _, r := range referrers {
+			if err := contentutil.CopyChain(ctx, store, mprovider, r); err != nil {
+				return nil, nil, err
+			}
+		}
 	}
 
 	return resp, nil, nil
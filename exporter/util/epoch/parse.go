@@ -18,6 +18,16 @@ func ParseBuildArgs(opt map[string]string) (string, bool) {
 	return v, ok
 }
 
+// ParseBuildArgsTime returns the time of the SOURCE_DATE_EPOCH build arg, or
+// nil if it isn't set.
+func ParseBuildArgsTime(opt map[string]string) (*time.Time, error) {
+	v, ok := ParseBuildArgs(opt)
+	if !ok {
+		return nil, nil
+	}
+	return parseTime(frontendSourceDateEpochArg, v)
+}
+
 func ParseExporterAttrs(opt map[string]string) (*time.Time, map[string]string, error) {
 	rest := make(map[string]string, len(opt))
 
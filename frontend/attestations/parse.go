@@ -11,6 +11,7 @@ import (
 const (
 	KeyTypeSbom       = "sbom"
 	KeyTypeProvenance = "provenance"
+	KeyTypeVuln       = "vuln"
 )
 
 const (
@@ -35,7 +36,7 @@ func Filter(v map[string]string) map[string]string {
 
 func Validate(values map[string]map[string]string) (map[string]map[string]string, error) {
 	for k := range values {
-		if k != KeyTypeSbom && k != KeyTypeProvenance {
+		if k != KeyTypeSbom && k != KeyTypeProvenance && k != KeyTypeVuln {
 			return nil, errors.Errorf("unknown attestation type %q", k)
 		}
 	}
@@ -56,6 +56,22 @@ func TestParse(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "vuln",
+			values: map[string]string{
+				"attest:sbom": "",
+				"attest:vuln": "generator=docker.io/foo/scanner,predicate-type=https://openvex.dev/ns/v0.2.0",
+			},
+			expected: map[string]map[string]string{
+				"sbom": {
+					"generator": "docker/buildkit-syft-scanner:stable-1", // intentionally not const
+				},
+				"vuln": {
+					"generator":      "docker.io/foo/scanner",
+					"predicate-type": "https://openvex.dev/ns/v0.2.0",
+				},
+			},
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			attests, err := Parse(tc.values)
@@ -0,0 +1,98 @@
+package sbom
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+	"github.com/moby/buildkit/client/llb"
+	"github.com/moby/buildkit/frontend/attestations/sbom/catalog"
+	gatewaypb "github.com/moby/buildkit/frontend/gateway/pb"
+	"github.com/moby/buildkit/solver/result"
+	"github.com/pkg/errors"
+)
+
+// BuiltinGenerator is the generator name that selects the builtin SBOM
+// generator instead of a scanner image.
+const BuiltinGenerator = "builtin"
+
+// SolveFunc solves a state and returns access to its filesystem.
+type SolveFunc func(ctx context.Context, st llb.State) (catalog.FS, error)
+
+// CreateBuiltinSBOMScanner returns a scanner that catalogs the packages of
+// the scanned states in process, without running a scanner container. The
+// results are returned as a bundle of in-toto statements in the requested
+// format, the same way as scanner images do. If epoch is set, it is used as
+// the creation time of the documents.
+func CreateBuiltinSBOMScanner(solve SolveFunc, format string, epoch *time.Time) (Scanner, error) {
+	predicateType, err := PredicateType(format)
+	if err != nil {
+		return nil, err
+	}
+	encode := catalog.SPDX
+	if format == FormatCycloneDX {
+		encode = catalog.CycloneDX
+	}
+
+	return func(ctx context.Context, name string, ref llb.State, extras map[string]llb.State, opts ...llb.ConstraintsOpt) (result.Attestation[*llb.State], error) {
+		created := time.Now()
+		if epoch != nil {
+			created = *epoch
+		}
+
+		targets := map[string]llb.State{CoreSBOMName: ref}
+		for k, st := range extras {
+			targets[ExtraSBOMPrefix+k] = st
+		}
+
+		out := llb.Scratch()
+		for _, k := range slices.Sorted(maps.Keys(targets)) {
+			fs, err := solve(ctx, targets[k])
+			if err != nil {
+				return result.Attestation[*llb.State]{}, err
+			}
+			// fs is nil for states without any files
+			res := &catalog.Result{}
+			if fs != nil {
+				res, err = catalog.Catalog(ctx, fs, catalog.Opt{})
+				if err != nil {
+					return result.Attestation[*llb.State]{}, errors.Wrapf(err, "failed to catalog packages for %s", k)
+				}
+			}
+			doc, err := encode(res, k, created)
+			if err != nil {
+				return result.Attestation[*llb.State]{}, err
+			}
+			dt, err := json.Marshal(intoto.Statement{
+				StatementHeader: intoto.StatementHeader{
+					Type:          intoto.StatementInTotoV01,
+					PredicateType: predicateType,
+					Subject:       []intoto.Subject{},
+				},
+				Predicate: json.RawMessage(doc),
+			})
+			if err != nil {
+				return result.Attestation[*llb.State]{}, err
+			}
+			out = out.File(llb.Mkfile(k+fileExtension(predicateType), 0600, dt), append([]llb.ConstraintsOpt{
+				llb.WithCustomName(fmt.Sprintf("[%s] generating sbom for %s", name, k)),
+			}, opts...)...)
+		}
+
+		return result.Attestation[*llb.State]{
+			Kind: gatewaypb.AttestationKind_Bundle,
+			Ref:  &out,
+			Metadata: map[string][]byte{
+				result.AttestationReasonKey: []byte(result.AttestationReasonSBOM),
+				result.AttestationSBOMCore:  []byte(CoreSBOMName),
+			},
+			InToto: result.InTotoAttestation{
+				PredicateType: predicateType,
+			},
+		}, nil
+	}, nil
+}
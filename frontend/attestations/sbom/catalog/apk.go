@@ -0,0 +1,60 @@
+package catalog
+
+import (
+	"context"
+	"strings"
+)
+
+const apkInstalled = "/lib/apk/db/installed"
+
+// catalogApk reads the installed database of Alpine based images. Each
+// package is a paragraph of single letter fields.
+func catalogApk(ctx context.Context, fs *fileSystem, distro *Distro) ([]Package, error) {
+	dt, ok, err := fs.readFile(ctx, apkInstalled)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	namespace := "alpine"
+	if distro != nil && distro.ID != "" {
+		namespace = distro.ID
+	}
+
+	var pkgs []Package
+	var cur *Package
+	flush := func() {
+		if cur != nil && cur.Name != "" {
+			pkgs = append(pkgs, *cur)
+		}
+		cur = nil
+	}
+	for _, l := range strings.Split(string(dt), "\n") {
+		if strings.TrimSpace(l) == "" {
+			flush()
+			continue
+		}
+		if len(l) < 2 || l[1] != ':' {
+			continue
+		}
+		if cur == nil {
+			cur = &Package{
+				Type:      TypeApk,
+				Namespace: namespace,
+				Location:  apkInstalled,
+			}
+		}
+		v := l[2:]
+		switch l[0] {
+		case 'P':
+			cur.Name = v
+		case 'V':
+			cur.Version = v
+		case 'A':
+			cur.Arch = v
+		case 'L':
+			cur.License = v
+		}
+	}
+	flush()
+	return pkgs, nil
+}
@@ -0,0 +1,174 @@
+// Package catalog finds the packages installed in a filesystem by reading
+// package manager databases, Go build information and lockfiles, without
+// running a scanner image.
+package catalog
+
+import (
+	"context"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/gateway/client"
+	"github.com/package-url/packageurl-go"
+	fstypes "github.com/tonistiigi/fsutil/types"
+)
+
+// FS gives read access to the scanned filesystem. It is implemented by
+// client.Reference.
+type FS interface {
+	ReadFile(ctx context.Context, req client.ReadRequest) ([]byte, error)
+	ReadDir(ctx context.Context, req client.ReadDirRequest) ([]*fstypes.Stat, error)
+}
+
+// Package types, as used in package URLs.
+const (
+	TypeDeb    = "deb"
+	TypeApk    = "apk"
+	TypeRPM    = "rpm"
+	TypeGolang = "golang"
+	TypeNPM    = "npm"
+	TypePyPI   = "pypi"
+)
+
+type Package struct {
+	Type    string
+	Name    string
+	Version string
+	// Namespace is the vendor of OS packages, the module path prefix of Go
+	// modules or the scope of npm packages.
+	Namespace string
+	Arch      string
+	Epoch     string
+	License   string
+	// Location is the file the package was found in.
+	Location string
+}
+
+// PURL returns the package URL of p. distro is added as a qualifier for OS
+// packages.
+func (p Package) PURL(distro *Distro) string {
+	var qualifiers packageurl.Qualifiers
+	if p.Arch != "" {
+		qualifiers = append(qualifiers, packageurl.Qualifier{Key: "arch", Value: p.Arch})
+	}
+	if p.Epoch != "" {
+		qualifiers = append(qualifiers, packageurl.Qualifier{Key: "epoch", Value: p.Epoch})
+	}
+	if p.isOS() && distro != nil && distro.ID != "" {
+		d := distro.ID
+		if distro.VersionID != "" {
+			d += "-" + distro.VersionID
+		}
+		qualifiers = append(qualifiers, packageurl.Qualifier{Key: "distro", Value: d})
+	}
+	return packageurl.NewPackageURL(p.Type, p.Namespace, p.Name, p.Version, qualifiers, "").ToString()
+}
+
+func (p Package) isOS() bool {
+	switch p.Type {
+	case TypeDeb, TypeApk, TypeRPM:
+		return true
+	}
+	return false
+}
+
+// Distro describes the operating system from /etc/os-release.
+type Distro struct {
+	ID         string
+	VersionID  string
+	PrettyName string
+}
+
+type Result struct {
+	Distro   *Distro
+	Packages []Package
+}
+
+type Opt struct {
+	// BinaryPaths are searched for Go binaries in addition to the default
+	// binary directories.
+	BinaryPaths []string
+}
+
+type cataloger func(ctx context.Context, fs *fileSystem, distro *Distro) ([]Package, error)
+
+// Catalog returns the packages found in fsys.
+func Catalog(ctx context.Context, fsys FS, opt Opt) (*Result, error) {
+	fs := newFileSystem(fsys)
+
+	distro, err := readOSRelease(ctx, fs)
+	if err != nil {
+		return nil, err
+	}
+
+	catalogers := []cataloger{
+		catalogDpkg,
+		catalogApk,
+		catalogRPM,
+		func(ctx context.Context, fs *fileSystem, _ *Distro) ([]Package, error) {
+			return catalogGoBinaries(ctx, fs, opt.BinaryPaths)
+		},
+		catalogLockfiles,
+	}
+
+	res := &Result{Distro: distro}
+	for _, c := range catalogers {
+		pkgs, err := c(ctx, fs, distro)
+		if err != nil {
+			return nil, err
+		}
+		res.Packages = append(res.Packages, pkgs...)
+	}
+	sort.SliceStable(res.Packages, func(i, j int) bool {
+		a, b := res.Packages[i], res.Packages[j]
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Version < b.Version
+	})
+	return res, nil
+}
+
+func readOSRelease(ctx context.Context, fs *fileSystem) (*Distro, error) {
+	for _, p := range []string{"/etc/os-release", "/usr/lib/os-release"} {
+		dt, ok, err := fs.readFile(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		d := &Distro{}
+		for _, l := range strings.Split(string(dt), "\n") {
+			k, v, ok := strings.Cut(strings.TrimSpace(l), "=")
+			if !ok {
+				continue
+			}
+			v = strings.Trim(v, `"'`)
+			switch k {
+			case "ID":
+				d.ID = v
+			case "VERSION_ID":
+				d.VersionID = v
+			case "PRETTY_NAME":
+				d.PrettyName = v
+			}
+		}
+		return d, nil
+	}
+	return nil, nil
+}
+
+// splitGoPath splits a Go module path into the namespace and name parts of a
+// package URL.
+func splitGoPath(p string) (string, string) {
+	dir, name := path.Split(p)
+	return strings.TrimSuffix(dir, "/"), name
+}
@@ -0,0 +1,319 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/moby/buildkit/frontend/gateway/client"
+	"github.com/moby/buildkit/util/sbomutil/cyclonedx"
+	"github.com/stretchr/testify/require"
+	fstypes "github.com/tonistiigi/fsutil/types"
+)
+
+func TestCatalogDpkg(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"etc/os-release": &fstest.MapFile{Data: []byte(`PRETTY_NAME="Debian GNU/Linux 12 (bookworm)"
+ID=debian
+VERSION_ID="12"
+`)},
+		"var/lib/dpkg/status": &fstest.MapFile{Data: []byte(`Package: base-files
+Status: install ok installed
+Architecture: amd64
+Version: 12.4+deb12u5
+
+Package: removed
+Status: deinstall ok config-files
+Architecture: amd64
+Version: 1.0
+
+Package: libc6
+Status: install ok installed
+Architecture: amd64
+Source: glibc
+Version: 2.36-9+deb12u4
+Description: GNU C Library: Shared libraries
+ Contains the standard libraries that are used by nearly all programs on
+ the system.
+`)},
+	}
+
+	res, err := Catalog(context.TODO(), &testFS{fsys}, Opt{})
+	require.NoError(t, err)
+	require.Equal(t, &Distro{ID: "debian", VersionID: "12", PrettyName: "Debian GNU/Linux 12 (bookworm)"}, res.Distro)
+	require.Equal(t, []string{
+		"pkg:deb/debian/base-files@12.4+deb12u5?arch=amd64&distro=debian-12",
+		"pkg:deb/debian/libc6@2.36-9+deb12u4?arch=amd64&distro=debian-12",
+	}, purls(res))
+}
+
+func TestCatalogApk(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"etc/os-release": &fstest.MapFile{Data: []byte("ID=alpine\nVERSION_ID=3.20.0\n")},
+		"lib/apk/db/installed": &fstest.MapFile{Data: []byte(`C:Q1abc=
+P:musl
+V:1.2.5-r0
+A:x86_64
+L:MIT
+
+P:busybox
+V:1.36.1-r29
+A:x86_64
+L:GPL-2.0-only
+`)},
+	}
+
+	res, err := Catalog(context.TODO(), &testFS{fsys}, Opt{})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"pkg:apk/alpine/busybox@1.36.1-r29?arch=x86_64&distro=alpine-3.20.0",
+		"pkg:apk/alpine/musl@1.2.5-r0?arch=x86_64&distro=alpine-3.20.0",
+	}, purls(res))
+	require.Equal(t, "GPL-2.0-only", res.Packages[0].License)
+}
+
+func TestCatalogRPM(t *testing.T) {
+	t.Parallel()
+
+	// testdata/rpmdb.sqlite contains 62 packages and a gpg-pubkey entry.
+	// glibc is large enough to be stored in overflow pages.
+	dt, err := os.ReadFile("testdata/rpmdb.sqlite")
+	require.NoError(t, err)
+
+	fsys := fstest.MapFS{
+		"etc/os-release":           &fstest.MapFile{Data: []byte("ID=\"rhel\"\nVERSION_ID=\"9.4\"\n")},
+		"var/lib/rpm/rpmdb.sqlite": &fstest.MapFile{Data: dt},
+	}
+
+	res, err := Catalog(context.TODO(), &testFS{fsys}, Opt{})
+	require.NoError(t, err)
+	require.Len(t, res.Packages, 62)
+
+	p := purls(res)
+	require.Equal(t, "pkg:rpm/rhel/bash@5.2.15-2.el9?arch=x86_64&distro=rhel-9.4", p[0])
+	require.Equal(t, "pkg:rpm/rhel/glibc@2.34-60.el9?arch=x86_64&epoch=2&distro=rhel-9.4", p[1])
+	require.Equal(t, "pkg:rpm/rhel/pkg59@1.0-1?arch=noarch&distro=rhel-9.4", p[61])
+	require.Equal(t, "LGPLv2+", res.Packages[1].License)
+}
+
+func TestCatalogGoBinary(t *testing.T) {
+	t.Parallel()
+
+	// the test binary itself carries build information
+	exe, err := os.Executable()
+	require.NoError(t, err)
+	dt, err := os.ReadFile(exe)
+	require.NoError(t, err)
+
+	fsys := fstest.MapFS{
+		"usr/local/bin/app": &fstest.MapFile{Data: dt, Mode: 0755},
+		"usr/local/bin/sh":  &fstest.MapFile{Data: []byte("#!/bin/sh\n"), Mode: 0755},
+		"opt/app/app":       &fstest.MapFile{Data: dt, Mode: 0755},
+		"opt/app/data":      &fstest.MapFile{Data: dt, Mode: 0644},
+	}
+
+	res, err := Catalog(context.TODO(), &testFS{fsys}, Opt{BinaryPaths: []string{"/opt/app"}})
+	require.NoError(t, err)
+
+	var stdlib, buildkit int
+	for _, p := range res.Packages {
+		require.Equal(t, TypeGolang, p.Type)
+		require.Contains(t, []string{"/usr/local/bin/app", "/opt/app/app"}, p.Location)
+		switch {
+		case p.Name == "stdlib":
+			stdlib++
+			require.True(t, strings.HasPrefix(p.Version, "go"), p.Version)
+		case p.Namespace == "github.com/moby" && p.Name == "buildkit":
+			buildkit++
+		}
+	}
+	require.Equal(t, 2, stdlib)
+	require.Equal(t, 2, buildkit)
+}
+
+func TestCatalogLockfiles(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"app/package-lock.json": &fstest.MapFile{Data: []byte(`{
+  "name": "app",
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "app", "version": "1.0.0"},
+    "node_modules/@babel/core": {"version": "7.24.0", "license": "MIT"},
+    "node_modules/@babel/core/node_modules/semver": {"version": "6.3.1"},
+    "node_modules/lib": {"resolved": "packages/lib", "link": true}
+  }
+}`)},
+		"app/node_modules/x/package-lock.json": &fstest.MapFile{Data: []byte(`{"packages": {"node_modules/ignored": {"version": "1.0.0"}}}`)},
+		"srv/old/package-lock.json": &fstest.MapFile{Data: []byte(`{
+  "lockfileVersion": 1,
+  "dependencies": {
+    "debug": {"version": "2.6.9", "dependencies": {"ms": {"version": "2.0.0"}}}
+  }
+}`)},
+		"srv/requirements.txt": &fstest.MapFile{Data: []byte(`# comment
+--index-url https://example.com/simple
+Django==4.2.11
+requests[socks] == 2.31.0 ; python_version >= "3.8"
+flask>=2.0
+`)},
+		"usr/lib/node_modules/npm/package-lock.json": &fstest.MapFile{Data: []byte(`{"packages": {"node_modules/ignored": {"version": "1.0.0"}}}`)},
+	}
+
+	res, err := Catalog(context.TODO(), &testFS{fsys}, Opt{})
+	require.NoError(t, err)
+	require.Nil(t, res.Distro)
+	require.Equal(t, []string{
+		"pkg:npm/debug@2.6.9",
+		"pkg:npm/ms@2.0.0",
+		"pkg:npm/semver@6.3.1",
+		"pkg:npm/%40babel/core@7.24.0",
+		"pkg:pypi/django@4.2.11",
+		"pkg:pypi/requests@2.31.0",
+	}, purls(res))
+	require.Equal(t, "/app/package-lock.json", res.Packages[3].Location)
+	require.Equal(t, "MIT", res.Packages[3].License)
+}
+
+func TestSPDX(t *testing.T) {
+	t.Parallel()
+
+	res := &Result{
+		Distro: &Distro{ID: "alpine", VersionID: "3.20.0"},
+		Packages: []Package{
+			{Type: TypeApk, Namespace: "alpine", Name: "musl", Version: "1.2.5-r0", License: "MIT", Location: apkInstalled},
+		},
+	}
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	dt, err := SPDX(res, "sbom", created)
+	require.NoError(t, err)
+
+	dt2, err := SPDX(res, "sbom", created)
+	require.NoError(t, err)
+	require.Equal(t, dt, dt2)
+
+	var doc struct {
+		SPDXVersion  string `json:"spdxVersion"`
+		CreationInfo struct {
+			Created string `json:"created"`
+		} `json:"creationInfo"`
+		Packages []struct {
+			Name            string `json:"name"`
+			Version         string `json:"versionInfo"`
+			LicenseDeclared string `json:"licenseDeclared"`
+			ExternalRefs    []struct {
+				Category string `json:"referenceCategory"`
+				Type     string `json:"referenceType"`
+				Locator  string `json:"referenceLocator"`
+			} `json:"externalRefs"`
+		} `json:"packages"`
+	}
+	require.NoError(t, json.Unmarshal(dt, &doc))
+	require.Equal(t, "SPDX-2.3", doc.SPDXVersion)
+	require.Equal(t, "2024-01-01T00:00:00Z", doc.CreationInfo.Created)
+	require.Len(t, doc.Packages, 1)
+	require.Equal(t, "musl", doc.Packages[0].Name)
+	require.Equal(t, "1.2.5-r0", doc.Packages[0].Version)
+	require.Equal(t, "MIT", doc.Packages[0].LicenseDeclared)
+	require.Len(t, doc.Packages[0].ExternalRefs, 1)
+	require.Equal(t, "PACKAGE-MANAGER", doc.Packages[0].ExternalRefs[0].Category)
+	require.Equal(t, "purl", doc.Packages[0].ExternalRefs[0].Type)
+	require.Equal(t, "pkg:apk/alpine/musl@1.2.5-r0?distro=alpine-3.20.0", doc.Packages[0].ExternalRefs[0].Locator)
+}
+
+func TestCycloneDX(t *testing.T) {
+	t.Parallel()
+
+	res := &Result{
+		Distro: &Distro{ID: "alpine", VersionID: "3.20.0"},
+		Packages: []Package{
+			{Type: TypeApk, Namespace: "alpine", Name: "musl", Version: "1.2.5-r0", License: "MIT", Location: apkInstalled},
+		},
+	}
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	dt, err := CycloneDX(res, "sbom", created)
+	require.NoError(t, err)
+
+	dt2, err := CycloneDX(res, "sbom", created)
+	require.NoError(t, err)
+	require.Equal(t, dt, dt2)
+
+	var bom cyclonedx.BOM
+	require.NoError(t, json.Unmarshal(dt, &bom))
+	require.Equal(t, "CycloneDX", bom.BOMFormat)
+	require.Equal(t, "1.5", bom.SpecVersion)
+	require.True(t, strings.HasPrefix(bom.SerialNumber, "urn:uuid:"), bom.SerialNumber)
+	require.Equal(t, "2024-01-01T00:00:00Z", bom.Metadata.Timestamp)
+	require.Len(t, bom.Components, 2)
+	require.Equal(t, cyclonedx.ComponentTypeOperatingSystem, bom.Components[0].Type)
+	require.Equal(t, "alpine", bom.Components[0].Name)
+	require.Equal(t, "3.20.0", bom.Components[0].Version)
+	require.Equal(t, cyclonedx.ComponentTypeLibrary, bom.Components[1].Type)
+	require.Equal(t, "pkg:apk/alpine/musl@1.2.5-r0?distro=alpine-3.20.0", bom.Components[1].PURL)
+	require.Equal(t, []cyclonedx.License{{License: &cyclonedx.LicenseID{Name: "MIT"}}}, bom.Components[1].Licenses)
+}
+
+func purls(res *Result) []string {
+	var out []string
+	for _, p := range res.Packages {
+		out = append(out, p.PURL(res.Distro))
+	}
+	return out
+}
+
+// testFS implements FS on top of an fs.FS.
+type testFS struct {
+	fs fs.FS
+}
+
+func (t *testFS) ReadFile(ctx context.Context, req client.ReadRequest) ([]byte, error) {
+	dt, err := fs.ReadFile(t.fs, strings.TrimPrefix(path.Clean("/"+req.Filename), "/"))
+	if err != nil {
+		return nil, err
+	}
+	if r := req.Range; r != nil {
+		if r.Offset >= len(dt) {
+			return nil, nil
+		}
+		dt = dt[r.Offset:]
+		if r.Length < len(dt) {
+			dt = dt[:r.Length]
+		}
+	}
+	return dt, nil
+}
+
+func (t *testFS) ReadDir(ctx context.Context, req client.ReadDirRequest) ([]*fstypes.Stat, error) {
+	p := strings.TrimPrefix(path.Clean("/"+req.Path), "/")
+	if p == "" {
+		p = "."
+	}
+	entries, err := fs.ReadDir(t.fs, p)
+	if err != nil {
+		return nil, err
+	}
+	var out []*fstypes.Stat
+	for _, e := range entries {
+		fi, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, &fstypes.Stat{
+			Path: e.Name(),
+			Mode: uint32(fi.Mode()),
+			Size: fi.Size(),
+		})
+	}
+	return out, nil
+}
@@ -0,0 +1,67 @@
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/moby/buildkit/util/sbomutil/cyclonedx"
+	"github.com/moby/buildkit/version"
+	digest "github.com/opencontainers/go-digest"
+)
+
+// CycloneDX returns res encoded as a CycloneDX JSON document for the
+// component name. Like with SPDX, the serial number is derived from the
+// content.
+func CycloneDX(res *Result, name string, created time.Time) ([]byte, error) {
+	bom := cyclonedx.BOM{
+		BOMFormat:   cyclonedx.BOMFormat,
+		SpecVersion: cyclonedx.SpecVersion,
+		Version:     1,
+		Metadata: &cyclonedx.Metadata{
+			Timestamp: created.UTC().Format(time.RFC3339),
+			Tools: &cyclonedx.Tools{
+				Components: []cyclonedx.Component{
+					{Type: cyclonedx.ComponentTypeApplication, Name: "buildkit", Version: version.Version},
+				},
+			},
+			Component: &cyclonedx.Component{
+				Type: cyclonedx.ComponentTypeContainer,
+				Name: name,
+			},
+		},
+	}
+
+	h := digest.SHA256.Digester()
+	if d := res.Distro; d != nil && d.ID != "" {
+		bom.Components = append(bom.Components, cyclonedx.Component{
+			BOMRef:  "os:" + d.ID,
+			Type:    cyclonedx.ComponentTypeOperatingSystem,
+			Name:    d.ID,
+			Version: d.VersionID,
+		})
+	}
+	for i, p := range res.Packages {
+		purl := p.PURL(res.Distro)
+		fmt.Fprintln(h.Hash(), purl)
+
+		c := cyclonedx.Component{
+			BOMRef:  fmt.Sprintf("Package-%d", i),
+			Type:    cyclonedx.ComponentTypeLibrary,
+			Name:    p.Name,
+			Version: p.Version,
+			PURL:    purl,
+		}
+		if p.License != "" {
+			c.Licenses = []cyclonedx.License{{License: &cyclonedx.LicenseID{Name: p.License}}}
+		}
+		if p.Location != "" {
+			c.Properties = []cyclonedx.Property{{Name: "buildkit:location", Value: p.Location}}
+		}
+		bom.Components = append(bom.Components, c)
+	}
+	bom.SerialNumber = uuid.NewSHA1(uuid.NameSpaceURL, []byte(name+"@"+h.Digest().String())).URN()
+
+	return json.MarshalIndent(bom, "", "  ")
+}
@@ -0,0 +1,102 @@
+package catalog
+
+import (
+	"context"
+	"path"
+	"strings"
+)
+
+const (
+	dpkgStatus    = "/var/lib/dpkg/status"
+	dpkgStatusDir = "/var/lib/dpkg/status.d"
+)
+
+// catalogDpkg reads the dpkg status database of Debian based images. The
+// status.d directory is used by distroless images instead of a single file.
+func catalogDpkg(ctx context.Context, fs *fileSystem, distro *Distro) ([]Package, error) {
+	files := []string{dpkgStatus}
+	entries, err := fs.readDir(ctx, dpkgStatusDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		name := path.Base(e.Path)
+		if strings.HasSuffix(name, ".md5sums") {
+			continue
+		}
+		files = append(files, path.Join(dpkgStatusDir, name))
+	}
+
+	namespace := "debian"
+	if distro != nil && distro.ID != "" {
+		namespace = distro.ID
+	}
+
+	var pkgs []Package
+	for _, f := range files {
+		dt, ok, err := fs.readFile(ctx, f)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		for _, fields := range parseControl(string(dt)) {
+			if fields["Package"] == "" {
+				continue
+			}
+			if status, ok := fields["Status"]; ok && !dpkgInstalled(status) {
+				continue
+			}
+			pkgs = append(pkgs, Package{
+				Type:      TypeDeb,
+				Namespace: namespace,
+				Name:      fields["Package"],
+				Version:   fields["Version"],
+				Arch:      fields["Architecture"],
+				Location:  f,
+			})
+		}
+	}
+	return pkgs, nil
+}
+
+// dpkgInstalled checks the "want flag status" triplet of the Status field.
+func dpkgInstalled(status string) bool {
+	f := strings.Fields(status)
+	return len(f) == 3 && f[2] == "installed"
+}
+
+// parseControl parses paragraphs of Debian control file fields.
+// Continuation lines are appended to the value of the previous field.
+func parseControl(dt string) []map[string]string {
+	var out []map[string]string
+	cur := map[string]string{}
+	var last string
+	for _, l := range strings.Split(dt, "\n") {
+		if strings.TrimSpace(l) == "" {
+			if len(cur) > 0 {
+				out = append(out, cur)
+				cur = map[string]string{}
+			}
+			last = ""
+			continue
+		}
+		if l[0] == ' ' || l[0] == '\t' {
+			if last != "" {
+				cur[last] += "\n" + strings.TrimSpace(l)
+			}
+			continue
+		}
+		k, v, ok := strings.Cut(l, ":")
+		if !ok {
+			continue
+		}
+		last = k
+		cur[k] = strings.TrimSpace(v)
+	}
+	if len(cur) > 0 {
+		out = append(out, cur)
+	}
+	return out
+}
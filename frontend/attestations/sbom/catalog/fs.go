@@ -0,0 +1,149 @@
+package catalog
+
+import (
+	"context"
+	"io"
+	"os"
+	"path"
+	"sync"
+
+	"github.com/moby/buildkit/frontend/gateway/client"
+	"github.com/pkg/errors"
+	fstypes "github.com/tonistiigi/fsutil/types"
+)
+
+// fileSystem wraps FS with cached directory listings so that missing files
+// can be detected without relying on the error types of the transport.
+type fileSystem struct {
+	fs FS
+
+	mu   sync.Mutex
+	dirs map[string][]*fstypes.Stat
+}
+
+func newFileSystem(fs FS) *fileSystem {
+	return &fileSystem{fs: fs, dirs: map[string][]*fstypes.Stat{}}
+}
+
+// readDir lists directory p. Returns nil if p is not a directory.
+func (fs *fileSystem) readDir(ctx context.Context, p string) ([]*fstypes.Stat, error) {
+	p = path.Clean("/" + p)
+
+	fs.mu.Lock()
+	entries, ok := fs.dirs[p]
+	fs.mu.Unlock()
+	if ok {
+		return entries, nil
+	}
+
+	var symlink bool
+	if p != "/" {
+		st, err := fs.stat(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		if st == nil {
+			return nil, nil
+		}
+		mode := os.FileMode(st.Mode)
+		symlink = mode&os.ModeSymlink != 0
+		if !mode.IsDir() && !symlink {
+			return nil, nil
+		}
+	}
+
+	entries, err := fs.fs.ReadDir(ctx, client.ReadDirRequest{Path: p})
+	if err != nil {
+		if !symlink {
+			return nil, errors.Wrapf(err, "failed to read directory %s", p)
+		}
+		// dangling link or link to a file
+		entries = nil
+	}
+	fs.mu.Lock()
+	fs.dirs[p] = entries
+	fs.mu.Unlock()
+	return entries, nil
+}
+
+// stat returns the stat of p, or nil if p doesn't exist.
+func (fs *fileSystem) stat(ctx context.Context, p string) (*fstypes.Stat, error) {
+	p = path.Clean("/" + p)
+	dir, base := path.Split(p)
+	entries, err := fs.readDir(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if path.Base(e.Path) == base {
+			return e, nil
+		}
+	}
+	return nil, nil
+}
+
+// maxFileSize limits the size of the files read for cataloging, e.g. package
+// databases, so that a large file in the image can't exhaust the memory of
+// the frontend.
+const maxFileSize = 256 << 20
+
+// readFile reads the regular file p. Returns false if p doesn't exist.
+func (fs *fileSystem) readFile(ctx context.Context, p string) ([]byte, bool, error) {
+	st, err := fs.stat(ctx, p)
+	if err != nil || st == nil {
+		return nil, false, err
+	}
+	mode := os.FileMode(st.Mode)
+	if !mode.IsRegular() && mode&os.ModeSymlink == 0 {
+		return nil, false, nil
+	}
+	if mode.IsRegular() && st.Size > maxFileSize {
+		return nil, false, errors.Errorf("%s is too large: %d bytes", p, st.Size)
+	}
+	// the size of symlink targets is only known after reading
+	dt, err := fs.fs.ReadFile(ctx, client.ReadRequest{
+		Filename: p,
+		Range:    &client.FileRange{Length: maxFileSize + 1},
+	})
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "failed to read %s", p)
+	}
+	if len(dt) > maxFileSize {
+		return nil, false, errors.Errorf("%s is too large", p)
+	}
+	return dt, true, nil
+}
+
+// readerAt reads a file in ranges so that only the parts needed for parsing
+// are transferred.
+type readerAt struct {
+	ctx  context.Context
+	fs   FS
+	name string
+	size int64
+}
+
+func (r *readerAt) ReadAt(p []byte, off int64) (int, error) {
+	if off >= r.size {
+		return 0, io.EOF
+	}
+	n := int64(len(p))
+	if off+n > r.size {
+		n = r.size - off
+	}
+	dt, err := r.fs.ReadFile(r.ctx, client.ReadRequest{
+		Filename: r.name,
+		Range: &client.FileRange{
+			Offset: int(off),
+			Length: int(n),
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+	c := copy(p, dt)
+	if c < len(p) {
+		return c, io.EOF
+	}
+	return c, nil
+}
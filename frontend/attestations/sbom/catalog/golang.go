@@ -0,0 +1,91 @@
+package catalog
+
+import (
+	"context"
+	"debug/buildinfo"
+	"os"
+	"path"
+	"runtime/debug"
+)
+
+// goBinaryPaths are the directories searched for Go binaries by default.
+var goBinaryPaths = []string{
+	"/bin",
+	"/sbin",
+	"/usr/bin",
+	"/usr/sbin",
+	"/usr/local/bin",
+	"/usr/local/sbin",
+	"/go/bin",
+}
+
+const maxGoBinarySize = 512 << 20
+
+// catalogGoBinaries reads the module information embedded in Go binaries
+// found directly under the binary directories.
+func catalogGoBinaries(ctx context.Context, fs *fileSystem, extra []string) ([]Package, error) {
+	var pkgs []Package
+	seen := map[string]struct{}{}
+	for _, dir := range append(append([]string{}, goBinaryPaths...), extra...) {
+		dir = path.Clean("/" + dir)
+		if _, ok := seen[dir]; ok {
+			continue
+		}
+		seen[dir] = struct{}{}
+
+		entries, err := fs.readDir(ctx, dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			mode := os.FileMode(e.Mode)
+			if !mode.IsRegular() || mode&0o111 == 0 || e.Size < 4 || e.Size > maxGoBinarySize {
+				continue
+			}
+			p := path.Join(dir, path.Base(e.Path))
+			info, err := buildinfo.Read(&readerAt{ctx: ctx, fs: fs.fs, name: p, size: e.Size})
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				// not a Go binary
+				continue
+			}
+			pkgs = append(pkgs, goModules(info, p)...)
+		}
+	}
+	return pkgs, nil
+}
+
+func goModules(info *debug.BuildInfo, location string) []Package {
+	var pkgs []Package
+	add := func(m *debug.Module) {
+		if m == nil || m.Path == "" {
+			return
+		}
+		if m.Replace != nil {
+			m = m.Replace
+		}
+		ns, name := splitGoPath(m.Path)
+		pkgs = append(pkgs, Package{
+			Type:      TypeGolang,
+			Namespace: ns,
+			Name:      name,
+			Version:   m.Version,
+			Location:  location,
+		})
+	}
+	add(&info.Main)
+	for _, d := range info.Deps {
+		add(d)
+	}
+	if info.GoVersion != "" {
+		pkgs = append(pkgs, Package{
+			Type:     TypeGolang,
+			Name:     "stdlib",
+			Version:  info.GoVersion,
+			Location: location,
+		})
+	}
+	return pkgs
+}
@@ -0,0 +1,175 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path"
+	"strings"
+)
+
+const maxLockfileDepth = 6
+
+// skipDirs are not searched for lockfiles. They contain system files that are
+// covered by the OS package catalogers.
+var skipDirs = map[string]struct{}{
+	"/proc":  {},
+	"/sys":   {},
+	"/dev":   {},
+	"/run":   {},
+	"/tmp":   {},
+	"/boot":  {},
+	"/etc":   {},
+	"/bin":   {},
+	"/sbin":  {},
+	"/lib":   {},
+	"/lib32": {},
+	"/lib64": {},
+	"/usr":   {},
+	"/var":   {},
+}
+
+// catalogLockfiles searches the filesystem for application lockfiles.
+func catalogLockfiles(ctx context.Context, fs *fileSystem, _ *Distro) ([]Package, error) {
+	var pkgs []Package
+	var walk func(dir string, depth int) error
+	walk = func(dir string, depth int) error {
+		entries, err := fs.readDir(ctx, dir)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			name := path.Base(e.Path)
+			p := path.Join(dir, name)
+			mode := os.FileMode(e.Mode)
+			if mode.IsDir() {
+				if _, ok := skipDirs[p]; ok || name == "node_modules" || name == ".git" || depth >= maxLockfileDepth {
+					continue
+				}
+				if err := walk(p, depth+1); err != nil {
+					return err
+				}
+				continue
+			}
+			if !mode.IsRegular() {
+				continue
+			}
+			var parse func([]byte) []Package
+			switch name {
+			case "package-lock.json":
+				parse = parsePackageLock
+			case "requirements.txt":
+				parse = parseRequirements
+			default:
+				continue
+			}
+			dt, ok, err := fs.readFile(ctx, p)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			for _, pkg := range parse(dt) {
+				pkg.Location = p
+				pkgs = append(pkgs, pkg)
+			}
+		}
+		return nil
+	}
+	if err := walk("/", 0); err != nil {
+		return nil, err
+	}
+	return pkgs, nil
+}
+
+type packageLock struct {
+	// lockfileVersion 2 and 3
+	Packages map[string]struct {
+		Version string `json:"version"`
+		License string `json:"license"`
+		Link    bool   `json:"link"`
+	} `json:"packages"`
+	// lockfileVersion 1
+	Dependencies map[string]packageLockDep `json:"dependencies"`
+}
+
+type packageLockDep struct {
+	Version      string                    `json:"version"`
+	Dependencies map[string]packageLockDep `json:"dependencies"`
+}
+
+// parsePackageLock returns the npm packages of a package-lock.json file.
+// Invalid files are ignored.
+func parsePackageLock(dt []byte) []Package {
+	var lock packageLock
+	if err := json.Unmarshal(dt, &lock); err != nil {
+		return nil
+	}
+	var pkgs []Package
+	if len(lock.Packages) > 0 {
+		for k, v := range lock.Packages {
+			i := strings.LastIndex(k, "node_modules/")
+			if i < 0 || v.Link || v.Version == "" {
+				// root project or workspace
+				continue
+			}
+			pkgs = append(pkgs, npmPackage(k[i+len("node_modules/"):], v.Version, v.License))
+		}
+		return pkgs
+	}
+	var walk func(map[string]packageLockDep)
+	walk = func(deps map[string]packageLockDep) {
+		for name, d := range deps {
+			if d.Version != "" {
+				pkgs = append(pkgs, npmPackage(name, d.Version, ""))
+			}
+			walk(d.Dependencies)
+		}
+	}
+	walk(lock.Dependencies)
+	return pkgs
+}
+
+func npmPackage(name, version, license string) Package {
+	pkg := Package{
+		Type:    TypeNPM,
+		Name:    name,
+		Version: version,
+		License: license,
+	}
+	if scope, n, ok := strings.Cut(name, "/"); ok && strings.HasPrefix(scope, "@") {
+		pkg.Namespace = scope
+		pkg.Name = n
+	}
+	return pkg
+}
+
+// parseRequirements returns the Python packages pinned with == in a
+// requirements.txt file.
+func parseRequirements(dt []byte) []Package {
+	var pkgs []Package
+	for _, l := range strings.Split(string(dt), "\n") {
+		l, _, _ = strings.Cut(l, "#")
+		l, _, _ = strings.Cut(l, ";")
+		name, version, ok := strings.Cut(strings.TrimSpace(l), "==")
+		if !ok {
+			continue
+		}
+		name = strings.TrimSpace(name)
+		if i := strings.IndexByte(name, '['); i >= 0 {
+			name = name[:i]
+		}
+		fields := strings.Fields(version)
+		if name == "" || len(fields) == 0 || strings.HasPrefix(name, "-") {
+			continue
+		}
+		version = fields[0]
+		pkgs = append(pkgs, Package{
+			Type:    TypePyPI,
+			Name:    strings.ToLower(name),
+			Version: version,
+		})
+	}
+	return pkgs
+}
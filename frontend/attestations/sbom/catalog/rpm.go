@@ -0,0 +1,176 @@
+package catalog
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// rpmDBPaths are the locations of the sqlite rpm database. Older Berkeley DB
+// and ndb databases are not supported.
+var rpmDBPaths = []string{
+	"/var/lib/rpm/rpmdb.sqlite",
+	"/usr/lib/sysimage/rpm/rpmdb.sqlite",
+}
+
+const (
+	rpmTagName    = 1000
+	rpmTagVersion = 1001
+	rpmTagRelease = 1002
+	rpmTagEpoch   = 1003
+	rpmTagLicense = 1014
+	rpmTagArch    = 1022
+
+	rpmTypeInt32       = 4
+	rpmTypeString      = 6
+	rpmTypeStringArray = 8
+	rpmTypeI18NString  = 9
+)
+
+// catalogRPM reads the headers stored in the Packages table of the rpm
+// database.
+func catalogRPM(ctx context.Context, fs *fileSystem, distro *Distro) ([]Package, error) {
+	for _, p := range rpmDBPaths {
+		dt, ok, err := fs.readFile(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		pkgs, err := parseRPMDB(dt, distro)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse %s", p)
+		}
+		for i := range pkgs {
+			pkgs[i].Location = p
+		}
+		return pkgs, nil
+	}
+	return nil, nil
+}
+
+func parseRPMDB(dt []byte, distro *Distro) ([]Package, error) {
+	db, err := openSQLite(dt)
+	if err != nil {
+		return nil, err
+	}
+	root, err := db.tableRoot("Packages")
+	if err != nil {
+		return nil, err
+	}
+
+	var namespace string
+	if distro != nil {
+		namespace = distro.ID
+	}
+
+	var pkgs []Package
+	err = db.scanTable(root, func(_ int64, values []any) error {
+		// CREATE TABLE Packages (hnum INTEGER PRIMARY KEY AUTOINCREMENT, blob BLOB NOT NULL)
+		if len(values) < 2 {
+			return nil
+		}
+		blob, ok := values[1].([]byte)
+		if !ok {
+			return nil
+		}
+		h, err := parseRPMHeader(blob)
+		if err != nil {
+			return err
+		}
+		name := h.string(rpmTagName)
+		if name == "" || name == "gpg-pubkey" {
+			return nil
+		}
+		pkg := Package{
+			Type:      TypeRPM,
+			Namespace: namespace,
+			Name:      name,
+			Version:   h.string(rpmTagVersion),
+			Arch:      h.string(rpmTagArch),
+			License:   h.string(rpmTagLicense),
+		}
+		if rel := h.string(rpmTagRelease); rel != "" {
+			pkg.Version += "-" + rel
+		}
+		if epoch, ok := h.int32(rpmTagEpoch); ok {
+			pkg.Epoch = strconv.FormatInt(int64(epoch), 10)
+		}
+		pkgs = append(pkgs, pkg)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pkgs, nil
+}
+
+type rpmEntry struct {
+	typ    uint32
+	offset int
+	count  uint32
+}
+
+type rpmHeader struct {
+	entries map[int32]rpmEntry
+	data    []byte
+}
+
+// parseRPMHeader parses a header blob as stored in the rpm database: the
+// index length and data length followed by the index entries and the data
+// store.
+func parseRPMHeader(b []byte) (*rpmHeader, error) {
+	if len(b) < 8 {
+		return nil, errors.New("short rpm header")
+	}
+	il := int(binary.BigEndian.Uint32(b[0:4]))
+	dl := int(binary.BigEndian.Uint32(b[4:8]))
+	if il < 0 || dl < 0 || il > len(b)/16 || 8+16*il+dl > len(b) {
+		return nil, errors.New("invalid rpm header size")
+	}
+	h := &rpmHeader{
+		entries: make(map[int32]rpmEntry, il),
+		data:    b[8+16*il : 8+16*il+dl],
+	}
+	for i := range il {
+		e := b[8+16*i:]
+		tag := int32(binary.BigEndian.Uint32(e[0:4]))
+		h.entries[tag] = rpmEntry{
+			typ:    binary.BigEndian.Uint32(e[4:8]),
+			offset: int(int32(binary.BigEndian.Uint32(e[8:12]))),
+			count:  binary.BigEndian.Uint32(e[12:16]),
+		}
+	}
+	return h, nil
+}
+
+// string returns the value of a string tag, or the first value of a string
+// array tag.
+func (h *rpmHeader) string(tag int32) string {
+	e, ok := h.entries[tag]
+	if !ok || e.offset < 0 || e.offset >= len(h.data) {
+		return ""
+	}
+	switch e.typ {
+	case rpmTypeString, rpmTypeStringArray, rpmTypeI18NString:
+	default:
+		return ""
+	}
+	dt := h.data[e.offset:]
+	if i := bytes.IndexByte(dt, 0); i >= 0 {
+		dt = dt[:i]
+	}
+	return string(dt)
+}
+
+func (h *rpmHeader) int32(tag int32) (int32, bool) {
+	e, ok := h.entries[tag]
+	if !ok || e.typ != rpmTypeInt32 || e.count == 0 || e.offset < 0 || e.offset+4 > len(h.data) {
+		return 0, false
+	}
+	return int32(binary.BigEndian.Uint32(h.data[e.offset:])), true
+}
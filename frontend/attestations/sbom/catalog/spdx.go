@@ -0,0 +1,75 @@
+package catalog
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/moby/buildkit/version"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	spdx_json "github.com/spdx/tools-golang/json"
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+const spdxNoAssertion = "NOASSERTION"
+
+// SPDX returns res encoded as an SPDX 2.3 JSON document named name. The
+// document namespace is derived from the content so that the same packages
+// always produce the same document for the same creation time.
+func SPDX(res *Result, name string, created time.Time) ([]byte, error) {
+	doc := &spdx.Document{
+		SPDXVersion:    spdx.Version,
+		DataLicense:    spdx.DataLicense,
+		SPDXIdentifier: "DOCUMENT",
+		DocumentName:   name,
+		CreationInfo: &spdx.CreationInfo{
+			Creators: []common.Creator{
+				{CreatorType: "Tool", Creator: "buildkit-" + version.Version},
+			},
+			Created: created.UTC().Format(time.RFC3339),
+		},
+	}
+
+	h := digest.SHA256.Digester()
+	for i, p := range res.Packages {
+		purl := p.PURL(res.Distro)
+		fmt.Fprintln(h.Hash(), purl)
+
+		license := p.License
+		if license == "" {
+			license = spdxNoAssertion
+		}
+		pkg := &spdx.Package{
+			PackageName:             p.Name,
+			PackageSPDXIdentifier:   common.ElementID(fmt.Sprintf("Package-%d", i)),
+			PackageVersion:          p.Version,
+			PackageDownloadLocation: spdxNoAssertion,
+			PackageLicenseConcluded: spdxNoAssertion,
+			PackageLicenseDeclared:  license,
+			PackageCopyrightText:    spdxNoAssertion,
+			PackageSourceInfo:       "acquired package info from " + p.Location,
+			PackageExternalReferences: []*spdx.PackageExternalReference{
+				{
+					Category: common.CategoryPackageManager,
+					RefType:  common.TypePackageManagerPURL,
+					Locator:  purl,
+				},
+			},
+		}
+		doc.Packages = append(doc.Packages, pkg)
+		doc.Relationships = append(doc.Relationships, &spdx.Relationship{
+			RefA:         common.MakeDocElementID("", "DOCUMENT"),
+			RefB:         common.MakeDocElementID("", string(pkg.PackageSPDXIdentifier)),
+			Relationship: common.TypeRelationshipDescribe,
+		})
+	}
+	doc.DocumentNamespace = fmt.Sprintf("https://mobyproject.org/buildkit/sbom/%s-%s", name, h.Digest().Encoded())
+
+	var buf bytes.Buffer
+	if err := spdx_json.Write(doc, &buf); err != nil {
+		return nil, errors.Wrap(err, "unable to encode spdx")
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,289 @@
+package catalog
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// sqliteDB is a minimal read-only reader for SQLite database files, enough
+// to iterate the rows of a table. It doesn't support WAL files.
+//
+// https://www.sqlite.org/fileformat.html
+type sqliteDB struct {
+	dt       []byte
+	pageSize int
+	usable   int
+}
+
+const sqliteMagic = "SQLite format 3\x00"
+
+func openSQLite(dt []byte) (*sqliteDB, error) {
+	if len(dt) < 100 || string(dt[:16]) != sqliteMagic {
+		return nil, errors.New("not a sqlite database")
+	}
+	pageSize := int(binary.BigEndian.Uint16(dt[16:18]))
+	if pageSize == 1 {
+		pageSize = 65536
+	}
+	if pageSize < 512 || pageSize&(pageSize-1) != 0 {
+		return nil, errors.Errorf("invalid sqlite page size %d", pageSize)
+	}
+	// the usable size of a page must be at least 480 bytes
+	usable := pageSize - int(dt[20])
+	if usable < 480 {
+		return nil, errors.Errorf("invalid sqlite reserved space %d", dt[20])
+	}
+	return &sqliteDB{
+		dt:       dt,
+		pageSize: pageSize,
+		usable:   usable,
+	}, nil
+}
+
+func (db *sqliteDB) page(n uint32) ([]byte, error) {
+	off := int64(n-1) * int64(db.pageSize)
+	if n == 0 || off+int64(db.pageSize) > int64(len(db.dt)) {
+		return nil, errors.Errorf("invalid sqlite page %d", n)
+	}
+	return db.dt[off : off+int64(db.pageSize)], nil
+}
+
+// tableRoot returns the root page of table name from the schema table.
+func (db *sqliteDB) tableRoot(name string) (uint32, error) {
+	var root uint32
+	err := db.scanTable(1, func(_ int64, values []any) error {
+		if len(values) < 4 {
+			return nil
+		}
+		typ, _ := values[0].(string)
+		n, _ := values[1].(string)
+		if typ == "table" && n == name {
+			if r, ok := values[3].(int64); ok {
+				root = uint32(r)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if root == 0 {
+		return 0, errors.Errorf("table %s not found", name)
+	}
+	return root, nil
+}
+
+// scanTable calls fn for every row of the table b-tree starting at root.
+func (db *sqliteDB) scanTable(root uint32, fn func(rowid int64, values []any) error) error {
+	return db.scanPage(root, fn, map[uint32]struct{}{})
+}
+
+func (db *sqliteDB) scanPage(n uint32, fn func(int64, []any) error, visited map[uint32]struct{}) error {
+	if _, ok := visited[n]; ok {
+		return errors.Errorf("cycle in sqlite b-tree at page %d", n)
+	}
+	visited[n] = struct{}{}
+
+	p, err := db.page(n)
+	if err != nil {
+		return err
+	}
+	hdr := 0
+	if n == 1 {
+		hdr = 100
+	}
+	if len(p) < hdr+12 {
+		return errors.Errorf("short sqlite page %d", n)
+	}
+	typ := p[hdr]
+	ncells := int(binary.BigEndian.Uint16(p[hdr+3 : hdr+5]))
+
+	switch typ {
+	case 0x05: // interior table page
+		ptrs := hdr + 12
+		if len(p) < ptrs+2*ncells {
+			return errors.Errorf("short sqlite page %d", n)
+		}
+		for i := range ncells {
+			off := int(binary.BigEndian.Uint16(p[ptrs+2*i:]))
+			if off+4 > len(p) {
+				return errors.Errorf("invalid cell offset in sqlite page %d", n)
+			}
+			if err := db.scanPage(binary.BigEndian.Uint32(p[off:]), fn, visited); err != nil {
+				return err
+			}
+		}
+		return db.scanPage(binary.BigEndian.Uint32(p[hdr+8:]), fn, visited)
+	case 0x0d: // leaf table page
+		ptrs := hdr + 8
+		if len(p) < ptrs+2*ncells {
+			return errors.Errorf("short sqlite page %d", n)
+		}
+		for i := range ncells {
+			off := int(binary.BigEndian.Uint16(p[ptrs+2*i:]))
+			rowid, payload, err := db.leafCell(p, off)
+			if err != nil {
+				return errors.Wrapf(err, "sqlite page %d", n)
+			}
+			values, err := parseRecord(payload)
+			if err != nil {
+				return errors.Wrapf(err, "sqlite page %d", n)
+			}
+			if err := fn(rowid, values); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return errors.Errorf("unexpected sqlite page type %#x for table page %d", typ, n)
+	}
+}
+
+// leafCell returns the rowid and the full payload of the cell at off,
+// following overflow pages if needed.
+func (db *sqliteDB) leafCell(p []byte, off int) (int64, []byte, error) {
+	if off < 0 || off >= len(p) {
+		return 0, nil, errors.New("invalid cell offset")
+	}
+	size, n := readVarint(p[off:])
+	off += n
+	if off >= len(p) {
+		return 0, nil, errors.New("invalid cell")
+	}
+	rowid, n := readVarint(p[off:])
+	off += n
+
+	// the size is read from the file, a payload can't be larger than the
+	// database itself
+	if size < 0 || size > int64(len(db.dt)) {
+		return 0, nil, errors.Errorf("invalid payload size %d", size)
+	}
+	total := int(size)
+	local := db.localPayload(total)
+	if off+local > len(p) {
+		return 0, nil, errors.New("invalid payload")
+	}
+	payload := make([]byte, 0, total)
+	payload = append(payload, p[off:off+local]...)
+	if local == total {
+		return int64(rowid), payload, nil
+	}
+
+	if off+local+4 > len(p) {
+		return 0, nil, errors.New("invalid overflow pointer")
+	}
+	next := binary.BigEndian.Uint32(p[off+local:])
+	visited := map[uint32]struct{}{}
+	for len(payload) < total {
+		if _, ok := visited[next]; ok {
+			return 0, nil, errors.New("cycle in overflow pages")
+		}
+		visited[next] = struct{}{}
+		op, err := db.page(next)
+		if err != nil {
+			return 0, nil, err
+		}
+		next = binary.BigEndian.Uint32(op)
+		chunk := op[4:db.usable]
+		if remaining := total - len(payload); len(chunk) > remaining {
+			chunk = chunk[:remaining]
+		}
+		payload = append(payload, chunk...)
+	}
+	return int64(rowid), payload, nil
+}
+
+// localPayload returns how many bytes of a table leaf payload of size total
+// are stored on the b-tree page itself.
+func (db *sqliteDB) localPayload(total int) int {
+	u := db.usable
+	x := u - 35
+	if total <= x {
+		return total
+	}
+	m := ((u-12)*32)/255 - 23
+	k := m + (total-m)%(u-4)
+	if k <= x {
+		return k
+	}
+	return m
+}
+
+func readVarint(b []byte) (int64, int) {
+	var v uint64
+	for i := 0; i < 9 && i < len(b); i++ {
+		if i == 8 {
+			v = v<<8 | uint64(b[i])
+			return int64(v), 9
+		}
+		v = v<<7 | uint64(b[i]&0x7f)
+		if b[i]&0x80 == 0 {
+			return int64(v), i + 1
+		}
+	}
+	return int64(v), len(b)
+}
+
+// parseRecord decodes a record into nil, int64, float64, string or []byte
+// values.
+func parseRecord(b []byte) ([]any, error) {
+	hdrSize, n := readVarint(b)
+	if hdrSize < int64(n) || hdrSize > int64(len(b)) {
+		return nil, errors.New("invalid record header")
+	}
+	var types []int64
+	for off := n; off < int(hdrSize); {
+		t, n := readVarint(b[off:hdrSize])
+		types = append(types, t)
+		off += n
+	}
+
+	values := make([]any, 0, len(types))
+	off := int(hdrSize)
+	for _, t := range types {
+		var size int
+		switch {
+		case t >= 1 && t <= 4:
+			size = int(t)
+		case t == 5:
+			size = 6
+		case t == 6 || t == 7:
+			size = 8
+		case t >= 12:
+			size = int((t - 12) / 2)
+		}
+		if off+size > len(b) {
+			return nil, errors.New("invalid record value")
+		}
+		v := b[off : off+size]
+		off += size
+
+		switch {
+		case t == 0:
+			values = append(values, nil)
+		case t >= 1 && t <= 6:
+			var i int64
+			for _, c := range v {
+				i = i<<8 | int64(c)
+			}
+			// sign extend
+			shift := 64 - 8*uint(len(v))
+			values = append(values, i<<shift>>shift)
+		case t == 7:
+			values = append(values, math.Float64frombits(binary.BigEndian.Uint64(v)))
+		case t == 8:
+			values = append(values, int64(0))
+		case t == 9:
+			values = append(values, int64(1))
+		case t >= 12 && t%2 == 0:
+			values = append(values, v)
+		case t >= 13:
+			values = append(values, string(v))
+		default:
+			return nil, errors.Errorf("invalid serial type %d", t)
+		}
+	}
+	return values, nil
+}
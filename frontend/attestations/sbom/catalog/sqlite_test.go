@@ -0,0 +1,129 @@
+package catalog
+
+import (
+	"encoding/binary"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLiteMalformed(t *testing.T) {
+	t.Parallel()
+
+	huge := []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}
+
+	for _, tc := range []struct {
+		name string
+		dt   []byte
+		err  string
+	}{
+		{"empty", nil, "not a sqlite database"},
+		{"reserved space", withByte(newSQLite(t, 0x0d, nil), 20, 255), "invalid sqlite reserved space"},
+		{"page size", withByte(newSQLite(t, 0x0d, nil), 17, 3), "invalid sqlite page size"},
+		{"page type", newSQLite(t, 0x02, nil), "unexpected sqlite page type"},
+		// a large payload size must not be allocated
+		{"payload size", newSQLite(t, 0x0d, [][]byte{append(huge, 1)}), "invalid payload size"},
+		{"negative payload size", newSQLite(t, 0x0d, [][]byte{{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 1}}), "invalid payload size"},
+		{"cell offset", withCellPointer(newSQLite(t, 0x0d, [][]byte{{2, 1, 1, 0}}), 0xffff), "invalid cell offset"},
+		{"truncated cell", withCellPointer(newSQLite(t, 0x0d, [][]byte{{2, 1, 1, 0}}), 511), "invalid cell"},
+		// the payload is larger than the local part and continues on a
+		// page that doesn't exist
+		{"overflow page", newSQLite(t, 0x0d, [][]byte{append(append([]byte{0x83, 0x5e, 1}, make([]byte, 39)...), 0, 0, 0, 2)}), "invalid sqlite page 2"},
+		{"record header", newSQLite(t, 0x0d, [][]byte{{2, 1, 5, 0}}), "invalid record header"},
+		{"record value", newSQLite(t, 0x0d, [][]byte{{2, 1, 2, 6}}), "invalid record value"},
+		{"b-tree cycle", newSQLite(t, 0x05, [][]byte{{0, 0, 0, 1, 1}}), "cycle in sqlite b-tree"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := scanSQLite(tc.dt)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+
+	dt, err := os.ReadFile("testdata/rpmdb.sqlite")
+	require.NoError(t, err)
+	for _, n := range []int{100, 4096, len(dt) / 2, len(dt) - 1} {
+		_, err := parseRPMDB(dt[:n], nil)
+		require.Error(t, err)
+	}
+}
+
+func FuzzSQLite(f *testing.F) {
+	dt, err := os.ReadFile("testdata/rpmdb.sqlite")
+	require.NoError(f, err)
+	f.Add(dt)
+	f.Add(newSQLite(f, 0x0d, [][]byte{{3, 1, 2, 1, 1}}))
+	f.Add(newSQLite(f, 0x05, [][]byte{{0, 0, 0, 1, 1}}))
+	f.Add(newSQLite(f, 0x0d, [][]byte{append(append([]byte{0x83, 0x5e, 1}, make([]byte, 39)...), 0, 0, 0, 2)}))
+	f.Fuzz(func(t *testing.T, dt []byte) {
+		if err := scanSQLite(dt); err == nil {
+			db, err := openSQLite(dt)
+			require.NoError(t, err)
+			db.tableRoot("Packages")
+		}
+		parseRPMDB(dt, nil)
+	})
+}
+
+func FuzzSQLiteRecord(f *testing.F) {
+	f.Add([]byte{3, 1, 2, 1, 1})
+	f.Add([]byte{4, 0, 7, 13, 0, 0, 0, 0, 0, 0, 0, 0, 'a'})
+	f.Add([]byte{2, 0x81})
+	f.Fuzz(func(t *testing.T, b []byte) {
+		values, err := parseRecord(b)
+		if err != nil {
+			return
+		}
+		for _, v := range values {
+			switch v := v.(type) {
+			case nil, int64, float64, string:
+			case []byte:
+				require.LessOrEqual(t, len(v), len(b))
+			default:
+				t.Fatalf("unexpected value type %T", v)
+			}
+		}
+	})
+}
+
+func scanSQLite(dt []byte) error {
+	db, err := openSQLite(dt)
+	if err != nil {
+		return err
+	}
+	return db.scanTable(1, func(int64, []any) error {
+		return nil
+	})
+}
+
+// newSQLite returns a database with a single 512 byte page of type typ with
+// cells. The cells are stored at the end of the page.
+func newSQLite(t testing.TB, typ byte, cells [][]byte) []byte {
+	dt := make([]byte, 512)
+	copy(dt, sqliteMagic)
+	binary.BigEndian.PutUint16(dt[16:], 512)
+	dt[100] = typ
+	binary.BigEndian.PutUint16(dt[103:], uint16(len(cells)))
+	ptrs := 108
+	if typ == 0x05 {
+		ptrs = 112
+	}
+	off := len(dt)
+	for i, c := range cells {
+		off -= len(c)
+		require.GreaterOrEqual(t, off, ptrs+2*len(cells))
+		copy(dt[off:], c)
+		binary.BigEndian.PutUint16(dt[ptrs+2*i:], uint16(off))
+	}
+	return dt
+}
+
+func withByte(dt []byte, off int, v byte) []byte {
+	dt[off] = v
+	return dt
+}
+
+func withCellPointer(dt []byte, off uint16) []byte {
+	binary.BigEndian.PutUint16(dt[108:], off)
+	return dt
+}
@@ -0,0 +1,36 @@
+package sbom
+
+import (
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+	"github.com/pkg/errors"
+)
+
+// SBOM formats selectable with the format parameter.
+const (
+	FormatSPDX      = "spdx"
+	FormatCycloneDX = "cyclonedx"
+)
+
+// FormatParam is the generator parameter selecting the SBOM format.
+const FormatParam = "format"
+
+// PredicateType returns the in-toto predicate type for an SBOM format. An
+// empty format defaults to SPDX.
+func PredicateType(format string) (string, error) {
+	switch format {
+	case "", FormatSPDX:
+		return intoto.PredicateSPDX, nil
+	case FormatCycloneDX:
+		return intoto.PredicateCycloneDX, nil
+	default:
+		return "", errors.Errorf("unsupported sbom format %q", format)
+	}
+}
+
+// fileExtension returns the extension of scan results for predicateType.
+func fileExtension(predicateType string) string {
+	if predicateType == intoto.PredicateCycloneDX {
+		return ".cdx.json"
+	}
+	return ".spdx.json"
+}
@@ -0,0 +1,23 @@
+package sbom
+
+import (
+	"testing"
+
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPredicateType(t *testing.T) {
+	t.Parallel()
+
+	pt, err := PredicateType("")
+	require.NoError(t, err)
+	require.Equal(t, intoto.PredicateSPDX, pt)
+
+	pt, err = PredicateType(FormatCycloneDX)
+	require.NoError(t, err)
+	require.Equal(t, intoto.PredicateCycloneDX, pt)
+
+	_, err = PredicateType("swid")
+	require.ErrorContains(t, err, "unsupported sbom format")
+}
@@ -7,11 +7,11 @@ import (
 	"path"
 	"strings"
 
-	intoto "github.com/in-toto/in-toto-golang/in_toto"
 	"github.com/moby/buildkit/client/llb"
 	"github.com/moby/buildkit/client/llb/sourceresolver"
 	gatewaypb "github.com/moby/buildkit/frontend/gateway/pb"
 	"github.com/moby/buildkit/solver/result"
+	"github.com/moby/buildkit/util/sbomutil"
 	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/pkg/errors"
 )
@@ -39,6 +39,12 @@ func CreateSBOMScanner(ctx context.Context, resolver sourceresolver.MetaResolver
 		return nil, nil
 	}
 
+	format := params[FormatParam]
+	predicateType, err := PredicateType(format)
+	if err != nil {
+		return nil, err
+	}
+
 	imr := sourceresolver.NewImageMetaResolver(resolver)
 	scanner, _, dt, err := imr.ResolveImageConfig(ctx, scanner, resolveOpt)
 	if err != nil {
@@ -66,7 +72,14 @@ func CreateSBOMScanner(ctx context.Context, resolver sourceresolver.MetaResolver
 			env = append(env, "BUILDKIT_SCAN_SOURCE_EXTRAS="+path.Join(srcDir, "extras/"))
 		}
 
+		if format != "" {
+			env = append(env, "BUILDKIT_SCAN_FORMAT="+format)
+		}
+
 		for k, v := range params {
+			if k == FormatParam {
+				continue
+			}
 			env = append(env, "BUILDKIT_SCAN_"+k+"="+v)
 		}
 
@@ -100,7 +113,7 @@ func CreateSBOMScanner(ctx context.Context, resolver sourceresolver.MetaResolver
 				result.AttestationSBOMCore:  []byte(CoreSBOMName),
 			},
 			InToto: result.InTotoAttestation{
-				PredicateType: intoto.PredicateSPDX,
+				PredicateType: predicateType,
 			},
 		}, nil
 	}, nil
@@ -109,7 +122,7 @@ func CreateSBOMScanner(ctx context.Context, resolver sourceresolver.MetaResolver
 func HasSBOM[T comparable](res *result.Result[T]) bool {
 	for _, as := range res.Attestations {
 		for _, a := range as {
-			if a.InToto.PredicateType == intoto.PredicateSPDX {
+			if sbomutil.IsSBOMPredicate(a.InToto.PredicateType) {
 				return true
 			}
 		}
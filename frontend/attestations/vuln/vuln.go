@@ -0,0 +1,152 @@
+package vuln
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/moby/buildkit/client/llb"
+	"github.com/moby/buildkit/client/llb/sourceresolver"
+	gatewaypb "github.com/moby/buildkit/frontend/gateway/pb"
+	"github.com/moby/buildkit/solver/result"
+	"github.com/moby/buildkit/util/attestation"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+const (
+	// PredicateTypeParam is the parameter that requires all statements
+	// produced by the scanner to have the given predicate type. Without it,
+	// the statements must be vulnerability reports or OpenVEX documents.
+	PredicateTypeParam = "predicate-type"
+
+	PredicateTypeVulns   = attestation.PredicateTypeVulns
+	PredicateTypeOpenVEX = attestation.PredicateTypeOpenVEX
+
+	srcDir  = "/run/src/"
+	sbomDir = "/run/src/sbom/"
+	outDir  = "/run/out/"
+)
+
+// Scanner is a function type for scanning a state, together with the SBOM
+// previously generated for it, and returning a new attestation and state
+// representing the scan results.
+type Scanner func(ctx context.Context, name string, ref llb.State, sbom result.Attestation[*llb.State], opts ...llb.ConstraintsOpt) (result.Attestation[*llb.State], error)
+
+// CreateVulnScanner returns a scanner that runs the scanner image with the
+// scanned state and its SBOM bundle mounted. The scanner writes in-toto
+// statements to the destination directory, the same way as SBOM scanners do.
+func CreateVulnScanner(ctx context.Context, resolver sourceresolver.MetaResolver, scanner string, resolveOpt sourceresolver.Opt, params map[string]string) (Scanner, error) {
+	if scanner == "" {
+		return nil, nil
+	}
+
+	predicateType := params[PredicateTypeParam]
+	if strings.HasPrefix(predicateType, "https://slsa.dev/provenance/") {
+		return nil, errors.Errorf("invalid vulnerability predicate type %s", predicateType)
+	}
+
+	imr := sourceresolver.NewImageMetaResolver(resolver)
+	scanner, _, dt, err := imr.ResolveImageConfig(ctx, scanner, resolveOpt)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg ocispecs.Image
+	if err := json.Unmarshal(dt, &cfg); err != nil {
+		return nil, err
+	}
+
+	var args []string
+	args = append(args, cfg.Config.Entrypoint...)
+	args = append(args, cfg.Config.Cmd...)
+	if len(args) == 0 {
+		return nil, errors.Errorf("scanner %s does not have cmd", scanner)
+	}
+
+	return func(ctx context.Context, name string, ref llb.State, sbom result.Attestation[*llb.State], opts ...llb.ConstraintsOpt) (result.Attestation[*llb.State], error) {
+		if sbom.Kind != gatewaypb.AttestationKind_Bundle || sbom.Ref == nil {
+			return result.Attestation[*llb.State]{}, errors.Errorf("vulnerability scan of %s requires an sbom bundle", name)
+		}
+
+		var env []string
+		env = append(env, cfg.Config.Env...)
+		env = append(env, "BUILDKIT_SCAN_DESTINATION="+outDir)
+		env = append(env, "BUILDKIT_SCAN_SOURCE="+path.Join(srcDir, "core"))
+		env = append(env, "BUILDKIT_SCAN_SBOM="+sbomDir)
+		if core, ok := sbom.Metadata[result.AttestationSBOMCore]; ok {
+			env = append(env, "BUILDKIT_SCAN_SBOM_CORE="+string(core))
+		}
+		if predicateType != "" {
+			env = append(env, "BUILDKIT_SCAN_PREDICATE_TYPE="+predicateType)
+		}
+
+		for k, v := range params {
+			if k == PredicateTypeParam {
+				continue
+			}
+			env = append(env, "BUILDKIT_SCAN_"+k+"="+v)
+		}
+
+		runOpts := []llb.RunOption{
+			llb.WithCustomName(fmt.Sprintf("[%s] scanning for vulnerabilities using %s", name, scanner)),
+		}
+		for _, opt := range opts {
+			runOpts = append(runOpts, opt)
+		}
+		runOpts = append(runOpts, llb.Dir(cfg.Config.WorkingDir))
+		runOpts = append(runOpts, llb.Args(args))
+		for _, e := range env {
+			k, v, _ := strings.Cut(e, "=")
+			runOpts = append(runOpts, llb.AddEnv(k, v))
+		}
+
+		runscan := llb.Image(scanner).Run(runOpts...)
+		runscan.AddMount("/tmp", llb.Scratch(), llb.Tmpfs())
+
+		runscan.AddMount(path.Join(srcDir, "core"), ref, llb.Readonly)
+		sbomOpts := []llb.MountOption{llb.Readonly}
+		if sbom.Path != "" {
+			sbomOpts = append(sbomOpts, llb.SourcePath(sbom.Path))
+		}
+		runscan.AddMount(sbomDir, *sbom.Ref, sbomOpts...)
+
+		stvuln := runscan.AddMount(outDir, llb.Scratch())
+		return result.Attestation[*llb.State]{
+			Kind: gatewaypb.AttestationKind_Bundle,
+			Ref:  &stvuln,
+			Metadata: map[string][]byte{
+				result.AttestationReasonKey: []byte(result.AttestationReasonVuln),
+			},
+			InToto: result.InTotoAttestation{
+				PredicateType: predicateType,
+			},
+		}, nil
+	}, nil
+}
+
+// FindSBOM returns the SBOM bundle attestation among atts, if there is one.
+func FindSBOM[T any](atts []result.Attestation[T]) (result.Attestation[T], bool) {
+	for _, att := range atts {
+		if att.Kind != gatewaypb.AttestationKind_Bundle {
+			continue
+		}
+		if string(att.Metadata[result.AttestationReasonKey]) == result.AttestationReasonSBOM {
+			return att, true
+		}
+	}
+	return result.Attestation[T]{}, false
+}
+
+func HasVuln[T comparable](res *result.Result[T]) bool {
+	for _, as := range res.Attestations {
+		for _, a := range as {
+			if string(a.Metadata[result.AttestationReasonKey]) == result.AttestationReasonVuln {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package vuln
+
+import (
+	"testing"
+
+	gatewaypb "github.com/moby/buildkit/frontend/gateway/pb"
+	"github.com/moby/buildkit/solver/result"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindSBOM(t *testing.T) {
+	provenance := result.Attestation[string]{
+		Kind:     gatewaypb.AttestationKind_InToto,
+		Metadata: map[string][]byte{result.AttestationReasonKey: []byte(result.AttestationReasonProvenance)},
+	}
+	sbom := result.Attestation[string]{
+		Kind:     gatewaypb.AttestationKind_Bundle,
+		Ref:      "sbom",
+		Metadata: map[string][]byte{result.AttestationReasonKey: []byte(result.AttestationReasonSBOM)},
+	}
+
+	_, ok := FindSBOM([]result.Attestation[string]{provenance})
+	require.False(t, ok)
+
+	att, ok := FindSBOM([]result.Attestation[string]{provenance, sbom})
+	require.True(t, ok)
+	require.Equal(t, "sbom", att.Ref)
+
+	res := &result.Result[string]{}
+	res.AddAttestation("linux/amd64", sbom)
+	require.False(t, HasVuln(res))
+	res.AddAttestation("linux/amd64", result.Attestation[string]{
+		Kind:     gatewaypb.AttestationKind_Bundle,
+		Metadata: map[string][]byte{result.AttestationReasonKey: []byte(result.AttestationReasonVuln)},
+	})
+	require.True(t, HasVuln(res))
+}
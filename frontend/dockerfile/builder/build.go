@@ -10,6 +10,8 @@ import (
 	"github.com/moby/buildkit/client/llb/sourceresolver"
 	"github.com/moby/buildkit/frontend"
 	"github.com/moby/buildkit/frontend/attestations/sbom"
+	"github.com/moby/buildkit/frontend/attestations/sbom/catalog"
+	"github.com/moby/buildkit/frontend/attestations/vuln"
 	"github.com/moby/buildkit/frontend/dockerfile/dockerfile2llb"
 	"github.com/moby/buildkit/frontend/dockerfile/linter"
 	"github.com/moby/buildkit/frontend/dockerfile/parser"
@@ -112,7 +114,25 @@ func Build(ctx context.Context, c client.Client) (_ *client.Result, err error) {
 	}()
 
 	var scanner sbom.Scanner
-	if bc.SBOM != nil {
+	if bc.SBOM != nil && bc.SBOM.Generator == sbom.BuiltinGenerator {
+		scanner, err = sbom.CreateBuiltinSBOMScanner(func(ctx context.Context, st llb.State) (catalog.FS, error) {
+			def, err := st.Marshal(ctx)
+			if err != nil {
+				return nil, err
+			}
+			r, err := c.Solve(ctx, client.SolveRequest{
+				Definition:   def.ToPB(),
+				CacheImports: bc.CacheImports,
+			})
+			if err != nil {
+				return nil, err
+			}
+			return r.SingleRef()
+		}, bc.SBOM.Parameters[sbom.FormatParam], bc.Epoch)
+		if err != nil {
+			return nil, err
+		}
+	} else if bc.SBOM != nil {
 		// TODO: scanner should pass policy
 		scanner, err = sbom.CreateSBOMScanner(ctx, c, bc.SBOM.Generator, sourceresolver.Opt{
 			ImageOpt: &sourceresolver.ResolveImageOpt{
@@ -124,6 +144,18 @@ func Build(ctx context.Context, c client.Client) (_ *client.Result, err error) {
 		}
 	}
 
+	var vulnScanner vuln.Scanner
+	if bc.Vuln != nil {
+		vulnScanner, err = vuln.CreateVulnScanner(ctx, c, bc.Vuln.Generator, sourceresolver.Opt{
+			ImageOpt: &sourceresolver.ResolveImageOpt{
+				ResolveMode: opts["image-resolve-mode"],
+			},
+		}, bc.Vuln.Parameters)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	scanTargets := sync.Map{}
 
 	rb, err := bc.Build(ctx, func(ctx context.Context, platform *ocispecs.Platform, idx int) (client.Reference, *dockerspec.DockerOCIImage, *dockerspec.DockerOCIImage, error) {
@@ -189,24 +221,36 @@ func Build(ctx context.Context, c client.Client) (_ *client.Result, err error) {
 			if err != nil {
 				return err
 			}
+			atts := []result.Attestation[*llb.State]{att}
 
-			attSolve, err := result.ConvertAttestation(&att, func(st *llb.State) (client.Reference, error) {
-				def, err := st.Marshal(ctx)
+			if vulnScanner != nil {
+				// the vulnerability scan runs against the sbom of the core target
+				vatt, err := vulnScanner(ctx, id, target.Core, att, opts...)
 				if err != nil {
-					return nil, err
+					return err
 				}
-				r, err := c.Solve(ctx, frontend.SolveRequest{
-					Definition: def.ToPB(),
+				atts = append(atts, vatt)
+			}
+
+			for _, att := range atts {
+				attSolve, err := result.ConvertAttestation(&att, func(st *llb.State) (client.Reference, error) {
+					def, err := st.Marshal(ctx)
+					if err != nil {
+						return nil, err
+					}
+					r, err := c.Solve(ctx, frontend.SolveRequest{
+						Definition: def.ToPB(),
+					})
+					if err != nil {
+						return nil, err
+					}
+					return r.Ref, nil
 				})
 				if err != nil {
-					return nil, err
+					return err
 				}
-				return r.Ref, nil
-			})
-			if err != nil {
-				return err
+				rb.AddAttestation(id, *attSolve)
 			}
-			rb.AddAttestation(id, *attSolve)
 			return nil
 		}); err != nil {
 			return nil, err
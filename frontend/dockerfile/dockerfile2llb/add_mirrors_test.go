@@ -0,0 +1,30 @@
+//go:build dfaddmirrors
+
+package dockerfile2llb
+
+import (
+	"testing"
+
+	"github.com/moby/buildkit/util/appcontext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDockerfileAddMirrors(t *testing.T) {
+	df := `FROM scratch
+ADD --checksum=sha256:24454f830cdb571e2c4ad15481119c43b3cafd48dd869a9b2945d1036d1dc68d --mirror=https://mirror1.example.com/linux-0.01.tar.gz --mirror=https://mirror2.example.com/linux-0.01.tar.gz https://example.com/linux-0.01.tar.gz /
+`
+	_, _, _, _, err := Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{})
+	assert.NoError(t, err)
+
+	df = `FROM scratch
+ADD --mirror=https://mirror.example.com/foo dir /sub/
+`
+	_, _, _, _, err = Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{})
+	assert.ErrorContains(t, err, "mirror requires an HTTP(S) source")
+
+	df = `FROM scratch
+ADD --mirror=git@example.com:foo https://example.com/foo /
+`
+	_, _, _, _, err = Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{})
+	assert.ErrorContains(t, err, "only HTTP(S) URLs are supported")
+}
@@ -0,0 +1,59 @@
+//go:build dfaddunpack
+
+package dockerfile2llb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/util/appcontext"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDockerfileAddUnpackOpts(t *testing.T) {
+	df := `FROM scratch
+ADD --strip-components=1 --include=bin/* --include=lib https://example.com/app.zip /opt/
+`
+	st, _, _, _, err := Dockerfile2LLB(appcontext.Context(), []byte(df), ConvertOpt{})
+	require.NoError(t, err)
+
+	def, err := st.Marshal(context.TODO())
+	require.NoError(t, err)
+	var copies []*pb.FileActionCopy
+	for _, dt := range def.Def {
+		var op pb.Op
+		require.NoError(t, op.Unmarshal(dt))
+		for _, a := range op.GetFile().GetActions() {
+			if c := a.GetCopy(); c != nil {
+				copies = append(copies, c)
+			}
+		}
+	}
+	require.Len(t, copies, 1)
+	require.True(t, copies[0].AttemptUnpackDockerCompatibility)
+	require.Equal(t, int32(1), copies[0].Unpack.GetStripComponents())
+	require.Equal(t, []string{"bin/*", "lib"}, copies[0].Unpack.GetIncludePatterns())
+	require.True(t, copies[0].Unpack.GetZip())
+
+	for _, tc := range []struct {
+		df  string
+		err string
+	}{
+		{
+			df:  "FROM scratch\nADD --unpack=false --strip-components=1 app.tar /opt/\n",
+			err: "can't be used with --unpack=false",
+		},
+		{
+			df:  "FROM scratch\nADD --strip-components=-1 app.tar /opt/\n",
+			err: "invalid value \"-1\" for --strip-components",
+		},
+		{
+			df:  "FROM scratch\nADD --include=bin https://github.com/moby/buildkit.git /opt/\n",
+			err: "can't be used with a Git source",
+		},
+	} {
+		_, _, _, _, err := Dockerfile2LLB(appcontext.Context(), []byte(tc.df), ConvertOpt{})
+		require.ErrorContains(t, err, tc.err)
+	}
+}
@@ -948,7 +948,10 @@ func dispatch(d *dispatchState, cmd command, opt dispatchOpt) error {
 			link:            c.Link,
 			keepGitDir:      c.KeepGitDir,
 			checksum:        c.Checksum,
+			mirrors:         c.Mirrors,
 			unpack:          c.Unpack,
+			stripComponents: c.StripComponents,
+			includeInUnpack: c.IncludePatterns,
 			location:        c.Location(),
 			ignoreMatcher:   opt.dockerIgnoreMatcher,
 			opt:             opt,
@@ -1305,6 +1308,14 @@ func dispatchRun(d *dispatchState, c *instructions.RunCommand, proxy *llb.ProxyE
 		opt = append(opt, networkOpt)
 	}
 
+	if dopt.llbCaps != nil && dopt.llbCaps.Supports(pb.CapExecResourceLimits) == nil {
+		resourcesOpt, err := dispatchRunResources(c)
+		if err != nil {
+			return err
+		}
+		opt = append(opt, resourcesOpt...)
+	}
+
 	if dopt.llbCaps != nil && dopt.llbCaps.Supports(pb.CapExecMetaUlimit) == nil {
 		for _, u := range dopt.ulimit {
 			opt = append(opt, llb.AddUlimit(llb.UlimitName(u.Name), u.Soft, u.Hard))
@@ -1470,6 +1481,28 @@ func dispatchCopy(d *dispatchState, cfg copyConfig) error {
 		}
 	}
 
+	if len(cfg.mirrors) > 0 {
+		if len(cfg.params.SourcePaths) != 1 {
+			return errors.New("mirror can't be specified for multiple sources")
+		}
+		if !isHTTPSource(cfg.params.SourcePaths[0]) {
+			return errors.New("mirror requires an HTTP(S) source")
+		}
+		for _, m := range cfg.mirrors {
+			if !strings.HasPrefix(m, "http://") && !strings.HasPrefix(m, "https://") {
+				return errors.Errorf("invalid mirror %q: only HTTP(S) URLs are supported", m)
+			}
+		}
+	}
+
+	if cfg.stripComponents > 0 || len(cfg.includeInUnpack) > 0 {
+		for _, src := range cfg.params.SourcePaths {
+			if gitRef, err := gitutil.ParseGitRef(src); err == nil && !gitRef.IndistinguishableFromLocal {
+				return errors.New("strip-components and include can't be used with a Git source")
+			}
+		}
+	}
+
 	commitMessage := bytes.NewBufferString("")
 	if cfg.isAddCommand {
 		commitMessage.WriteString("ADD")
@@ -1553,7 +1586,7 @@ func dispatchCopy(d *dispatchState, cfg copyConfig) error {
 				}
 			}
 
-			st := llb.HTTP(src, llb.Filename(f), llb.WithCustomName(pgName), llb.Checksum(checksum), dfCmd(cfg.params))
+			st := llb.HTTP(src, llb.Filename(f), llb.WithCustomName(pgName), llb.Checksum(checksum), llb.Mirrors(cfg.mirrors...), dfCmd(cfg.params))
 
 			var unpack bool
 			if cfg.unpack != nil {
@@ -1564,6 +1597,7 @@ func dispatchCopy(d *dispatchState, cfg copyConfig) error {
 				Mode:           chopt,
 				CreateDestPath: true,
 				AttemptUnpack:  unpack,
+				UnpackOpt:      cfg.unpackOpt(),
 			}}, copyOpt...)
 
 			if a == nil {
@@ -1608,6 +1642,7 @@ func dispatchCopy(d *dispatchState, cfg copyConfig) error {
 				CopyDirContentsOnly: true,
 				IncludePatterns:     patterns,
 				AttemptUnpack:       unpack,
+				UnpackOpt:           cfg.unpackOpt(),
 				CreateDestPath:      true,
 				AllowWildcard:       true,
 				AllowEmptyWildcard:  true,
@@ -1692,11 +1727,28 @@ type copyConfig struct {
 	link            bool
 	keepGitDir      bool
 	checksum        string
+	mirrors         []string
 	parents         bool
 	location        []parser.Range
 	ignoreMatcher   *patternmatcher.PatternMatcher
 	opt             dispatchOpt
 	unpack          *bool
+	stripComponents int
+	includeInUnpack []string
+}
+
+// unpackOpt returns the unpack options for the copy. Zip archives are only
+// extracted if --unpack is set explicitly so that the default ADD behavior
+// stays compatible with Docker.
+func (cfg *copyConfig) unpackOpt() *llb.UnpackOpt {
+	if cfg.unpack == nil || !*cfg.unpack {
+		return nil
+	}
+	return &llb.UnpackOpt{
+		Zip:             true,
+		StripComponents: cfg.stripComponents,
+		IncludePatterns: cfg.includeInUnpack,
+	}
 }
 
 func dispatchMaintainer(d *dispatchState, c *instructions.MaintainerCommand) error {
@@ -0,0 +1,16 @@
+//go:build !dfrunresources
+
+package dockerfile2llb
+
+import (
+	"github.com/moby/buildkit/client/llb"
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/pkg/errors"
+)
+
+func dispatchRunResources(c *instructions.RunCommand) ([]llb.RunOption, error) {
+	if r := instructions.GetResources(c); r != (instructions.Resources{}) {
+		return nil, errors.Errorf("resource limits are only supported in Dockerfile frontend 1.14.0-labs or later")
+	}
+	return nil, nil
+}
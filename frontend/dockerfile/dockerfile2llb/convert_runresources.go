@@ -0,0 +1,23 @@
+//go:build dfrunresources
+
+package dockerfile2llb
+
+import (
+	"github.com/moby/buildkit/client/llb"
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+)
+
+func dispatchRunResources(c *instructions.RunCommand) ([]llb.RunOption, error) {
+	r := instructions.GetResources(c)
+	var opts []llb.RunOption
+	if r.Memory > 0 {
+		opts = append(opts, llb.MemoryLimit(r.Memory))
+	}
+	if r.CPUs > 0 {
+		opts = append(opts, llb.CPULimit(r.CPUs))
+	}
+	if r.PIDsLimit > 0 {
+		opts = append(opts, llb.PIDsLimit(r.PIDsLimit))
+	}
+	return opts, nil
+}
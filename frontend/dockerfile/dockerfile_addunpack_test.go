@@ -0,0 +1,124 @@
+//go:build dfaddunpack
+
+package dockerfile
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containerd/continuity/fs/fstest"
+	"github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/frontend/dockerui"
+	"github.com/moby/buildkit/identity"
+	"github.com/moby/buildkit/util/testutil/httpserver"
+	"github.com/moby/buildkit/util/testutil/integration"
+	"github.com/stretchr/testify/require"
+	"github.com/tonistiigi/fsutil"
+)
+
+var addUnpackTests = integration.TestFuncs(
+	testAddUnpackStripComponents,
+)
+
+func init() {
+	allTests = append(allTests, addUnpackTests...)
+}
+
+func testAddUnpackStripComponents(t *testing.T, sb integration.Sandbox) {
+	integration.SkipOnPlatform(t, "windows")
+	f := getFrontend(t, sb)
+
+	files := map[string]string{
+		"app-1.0/bin/app":    "binary",
+		"app-1.0/README.md":  "readme",
+		"app-1.0/lib/lib.so": "library",
+	}
+
+	tarBuf := bytes.NewBuffer(nil)
+	tw := tar.NewWriter(tarBuf)
+	zipBuf := bytes.NewBuffer(nil)
+	zw := zip.NewWriter(zipBuf)
+	for _, name := range []string{"app-1.0/bin/app", "app-1.0/README.md", "app-1.0/lib/lib.so"} {
+		content := files[name]
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Name:     name,
+			Typeflag: tar.TypeReg,
+			Size:     int64(len(content)),
+			Mode:     0644,
+		}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, zw.Close())
+
+	server := httpserver.NewTestServer(map[string]httpserver.Response{
+		"/app.zip": {
+			Etag:    identity.NewID(),
+			Content: zipBuf.Bytes(),
+		},
+	})
+	defer server.Close()
+
+	dockerfile := fmt.Appendf(nil, `
+FROM scratch
+ADD --strip-components=1 app.tar /local/
+ADD --strip-components=1 %[1]s /remote/
+ADD --strip-components=1 --include=bin/* --include=lib %[1]s /remote-include/
+`, server.URL+"/app.zip")
+
+	dir := integration.Tmpdir(
+		t,
+		fstest.CreateFile("Dockerfile", dockerfile, 0600),
+		fstest.CreateFile("app.tar", tarBuf.Bytes(), 0600),
+	)
+
+	c, err := client.New(sb.Context(), sb.Address())
+	require.NoError(t, err)
+	defer c.Close()
+
+	destDir := t.TempDir()
+
+	_, err = f.Solve(sb.Context(), c, client.SolveOpt{
+		Exports: []client.ExportEntry{
+			{
+				Type:      client.ExporterLocal,
+				OutputDir: destDir,
+			},
+		},
+		LocalMounts: map[string]fsutil.FS{
+			dockerui.DefaultLocalNameDockerfile: dir,
+			dockerui.DefaultLocalNameContext:    dir,
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	for _, d := range []string{"local", "remote"} {
+		for _, p := range []string{"bin/app", "README.md", "lib/lib.so"} {
+			dt, err := os.ReadFile(filepath.Join(destDir, d, p))
+			require.NoError(t, err)
+			require.Equal(t, files["app-1.0/"+p], string(dt))
+		}
+		_, err = os.Stat(filepath.Join(destDir, d, "app-1.0"))
+		require.ErrorIs(t, err, os.ErrNotExist)
+	}
+
+	dt, err := os.ReadFile(filepath.Join(destDir, "remote-include/bin/app"))
+	require.NoError(t, err)
+	require.Equal(t, "binary", string(dt))
+	dt, err = os.ReadFile(filepath.Join(destDir, "remote-include/lib/lib.so"))
+	require.NoError(t, err)
+	require.Equal(t, "library", string(dt))
+	_, err = os.Stat(filepath.Join(destDir, "remote-include/README.md"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -2,6 +2,7 @@ package dockerfile
 
 import (
 	"archive/tar"
+	"archive/zip"
 	"bytes"
 	"compress/gzip"
 	"context"
@@ -83,6 +84,7 @@ var allTests = integration.TestFuncs(
 	testDockerfileInvalidCommand,
 	testDockerfileADDFromURL,
 	testDockerfileAddArchive,
+	testDockerfileAddZipArchive,
 	testDockerfileAddChownArchive,
 	testDockerfileScratchConfig,
 	testExportedHistory,
@@ -3343,6 +3345,61 @@ ADD %s /newname.tar.gz
 	require.Equal(t, buf2.Bytes(), dt)
 }
 
+func testDockerfileAddZipArchive(t *testing.T, sb integration.Sandbox) {
+	integration.SkipOnPlatform(t, "windows")
+	f := getFrontend(t, sb)
+
+	buf := bytes.NewBuffer(nil)
+	zw := zip.NewWriter(buf)
+	w, err := zw.Create("dir/foo")
+	require.NoError(t, err)
+	_, err = w.Write([]byte("content0"))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	server := httpserver.NewTestServer(map[string]httpserver.Response{
+		"/t.zip": {
+			Etag:    identity.NewID(),
+			Content: buf.Bytes(),
+		},
+	})
+	defer server.Close()
+
+	dockerfile := fmt.Appendf(nil, `
+FROM scratch
+ADD t.zip /local/
+ADD %[1]s /remote/
+ADD --unpack=true t.zip /local-unpack/
+ADD --unpack=true %[1]s /remote-unpack/
+`, server.URL+"/t.zip")
+
+	dir := integration.Tmpdir(
+		t,
+		fstest.CreateFile("Dockerfile", dockerfile, 0600),
+		fstest.CreateFile("t.zip", buf.Bytes(), 0600),
+	)
+
+	args, trace := f.DFCmdArgs(dir.Name, dir.Name)
+	defer os.RemoveAll(trace)
+
+	destDir := t.TempDir()
+
+	cmd := sb.Cmd(args + fmt.Sprintf(" --output type=local,dest=%s", destDir))
+	require.NoError(t, cmd.Run())
+
+	// zip archives are only extracted with --unpack=true
+	for _, p := range []string{"local/t.zip", "remote/t.zip"} {
+		dt, err := os.ReadFile(filepath.Join(destDir, p))
+		require.NoError(t, err)
+		require.Equal(t, buf.Bytes(), dt)
+	}
+	for _, p := range []string{"local-unpack/dir/foo", "remote-unpack/dir/foo"} {
+		dt, err := os.ReadFile(filepath.Join(destDir, p))
+		require.NoError(t, err)
+		require.Equal(t, "content0", string(dt))
+	}
+}
+
 func testDockerfileAddChownArchive(t *testing.T, sb integration.Sandbox) {
 	integration.SkipOnPlatform(t, "windows")
 	f := getFrontend(t, sb)
@@ -247,6 +247,9 @@ type AddCommand struct {
 	KeepGitDir      bool // whether to keep .git dir, only meaningful for git sources
 	Checksum        string
 	Unpack          *bool
+	Mirrors         []string // URLs to fetch a remote source from if it fails
+	StripComponents int      // leading path components removed from unpacked archive entries
+	IncludePatterns []string // unpacked archive entries, all entries if empty
 }
 
 func (c *AddCommand) Expand(expander SingleWordExpander) error {
@@ -268,6 +271,14 @@ func (c *AddCommand) Expand(expander SingleWordExpander) error {
 	}
 	c.Checksum = expandedChecksum
 
+	for i, m := range c.Mirrors {
+		expandedMirror, err := expander(m)
+		if err != nil {
+			return err
+		}
+		c.Mirrors[i] = expandedMirror
+	}
+
 	return c.SourcesAndDest.Expand(expander)
 }
 
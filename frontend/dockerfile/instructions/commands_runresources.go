@@ -0,0 +1,101 @@
+package instructions
+
+import (
+	"strconv"
+
+	"github.com/docker/go-units"
+	"github.com/pkg/errors"
+)
+
+var resourcesKey = "dockerfile/run/resources"
+
+func init() {
+	parseRunPreHooks = append(parseRunPreHooks, runResourcesPreHook)
+	parseRunPostHooks = append(parseRunPostHooks, runResourcesPostHook)
+}
+
+// Resources are the resource limits requested with RUN --memory, --cpus
+// and --pids-limit. Zero values mean no limit was requested.
+type Resources struct {
+	Memory    int64
+	CPUs      float64
+	PIDsLimit int64
+}
+
+type resourcesState struct {
+	memoryFlag    *Flag
+	cpusFlag      *Flag
+	pidsLimitFlag *Flag
+	resources     Resources
+}
+
+func runResourcesPreHook(cmd *RunCommand, req parseRequest) error {
+	st := &resourcesState{}
+	st.memoryFlag = req.flags.AddString("memory", "")
+	st.cpusFlag = req.flags.AddString("cpus", "")
+	st.pidsLimitFlag = req.flags.AddString("pids-limit", "")
+	cmd.setExternalValue(resourcesKey, st)
+	return nil
+}
+
+func runResourcesPostHook(cmd *RunCommand, req parseRequest) error {
+	st := getResourcesState(cmd)
+	if st == nil {
+		return errors.Errorf("no resources state")
+	}
+	r, err := ParseResources(st.memoryFlag.Value, st.cpusFlag.Value, st.pidsLimitFlag.Value)
+	if err != nil {
+		return err
+	}
+	st.resources = *r
+	return nil
+}
+
+func getResourcesState(cmd *RunCommand) *resourcesState {
+	v := cmd.getExternalValue(resourcesKey)
+	if v == nil {
+		return nil
+	}
+	return v.(*resourcesState)
+}
+
+func GetResources(cmd *RunCommand) Resources {
+	return getResourcesState(cmd).resources
+}
+
+// ParseResources parses the values of the RUN --memory, --cpus and
+// --pids-limit flags. Empty values are left unset.
+func ParseResources(memory, cpus, pidsLimit string) (*Resources, error) {
+	r := &Resources{}
+	if memory != "" {
+		v, err := units.RAMInBytes(memory)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid memory limit %q", memory)
+		}
+		if v <= 0 {
+			return nil, errors.Errorf("invalid memory limit %q: must be positive", memory)
+		}
+		r.Memory = v
+	}
+	if cpus != "" {
+		v, err := strconv.ParseFloat(cpus, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid cpus value %q", cpus)
+		}
+		if v < 0.01 {
+			return nil, errors.Errorf("invalid cpus value %q: must be at least 0.01", cpus)
+		}
+		r.CPUs = v
+	}
+	if pidsLimit != "" {
+		v, err := strconv.ParseInt(pidsLimit, 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid pids-limit value %q", pidsLimit)
+		}
+		if v <= 0 {
+			return nil, errors.Errorf("invalid pids-limit value %q: must be positive", pidsLimit)
+		}
+		r.PIDsLimit = v
+	}
+	return r, nil
+}
@@ -0,0 +1,60 @@
+package instructions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseResources(t *testing.T) {
+	cases := []struct {
+		memory, cpus, pids string
+		expected           *Resources
+		expectedErr        string
+	}{
+		{
+			expected: &Resources{},
+		},
+		{
+			memory:   "4g",
+			cpus:     "1.5",
+			pids:     "100",
+			expected: &Resources{Memory: 4 << 30, CPUs: 1.5, PIDsLimit: 100},
+		},
+		{
+			memory:   "512m",
+			expected: &Resources{Memory: 512 << 20},
+		},
+		{
+			memory:      "foo",
+			expectedErr: `invalid memory limit "foo"`,
+		},
+		{
+			cpus:        "0",
+			expectedErr: `invalid cpus value "0": must be at least 0.01`,
+		},
+		{
+			cpus:        "0.001",
+			expectedErr: `invalid cpus value "0.001": must be at least 0.01`,
+		},
+		{
+			cpus:     "0.01",
+			expected: &Resources{CPUs: 0.01},
+		},
+		{
+			pids:        "-1",
+			expectedErr: `invalid pids-limit value "-1": must be positive`,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.memory+"/"+tt.cpus+"/"+tt.pids, func(t *testing.T) {
+			r, err := ParseResources(tt.memory, tt.cpus, tt.pids)
+			if tt.expectedErr != "" {
+				require.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, r)
+		})
+	}
+}
@@ -38,6 +38,10 @@ var parseRunPostHooks []func(*RunCommand, parseRequest) error
 
 var parentsEnabled = false
 
+var addMirrorsEnabled = false
+
+var addUnpackOptsEnabled = false
+
 func nodeArgs(node *parser.Node) []string {
 	result := []string{}
 	for ; node.Next != nil; node = node.Next {
@@ -339,6 +343,15 @@ func parseAdd(req parseRequest) (*AddCommand, error) {
 	flKeepGitDir := req.flags.AddBool("keep-git-dir", false)
 	flChecksum := req.flags.AddString("checksum", "")
 	flUnpack := req.flags.AddBool("unpack", false)
+	var flMirrors *Flag
+	if addMirrorsEnabled {
+		flMirrors = req.flags.AddStrings("mirror")
+	}
+	var flStripComponents, flIncludes *Flag
+	if addUnpackOptsEnabled {
+		flStripComponents = req.flags.AddString("strip-components", "")
+		flIncludes = req.flags.AddStrings("include")
+	}
 	if err := req.flags.Parse(); err != nil {
 		return nil, err
 	}
@@ -354,6 +367,23 @@ func parseAdd(req parseRequest) (*AddCommand, error) {
 		unpack = &b
 	}
 
+	var stripComponents int
+	if flStripComponents != nil && flStripComponents.Value != "" {
+		stripComponents, err = strconv.Atoi(flStripComponents.Value)
+		if err != nil || stripComponents < 0 {
+			return nil, errors.Errorf("invalid value %q for --strip-components", flStripComponents.Value)
+		}
+	}
+	includePatterns := stringValuesFromFlagIfPossible(flIncludes)
+	if stripComponents > 0 || len(includePatterns) > 0 {
+		if unpack != nil && !*unpack {
+			return nil, errors.New("--strip-components and --include can't be used with --unpack=false")
+		}
+		// the options only apply to unpacked archives
+		b := true
+		unpack = &b
+	}
+
 	return &AddCommand{
 		withNameAndCode: newWithNameAndCode(req),
 		SourcesAndDest:  *sourcesAndDest,
@@ -364,6 +394,9 @@ func parseAdd(req parseRequest) (*AddCommand, error) {
 		Checksum:        flChecksum.Value,
 		ExcludePatterns: stringValuesFromFlagIfPossible(flExcludes),
 		Unpack:          unpack,
+		Mirrors:         stringValuesFromFlagIfPossible(flMirrors),
+		StripComponents: stripComponents,
+		IncludePatterns: includePatterns,
 	}, nil
 }
 
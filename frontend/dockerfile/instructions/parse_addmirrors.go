@@ -0,0 +1,7 @@
+//go:build dfaddmirrors
+
+package instructions
+
+func init() {
+	addMirrorsEnabled = true
+}
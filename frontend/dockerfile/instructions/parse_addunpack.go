@@ -0,0 +1,7 @@
+//go:build dfaddunpack
+
+package instructions
+
+func init() {
+	addUnpackOptsEnabled = true
+}
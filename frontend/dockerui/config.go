@@ -15,6 +15,7 @@ import (
 	controlapi "github.com/moby/buildkit/api/services/control"
 	"github.com/moby/buildkit/client/llb"
 	"github.com/moby/buildkit/frontend/attestations"
+	"github.com/moby/buildkit/frontend/attestations/sbom"
 	"github.com/moby/buildkit/frontend/dockerfile/linter"
 	"github.com/moby/buildkit/frontend/gateway/client"
 	"github.com/moby/buildkit/solver/pb"
@@ -75,6 +76,7 @@ type Config struct {
 	BuildPlatforms         []ocispecs.Platform
 	MultiPlatformRequested bool
 	SBOM                   *SBOM
+	Vuln                   *Vuln
 }
 
 type Client struct {
@@ -95,6 +97,11 @@ type SBOM struct {
 	Parameters map[string]string
 }
 
+type Vuln struct {
+	Generator  string
+	Parameters map[string]string
+}
+
 type Source struct {
 	*llb.SourceMap
 	Warn func(context.Context, string, client.WarnOpts)
@@ -263,24 +270,54 @@ func (bc *Client) init() error {
 	}
 	if attrs, ok := attests[attestations.KeyTypeSbom]; ok {
 		params := make(map[string]string)
-		var ref reference.Named
+		var generator string
 		for k, v := range attrs {
 			if k == "generator" {
-				ref, err = reference.ParseNormalizedNamed(v)
+				if v == sbom.BuiltinGenerator {
+					generator = v
+					continue
+				}
+				ref, err := reference.ParseNormalizedNamed(v)
 				if err != nil {
 					return errors.Wrapf(err, "failed to parse sbom scanner %s", v)
 				}
-				ref = reference.TagNameOnly(ref)
+				generator = reference.TagNameOnly(ref).String()
 			} else {
 				params[k] = v
 			}
 		}
-		if ref == nil {
+		if generator == "" {
 			return errors.Errorf("sbom scanner cannot be empty")
 		}
 
 		bc.SBOM = &SBOM{
-			Generator:  ref.String(),
+			Generator:  generator,
+			Parameters: params,
+		}
+	}
+	if attrs, ok := attests[attestations.KeyTypeVuln]; ok {
+		if bc.SBOM == nil {
+			return errors.Errorf("vuln attestation requires sbom attestation")
+		}
+		params := make(map[string]string)
+		var generator string
+		for k, v := range attrs {
+			if k == "generator" {
+				ref, err := reference.ParseNormalizedNamed(v)
+				if err != nil {
+					return errors.Wrapf(err, "failed to parse vuln scanner %s", v)
+				}
+				generator = reference.TagNameOnly(ref).String()
+			} else {
+				params[k] = v
+			}
+		}
+		if generator == "" {
+			return errors.Errorf("vuln scanner cannot be empty")
+		}
+
+		bc.Vuln = &Vuln{
+			Generator:  generator,
 			Parameters: params,
 		}
 	}
@@ -1503,6 +1503,7 @@ func (lbf *llbBridgeForwarder) ExecProcess(srv pb.LLBBridge_ExecProcessServer) e
 
 					var statusCode uint32
 					var exitError *pb.ExitError
+					var exitReason *errdefs.ExitReason
 					var statusError *spb.Status
 					if err != nil {
 						statusCode = pb.UnknownExitStatus
@@ -1516,14 +1517,16 @@ func (lbf *llbBridgeForwarder) ExecProcess(srv pb.LLBBridge_ExecProcessServer) e
 					}
 					if errors.As(err, &exitError) {
 						statusCode = exitError.ExitCode
+						exitReason = exitError.Reason
 					}
 					bklog.G(ctx).Debugf("|---> Exit Message %s, code=%d, error=%s", pid, statusCode, err)
 					sendErr := srv.Send(&pb.ExecMessage{
 						ProcessID: pid,
 						Input: &pb.ExecMessage_Exit{
 							Exit: &pb.ExitMessage{
-								Code:  statusCode,
-								Error: statusError,
+								Code:   statusCode,
+								Error:  statusError,
+								Reason: exitReason,
 							},
 						},
 					})
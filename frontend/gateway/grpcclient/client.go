@@ -1084,7 +1084,7 @@ func (ctr *container) Start(ctx context.Context, req client.StartRequest) (clien
 					Details: exit.Error.Details,
 				}))
 				if exit.Code != pb.UnknownExitStatus {
-					exitError = &pb.ExitError{ExitCode: exit.Code, Err: exitError}
+					exitError = &pb.ExitError{ExitCode: exit.Code, Err: exitError, Reason: exit.Reason}
 				}
 			} else if serverDone := msg.GetDone(); serverDone != nil {
 				return exitError
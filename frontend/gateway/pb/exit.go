@@ -2,8 +2,10 @@ package moby_buildkit_v1_frontend //nolint:revive,staticcheck
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/containerd/typeurl/v2"
+	"github.com/moby/buildkit/solver/errdefs"
 	"github.com/moby/buildkit/util/grpcerrors"
 )
 
@@ -26,19 +28,36 @@ func init() {
 type ExitError struct {
 	ExitCode uint32
 	Err      error
+	// Reason is set when it is known why the process exited, e.g. because
+	// it was killed by the OOM killer or terminated by a signal.
+	Reason *errdefs.ExitReason
 }
 
 func (err *ExitError) ToProto() grpcerrors.TypedErrorProto {
 	return &ExitMessage{
-		Code: err.ExitCode,
+		Code:   err.ExitCode,
+		Reason: err.Reason,
 	}
 }
 
 func (err *ExitError) Error() string {
+	var msg string
 	if err.Err != nil {
-		return err.Err.Error()
+		msg = err.Err.Error()
+	} else {
+		msg = fmt.Sprintf("exit code: %d", err.ExitCode)
 	}
-	return fmt.Sprintf("exit code: %d", err.ExitCode)
+	// errors decoded from grpc or wrapped on cancellation may already carry
+	// the reason in the message
+	if reason := errdefs.FormatExitReason(err.Reason); reason != "" && !strings.Contains(msg, "("+reason+")") {
+		msg += " (" + reason + ")"
+	}
+	return msg
+}
+
+// ExitReason returns the reason the process exited, if known.
+func (err *ExitError) ExitReason() *errdefs.ExitReason {
+	return err.Reason
 }
 
 func (err *ExitError) Unwrap() error {
@@ -49,5 +68,6 @@ func (e *ExitMessage) WrapError(err error) error {
 	return &ExitError{
 		Err:      err,
 		ExitCode: e.Code,
+		Reason:   e.Reason,
 	}
 }
@@ -0,0 +1,41 @@
+package moby_buildkit_v1_frontend //nolint:revive,staticcheck
+
+import (
+	"testing"
+
+	"github.com/moby/buildkit/solver/errdefs"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExitErrorMessage(t *testing.T) {
+	t.Parallel()
+
+	oom := &errdefs.ExitReason{Type: errdefs.ExitReasonType_OOM_KILLED}
+	for _, tc := range []struct {
+		err *ExitError
+		exp string
+	}{
+		{&ExitError{ExitCode: 1}, "exit code: 1"},
+		{&ExitError{ExitCode: 137, Reason: oom}, "exit code: 137 (OOMKilled)"},
+		{&ExitError{ExitCode: 1, Err: errors.New("failed")}, "failed"},
+		{&ExitError{ExitCode: 137, Err: errors.New("failed"), Reason: oom}, "failed (OOMKilled)"},
+		// the reason is not repeated for errors decoded from grpc
+		{&ExitError{ExitCode: 137, Err: errors.New("exit code: 137 (OOMKilled)"), Reason: oom}, "exit code: 137 (OOMKilled)"},
+		{&ExitError{ExitCode: 1, Reason: &errdefs.ExitReason{}}, "exit code: 1"},
+	} {
+		require.Equal(t, tc.exp, tc.err.Error())
+	}
+
+	// the reason survives the proto round trip
+	err := (&ExitError{ExitCode: 137, Reason: oom}).ToProto().WrapError(errors.New("exit code: 137"))
+	var exitErr *ExitError
+	require.True(t, errors.As(err, &exitErr))
+	require.Equal(t, uint32(137), exitErr.ExitCode)
+	require.Equal(t, "exit code: 137 (OOMKilled)", exitErr.Error())
+
+	// the reason is found in the chain of wrapped errors
+	wrapped := errors.Wrap(errors.Wrap(err, "process did not complete successfully"), "failed to solve")
+	require.True(t, oom.EqualVT(errdefs.ExitReasonFromError(wrapped)))
+	require.Nil(t, errdefs.ExitReasonFromError(errors.New("exit code: 137")))
+}
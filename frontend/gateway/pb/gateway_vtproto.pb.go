@@ -7,6 +7,7 @@ package moby_buildkit_v1_frontend
 import (
 	fmt "fmt"
 	types1 "github.com/moby/buildkit/api/types"
+	errdefs "github.com/moby/buildkit/solver/errdefs"
 	pb "github.com/moby/buildkit/solver/pb"
 	pb1 "github.com/moby/buildkit/sourcepolicy/pb"
 	pb2 "github.com/moby/buildkit/util/apicaps/pb"
@@ -1031,6 +1032,7 @@ func (m *ExitMessage) CloneVT() *ExitMessage {
 	}
 	r := new(ExitMessage)
 	r.Code = m.Code
+	r.Reason = m.Reason.CloneVT()
 	if rhs := m.Error; rhs != nil {
 		if vtpb, ok := interface{}(rhs).(interface{ CloneVT() *status.Status }); ok {
 			r.Error = vtpb.CloneVT()
@@ -2670,6 +2672,9 @@ func (this *ExitMessage) EqualVT(that *ExitMessage) bool {
 	} else if !proto.Equal(this.Error, that.Error) {
 		return false
 	}
+	if !this.Reason.EqualVT(that.Reason) {
+		return false
+	}
 	return string(this.unknownFields) == string(that.unknownFields)
 }
 
@@ -5268,6 +5273,16 @@ func (m *ExitMessage) MarshalToSizedBufferVT(dAtA []byte) (int, error) {
 		i -= len(m.unknownFields)
 		copy(dAtA[i:], m.unknownFields)
 	}
+	if m.Reason != nil {
+		size, err := m.Reason.MarshalToSizedBufferVT(dAtA[:i])
+		if err != nil {
+			return 0, err
+		}
+		i -= size
+		i = protohelpers.EncodeVarint(dAtA, i, uint64(size))
+		i--
+		dAtA[i] = 0x1a
+	}
 	if m.Error != nil {
 		if vtmsg, ok := interface{}(m.Error).(interface {
 			MarshalToSizedBufferVT([]byte) (int, error)
@@ -6527,6 +6542,10 @@ func (m *ExitMessage) SizeVT() (n int) {
 		}
 		n += 1 + l + protohelpers.SizeOfVarint(uint64(l))
 	}
+	if m.Reason != nil {
+		l = m.Reason.SizeVT()
+		n += 1 + l + protohelpers.SizeOfVarint(uint64(l))
+	}
 	n += len(m.unknownFields)
 	return n
 }
@@ -12924,6 +12943,42 @@ func (m *ExitMessage) UnmarshalVT(dAtA []byte) error {
 				}
 			}
 			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Reason", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return protohelpers.ErrIntOverflow
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return protohelpers.ErrInvalidLength
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return protohelpers.ErrInvalidLength
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Reason == nil {
+				m.Reason = &errdefs.ExitReason{}
+			}
+			if err := m.Reason.UnmarshalVT(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := protohelpers.Skip(dAtA[iNdEx:])
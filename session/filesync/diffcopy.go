@@ -111,12 +111,15 @@ func recvDiffCopy(ds grpc.ClientStream, dest string, cu CacheUpdater, progress p
 	}))
 }
 
-func syncTargetDiffCopy(ds grpc.ServerStream, dest string) error {
+// syncTargetDiffCopy receives files to dest. Files that already exist in
+// dest are kept, unless sync is set. In that case dest is made to match the
+// received files and only changed files are requested from the sender.
+func syncTargetDiffCopy(ds grpc.ServerStream, dest string, sync bool) error {
 	if err := os.MkdirAll(dest, 0700); err != nil {
 		return errors.Wrapf(err, "failed to create synctarget dest dir %s", dest)
 	}
 	return errors.WithStack(fsutil.Receive(ds.Context(), ds, dest, fsutil.ReceiveOpt{
-		Merge: true,
+		Merge: !sync,
 		Filter: func() func(string, *fstypes.Stat) bool {
 			uid := os.Getuid()
 			gid := os.Getgid()
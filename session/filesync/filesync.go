@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"unicode"
@@ -28,7 +29,8 @@ const (
 	keyDirName            = "dir-name"
 	keyExporterMetaPrefix = "exporter-md-"
 
-	keyExporterID = "buildkit-attachable-exporter-id"
+	keyExporterID   = "buildkit-attachable-exporter-id"
+	keyExporterSync = "buildkit-attachable-exporter-sync"
 )
 
 type fsSyncProvider struct {
@@ -255,6 +257,8 @@ type fsSyncTarget struct {
 	id     int
 	outdir string
 	f      FileOutputFunc
+	sync   bool
+	prune  bool
 }
 
 func (target *fsSyncTarget) target() *fsSyncTarget {
@@ -275,10 +279,24 @@ func WithFSSyncDir(id int, outdir string) FSSyncTarget {
 	}
 }
 
+// WithFSSyncDirSync is like WithFSSyncDir but also allows the exporter to
+// make outdir match the result, removing the files that are not part of it.
+// Unless prune is set, outdir must be empty or not exist. outdir may never
+// contain the current working directory.
+func WithFSSyncDirSync(id int, outdir string, prune bool) FSSyncTarget {
+	return &fsSyncTarget{
+		id:     id,
+		outdir: outdir,
+		sync:   true,
+		prune:  prune,
+	}
+}
+
 func NewFSSyncTarget(targets ...FSSyncTarget) *SyncTarget {
 	st := &SyncTarget{
-		fs:      make(map[int]FileOutputFunc),
-		outdirs: make(map[int]string),
+		fs:       make(map[int]FileOutputFunc),
+		outdirs:  make(map[int]string),
+		syncdirs: make(map[int]bool),
 	}
 	st.Add(targets...)
 	return st
@@ -287,6 +305,9 @@ func NewFSSyncTarget(targets ...FSSyncTarget) *SyncTarget {
 type SyncTarget struct {
 	fs      map[int]FileOutputFunc
 	outdirs map[int]string
+	// syncdirs holds the outdirs that allow sync and whether files may be
+	// pruned from them
+	syncdirs map[int]bool
 }
 
 var _ session.Attachable = &SyncTarget{}
@@ -299,6 +320,9 @@ func (sp *SyncTarget) Add(targets ...FSSyncTarget) {
 		}
 		if t.outdir != "" {
 			sp.outdirs[t.id] = t.outdir
+			if t.sync {
+				sp.syncdirs[t.id] = t.prune
+			}
 		}
 	}
 }
@@ -326,7 +350,18 @@ func (sp *SyncTarget) chooser(ctx context.Context) int {
 func (sp *SyncTarget) DiffCopy(stream FileSend_DiffCopyServer) (err error) {
 	id := sp.chooser(stream.Context())
 	if outdir, ok := sp.outdirs[id]; ok {
-		return syncTargetDiffCopy(stream, outdir)
+		md, _ := metadata.FromIncomingContext(stream.Context())
+		sync := len(md[keyExporterSync]) > 0 && md[keyExporterSync][0] == "true"
+		if sync {
+			prune, ok := sp.syncdirs[id]
+			if !ok {
+				return errors.Errorf("syncing to %s was not allowed by the client", outdir)
+			}
+			if err := checkSyncDir(outdir, prune); err != nil {
+				return err
+			}
+		}
+		return syncTargetDiffCopy(stream, outdir, sync)
 	}
 	f, ok := sp.fs[id]
 	if !ok {
@@ -356,7 +391,58 @@ func (sp *SyncTarget) DiffCopy(stream FileSend_DiffCopyServer) (err error) {
 	return writeTargetFile(stream, wc)
 }
 
+// checkSyncDir returns an error if syncing to dir could remove files that the
+// user didn't ask for.
+func checkSyncDir(dir string, prune bool) error {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	if p, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = p
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	if p, err := filepath.EvalSymlinks(wd); err == nil {
+		wd = p
+	}
+	if rel, err := filepath.Rel(abs, wd); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return errors.Errorf("refusing to sync to %s as it contains the current working directory", dir)
+	}
+	if prune {
+		return nil
+	}
+	entries, err := os.ReadDir(abs)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if len(entries) > 0 {
+		return errors.Errorf("refusing to sync to %s as it is not empty, prune must be set to remove files that are not part of the result", dir)
+	}
+	return nil
+}
+
+// CopyToCaller sends fs to the directory of the exporter with id on the
+// client. Existing files in the directory are overwritten.
 func CopyToCaller(ctx context.Context, fs fsutil.FS, id int, c session.Caller, progress func(int, bool)) error {
+	return copyToCaller(ctx, fs, id, c, progress, false)
+}
+
+// SyncToCaller makes the directory of the exporter with id on the client match
+// fs. Only files that differ from the ones in the directory are transferred,
+// and files that are not in fs are removed.
+func SyncToCaller(ctx context.Context, fs fsutil.FS, id int, c session.Caller, progress func(int, bool)) error {
+	return copyToCaller(ctx, fs, id, c, progress, true)
+}
+
+func copyToCaller(ctx context.Context, fs fsutil.FS, id int, c session.Caller, progress func(int, bool), sync bool) error {
 	method := session.MethodURL(FileSend_ServiceDesc.ServiceName, "diffcopy")
 	if !c.Supports(method) {
 		return errors.Errorf("method %s not supported by the client", method)
@@ -372,6 +458,9 @@ func CopyToCaller(ctx context.Context, fs fsutil.FS, id int, c session.Caller, p
 		bklog.G(ctx).Warnf("overwriting grpc metadata key %q from value %+v to %+v", keyExporterID, existingVal, id)
 	}
 	opts[keyExporterID] = []string{fmt.Sprint(id)}
+	if sync {
+		opts[keyExporterSync] = []string{"true"}
+	}
 	ctx = metadata.NewOutgoingContext(ctx, opts)
 
 	cc, err := client.DiffCopy(ctx)
@@ -2,9 +2,12 @@ package filesync
 
 import (
 	"context"
+	"io"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/session/testutil"
@@ -82,3 +85,132 @@ func TestFileSyncIncludePatterns(t *testing.T) {
 	err = g.Wait()
 	require.NoError(t, err)
 }
+
+func TestSyncToCaller(t *testing.T) {
+	t.Parallel()
+
+	srcDir := t.TempDir()
+	destDir := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "foo"), []byte("content1"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "bar"), []byte("content2"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "same"), []byte("unchanged"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(destDir, "foo"), []byte("stale"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(destDir, "baz"), []byte("removed"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(destDir, "same"), []byte("unchanged"), 0600))
+	tm := time.Unix(1700000000, 0)
+	require.NoError(t, os.Chtimes(filepath.Join(srcDir, "same"), tm, tm))
+	require.NoError(t, os.Chtimes(filepath.Join(destDir, "same"), tm, tm))
+	before, err := os.Stat(filepath.Join(destDir, "same"))
+	require.NoError(t, err)
+
+	srcFS, err := fsutil.NewFS(srcDir)
+	require.NoError(t, err)
+	rfs := &recordFS{FS: srcFS}
+	require.NoError(t, syncToTarget(t, NewFSSyncTarget(WithFSSyncDirSync(0, destDir, true)), rfs))
+
+	_, err = os.Stat(filepath.Join(destDir, "baz"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+	for name, content := range map[string]string{"foo": "content1", "bar": "content2", "same": "unchanged"} {
+		dt, err := os.ReadFile(filepath.Join(destDir, name))
+		require.NoError(t, err)
+		require.Equal(t, content, string(dt))
+	}
+
+	// the unchanged file is neither read from the source nor written again
+	require.ElementsMatch(t, []string{"foo", "bar"}, rfs.opened)
+	after, err := os.Stat(filepath.Join(destDir, "same"))
+	require.NoError(t, err)
+	require.True(t, os.SameFile(before, after))
+}
+
+func TestSyncToCallerRefused(t *testing.T) {
+	t.Parallel()
+
+	srcDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "foo"), []byte("content1"), 0600))
+	srcFS, err := fsutil.NewFS(srcDir)
+	require.NoError(t, err)
+
+	nonEmpty := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(nonEmpty, "keep"), []byte("keep"), 0600))
+
+	// sync requires an opt-in from the client
+	err = syncToTarget(t, NewFSSyncTarget(WithFSSyncDir(0, nonEmpty)), srcFS)
+	require.ErrorContains(t, err, "was not allowed by the client")
+
+	// files are only pruned from a non-empty directory if requested
+	err = syncToTarget(t, NewFSSyncTarget(WithFSSyncDirSync(0, nonEmpty, false)), srcFS)
+	require.ErrorContains(t, err, "is not empty")
+	_, err = os.Stat(filepath.Join(nonEmpty, "keep"))
+	require.NoError(t, err)
+
+	// empty and missing directories don't need prune
+	empty := t.TempDir()
+	require.NoError(t, syncToTarget(t, NewFSSyncTarget(WithFSSyncDirSync(0, empty, false)), srcFS))
+	missing := filepath.Join(t.TempDir(), "missing")
+	require.NoError(t, syncToTarget(t, NewFSSyncTarget(WithFSSyncDirSync(0, missing, false)), srcFS))
+}
+
+func TestCheckSyncDir(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	for _, dir := range []string{".", wd, filepath.Dir(wd), "/"} {
+		require.ErrorContains(t, checkSyncDir(dir, true), "contains the current working directory", dir)
+	}
+	require.NoError(t, checkSyncDir(filepath.Join(wd, "..sibling-missing"), false))
+	require.NoError(t, checkSyncDir(t.TempDir(), false))
+}
+
+// syncToTarget runs SyncToCaller for the exporter with ID 0 against target.
+func syncToTarget(t *testing.T, target *SyncTarget, fs fsutil.FS) error {
+	ctx := context.TODO()
+
+	s, err := session.NewSession(ctx, "bar")
+	require.NoError(t, err)
+
+	m, err := session.NewManager()
+	require.NoError(t, err)
+
+	s.Allow(target)
+
+	dialer := session.Dialer(testutil.TestStream(testutil.Handler(m.HandleConn)))
+
+	g, ctx := errgroup.WithContext(context.Background())
+
+	g.Go(func() error {
+		return s.Run(ctx, dialer)
+	})
+
+	g.Go(func() (reterr error) {
+		defer func() {
+			err := s.Close()
+			if reterr == nil {
+				reterr = err
+			}
+		}()
+
+		c, err := m.Get(ctx, s.ID(), false)
+		if err != nil {
+			return err
+		}
+		return SyncToCaller(ctx, fs, 0, c, nil)
+	})
+
+	return g.Wait()
+}
+
+// recordFS records the files that are read from the underlying FS.
+type recordFS struct {
+	fsutil.FS
+	mu     sync.Mutex
+	opened []string
+}
+
+func (fs *recordFS) Open(p string) (io.ReadCloser, error) {
+	fs.mu.Lock()
+	fs.opened = append(fs.opened, p)
+	fs.mu.Unlock()
+	return fs.FS.Open(p)
+}
@@ -0,0 +1,117 @@
+package signer
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// LoadKey reads a PEM encoded ECDSA, Ed25519 or RSA private key from path.
+func LoadKey(path string) (crypto.Signer, error) {
+	dt, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read signing key")
+	}
+	block, _ := pem.Decode(dt)
+	if block == nil {
+		return nil, errors.Errorf("no PEM data found in %s", path)
+	}
+
+	var key any
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, errors.Errorf("unsupported PEM block type %q in %s", block.Type, path)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse signing key %s", path)
+	}
+	s, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.Errorf("unsupported signing key type %T", key)
+	}
+	return s, nil
+}
+
+// SignWithKey signs data with key. ECDSA and RSA keys sign the SHA-256
+// digest of data, Ed25519 keys sign data directly.
+func SignWithKey(key crypto.Signer, data []byte) ([]byte, error) {
+	switch key.(type) {
+	case ed25519.PrivateKey, *ed25519.PrivateKey:
+		return key.Sign(rand.Reader, data, crypto.Hash(0))
+	case *ecdsa.PrivateKey, *rsa.PrivateKey:
+		dgst := sha256.Sum256(data)
+		return key.Sign(rand.Reader, dgst[:], crypto.SHA256)
+	default:
+		return nil, errors.Errorf("unsupported signing key type %T", key)
+	}
+}
+
+// KeyID returns the hex encoded SHA-256 of the PKIX encoding of pub.
+func KeyID(pub crypto.PublicKey) (string, error) {
+	dt, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+	dgst := sha256.Sum256(dt)
+	return hex.EncodeToString(dgst[:]), nil
+}
+
+// ParsePublicKeys parses all PEM encoded "PUBLIC KEY" blocks in dt.
+func ParsePublicKeys(dt []byte) ([]crypto.PublicKey, error) {
+	var keys []crypto.PublicKey
+	for {
+		var block *pem.Block
+		block, dt = pem.Decode(dt)
+		if block == nil {
+			break
+		}
+		if block.Type != "PUBLIC KEY" {
+			return nil, errors.Errorf("unsupported PEM block type %q for public key", block.Type)
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse public key")
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no PEM encoded public key found")
+	}
+	return keys, nil
+}
+
+// VerifyWithKey verifies a signature created by SignWithKey.
+func VerifyWithKey(pub crypto.PublicKey, data, sig []byte) error {
+	var ok bool
+	switch pub := pub.(type) {
+	case ed25519.PublicKey:
+		ok = ed25519.Verify(pub, data, sig)
+	case *ecdsa.PublicKey:
+		dgst := sha256.Sum256(data)
+		ok = ecdsa.VerifyASN1(pub, dgst[:], sig)
+	case *rsa.PublicKey:
+		dgst := sha256.Sum256(data)
+		ok = rsa.VerifyPKCS1v15(pub, crypto.SHA256, dgst[:], sig) == nil
+	default:
+		return errors.Errorf("unsupported public key type %T", pub)
+	}
+	if !ok {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
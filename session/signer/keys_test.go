@@ -0,0 +1,97 @@
+package signer
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadKeyAndSign(t *testing.T) {
+	data := []byte("payload")
+	dgst := sha256.Sum256(data)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ecDER, err := x509.MarshalECPrivateKey(ecKey)
+	require.NoError(t, err)
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	edDER, err := x509.MarshalPKCS8PrivateKey(edKey)
+	require.NoError(t, err)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	tcs := []struct {
+		name   string
+		block  *pem.Block
+		verify func(t *testing.T, pub crypto.PublicKey, sig []byte)
+	}{
+		{
+			name:  "ecdsa",
+			block: &pem.Block{Type: "EC PRIVATE KEY", Bytes: ecDER},
+			verify: func(t *testing.T, pub crypto.PublicKey, sig []byte) {
+				require.True(t, ecdsa.VerifyASN1(pub.(*ecdsa.PublicKey), dgst[:], sig))
+			},
+		},
+		{
+			name:  "ed25519",
+			block: &pem.Block{Type: "PRIVATE KEY", Bytes: edDER},
+			verify: func(t *testing.T, pub crypto.PublicKey, sig []byte) {
+				require.True(t, ed25519.Verify(pub.(ed25519.PublicKey), data, sig))
+			},
+		},
+		{
+			name:  "rsa",
+			block: &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)},
+			verify: func(t *testing.T, pub crypto.PublicKey, sig []byte) {
+				require.NoError(t, rsa.VerifyPKCS1v15(pub.(*rsa.PublicKey), crypto.SHA256, dgst[:], sig))
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			p := filepath.Join(t.TempDir(), "key.pem")
+			require.NoError(t, os.WriteFile(p, pem.EncodeToMemory(tc.block), 0600))
+
+			key, err := LoadKey(p)
+			require.NoError(t, err)
+
+			sig, err := SignWithKey(key, data)
+			require.NoError(t, err)
+			tc.verify(t, key.Public(), sig)
+
+			pubDER, err := x509.MarshalPKIXPublicKey(key.Public())
+			require.NoError(t, err)
+			pubs, err := ParsePublicKeys(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}))
+			require.NoError(t, err)
+			require.Len(t, pubs, 1)
+			require.NoError(t, VerifyWithKey(pubs[0], data, sig))
+			require.Error(t, VerifyWithKey(pubs[0], []byte("other"), sig))
+
+			keyID, err := KeyID(key.Public())
+			require.NoError(t, err)
+			require.Len(t, keyID, 64)
+		})
+	}
+}
+
+func TestLoadKeyInvalid(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(p, []byte("not a key"), 0600))
+	_, err := LoadKey(p)
+	require.ErrorContains(t, err, "no PEM data")
+}
@@ -0,0 +1,66 @@
+package signer
+
+import (
+	"crypto"
+	"encoding/json"
+
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+	attestationTypes "github.com/moby/buildkit/util/attestation"
+	"github.com/pkg/errors"
+	"github.com/secure-systems-lab/go-securesystemslib/dsse"
+)
+
+// statementInTotoV1 is the statement type of in-toto v1 statements.
+const statementInTotoV1 = "https://in-toto.io/Statement/v1"
+
+// SignPayload signs payload of payloadType with key. Only in-toto statements
+// and simple signing image signatures can be signed so that a signer can't be
+// used to sign arbitrary data. In-toto statements are signed with the DSSE
+// pre-authentication encoding of the payload and its type.
+func SignPayload(key crypto.Signer, payloadType string, payload []byte) ([]byte, error) {
+	data, err := signedData(payloadType, payload)
+	if err != nil {
+		return nil, err
+	}
+	return SignWithKey(key, data)
+}
+
+// signedData validates payload and returns the data signed for it.
+func signedData(payloadType string, payload []byte) ([]byte, error) {
+	switch payloadType {
+	case intoto.PayloadType:
+		var st struct {
+			Type          string            `json:"_type"`
+			PredicateType string            `json:"predicateType"`
+			Subject       []json.RawMessage `json:"subject"`
+		}
+		if err := json.Unmarshal(payload, &st); err != nil {
+			return nil, errors.Wrap(err, "invalid in-toto statement")
+		}
+		if st.Type != intoto.StatementInTotoV01 && st.Type != statementInTotoV1 {
+			return nil, errors.Errorf("unsupported in-toto statement type %q", st.Type)
+		}
+		if st.PredicateType == "" || len(st.Subject) == 0 {
+			return nil, errors.New("invalid in-toto statement: missing predicate type or subject")
+		}
+		return dsse.PAE(payloadType, payload), nil
+	case attestationTypes.MediaTypeSimpleSigning:
+		var p struct {
+			Critical struct {
+				Image struct {
+					DockerManifestDigest string `json:"docker-manifest-digest"`
+				} `json:"image"`
+				Type string `json:"type"`
+			} `json:"critical"`
+		}
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return nil, errors.Wrap(err, "invalid simple signing payload")
+		}
+		if p.Critical.Type != attestationTypes.SimpleSigningType || p.Critical.Image.DockerManifestDigest == "" {
+			return nil, errors.New("invalid simple signing payload: not an image signature")
+		}
+		return payload, nil
+	default:
+		return nil, errors.Errorf("unsupported payload type %q", payloadType)
+	}
+}
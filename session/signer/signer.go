@@ -0,0 +1,29 @@
+package signer
+
+import (
+	"context"
+
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/util/grpcerrors"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+)
+
+var ErrNotSupported = errors.Errorf("signing not supported")
+
+// Sign requests the client of the session to sign payload. Returns
+// ErrNotSupported if the client didn't provide a signer.
+func Sign(ctx context.Context, c session.Caller, payloadType string, payload []byte) ([]byte, string, error) {
+	client := NewSignerClient(c.Conn())
+	resp, err := client.Sign(ctx, &SignRequest{
+		PayloadType: payloadType,
+		Data:        payload,
+	})
+	if err != nil {
+		if code := grpcerrors.Code(err); code == codes.Unimplemented {
+			return nil, "", errors.WithStack(ErrNotSupported)
+		}
+		return nil, "", err
+	}
+	return resp.Signature, resp.KeyID, nil
+}
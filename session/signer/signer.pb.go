@@ -0,0 +1,195 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v3.11.4
+// source: github.com/moby/buildkit/session/signer/signer.proto
+
+package signer
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SignRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// payloadType is the type of the payload, the DSSE payload type of an
+	// in-toto statement or the media type of a simple signing payload.
+	PayloadType string `protobuf:"bytes,1,opt,name=payloadType,proto3" json:"payloadType,omitempty"`
+	// data is the payload. The signer validates it and computes the signed
+	// data, e.g. the DSSE pre-authentication encoding, from it.
+	Data          []byte `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignRequest) Reset() {
+	*x = SignRequest{}
+	mi := &file_github_com_moby_buildkit_session_signer_signer_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignRequest) ProtoMessage() {}
+
+func (x *SignRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_moby_buildkit_session_signer_signer_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignRequest.ProtoReflect.Descriptor instead.
+func (*SignRequest) Descriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_session_signer_signer_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SignRequest) GetPayloadType() string {
+	if x != nil {
+		return x.PayloadType
+	}
+	return ""
+}
+
+func (x *SignRequest) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type SignResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Signature     []byte                 `protobuf:"bytes,1,opt,name=signature,proto3" json:"signature,omitempty"`
+	KeyID         string                 `protobuf:"bytes,2,opt,name=keyID,proto3" json:"keyID,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignResponse) Reset() {
+	*x = SignResponse{}
+	mi := &file_github_com_moby_buildkit_session_signer_signer_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignResponse) ProtoMessage() {}
+
+func (x *SignResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_moby_buildkit_session_signer_signer_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignResponse.ProtoReflect.Descriptor instead.
+func (*SignResponse) Descriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_session_signer_signer_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SignResponse) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+func (x *SignResponse) GetKeyID() string {
+	if x != nil {
+		return x.KeyID
+	}
+	return ""
+}
+
+var File_github_com_moby_buildkit_session_signer_signer_proto protoreflect.FileDescriptor
+
+const file_github_com_moby_buildkit_session_signer_signer_proto_rawDesc = "" +
+	"\n" +
+	"4github.com/moby/buildkit/session/signer/signer.proto\x12\x17moby.buildkit.signer.v1\"C\n" +
+	"\vSignRequest\x12 \n" +
+	"\vpayloadType\x18\x01 \x01(\tR\vpayloadType\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\fR\x04data\"B\n" +
+	"\fSignResponse\x12\x1c\n" +
+	"\tsignature\x18\x01 \x01(\fR\tsignature\x12\x14\n" +
+	"\x05keyID\x18\x02 \x01(\tR\x05keyID2]\n" +
+	"\x06Signer\x12S\n" +
+	"\x04Sign\x12$.moby.buildkit.signer.v1.SignRequest\x1a%.moby.buildkit.signer.v1.SignResponseB)Z'github.com/moby/buildkit/session/signerb\x06proto3"
+
+var (
+	file_github_com_moby_buildkit_session_signer_signer_proto_rawDescOnce sync.Once
+	file_github_com_moby_buildkit_session_signer_signer_proto_rawDescData []byte
+)
+
+func file_github_com_moby_buildkit_session_signer_signer_proto_rawDescGZIP() []byte {
+	file_github_com_moby_buildkit_session_signer_signer_proto_rawDescOnce.Do(func() {
+		file_github_com_moby_buildkit_session_signer_signer_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_github_com_moby_buildkit_session_signer_signer_proto_rawDesc), len(file_github_com_moby_buildkit_session_signer_signer_proto_rawDesc)))
+	})
+	return file_github_com_moby_buildkit_session_signer_signer_proto_rawDescData
+}
+
+var file_github_com_moby_buildkit_session_signer_signer_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_github_com_moby_buildkit_session_signer_signer_proto_goTypes = []any{
+	(*SignRequest)(nil),  // 0: moby.buildkit.signer.v1.SignRequest
+	(*SignResponse)(nil), // 1: moby.buildkit.signer.v1.SignResponse
+}
+var file_github_com_moby_buildkit_session_signer_signer_proto_depIdxs = []int32{
+	0, // 0: moby.buildkit.signer.v1.Signer.Sign:input_type -> moby.buildkit.signer.v1.SignRequest
+	1, // 1: moby.buildkit.signer.v1.Signer.Sign:output_type -> moby.buildkit.signer.v1.SignResponse
+	1, // [1:2] is the sub-list for method output_type
+	0, // [0:1] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_github_com_moby_buildkit_session_signer_signer_proto_init() }
+func file_github_com_moby_buildkit_session_signer_signer_proto_init() {
+	if File_github_com_moby_buildkit_session_signer_signer_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_github_com_moby_buildkit_session_signer_signer_proto_rawDesc), len(file_github_com_moby_buildkit_session_signer_signer_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_github_com_moby_buildkit_session_signer_signer_proto_goTypes,
+		DependencyIndexes: file_github_com_moby_buildkit_session_signer_signer_proto_depIdxs,
+		MessageInfos:      file_github_com_moby_buildkit_session_signer_signer_proto_msgTypes,
+	}.Build()
+	File_github_com_moby_buildkit_session_signer_signer_proto = out.File
+	file_github_com_moby_buildkit_session_signer_signer_proto_goTypes = nil
+	file_github_com_moby_buildkit_session_signer_signer_proto_depIdxs = nil
+}
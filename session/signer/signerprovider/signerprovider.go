@@ -0,0 +1,69 @@
+package signerprovider
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/session/signer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// NewSignerProvider returns a session attachable that signs attestations and
+// image signatures for the daemon with key. The private key never leaves the
+// client and the data to sign is computed from the payload on the client.
+func NewSignerProvider(key crypto.Signer) (session.Attachable, error) {
+	keyID, err := signer.KeyID(key.Public())
+	if err != nil {
+		return nil, err
+	}
+	return &signerProvider{key: key, keyID: keyID}, nil
+}
+
+// FromKeyFile returns a signer provider for a PEM encoded private key file.
+func FromKeyFile(path string) (session.Attachable, error) {
+	key, err := signer.LoadKey(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewSignerProvider(key)
+}
+
+// NewEphemeral returns a signer provider with a newly generated ECDSA P-256
+// key. Useful for testing signing without managing keys.
+func NewEphemeral() (session.Attachable, crypto.PublicKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	sp, err := NewSignerProvider(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sp, key.Public(), nil
+}
+
+type signerProvider struct {
+	key   crypto.Signer
+	keyID string
+}
+
+func (sp *signerProvider) Register(server *grpc.Server) {
+	signer.RegisterSignerServer(server, sp)
+}
+
+func (sp *signerProvider) Sign(ctx context.Context, req *signer.SignRequest) (*signer.SignResponse, error) {
+	sig, err := signer.SignPayload(sp.key, req.PayloadType, req.Data)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &signer.SignResponse{
+		Signature: sig,
+		KeyID:     sp.keyID,
+	}, nil
+}
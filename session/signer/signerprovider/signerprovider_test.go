@@ -0,0 +1,63 @@
+package signerprovider
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+	"github.com/moby/buildkit/session/signer"
+	attestationTypes "github.com/moby/buildkit/util/attestation"
+	"github.com/secure-systems-lab/go-securesystemslib/dsse"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSign(t *testing.T) {
+	ctx := context.TODO()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	a, err := NewSignerProvider(key)
+	require.NoError(t, err)
+	sp := a.(*signerProvider)
+	keyID, err := signer.KeyID(key.Public())
+	require.NoError(t, err)
+
+	statement := []byte(`{"_type":"https://in-toto.io/Statement/v0.1","predicateType":"https://slsa.dev/provenance/v0.2","subject":[{"name":"foo","digest":{"sha256":"abcd"}}],"predicate":{}}`)
+	resp, err := sp.Sign(ctx, &signer.SignRequest{PayloadType: intoto.PayloadType, Data: statement})
+	require.NoError(t, err)
+	require.Equal(t, keyID, resp.KeyID)
+	// in-toto statements are signed with the DSSE pre-authentication encoding
+	require.NoError(t, signer.VerifyWithKey(key.Public(), dsse.PAE(intoto.PayloadType, statement), resp.Signature))
+	require.Error(t, signer.VerifyWithKey(key.Public(), statement, resp.Signature))
+
+	simpleSigning := []byte(`{"critical":{"identity":{"docker-reference":"docker.io/library/foo"},"image":{"docker-manifest-digest":"sha256:abcd"},"type":"cosign container image signature"},"optional":null}`)
+	resp, err = sp.Sign(ctx, &signer.SignRequest{PayloadType: attestationTypes.MediaTypeSimpleSigning, Data: simpleSigning})
+	require.NoError(t, err)
+	require.NoError(t, signer.VerifyWithKey(key.Public(), simpleSigning, resp.Signature))
+
+	for _, tc := range []struct {
+		name        string
+		payloadType string
+		data        string
+		err         string
+	}{
+		{"unknown type", "application/octet-stream", "data", "unsupported payload type"},
+		{"no type", "", "data", "unsupported payload type"},
+		{"not json", intoto.PayloadType, "data", "invalid in-toto statement"},
+		{"not a statement", intoto.PayloadType, `{"_type":"other","predicateType":"x","subject":[{}]}`, "unsupported in-toto statement type"},
+		{"no subject", intoto.PayloadType, `{"_type":"https://in-toto.io/Statement/v1","predicateType":"x"}`, "missing predicate type or subject"},
+		// a precomputed PAE can't be passed as the payload
+		{"pae", intoto.PayloadType, string(dsse.PAE(intoto.PayloadType, statement)), "invalid in-toto statement"},
+		{"not an image signature", attestationTypes.MediaTypeSimpleSigning, `{"critical":{"type":"other"}}`, "not an image signature"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := sp.Sign(ctx, &signer.SignRequest{PayloadType: tc.payloadType, Data: []byte(tc.data)})
+			require.ErrorContains(t, err, tc.err)
+			require.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+}
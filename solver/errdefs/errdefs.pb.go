@@ -22,6 +22,61 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type ExitReasonType int32
+
+const (
+	ExitReasonType_UNKNOWN    ExitReasonType = 0
+	ExitReasonType_OOM_KILLED ExitReasonType = 1
+	ExitReasonType_SIGNALED   ExitReasonType = 2
+	ExitReasonType_TIMEOUT    ExitReasonType = 3
+	ExitReasonType_CANCELLED  ExitReasonType = 4
+)
+
+// Enum value maps for ExitReasonType.
+var (
+	ExitReasonType_name = map[int32]string{
+		0: "UNKNOWN",
+		1: "OOM_KILLED",
+		2: "SIGNALED",
+		3: "TIMEOUT",
+		4: "CANCELLED",
+	}
+	ExitReasonType_value = map[string]int32{
+		"UNKNOWN":    0,
+		"OOM_KILLED": 1,
+		"SIGNALED":   2,
+		"TIMEOUT":    3,
+		"CANCELLED":  4,
+	}
+)
+
+func (x ExitReasonType) Enum() *ExitReasonType {
+	p := new(ExitReasonType)
+	*p = x
+	return p
+}
+
+func (x ExitReasonType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ExitReasonType) Descriptor() protoreflect.EnumDescriptor {
+	return file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_enumTypes[0].Descriptor()
+}
+
+func (ExitReasonType) Type() protoreflect.EnumType {
+	return &file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_enumTypes[0]
+}
+
+func (x ExitReasonType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ExitReasonType.Descriptor instead.
+func (ExitReasonType) EnumDescriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_rawDescGZIP(), []int{0}
+}
+
 type Vertex struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Digest        string                 `protobuf:"bytes,1,opt,name=digest,proto3" json:"digest,omitempty"`
@@ -462,6 +517,70 @@ func (x *ContentCache) GetIndex() int64 {
 	return 0
 }
 
+// ExitReason describes why a process in a container exited with a non-zero
+// exit code.
+type ExitReason struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Type  ExitReasonType         `protobuf:"varint,1,opt,name=type,proto3,enum=errdefs.ExitReasonType" json:"type,omitempty"`
+	// Signal number that terminated the process when type is SIGNALED.
+	Signal int32 `protobuf:"varint,2,opt,name=signal,proto3" json:"signal,omitempty"`
+	// Name of the signal, e.g. SIGSEGV.
+	SignalName    string `protobuf:"bytes,3,opt,name=signalName,proto3" json:"signalName,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExitReason) Reset() {
+	*x = ExitReason{}
+	mi := &file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExitReason) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExitReason) ProtoMessage() {}
+
+func (x *ExitReason) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExitReason.ProtoReflect.Descriptor instead.
+func (*ExitReason) Descriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ExitReason) GetType() ExitReasonType {
+	if x != nil {
+		return x.Type
+	}
+	return ExitReasonType_UNKNOWN
+}
+
+func (x *ExitReason) GetSignal() int32 {
+	if x != nil {
+		return x.Signal
+	}
+	return 0
+}
+
+func (x *ExitReason) GetSignalName() string {
+	if x != nil {
+		return x.SignalName
+	}
+	return ""
+}
+
 var File_github_com_moby_buildkit_solver_errdefs_errdefs_proto protoreflect.FileDescriptor
 
 const file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_rawDesc = "" +
@@ -495,7 +614,21 @@ const file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_rawDesc = "" +
 	"FileAction\x12\x14\n" +
 	"\x05index\x18\x01 \x01(\x03R\x05index\"$\n" +
 	"\fContentCache\x12\x14\n" +
-	"\x05index\x18\x01 \x01(\x03R\x05indexB)Z'github.com/moby/buildkit/solver/errdefsb\x06proto3"
+	"\x05index\x18\x01 \x01(\x03R\x05index\"q\n" +
+	"\n" +
+	"ExitReason\x12+\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x17.errdefs.ExitReasonTypeR\x04type\x12\x16\n" +
+	"\x06signal\x18\x02 \x01(\x05R\x06signal\x12\x1e\n" +
+	"\n" +
+	"signalName\x18\x03 \x01(\tR\n" +
+	"signalName*W\n" +
+	"\x0eExitReasonType\x12\v\n" +
+	"\aUNKNOWN\x10\x00\x12\x0e\n" +
+	"\n" +
+	"OOM_KILLED\x10\x01\x12\f\n" +
+	"\bSIGNALED\x10\x02\x12\v\n" +
+	"\aTIMEOUT\x10\x03\x12\r\n" +
+	"\tCANCELLED\x10\x04B)Z'github.com/moby/buildkit/solver/errdefsb\x06proto3"
 
 var (
 	file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_rawDescOnce sync.Once
@@ -509,33 +642,37 @@ func file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_rawDescGZIP() []
 	return file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_rawDescData
 }
 
-var file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
 var file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_goTypes = []any{
-	(*Vertex)(nil),        // 0: errdefs.Vertex
-	(*Source)(nil),        // 1: errdefs.Source
-	(*Frontend)(nil),      // 2: errdefs.Frontend
-	(*FrontendCap)(nil),   // 3: errdefs.FrontendCap
-	(*Subrequest)(nil),    // 4: errdefs.Subrequest
-	(*Solve)(nil),         // 5: errdefs.Solve
-	(*FileAction)(nil),    // 6: errdefs.FileAction
-	(*ContentCache)(nil),  // 7: errdefs.ContentCache
-	nil,                   // 8: errdefs.Solve.DescriptionEntry
-	(*pb.SourceInfo)(nil), // 9: pb.SourceInfo
-	(*pb.Range)(nil),      // 10: pb.Range
-	(*pb.Op)(nil),         // 11: pb.Op
+	(ExitReasonType)(0),   // 0: errdefs.ExitReasonType
+	(*Vertex)(nil),        // 1: errdefs.Vertex
+	(*Source)(nil),        // 2: errdefs.Source
+	(*Frontend)(nil),      // 3: errdefs.Frontend
+	(*FrontendCap)(nil),   // 4: errdefs.FrontendCap
+	(*Subrequest)(nil),    // 5: errdefs.Subrequest
+	(*Solve)(nil),         // 6: errdefs.Solve
+	(*FileAction)(nil),    // 7: errdefs.FileAction
+	(*ContentCache)(nil),  // 8: errdefs.ContentCache
+	(*ExitReason)(nil),    // 9: errdefs.ExitReason
+	nil,                   // 10: errdefs.Solve.DescriptionEntry
+	(*pb.SourceInfo)(nil), // 11: pb.SourceInfo
+	(*pb.Range)(nil),      // 12: pb.Range
+	(*pb.Op)(nil),         // 13: pb.Op
 }
 var file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_depIdxs = []int32{
-	9,  // 0: errdefs.Source.info:type_name -> pb.SourceInfo
-	10, // 1: errdefs.Source.ranges:type_name -> pb.Range
-	11, // 2: errdefs.Solve.op:type_name -> pb.Op
-	6,  // 3: errdefs.Solve.file:type_name -> errdefs.FileAction
-	7,  // 4: errdefs.Solve.cache:type_name -> errdefs.ContentCache
-	8,  // 5: errdefs.Solve.description:type_name -> errdefs.Solve.DescriptionEntry
-	6,  // [6:6] is the sub-list for method output_type
-	6,  // [6:6] is the sub-list for method input_type
-	6,  // [6:6] is the sub-list for extension type_name
-	6,  // [6:6] is the sub-list for extension extendee
-	0,  // [0:6] is the sub-list for field type_name
+	11, // 0: errdefs.Source.info:type_name -> pb.SourceInfo
+	12, // 1: errdefs.Source.ranges:type_name -> pb.Range
+	13, // 2: errdefs.Solve.op:type_name -> pb.Op
+	7,  // 3: errdefs.Solve.file:type_name -> errdefs.FileAction
+	8,  // 4: errdefs.Solve.cache:type_name -> errdefs.ContentCache
+	10, // 5: errdefs.Solve.description:type_name -> errdefs.Solve.DescriptionEntry
+	0,  // 6: errdefs.ExitReason.type:type_name -> errdefs.ExitReasonType
+	7,  // [7:7] is the sub-list for method output_type
+	7,  // [7:7] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
 }
 
 func init() { file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_init() }
@@ -552,13 +689,14 @@ func file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_rawDesc), len(file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   9,
+			NumEnums:      1,
+			NumMessages:   10,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
 		GoTypes:           file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_goTypes,
 		DependencyIndexes: file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_depIdxs,
+		EnumInfos:         file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_enumTypes,
 		MessageInfos:      file_github_com_moby_buildkit_solver_errdefs_errdefs_proto_msgTypes,
 	}.Build()
 	File_github_com_moby_buildkit_solver_errdefs_errdefs_proto = out.File
@@ -0,0 +1,43 @@
+package errdefs
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ExitReasonFromError returns the exit reason of the first process exit
+// error in the chain of err, or nil if there is none or its reason is not
+// known.
+func ExitReasonFromError(err error) *ExitReason {
+	var e interface{ ExitReason() *ExitReason }
+	if errors.As(err, &e) {
+		return e.ExitReason()
+	}
+	return nil
+}
+
+// FormatExitReason returns a short human readable form of the exit reason,
+// e.g. "OOMKilled" or "Signaled(SIGSEGV)". Returns an empty string if the
+// reason is unknown.
+func FormatExitReason(r *ExitReason) string {
+	if r == nil {
+		return ""
+	}
+	switch r.Type {
+	case ExitReasonType_OOM_KILLED:
+		return "OOMKilled"
+	case ExitReasonType_SIGNALED:
+		name := r.SignalName
+		if name == "" {
+			name = strconv.Itoa(int(r.Signal))
+		}
+		return fmt.Sprintf("Signaled(%s)", name)
+	case ExitReasonType_TIMEOUT:
+		return "Timeout"
+	case ExitReasonType_CANCELLED:
+		return "Cancelled"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,26 @@
+package errdefs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatExitReason(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		reason *ExitReason
+		exp    string
+	}{
+		{nil, ""},
+		{&ExitReason{}, ""},
+		{&ExitReason{Type: ExitReasonType_OOM_KILLED}, "OOMKilled"},
+		{&ExitReason{Type: ExitReasonType_SIGNALED, Signal: 11, SignalName: "SIGSEGV"}, "Signaled(SIGSEGV)"},
+		{&ExitReason{Type: ExitReasonType_SIGNALED, Signal: 9}, "Signaled(9)"},
+		{&ExitReason{Type: ExitReasonType_TIMEOUT}, "Timeout"},
+		{&ExitReason{Type: ExitReasonType_CANCELLED}, "Cancelled"},
+	} {
+		require.Equal(t, tc.exp, FormatExitReason(tc.reason))
+	}
+}
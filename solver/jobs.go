@@ -1232,8 +1232,10 @@ func notifyStarted(ctx context.Context, v *client.Vertex, cached bool) func(err
 		v.Cached = cached
 		if err != nil {
 			v.Error = err.Error()
+			v.ExitReason = errdefs.ExitReasonFromError(err)
 		} else {
 			v.Error = ""
+			v.ExitReason = nil
 		}
 		pw.Write(id, *v)
 	}
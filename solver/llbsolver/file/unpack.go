@@ -2,27 +2,44 @@ package file
 
 import (
 	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"io"
+	"io/fs"
 	"os"
+	"path"
+	"path/filepath"
+	"strings"
 	"time"
 
-	"github.com/containerd/continuity/fs"
+	cfs "github.com/containerd/continuity/fs"
+	"github.com/moby/buildkit/solver/pb"
 	"github.com/moby/go-archive"
 	"github.com/moby/go-archive/chrootarchive"
 	"github.com/moby/go-archive/compression"
+	"github.com/moby/patternmatcher"
 	"github.com/moby/sys/user"
+	"github.com/pkg/errors"
 	copy "github.com/tonistiigi/fsutil/copy"
 )
 
-func unpack(srcRoot string, src string, destRoot string, dest string, ch copy.Chowner, u *copy.User, tm *time.Time, idmap *user.IdentityMapping) (bool, error) {
-	src, err := fs.RootPath(srcRoot, src)
+func unpack(srcRoot string, src string, destRoot string, dest string, ch copy.Chowner, u *copy.User, tm *time.Time, idmap *user.IdentityMapping, opt *pb.UnpackOpt) (bool, error) {
+	src, err := cfs.RootPath(srcRoot, src)
 	if err != nil {
 		return false, err
 	}
-	if !isArchivePath(src) {
+	isTar := isArchivePath(src)
+	isZip := !isTar && opt.GetZip() && isZipPath(src)
+	if !isTar && !isZip {
 		return false, nil
 	}
 
-	dest, err = fs.RootPath(destRoot, dest)
+	f, err := newEntryFilter(opt)
+	if err != nil {
+		return false, err
+	}
+
+	dest, err = cfs.RootPath(destRoot, dest)
 	if err != nil {
 		return false, err
 	}
@@ -30,11 +47,19 @@ func unpack(srcRoot string, src string, destRoot string, dest string, ch copy.Ch
 		return false, err
 	}
 
-	file, err := os.Open(src)
+	var rdr io.ReadCloser
+	switch {
+	case isZip:
+		rdr, err = zipToTar(src, f)
+	case f != nil:
+		rdr, err = filterTar(src, f)
+	default:
+		rdr, err = os.Open(src)
+	}
 	if err != nil {
 		return false, err
 	}
-	defer file.Close()
+	defer rdr.Close()
 
 	opts := &archive.TarOptions{
 		BestEffortXattrs: true,
@@ -48,7 +73,7 @@ func unpack(srcRoot string, src string, destRoot string, dest string, ch copy.Ch
 			GID: u.GID,
 		}
 	}
-	return true, chrootarchive.Untar(file, dest, opts)
+	return true, chrootarchive.Untar(rdr, dest, opts)
 }
 
 func isArchivePath(path string) bool {
@@ -73,3 +98,368 @@ func isArchivePath(path string) bool {
 	_, err = r.Next()
 	return err == nil
 }
+
+var (
+	zipMagic      = []byte("PK\x03\x04")
+	zipEmptyMagic = []byte("PK\x05\x06")
+)
+
+func isZipPath(path string) bool {
+	fi, err := os.Lstat(path)
+	if err != nil || !fi.Mode().IsRegular() {
+		return false
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	b := make([]byte, 4)
+	if _, err := io.ReadFull(file, b); err != nil {
+		return false
+	}
+	if !bytes.Equal(b, zipMagic) && !bytes.Equal(b, zipEmptyMagic) {
+		return false
+	}
+	_, err = zip.NewReader(file, fi.Size())
+	return err == nil
+}
+
+// entryFilter strips leading path components from archive entries and skips
+// the entries not matching the include patterns.
+type entryFilter struct {
+	strip int
+	pm    *patternmatcher.PatternMatcher
+}
+
+// newEntryFilter returns nil if the archive entries don't need to be
+// filtered.
+func newEntryFilter(opt *pb.UnpackOpt) (*entryFilter, error) {
+	if opt.GetStripComponents() < 0 {
+		return nil, errors.Errorf("invalid strip components %d", opt.GetStripComponents())
+	}
+	if opt.GetStripComponents() == 0 && len(opt.GetIncludePatterns()) == 0 {
+		return nil, nil
+	}
+	f := &entryFilter{strip: int(opt.GetStripComponents())}
+	if len(opt.GetIncludePatterns()) > 0 {
+		pm, err := patternmatcher.New(opt.GetIncludePatterns())
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid unpack include patterns")
+		}
+		f.pm = pm
+	}
+	return f, nil
+}
+
+// name returns the name of the entry after stripping, or false if the entry
+// is skipped.
+func (f *entryFilter) name(name string) (string, bool, error) {
+	name = cleanName(name)
+	if name == "" {
+		return "", false, nil
+	}
+	if f != nil && f.strip > 0 {
+		parts := strings.SplitN(name, "/", f.strip+1)
+		if len(parts) <= f.strip {
+			return "", false, nil
+		}
+		name = parts[f.strip]
+	}
+	if f != nil && f.pm != nil {
+		ok, err := f.pm.MatchesOrParentMatches(filepath.FromSlash(name))
+		if err != nil || !ok {
+			return "", false, err
+		}
+	}
+	return name, true, nil
+}
+
+// cleanName returns the archive entry name as a relative path.
+func cleanName(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+// header renames the tar header with the filter, or returns false if the
+// entry is skipped. The target of a hardlink is renamed as well, unless the
+// target is skipped, which is returned as targetSkipped.
+func (f *entryFilter) header(hdr *tar.Header) (ok bool, targetSkipped bool, err error) {
+	name, ok, err := f.name(hdr.Name)
+	if err != nil || !ok {
+		return false, false, err
+	}
+	hdr.Name = name
+	if hdr.Typeflag == tar.TypeLink {
+		link, ok, err := f.name(hdr.Linkname)
+		if err != nil {
+			return false, false, err
+		}
+		if !ok {
+			return true, true, nil
+		}
+		hdr.Linkname = link
+	}
+	return true, false, nil
+}
+
+// skippedTarget is a skipped regular file that extracted hardlinks point to.
+// Its content is kept in a temporary file so that the first of the links can
+// be written as a regular file instead.
+type skippedTarget struct {
+	hdr  *tar.Header
+	file *os.File
+	// name is the entry the content was written to
+	name string
+}
+
+func (t *skippedTarget) close() {
+	if t.file != nil {
+		t.file.Close()
+		os.Remove(t.file.Name())
+	}
+}
+
+// store keeps the content of the skipped target.
+func (t *skippedTarget) store(hdr *tar.Header, r io.Reader) error {
+	// a later entry with the same name replaces the earlier one
+	t.close()
+	file, err := os.CreateTemp("", "buildkit-unpack-")
+	if err != nil {
+		return err
+	}
+	t.hdr = hdr
+	t.file = file
+	_, err = io.Copy(file, r)
+	return err
+}
+
+// write writes the skipped target as a regular file named name.
+func (t *skippedTarget) write(tw *tar.Writer, name string) error {
+	if _, err := t.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	hdr := *t.hdr
+	hdr.Name = name
+	if err := tw.WriteHeader(&hdr); err != nil {
+		return err
+	}
+	if _, err := io.Copy(tw, t.file); err != nil {
+		return err
+	}
+	t.name = name
+	return nil
+}
+
+// archiveReader reads the decompressed archive and closes the file with it.
+type archiveReader struct {
+	io.ReadCloser
+	file *os.File
+}
+
+func openArchive(p string) (*archiveReader, error) {
+	file, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	dr, err := compression.DecompressStream(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &archiveReader{ReadCloser: dr, file: file}, nil
+}
+
+func (r *archiveReader) Close() error {
+	r.ReadCloser.Close()
+	return r.file.Close()
+}
+
+// skippedTargets returns the skipped entries of the archive at p that
+// extracted hardlinks point to, by their original name.
+func skippedTargets(p string, f *entryFilter) (map[string]*skippedTarget, error) {
+	r, err := openArchive(p)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	targets := map[string]*skippedTarget{}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return targets, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeLink {
+			continue
+		}
+		target := cleanName(hdr.Linkname)
+		if ok, skipped, err := f.header(hdr); err != nil {
+			return nil, err
+		} else if ok && skipped {
+			targets[target] = &skippedTarget{}
+		}
+	}
+}
+
+// filterTar returns an uncompressed tar stream of the archive at p with the
+// filter applied to its entries. Hardlinks to skipped files are written as
+// regular files with the content of their target, the archive is read twice
+// to find these targets.
+func filterTar(p string, f *entryFilter) (io.ReadCloser, error) {
+	targets, err := skippedTargets(p, f)
+	if err != nil {
+		return nil, err
+	}
+	r, err := openArchive(p)
+	if err != nil {
+		return nil, err
+	}
+	return newTarPipe(func(tw *tar.Writer) error {
+		defer r.Close()
+		defer func() {
+			for _, t := range targets {
+				t.close()
+			}
+		}()
+		tr := tar.NewReader(r)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			orig := cleanName(hdr.Name)
+			linkname := hdr.Linkname
+			ok, targetSkipped, err := f.header(hdr)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				if t := targets[orig]; t != nil && hdr.Typeflag == tar.TypeReg {
+					if err := t.store(hdr, tr); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			if targetSkipped {
+				t := targets[cleanName(linkname)]
+				if t == nil || t.file == nil {
+					return errors.Errorf("hardlink %s points to %s, which is not a regular file before it in the archive", orig, linkname)
+				}
+				if t.name != "" {
+					// link to the first copy of the content
+					hdr.Linkname = t.name
+				} else {
+					if err := t.write(tw, hdr.Name); err != nil {
+						return err
+					}
+					continue
+				}
+			}
+			if err := tw.WriteHeader(hdr); err != nil {
+				return err
+			}
+			if _, err := io.Copy(tw, tr); err != nil {
+				return err
+			}
+		}
+	}), nil
+}
+
+// zipToTar returns an uncompressed tar stream of the zip archive at p with
+// the filter applied to its entries, so that it can be unpacked the same way
+// as tar archives.
+func zipToTar(p string, f *entryFilter) (io.ReadCloser, error) {
+	zr, err := zip.OpenReader(p)
+	if err != nil {
+		return nil, err
+	}
+	return newTarPipe(func(tw *tar.Writer) error {
+		defer zr.Close()
+		for _, zf := range zr.File {
+			name, ok, err := f.name(zf.Name)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			if err := writeZipEntry(tw, zf, name); err != nil {
+				return errors.Wrapf(err, "failed to unpack %s", zf.Name)
+			}
+		}
+		return nil
+	}), nil
+}
+
+func writeZipEntry(tw *tar.Writer, zf *zip.File, name string) error {
+	fi := zf.FileInfo()
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    int64(fi.Mode().Perm()),
+		ModTime: zf.Modified,
+		Format:  tar.FormatPAX,
+	}
+	if hdr.Mode == 0 {
+		// archives created on Windows don't have unix permissions
+		hdr.Mode = 0644
+		if fi.IsDir() {
+			hdr.Mode = 0755
+		}
+	}
+
+	switch {
+	case fi.IsDir():
+		hdr.Typeflag = tar.TypeDir
+		return tw.WriteHeader(hdr)
+	case fi.Mode()&fs.ModeSymlink != 0:
+		r, err := zf.Open()
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		target, err := io.ReadAll(io.LimitReader(r, 4096))
+		if err != nil {
+			return err
+		}
+		hdr.Typeflag = tar.TypeSymlink
+		hdr.Linkname = string(target)
+		return tw.WriteHeader(hdr)
+	case fi.Mode().IsRegular():
+		r, err := zf.Open()
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		hdr.Typeflag = tar.TypeReg
+		hdr.Size = int64(zf.UncompressedSize64)
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err = io.Copy(tw, r)
+		return err
+	default:
+		// other file types can't be represented in zip archives
+		return nil
+	}
+}
+
+// newTarPipe returns a reader for the tar stream written by fn.
+func newTarPipe(fn func(*tar.Writer) error) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		tw := tar.NewWriter(pw)
+		err := fn(tw)
+		if err == nil {
+			err = tw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr
+}
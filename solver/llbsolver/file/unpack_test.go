@@ -0,0 +1,263 @@
+package file
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/stretchr/testify/require"
+)
+
+type archiveEntry struct {
+	name    string
+	content string
+	link    string
+}
+
+func writeTarGz(t *testing.T, p string, entries []archiveEntry) {
+	buf := &bytes.Buffer{}
+	gw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gw)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: 0644, Typeflag: tar.TypeReg, Size: int64(len(e.content))}
+		switch {
+		case e.link != "":
+			hdr.Typeflag = tar.TypeLink
+			hdr.Linkname = e.link
+			hdr.Size = 0
+		case e.name[len(e.name)-1] == '/':
+			hdr.Typeflag = tar.TypeDir
+			hdr.Mode = 0755
+		}
+		require.NoError(t, tw.WriteHeader(hdr))
+		if hdr.Typeflag == tar.TypeReg {
+			_, err := tw.Write([]byte(e.content))
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+	require.NoError(t, os.WriteFile(p, buf.Bytes(), 0644))
+}
+
+func writeZip(t *testing.T, p string, entries []archiveEntry) {
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	for _, e := range entries {
+		fh := &zip.FileHeader{Name: e.name, Method: zip.Deflate}
+		switch {
+		case e.link != "":
+			fh.SetMode(os.ModeSymlink | 0777)
+			e.content = e.link
+		case e.name[len(e.name)-1] == '/':
+			fh.SetMode(os.ModeDir | 0755)
+		default:
+			fh.SetMode(0640)
+		}
+		w, err := zw.CreateHeader(fh)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(e.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	require.NoError(t, os.WriteFile(p, buf.Bytes(), 0644))
+}
+
+func listFiles(t *testing.T, root string) map[string]string {
+	files := map[string]string{}
+	err := filepath.Walk(root, func(p string, fi os.FileInfo, err error) error {
+		if err != nil || p == root {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		switch {
+		case fi.Mode()&os.ModeSymlink != 0:
+			target, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+			files[rel] = "-> " + target
+		case fi.IsDir():
+			files[rel+"/"] = ""
+		default:
+			dt, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			files[rel] = string(dt)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	return files
+}
+
+func TestUnpackZip(t *testing.T) {
+	src := t.TempDir()
+	writeZip(t, filepath.Join(src, "a.zip"), []archiveEntry{
+		{name: "dir/"},
+		{name: "dir/foo", content: "foo"},
+		{name: "dir/link", link: "foo"},
+	})
+
+	// zip archives are only unpacked when requested
+	dest := t.TempDir()
+	ok, err := unpack(src, "a.zip", dest, "/out", nil, nil, nil, nil, nil)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	ok, err = unpack(src, "a.zip", dest, "/out", nil, nil, nil, nil, &pb.UnpackOpt{Zip: true})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, map[string]string{
+		"dir/":     "",
+		"dir/foo":  "foo",
+		"dir/link": "-> foo",
+	}, listFiles(t, filepath.Join(dest, "out")))
+
+	fi, err := os.Stat(filepath.Join(dest, "out/dir/foo"))
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0640), fi.Mode().Perm())
+}
+
+func TestUnpackStripComponents(t *testing.T) {
+	entries := []archiveEntry{
+		{name: "top"},
+		{name: "pkg-1.0/"},
+		{name: "pkg-1.0/bin/"},
+		{name: "pkg-1.0/bin/tool", content: "tool"},
+		{name: "pkg-1.0/README", content: "readme"},
+		{name: "pkg-1.0/bin/tool2", link: "pkg-1.0/bin/tool"},
+	}
+	src := t.TempDir()
+	writeTarGz(t, filepath.Join(src, "a.tar.gz"), entries)
+	writeZip(t, filepath.Join(src, "a.zip"), entries[:5])
+
+	expected := map[string]string{
+		"bin/":     "",
+		"bin/tool": "tool",
+		"README":   "readme",
+	}
+
+	dest := t.TempDir()
+	ok, err := unpack(src, "a.tar.gz", dest, "/tar", nil, nil, nil, nil, &pb.UnpackOpt{StripComponents: 1})
+	require.NoError(t, err)
+	require.True(t, ok)
+	expectedTar := map[string]string{"bin/tool2": "tool"}
+	for k, v := range expected {
+		expectedTar[k] = v
+	}
+	require.Equal(t, expectedTar, listFiles(t, filepath.Join(dest, "tar")))
+
+	ok, err = unpack(src, "a.zip", dest, "/zip", nil, nil, nil, nil, &pb.UnpackOpt{Zip: true, StripComponents: 1})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, expected, listFiles(t, filepath.Join(dest, "zip")))
+
+	_, err = unpack(src, "a.tar.gz", dest, "/invalid", nil, nil, nil, nil, &pb.UnpackOpt{StripComponents: -1})
+	require.ErrorContains(t, err, "invalid strip components")
+}
+
+func TestUnpackIncludePatterns(t *testing.T) {
+	entries := []archiveEntry{
+		{name: "bin/"},
+		{name: "bin/tool", content: "tool"},
+		{name: "lib/"},
+		{name: "lib/a.so", content: "a"},
+		{name: "lib/a.txt", content: "txt"},
+		{name: "docs/README", content: "readme"},
+		{name: "docs/tool", link: "bin/tool"},
+	}
+	src := t.TempDir()
+	writeTarGz(t, filepath.Join(src, "a.tar.gz"), entries)
+	writeZip(t, filepath.Join(src, "a.zip"), entries)
+
+	opt := &pb.UnpackOpt{
+		Zip:             true,
+		IncludePatterns: []string{"bin", "lib/*.so", "docs/tool"},
+	}
+
+	dest := t.TempDir()
+	ok, err := unpack(src, "a.tar.gz", dest, "/tar", nil, nil, nil, nil, opt)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, map[string]string{
+		"bin/":      "",
+		"bin/tool":  "tool",
+		"lib/":      "",
+		"lib/a.so":  "a",
+		"docs/":     "",
+		"docs/tool": "tool",
+	}, listFiles(t, filepath.Join(dest, "tar")))
+
+	ok, err = unpack(src, "a.zip", dest, "/zip", nil, nil, nil, nil, opt)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, map[string]string{
+		"bin/":      "",
+		"bin/tool":  "tool",
+		"lib/":      "",
+		"lib/a.so":  "a",
+		"docs/":     "",
+		"docs/tool": "-> bin/tool",
+	}, listFiles(t, filepath.Join(dest, "zip")))
+}
+
+func TestUnpackSkippedHardlinkTarget(t *testing.T) {
+	src := t.TempDir()
+	writeTarGz(t, filepath.Join(src, "a.tar.gz"), []archiveEntry{
+		{name: "pkg/lib/"},
+		{name: "pkg/lib/tool", content: "tool"},
+		{name: "pkg/bin/"},
+		{name: "pkg/bin/tool", link: "pkg/lib/tool"},
+		{name: "pkg/bin/tool2", link: "pkg/lib/tool"},
+		{name: "pkg/top", content: "top"},
+		{name: "pkg/bin/top", link: "pkg/top"},
+	})
+
+	// links to skipped files are written as regular files
+	dest := t.TempDir()
+	ok, err := unpack(src, "a.tar.gz", dest, "/out", nil, nil, nil, nil, &pb.UnpackOpt{IncludePatterns: []string{"pkg/bin"}})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, map[string]string{
+		"pkg/":          "",
+		"pkg/bin/":      "",
+		"pkg/bin/tool":  "tool",
+		"pkg/bin/tool2": "tool",
+		"pkg/bin/top":   "top",
+	}, listFiles(t, filepath.Join(dest, "out")))
+
+	// later links still share the content with the first one
+	fi1, err := os.Stat(filepath.Join(dest, "out/pkg/bin/tool"))
+	require.NoError(t, err)
+	fi2, err := os.Stat(filepath.Join(dest, "out/pkg/bin/tool2"))
+	require.NoError(t, err)
+	require.True(t, os.SameFile(fi1, fi2))
+
+	// same for targets removed by stripping
+	writeTarGz(t, filepath.Join(src, "b.tar.gz"), []archiveEntry{
+		{name: "pkg/top", content: "top"},
+		{name: "pkg/bin/top", link: "pkg/top"},
+	})
+	ok, err = unpack(src, "b.tar.gz", dest, "/strip", nil, nil, nil, nil, &pb.UnpackOpt{StripComponents: 2})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, map[string]string{"top": "top"}, listFiles(t, filepath.Join(dest, "strip")))
+
+	// the target has to be a file before the link
+	writeTarGz(t, filepath.Join(src, "c.tar.gz"), []archiveEntry{
+		{name: "bin/tool", link: "lib/tool"},
+		{name: "lib/tool", content: "tool"},
+	})
+	_, err = unpack(src, "c.tar.gz", dest, "/invalid", nil, nil, nil, nil, &pb.UnpackOpt{IncludePatterns: []string{"bin"}})
+	require.ErrorContains(t, err, "hardlink bin/tool points to lib/tool")
+}
@@ -10,12 +10,15 @@ import (
 	"runtime"
 	"slices"
 	"strings"
+	"sync"
 
 	"github.com/containerd/platforms"
 	"github.com/moby/buildkit/cache"
+	"github.com/moby/buildkit/client"
 	"github.com/moby/buildkit/executor"
 	resourcestypes "github.com/moby/buildkit/executor/resources/types"
 	"github.com/moby/buildkit/frontend/gateway/container"
+	"github.com/moby/buildkit/identity"
 	"github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/session/secrets"
 	"github.com/moby/buildkit/solver"
@@ -24,6 +27,7 @@ import (
 	"github.com/moby/buildkit/solver/llbsolver/ops/opsutils"
 	"github.com/moby/buildkit/solver/pb"
 	"github.com/moby/buildkit/util/cachedigest"
+	"github.com/moby/buildkit/util/progress"
 	"github.com/moby/buildkit/util/progress/logs"
 	utilsystem "github.com/moby/buildkit/util/system"
 	"github.com/moby/buildkit/worker"
@@ -123,6 +127,9 @@ func (e *ExecOp) CacheMap(ctx context.Context, g session.Group, index int) (*sol
 		}
 	}
 	op.Meta.ProxyEnv = nil
+	// resource limits and the access log don't change the result of the exec
+	op.ResourceLimits = nil
+	op.NetworkAccessLog = false
 
 	var p ocispecs.Platform
 	if e.platform != nil {
@@ -455,8 +462,10 @@ func (e *ExecOp) Exec(ctx context.Context, g session.Group, inputs []solver.Resu
 		Ulimit:                    e.op.Meta.Ulimit,
 		CDIDevices:                e.op.CdiDevices,
 		CgroupParent:              e.op.Meta.CgroupParent,
+		ResourceLimits:            e.op.ResourceLimits,
 		NetMode:                   e.op.Network,
 		SecurityMode:              e.op.Security,
+		NetworkAccessLog:          e.op.NetworkAccessLog,
 		RemoveMountStubsRecursive: e.op.Meta.RemoveMountStubsRecursive,
 	}
 
@@ -494,11 +503,15 @@ func (e *ExecOp) Exec(ctx context.Context, g session.Group, inputs []solver.Resu
 		}
 	}()
 
+	stats, closeStats := newStatsWriter(ctx)
+	defer closeStats()
+
 	rec, execErr := e.exec.Run(ctx, "", p.Root, p.Mounts, executor.ProcessInfo{
 		Meta:   meta,
 		Stdin:  nil,
 		Stdout: stdout,
 		Stderr: stderr,
+		Stats:  stats,
 	}, nil)
 
 	for i, out := range p.OutputRefs {
@@ -518,6 +531,61 @@ func (e *ExecOp) Exec(ctx context.Context, g session.Group, inputs []solver.Resu
 	return results, errors.Wrapf(execErr, "process %q did not complete successfully", strings.Join(e.op.Meta.Args, " "))
 }
 
+// newStatsWriter returns a callback that forwards live resource samples of
+// the running process to the progress stream of the current vertex.
+func newStatsWriter(ctx context.Context) (func(*resourcestypes.Sample), func()) {
+	pw, _, _ := progress.NewFromContext(ctx)
+	id := identity.NewID()
+	var mu sync.Mutex
+	closed := false
+	return func(s *resourcestypes.Sample) {
+			mu.Lock()
+			defer mu.Unlock()
+			if closed {
+				return
+			}
+			pw.Write(id, vertexStats(s))
+		}, func() {
+			mu.Lock()
+			defer mu.Unlock()
+			closed = true
+			pw.Close()
+		}
+}
+
+func vertexStats(s *resourcestypes.Sample) client.VertexStats {
+	st := client.VertexStats{
+		Timestamp: s.Timestamp(),
+	}
+	if s.CPUStat != nil && s.CPUStat.UsageNanos != nil {
+		st.CPUNanos = *s.CPUStat.UsageNanos
+	}
+	if m := s.MemoryStat; m != nil {
+		if m.Anon != nil {
+			st.MemoryBytes = *m.Anon
+		}
+		if m.Peak != nil {
+			st.MemoryPeakBytes = *m.Peak
+		}
+	}
+	if ios := s.IOStat; ios != nil {
+		if ios.ReadBytes != nil {
+			st.IOReadBytes = *ios.ReadBytes
+		}
+		if ios.WriteBytes != nil {
+			st.IOWriteBytes = *ios.WriteBytes
+		}
+	}
+	if s.PIDsStat != nil && s.PIDsStat.Current != nil {
+		st.PIDs = *s.PIDsStat.Current
+	}
+	if s.NetStat != nil {
+		st.NetRxBytes = s.NetStat.RxBytes
+		st.NetTxBytes = s.NetStat.TxBytes
+	}
+	return st
+}
+
 func proxyEnvList(p *pb.ProxyEnv) []string {
 	out := []string{}
 	if v := p.HttpProxy; v != "" {
@@ -39,6 +39,11 @@ func Validate(op *pb.Op) error {
 		if !isRoot {
 			return errors.Errorf("invalid exec op with no rootfs")
 		}
+		if rl := op.Exec.ResourceLimits; rl != nil {
+			if rl.Memory < 0 || rl.NanoCPUs < 0 || rl.Pids < 0 {
+				return errors.Errorf("invalid exec op with negative resource limits")
+			}
+		}
 	case *pb.Op_File:
 		if op.File == nil {
 			return errors.Errorf("invalid nil file op")
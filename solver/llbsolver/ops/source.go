@@ -52,6 +52,8 @@ func NewSourceOp(vtx solver.Vertex, op *pb.Op_Source, platform *pb.Platform, sm
 func (s *SourceOp) IsProvenanceProvider() {}
 
 func (s *SourceOp) Pin() (source.Identifier, string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	return s.id, s.pin
 }
 
@@ -85,9 +87,16 @@ func (s *SourceOp) CacheMap(ctx context.Context, g session.Group, index int) (*s
 		return nil, false, err
 	}
 
+	s.mu.Lock()
 	if s.pin == "" {
 		s.pin = pin
+		if pi, ok := src.(source.PinnedIdentifier); ok {
+			if id := pi.PinnedIdentifier(); id != nil {
+				s.id = id
+			}
+		}
 	}
+	s.mu.Unlock()
 
 	dgst, err := cachedigest.FromBytes([]byte(sourceCacheType+":"+k), cachedigest.TypeString)
 	if err != nil {
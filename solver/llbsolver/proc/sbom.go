@@ -2,21 +2,29 @@ package proc
 
 import (
 	"context"
+	"time"
 
+	cacheutil "github.com/moby/buildkit/cache/util"
 	"github.com/moby/buildkit/client/llb"
 	"github.com/moby/buildkit/client/llb/sourceresolver"
 	"github.com/moby/buildkit/executor/resources"
 	"github.com/moby/buildkit/exporter/containerimage/exptypes"
 	"github.com/moby/buildkit/frontend"
 	"github.com/moby/buildkit/frontend/attestations/sbom"
+	"github.com/moby/buildkit/frontend/attestations/sbom/catalog"
+	gwclient "github.com/moby/buildkit/frontend/gateway/client"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/snapshot"
 	"github.com/moby/buildkit/solver"
 	"github.com/moby/buildkit/solver/llbsolver"
 	"github.com/moby/buildkit/solver/result"
 	"github.com/moby/buildkit/util/tracing"
+	"github.com/moby/buildkit/worker"
 	"github.com/pkg/errors"
+	fstypes "github.com/tonistiigi/fsutil/types"
 )
 
-func SBOMProcessor(scannerRef string, useCache bool, resolveMode string, params map[string]string) llbsolver.Processor {
+func SBOMProcessor(scannerRef string, useCache bool, resolveMode string, params map[string]string, epoch *time.Time) llbsolver.Processor {
 	return func(ctx context.Context, res *llbsolver.Result, s *llbsolver.Solver, j *solver.Job, usage *resources.SysSampler) (*llbsolver.Result, error) {
 		// skip sbom generation if we already have an sbom
 		if sbom.HasSBOM(res.Result) {
@@ -31,13 +39,36 @@ func SBOMProcessor(scannerRef string, useCache bool, resolveMode string, params
 			return nil, err
 		}
 
-		scanner, err := sbom.CreateSBOMScanner(ctx, s.Bridge(j), scannerRef, sourceresolver.Opt{
-			ImageOpt: &sourceresolver.ResolveImageOpt{
-				ResolveMode: resolveMode,
-			},
-		}, params)
-		if err != nil {
-			return nil, err
+		var scanner sbom.Scanner
+		if scannerRef == sbom.BuiltinGenerator {
+			scanner, err = sbom.CreateBuiltinSBOMScanner(func(ctx context.Context, st llb.State) (catalog.FS, error) {
+				def, err := st.Marshal(ctx)
+				if err != nil {
+					return nil, err
+				}
+				r, err := s.Bridge(j).Solve(ctx, frontend.SolveRequest{
+					Definition: def.ToPB(),
+				}, j.SessionID)
+				if err != nil {
+					return nil, err
+				}
+				if r.Ref == nil {
+					return nil, nil
+				}
+				return &resultFS{ref: r.Ref, sid: j.SessionID}, nil
+			}, params[sbom.FormatParam], epoch)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			scanner, err = sbom.CreateSBOMScanner(ctx, s.Bridge(j), scannerRef, sourceresolver.Opt{
+				ImageOpt: &sourceresolver.ResolveImageOpt{
+					ResolveMode: resolveMode,
+				},
+			}, params)
+			if err != nil {
+				return nil, err
+			}
 		}
 		if scanner == nil {
 			return res, nil
@@ -88,3 +119,52 @@ func SBOMProcessor(scannerRef string, useCache bool, resolveMode string, params
 		return res, nil
 	}
 }
+
+// resultFS gives the builtin SBOM generator read access to a solved result.
+type resultFS struct {
+	ref solver.ResultProxy
+	sid string
+}
+
+func (fs *resultFS) mount(ctx context.Context) (snapshot.Mountable, error) {
+	r, err := fs.ref.Result(ctx)
+	if err != nil {
+		return nil, err
+	}
+	workerRef, ok := r.Sys().(*worker.WorkerRef)
+	if !ok {
+		return nil, errors.Errorf("invalid ref: %T", r.Sys())
+	}
+	if workerRef.ImmutableRef == nil {
+		return nil, nil
+	}
+	return workerRef.ImmutableRef.Mount(ctx, true, session.NewGroup(fs.sid))
+}
+
+func (fs *resultFS) ReadFile(ctx context.Context, req gwclient.ReadRequest) ([]byte, error) {
+	m, err := fs.mount(ctx)
+	if err != nil {
+		return nil, err
+	}
+	newReq := cacheutil.ReadRequest{
+		Filename: req.Filename,
+	}
+	if r := req.Range; r != nil {
+		newReq.Range = &cacheutil.FileRange{
+			Offset: r.Offset,
+			Length: r.Length,
+		}
+	}
+	return cacheutil.ReadFile(ctx, m, newReq)
+}
+
+func (fs *resultFS) ReadDir(ctx context.Context, req gwclient.ReadDirRequest) ([]*fstypes.Stat, error) {
+	m, err := fs.mount(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return cacheutil.ReadDir(ctx, m, cacheutil.ReadDirRequest{
+		Path:           req.Path,
+		IncludePattern: req.IncludePattern,
+	})
+}
@@ -0,0 +1,111 @@
+package proc
+
+import (
+	"context"
+
+	"github.com/moby/buildkit/client/llb"
+	"github.com/moby/buildkit/client/llb/sourceresolver"
+	"github.com/moby/buildkit/executor/resources"
+	"github.com/moby/buildkit/exporter/containerimage/exptypes"
+	"github.com/moby/buildkit/frontend"
+	"github.com/moby/buildkit/frontend/attestations/vuln"
+	"github.com/moby/buildkit/solver"
+	"github.com/moby/buildkit/solver/llbsolver"
+	"github.com/moby/buildkit/solver/result"
+	"github.com/moby/buildkit/util/tracing"
+	"github.com/pkg/errors"
+)
+
+func VulnProcessor(scannerRef string, useCache bool, resolveMode string, params map[string]string) llbsolver.Processor {
+	return func(ctx context.Context, res *llbsolver.Result, s *llbsolver.Solver, j *solver.Job, usage *resources.SysSampler) (*llbsolver.Result, error) {
+		// skip vulnerability scanning if we already have a scan result
+		if vuln.HasVuln(res.Result) {
+			return res, nil
+		}
+
+		span, ctx := tracing.StartSpan(ctx, "create vulnerability attestation")
+		defer span.End()
+
+		ps, err := exptypes.ParsePlatforms(res.Metadata)
+		if err != nil {
+			return nil, err
+		}
+
+		scanner, err := vuln.CreateVulnScanner(ctx, s.Bridge(j), scannerRef, sourceresolver.Opt{
+			ImageOpt: &sourceresolver.ResolveImageOpt{
+				ResolveMode: resolveMode,
+			},
+		}, params)
+		if err != nil {
+			return nil, err
+		}
+		if scanner == nil {
+			return res, nil
+		}
+
+		for _, p := range ps.Platforms {
+			ref, ok := res.FindRef(p.ID)
+			if !ok {
+				return nil, errors.Errorf("could not find ref %s", p.ID)
+			}
+			if ref == nil {
+				continue
+			}
+			sbomAtt, ok := vuln.FindSBOM(res.Attestations[p.ID])
+			if !ok || sbomAtt.Ref == nil {
+				return nil, errors.Errorf("no sbom found to scan for vulnerabilities for %s", p.ID)
+			}
+
+			st, err := resultState(ref)
+			if err != nil {
+				return nil, err
+			}
+			sbom, err := result.ConvertAttestation(&sbomAtt, func(ref solver.ResultProxy) (*llb.State, error) {
+				st, err := resultState(ref)
+				if err != nil {
+					return nil, err
+				}
+				return &st, nil
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			var opts []llb.ConstraintsOpt
+			if !useCache {
+				opts = append(opts, llb.IgnoreCache)
+			}
+			att, err := scanner(ctx, p.ID, st, *sbom, opts...)
+			if err != nil {
+				return nil, err
+			}
+			attSolve, err := result.ConvertAttestation(&att, func(st *llb.State) (solver.ResultProxy, error) {
+				def, err := st.Marshal(ctx)
+				if err != nil {
+					return nil, err
+				}
+
+				r, err := s.Bridge(j).Solve(ctx, frontend.SolveRequest{
+					Definition: def.ToPB(),
+				}, j.SessionID)
+				if err != nil {
+					return nil, err
+				}
+				return r.Ref, nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			res.AddAttestation(p.ID, *attSolve)
+		}
+		return res, nil
+	}
+}
+
+func resultState(ref solver.ResultProxy) (llb.State, error) {
+	defop, err := llb.NewDefinitionOp(ref.Definition())
+	if err != nil {
+		return llb.State{}, err
+	}
+	return llb.NewState(defop), nil
+}
@@ -1,8 +1,11 @@
 package llbsolver
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"maps"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -318,6 +321,12 @@ func captureProvenance(ctx context.Context, res solver.CachedResultWithProvenanc
 				return err
 			}
 			if samples != nil {
+				if samples.NetworkAccess != nil {
+					c.AddNetworkAccessLog(op.Digest(), samples.NetworkAccess)
+					s := *samples
+					s.NetworkAccess = nil
+					samples = &s
+				}
 				c.AddSamples(op.Digest(), samples)
 			}
 		case *ops.BuildOp:
@@ -381,6 +390,7 @@ func NewProvenanceCreator(ctx context.Context, slsaVersion provenancetypes.Prove
 	pr.Builder.ID = attrs["builder-id"]
 
 	var addLayers func(context.Context) error
+	var stepIndexes map[digest.Digest]int
 
 	switch mode {
 	case "min":
@@ -400,6 +410,7 @@ func NewProvenanceCreator(ctx context.Context, slsaVersion provenancetypes.Prove
 		if err != nil {
 			return nil, err
 		}
+		stepIndexes = dgsts
 
 		r, err := res.Result(ctx)
 		if err != nil {
@@ -451,6 +462,8 @@ func NewProvenanceCreator(ctx context.Context, slsaVersion provenancetypes.Prove
 		return nil, errors.Errorf("invalid mode %q", mode)
 	}
 
+	pr.Metadata.BuildKitMetadata.NetworkAccess = networkAccess(cp, stepIndexes)
+
 	pc := &ProvenanceCreator{
 		pr:          pr,
 		slsaVersion: slsaVersion,
@@ -463,6 +476,42 @@ func NewProvenanceCreator(ctx context.Context, slsaVersion provenancetypes.Prove
 	return pc, nil
 }
 
+// networkAccess summarizes the network access logs of the captured steps.
+// If indexes are set, the entries are ordered and labeled by their step in
+// the build config.
+func networkAccess(c *provenance.Capture, indexes map[digest.Digest]int) []provenancetypes.NetworkAccess {
+	dgsts := slices.Collect(maps.Keys(c.NetworkAccessLogs))
+	slices.SortFunc(dgsts, func(a, b digest.Digest) int {
+		ia, oka := indexes[a]
+		ib, okb := indexes[b]
+		if oka && okb {
+			return cmp.Compare(ia, ib)
+		}
+		if oka != okb {
+			if oka {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(a, b)
+	})
+
+	var out []provenancetypes.NetworkAccess
+	for _, dgst := range dgsts {
+		l := c.NetworkAccessLogs[dgst]
+		na := provenancetypes.NetworkAccess{
+			DNS:         l.DNS,
+			Connections: l.Connections,
+			Truncated:   l.Truncated,
+		}
+		if idx, ok := indexes[dgst]; ok {
+			na.Step = fmt.Sprintf("step%d", idx)
+		}
+		out = append(out, na)
+	}
+	return out
+}
+
 func (p *ProvenanceCreator) PredicateType() string {
 	if p.slsaVersion == provenancetypes.ProvenanceSLSA1 {
 		return slsa1.PredicateSLSAProvenance
@@ -23,6 +23,7 @@ type Capture struct {
 	NetworkAccess       bool
 	IncompleteMaterials bool
 	Samples             map[digest.Digest]*resourcestypes.Samples
+	NetworkAccessLogs   map[digest.Digest]*resourcestypes.NetworkAccessLog
 }
 
 func (c *Capture) Merge(c2 *Capture) error {
@@ -32,6 +33,9 @@ func (c *Capture) Merge(c2 *Capture) error {
 	for _, i := range c2.Sources.Images {
 		c.AddImage(i)
 	}
+	for _, a := range c2.Sources.Artifacts {
+		c.AddArtifact(a)
+	}
 	for _, l := range c2.Sources.Local {
 		c.AddLocal(l)
 	}
@@ -41,6 +45,9 @@ func (c *Capture) Merge(c2 *Capture) error {
 	for _, h := range c2.Sources.HTTP {
 		c.AddHTTP(h)
 	}
+	for _, e := range c2.Sources.External {
+		c.AddExternal(e)
+	}
 	for _, s := range c2.Secrets {
 		c.AddSecret(s)
 	}
@@ -53,6 +60,9 @@ func (c *Capture) Merge(c2 *Capture) error {
 	if c2.IncompleteMaterials {
 		c.IncompleteMaterials = true
 	}
+	for dgst, l := range c2.NetworkAccessLogs {
+		c.AddNetworkAccessLog(dgst, l)
+	}
 	return nil
 }
 
@@ -60,6 +70,9 @@ func (c *Capture) Sort() {
 	slices.SortFunc(c.Sources.Images, func(a, b provenancetypes.ImageSource) int {
 		return cmp.Compare(a.Ref, b.Ref)
 	})
+	slices.SortFunc(c.Sources.Artifacts, func(a, b provenancetypes.ArtifactSource) int {
+		return cmp.Compare(a.Ref, b.Ref)
+	})
 	slices.SortFunc(c.Sources.Local, func(a, b provenancetypes.LocalSource) int {
 		return cmp.Compare(a.Name, b.Name)
 	})
@@ -69,6 +82,9 @@ func (c *Capture) Sort() {
 	slices.SortFunc(c.Sources.HTTP, func(a, b provenancetypes.HTTPSource) int {
 		return cmp.Compare(a.URL, b.URL)
 	})
+	slices.SortFunc(c.Sources.External, func(a, b provenancetypes.ExternalSource) int {
+		return cmp.Or(cmp.Compare(a.Scheme, b.Scheme), cmp.Compare(a.Ref, b.Ref))
+	})
 	slices.SortFunc(c.Secrets, func(a, b provenancetypes.Secret) int {
 		return cmp.Compare(a.ID, b.ID)
 	})
@@ -109,15 +125,17 @@ func (c *Capture) OptimizeImageSources() error {
 }
 
 func (c *Capture) AddImage(i provenancetypes.ImageSource) {
-	for _, v := range c.Sources.Images {
+	for idx, v := range c.Sources.Images {
 		if v.Ref == i.Ref && v.Local == i.Local {
 			if v.Platform == i.Platform {
+				c.mergeImageVerification(idx, i)
 				return
 			}
 			if v.Platform != nil && i.Platform != nil {
 				// NOTE: Deliberately excluding OSFeatures, as there's no extant (or rational) case where a source image is an index and contains images distinguished only by OSFeature
 				// See https://github.com/moby/buildkit/pull/4387#discussion_r1376234241 and https://github.com/opencontainers/image-spec/issues/1147
 				if v.Platform.Architecture == i.Platform.Architecture && v.Platform.OS == i.Platform.OS && v.Platform.OSVersion == i.Platform.OSVersion && v.Platform.Variant == i.Platform.Variant {
+					c.mergeImageVerification(idx, i)
 					return
 				}
 			}
@@ -126,6 +144,21 @@ func (c *Capture) AddImage(i provenancetypes.ImageSource) {
 	c.Sources.Images = append(c.Sources.Images, i)
 }
 
+func (c *Capture) mergeImageVerification(idx int, i provenancetypes.ImageSource) {
+	if c.Sources.Images[idx].Verified == nil {
+		c.Sources.Images[idx].Verified = i.Verified
+	}
+}
+
+func (c *Capture) AddArtifact(a provenancetypes.ArtifactSource) {
+	for _, v := range c.Sources.Artifacts {
+		if v.Ref == a.Ref && v.Digest == a.Digest {
+			return
+		}
+	}
+	c.Sources.Artifacts = append(c.Sources.Artifacts, a)
+}
+
 func (c *Capture) AddLocal(l provenancetypes.LocalSource) {
 	for _, v := range c.Sources.Local {
 		if v.Name == l.Name {
@@ -155,6 +188,16 @@ func (c *Capture) AddHTTP(h provenancetypes.HTTPSource) {
 	c.Sources.HTTP = append(c.Sources.HTTP, h)
 }
 
+func (c *Capture) AddExternal(e provenancetypes.ExternalSource) {
+	e.Ref = urlutil.RedactCredentials(e.Ref)
+	for _, v := range c.Sources.External {
+		if v.Scheme == e.Scheme && v.Ref == e.Ref && v.Key == e.Key {
+			return
+		}
+	}
+	c.Sources.External = append(c.Sources.External, e)
+}
+
 func (c *Capture) AddSecret(s provenancetypes.Secret) {
 	for i, v := range c.Secrets {
 		if v.ID == s.ID {
@@ -189,6 +232,13 @@ func (c *Capture) AddSamples(dgst digest.Digest, samples *resourcestypes.Samples
 	c.Samples[dgst] = samples
 }
 
+func (c *Capture) AddNetworkAccessLog(dgst digest.Digest, l *resourcestypes.NetworkAccessLog) {
+	if c.NetworkAccessLogs == nil {
+		c.NetworkAccessLogs = map[digest.Digest]*resourcestypes.NetworkAccessLog{}
+	}
+	c.NetworkAccessLogs[dgst] = l
+}
+
 func parseRefName(s string) (distreference.Named, string, error) {
 	ref, err := distreference.ParseNormalizedNamed(s)
 	if err != nil {
@@ -1,29 +1,26 @@
 package provenance
 
 import (
+	"path"
 	"strings"
 
 	"github.com/containerd/platforms"
+	distreference "github.com/distribution/reference"
 	slsa "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/common"
 	slsa02 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v0.2"
 	provenancetypes "github.com/moby/buildkit/solver/llbsolver/provenance/types"
 	"github.com/moby/buildkit/util/purl"
 	"github.com/moby/buildkit/util/urlutil"
 	"github.com/package-url/packageurl-go"
+	"github.com/pkg/errors"
 )
 
 func slsaMaterials(srcs provenancetypes.Sources) ([]slsa.ProvenanceMaterial, error) {
-	count := len(srcs.Images) + len(srcs.Git) + len(srcs.HTTP)
+	count := len(srcs.Images) + len(srcs.Artifacts) + len(srcs.Git) + len(srcs.HTTP) + len(srcs.External)
 	out := make([]slsa.ProvenanceMaterial, 0, count)
 
 	for _, s := range srcs.Images {
-		var uri string
-		var err error
-		if s.Local {
-			uri, err = purl.RefToPURL(packageurl.TypeOCI, s.Ref, s.Platform)
-		} else {
-			uri, err = purl.RefToPURL(packageurl.TypeDocker, s.Ref, s.Platform)
-		}
+		uri, err := imageURI(s)
 		if err != nil {
 			return nil, err
 		}
@@ -38,6 +35,19 @@ func slsaMaterials(srcs provenancetypes.Sources) ([]slsa.ProvenanceMaterial, err
 		out = append(out, material)
 	}
 
+	for _, s := range srcs.Artifacts {
+		uri, err := artifactURI(s)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, slsa.ProvenanceMaterial{
+			URI: uri,
+			Digest: slsa.DigestSet{
+				s.Digest.Algorithm().String(): s.Digest.Hex(),
+			},
+		})
+	}
+
 	for _, s := range srcs.Git {
 		out = append(out, slsa.ProvenanceMaterial{
 			URI: s.URL,
@@ -56,6 +66,107 @@ func slsaMaterials(srcs provenancetypes.Sources) ([]slsa.ProvenanceMaterial, err
 		})
 	}
 
+	for _, s := range srcs.External {
+		material := slsa.ProvenanceMaterial{
+			URI: externalURI(s),
+		}
+		if s.Digest != "" {
+			material.Digest = slsa.DigestSet{
+				s.Digest.Algorithm().String(): s.Digest.Hex(),
+			}
+		}
+		out = append(out, material)
+	}
+
+	return out, nil
+}
+
+func externalURI(s provenancetypes.ExternalSource) string {
+	return s.Scheme + "://" + s.Ref
+}
+
+// externalMaterials returns the materials of external sources with the
+// version keys that can't be recorded in the SLSA materials.
+func externalMaterials(srcs provenancetypes.Sources) []provenancetypes.ExternalMaterial {
+	var out []provenancetypes.ExternalMaterial
+	for _, s := range srcs.External {
+		m := provenancetypes.ExternalMaterial{
+			URI:    externalURI(s),
+			Scheme: s.Scheme,
+			Key:    s.Key,
+		}
+		if s.Digest != "" {
+			m.Digest = slsa.DigestSet{
+				s.Digest.Algorithm().String(): s.Digest.Hex(),
+			}
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+func imageURI(s provenancetypes.ImageSource) (string, error) {
+	if s.Local {
+		return purl.RefToPURL(packageurl.TypeOCI, s.Ref, s.Platform)
+	}
+	return purl.RefToPURL(packageurl.TypeDocker, s.Ref, s.Platform)
+}
+
+// artifactURI returns the package URL of an OCI artifact. Unlike images, that
+// use the docker type, artifacts use the oci type that identifies them by
+// digest and records the repository and tag as qualifiers.
+func artifactURI(s provenancetypes.ArtifactSource) (string, error) {
+	named, err := distreference.ParseNormalizedNamed(s.Ref)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse ref %q", s.Ref)
+	}
+	var qualifiers packageurl.Qualifiers
+	if s.Platform != nil {
+		qualifiers = append(qualifiers, packageurl.Qualifier{
+			Key:   "platform",
+			Value: platforms.Format(platforms.Normalize(*s.Platform)),
+		})
+	}
+	qualifiers = append(qualifiers, packageurl.Qualifier{
+		Key:   "repository_url",
+		Value: named.Name(),
+	})
+	if tagged, ok := named.(distreference.Tagged); ok {
+		qualifiers = append(qualifiers, packageurl.Qualifier{
+			Key:   "tag",
+			Value: tagged.Tag(),
+		})
+	}
+	return packageurl.NewPackageURL(packageurl.TypeOCI, "", path.Base(named.Name()), s.Digest.String(), qualifiers, "").ToString(), nil
+}
+
+func verifiedMaterials(srcs provenancetypes.Sources) ([]provenancetypes.VerifiedMaterial, error) {
+	var out []provenancetypes.VerifiedMaterial
+	for _, s := range srcs.Images {
+		v := s.Verified
+		if v == nil {
+			continue
+		}
+		uri, err := imageURI(s)
+		if err != nil {
+			return nil, err
+		}
+		m := provenancetypes.VerifiedMaterial{
+			URI: uri,
+			Provenance: slsa.DigestSet{
+				v.Provenance.Algorithm().String(): v.Provenance.Hex(),
+			},
+			PredicateType: v.PredicateType,
+			BuilderID:     v.BuilderID,
+			KeyIDs:        v.KeyIDs,
+		}
+		if s.Digest != "" {
+			m.Digest = slsa.DigestSet{
+				s.Digest.Algorithm().String(): s.Digest.Hex(),
+			}
+		}
+		out = append(out, m)
+	}
 	return out, nil
 }
 
@@ -176,6 +287,12 @@ func NewPredicate(c *Capture) (*provenancetypes.ProvenancePredicateSLSA02, error
 		pr.Metadata.BuildKitMetadata.VCS = vcs
 	}
 
+	pr.Metadata.BuildKitMetadata.VerifiedMaterials, err = verifiedMaterials(c.Sources)
+	if err != nil {
+		return nil, err
+	}
+	pr.Metadata.BuildKitMetadata.ExternalMaterials = externalMaterials(c.Sources)
+
 	return pr, nil
 }
 
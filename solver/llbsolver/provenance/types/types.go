@@ -58,6 +58,24 @@ type ImageSource struct {
 	Platform *ocispecs.Platform
 	Digest   digest.Digest
 	Local    bool
+	Verified *ImageVerification
+}
+
+// ImageVerification is the provenance an image source was verified against
+// before it was used in the build.
+type ImageVerification struct {
+	Manifest      digest.Digest
+	Provenance    digest.Digest
+	PredicateType string
+	BuilderID     string
+	KeyIDs        []string
+}
+
+// ArtifactSource is an OCI artifact that was pulled as files.
+type ArtifactSource struct {
+	Ref      string
+	Platform *ocispecs.Platform
+	Digest   digest.Digest
 }
 
 type GitSource struct {
@@ -70,6 +88,17 @@ type HTTPSource struct {
 	Digest digest.Digest
 }
 
+// ExternalSource is a source handled by an external provider for a custom
+// scheme.
+type ExternalSource struct {
+	Scheme string
+	Ref    string
+	// Key is the version key returned by the provider.
+	Key string
+	// Digest of the content, if returned by the provider.
+	Digest digest.Digest
+}
+
 type LocalSource struct {
 	Name string `json:"name"`
 }
@@ -85,10 +114,12 @@ type SSH struct {
 }
 
 type Sources struct {
-	Images []ImageSource
-	Git    []GitSource
-	HTTP   []HTTPSource
-	Local  []LocalSource
+	Images    []ImageSource
+	Artifacts []ArtifactSource
+	Git       []GitSource
+	HTTP      []HTTPSource
+	External  []ExternalSource
+	Local     []LocalSource
 }
 
 func (ps *ProvenanceSLSA) Validate() error {
@@ -180,6 +211,47 @@ type BuildKitMetadata struct {
 	Source   *Source                            `json:"source,omitempty"`
 	Layers   map[string][][]ocispecs.Descriptor `json:"layers,omitempty"`
 	SysUsage []*resourcestypes.SysSample        `json:"sysUsage,omitempty"`
+	// VerifiedMaterials lists the image materials whose provenance was
+	// verified before they were used in the build.
+	VerifiedMaterials []VerifiedMaterial `json:"verifiedMaterials,omitempty"`
+	// ExternalMaterials lists the materials handled by external source
+	// providers with the version keys returned by the providers.
+	ExternalMaterials []ExternalMaterial `json:"externalMaterials,omitempty"`
+	// NetworkAccess lists the network access recorded for the steps of the
+	// build if network access logging is enabled in the worker.
+	NetworkAccess []NetworkAccess `json:"networkAccess,omitempty"`
+}
+
+// VerifiedMaterial is an image material and the provenance attestation it
+// was verified against.
+type VerifiedMaterial struct {
+	URI    string         `json:"uri"`
+	Digest slsa.DigestSet `json:"digest,omitempty"`
+	// Provenance is the digest of the verified provenance attestation.
+	Provenance    slsa.DigestSet `json:"provenance"`
+	PredicateType string         `json:"predicateType"`
+	BuilderID     string         `json:"builderID,omitempty"`
+	// KeyIDs are the IDs of the trusted keys that signed the provenance.
+	KeyIDs []string `json:"keyIDs,omitempty"`
+}
+
+// ExternalMaterial is a material handled by an external source provider.
+type ExternalMaterial struct {
+	URI    string         `json:"uri"`
+	Digest slsa.DigestSet `json:"digest,omitempty"`
+	Scheme string         `json:"scheme"`
+	// Key is the version key of the source returned by the provider.
+	Key string `json:"key"`
+}
+
+// NetworkAccess is the network access log of a single build step.
+type NetworkAccess struct {
+	// Step is the ID of the step in the build config. It is only set in
+	// mode=max.
+	Step        string                             `json:"step,omitempty"`
+	DNS         []resourcestypes.DNSLookup         `json:"dns,omitempty"`
+	Connections []resourcestypes.NetworkConnection `json:"connections,omitempty"`
+	Truncated   bool                               `json:"truncated,omitempty"`
 }
 
 type BuildKitComplete struct {
@@ -31,6 +31,7 @@ import (
 	"github.com/moby/buildkit/session"
 	sessionexporter "github.com/moby/buildkit/session/exporter"
 	"github.com/moby/buildkit/solver"
+	solvererrdefs "github.com/moby/buildkit/solver/errdefs"
 	"github.com/moby/buildkit/solver/llbsolver/provenance"
 	provenancetypes "github.com/moby/buildkit/solver/llbsolver/provenance/types"
 	"github.com/moby/buildkit/solver/result"
@@ -403,6 +404,7 @@ func (s *Solver) recordBuildHistory(ctx context.Context, id string, req frontend
 			}
 			rec.ExternalError = desc
 			rec.Error = status
+			rec.ExitReason = solvererrdefs.ExitReasonFromError(err)
 		}
 
 		ready, done := s.history.AcquireFinalizer(rec.Ref)
@@ -514,6 +516,10 @@ func (s *Solver) Solve(ctx context.Context, id string, sessionID string, req fro
 	}
 	j.SetValue(keyEntitlements, set)
 
+	srcPol, err = withImageVerifyPolicy(srcPol, req.FrontendOpt)
+	if err != nil {
+		return nil, err
+	}
 	if srcPol != nil {
 		if err := validateSourcePolicy(srcPol); err != nil {
 			return nil, err
@@ -1170,6 +1176,7 @@ func notifyStarted(ctx context.Context, v *client.Vertex) func(err error) {
 		v.Cached = false
 		if err != nil {
 			v.Error = err.Error()
+			v.ExitReason = solvererrdefs.ExitReasonFromError(err)
 		}
 		pw.Write(id, *v)
 	}
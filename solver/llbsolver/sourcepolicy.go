@@ -4,8 +4,70 @@ import (
 	"context"
 
 	"github.com/moby/buildkit/solver/pb"
+	spb "github.com/moby/buildkit/sourcepolicy/pb"
+	"github.com/moby/buildkit/util/imageverify"
+	"github.com/pkg/errors"
 )
 
 type SourcePolicyEvaluator interface {
 	Evaluate(ctx context.Context, op *pb.SourceOp) (bool, error)
 }
+
+const (
+	keyImageVerifyBuilderID = "image-verify:builder-id"
+	keyImageVerifyPublicKey = "image-verify:public-key"
+	keyImageVerifyMode      = "image-verify:mode"
+)
+
+// withImageVerifyPolicy returns the source policy with a rule that enables
+// provenance verification for all image sources if it was requested with
+// build attributes. Image sources that already define their own verification,
+// e.g. with a source policy rule, are not changed.
+func withImageVerifyPolicy(pol *spb.Policy, attrs map[string]string) (*spb.Policy, error) {
+	builderID, publicKey := attrs[keyImageVerifyBuilderID], attrs[keyImageVerifyPublicKey]
+	if builderID == "" && publicKey == "" {
+		if _, ok := attrs[keyImageVerifyMode]; ok {
+			return nil, errors.Errorf("%s requires %s or %s", keyImageVerifyMode, keyImageVerifyBuilderID, keyImageVerifyPublicKey)
+		}
+		return pol, nil
+	}
+	// validate early so errors are not reported for every image
+	if _, err := imageverify.ParsePolicy(builderID, publicKey, attrs[keyImageVerifyMode]); err != nil {
+		return nil, err
+	}
+
+	updates := map[string]string{}
+	for k, v := range map[string]string{
+		pb.AttrImageVerifyBuilderID: builderID,
+		pb.AttrImageVerifyPublicKey: publicKey,
+		pb.AttrImageVerifyMode:      attrs[keyImageVerifyMode],
+	} {
+		if v != "" {
+			updates[k] = v
+		}
+	}
+
+	rule := &spb.Rule{
+		Action: spb.PolicyAction_CONVERT,
+		Selector: &spb.Selector{
+			Identifier: `^docker-image://(.+)$`,
+			MatchType:  spb.MatchType_REGEX,
+			Constraints: []*spb.AttrConstraint{
+				{Key: pb.AttrImageVerifyBuilderID, Value: "", Condition: spb.AttrMatch_EQUAL},
+				{Key: pb.AttrImageVerifyPublicKey, Value: "", Condition: spb.AttrMatch_EQUAL},
+			},
+		},
+		Updates: &spb.Update{
+			Identifier: "docker-image://$1",
+			Attrs:      updates,
+		},
+	}
+
+	out := &spb.Policy{Version: 1}
+	if pol != nil {
+		out.Version = pol.Version
+		out.Rules = append(out.Rules, pol.Rules...)
+	}
+	out.Rules = append(out.Rules, rule)
+	return out, nil
+}
@@ -0,0 +1,66 @@
+package llbsolver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/sourcepolicy"
+	spb "github.com/moby/buildkit/sourcepolicy/pb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithImageVerifyPolicy(t *testing.T) {
+	pol, err := withImageVerifyPolicy(nil, map[string]string{})
+	require.NoError(t, err)
+	require.Nil(t, pol)
+
+	_, err = withImageVerifyPolicy(nil, map[string]string{keyImageVerifyMode: "warn"})
+	require.Error(t, err)
+
+	_, err = withImageVerifyPolicy(nil, map[string]string{keyImageVerifyBuilderID: "https://example.com", keyImageVerifyMode: "never"})
+	require.Error(t, err)
+
+	pol, err = withImageVerifyPolicy(&spb.Policy{
+		Version: 1,
+		Rules: []*spb.Rule{{
+			Action: spb.PolicyAction_CONVERT,
+			Selector: &spb.Selector{
+				Identifier: "docker-image://docker.io/library/busybox:latest",
+			},
+			Updates: &spb.Update{
+				Attrs: map[string]string{pb.AttrImageVerifyBuilderID: "https://example.com/busybox"},
+			},
+		}},
+	}, map[string]string{
+		keyImageVerifyBuilderID: "https://example.com",
+		keyImageVerifyMode:      "warn",
+	})
+	require.NoError(t, err)
+	require.Len(t, pol.Rules, 2)
+
+	e := sourcepolicy.NewEngine([]*spb.Policy{pol})
+
+	op := &pb.SourceOp{Identifier: "docker-image://docker.io/library/alpine:latest"}
+	mut, err := e.Evaluate(context.TODO(), op)
+	require.NoError(t, err)
+	require.True(t, mut)
+	require.Equal(t, "docker-image://docker.io/library/alpine:latest", op.Identifier)
+	require.Equal(t, map[string]string{
+		pb.AttrImageVerifyBuilderID: "https://example.com",
+		pb.AttrImageVerifyMode:      "warn",
+	}, op.Attrs)
+
+	// the source policy takes precedence over the build attributes
+	op = &pb.SourceOp{Identifier: "docker-image://docker.io/library/busybox:latest"}
+	_, err = e.Evaluate(context.TODO(), op)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		pb.AttrImageVerifyBuilderID: "https://example.com/busybox",
+	}, op.Attrs)
+
+	op = &pb.SourceOp{Identifier: "git://github.com/moby/buildkit.git"}
+	mut, err = e.Evaluate(context.TODO(), op)
+	require.NoError(t, err)
+	require.False(t, mut)
+}
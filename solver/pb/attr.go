@@ -27,6 +27,10 @@ const AttrHTTPGID = "http.gid"
 const AttrHTTPAuthHeaderSecret = "http.authheadersecret"
 const AttrHTTPHeaderPrefix = "http.header."
 
+// AttrHTTPMirrors is a newline separated list of URLs that are tried in order
+// if the source URL can't be fetched.
+const AttrHTTPMirrors = "http.mirrors"
+
 const AttrImageResolveMode = "image.resolvemode"
 const AttrImageResolveModeDefault = "default"
 const AttrImageResolveModeForcePull = "pull"
@@ -34,6 +38,40 @@ const AttrImageResolveModePreferLocal = "local"
 const AttrImageRecordType = "image.recordtype"
 const AttrImageLayerLimit = "image.layerlimit"
 
+// AttrImageVerifyBuilderID is a comma separated list of builder IDs that the
+// provenance attestation of the image must have been created by.
+const AttrImageVerifyBuilderID = "image.verify.builderid"
+
+// AttrImageVerifyPublicKey holds PEM encoded public keys, one of which must
+// have signed the provenance attestation of the image.
+const AttrImageVerifyPublicKey = "image.verify.publickey"
+
+// AttrImageVerifyMode is "error" (default) to fail the build if the image
+// can't be verified, or "warn" to only report a warning.
+const AttrImageVerifyMode = "image.verify.mode"
+
+// AttrGoModSum is the go.sum hash of the module zip. The zip isn't fetched if
+// it is not set.
+const AttrGoModSum = "gomod.sum"
+
+// AttrGoModModSum is the go.sum hash of the go.mod file of the module.
+const AttrGoModModSum = "gomod.modsum"
+
+// AttrGoModProxy is the URL of the Go module proxy, https://proxy.golang.org
+// by default.
+const AttrGoModProxy = "gomod.proxy"
+
+// AttrNPMURL is the resolved URL of the package tarball from
+// package-lock.json.
+const AttrNPMURL = "npm.url"
+
+// AttrNPMIntegrity is the subresource integrity of the package tarball.
+const AttrNPMIntegrity = "npm.integrity"
+
+// AttrNPMRegistry replaces the https://registry.npmjs.org/ prefix of the
+// package URL.
+const AttrNPMRegistry = "npm.registry"
+
 const AttrOCILayoutSessionID = "oci.session"
 const AttrOCILayoutStoreID = "oci.store"
 const AttrOCILayoutLayerLimit = "oci.layerlimit"
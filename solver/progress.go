@@ -76,6 +76,21 @@ func (j *Job) Status(ctx context.Context, ch chan *client.SolveStatus) error {
 					v.Vertex = vtx.(digest.Digest)
 				}
 				ss.Warnings = append(ss.Warnings, &v)
+			case client.VertexStats:
+				vtx, ok := p.Meta("vertex")
+				if !ok {
+					bklog.G(ctx).Warnf("progress %s stats without vertex info", p.ID)
+					continue
+				}
+				if v.Vertex == "" {
+					v.Vertex = vtx.(digest.Digest)
+				}
+				v.ID = p.ID
+				// keep the time the sample was taken
+				if v.Timestamp.IsZero() {
+					v.Timestamp = p.Timestamp
+				}
+				ss.Stats = append(ss.Stats, &v)
 			}
 		}
 		slices.SortFunc(ss.Vertexes, func(a, b *client.Vertex) int {
@@ -93,6 +108,9 @@ func (j *Job) Status(ctx context.Context, ch chan *client.SolveStatus) error {
 		slices.SortFunc(ss.Logs, func(a, b *client.VertexLog) int {
 			return a.Timestamp.Compare(b.Timestamp)
 		})
+		slices.SortFunc(ss.Stats, func(a, b *client.VertexStats) int {
+			return a.Timestamp.Compare(b.Timestamp)
+		})
 
 		select {
 		case <-ctx.Done():
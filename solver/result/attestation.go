@@ -16,6 +16,7 @@ const (
 const (
 	AttestationReasonSBOM       = "sbom"
 	AttestationReasonProvenance = "provenance"
+	AttestationReasonVuln       = "vuln"
 )
 
 type Attestation[T any] struct {
@@ -7,6 +7,7 @@ import (
 	provenancetypes "github.com/moby/buildkit/solver/llbsolver/provenance/types"
 	"github.com/moby/buildkit/source"
 	srctypes "github.com/moby/buildkit/source/types"
+	"github.com/moby/buildkit/util/imageverify"
 	"github.com/moby/buildkit/util/resolver"
 	digest "github.com/opencontainers/go-digest"
 	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
@@ -19,6 +20,10 @@ type ImageIdentifier struct {
 	ResolveMode resolver.ResolveMode
 	RecordType  client.UsageRecordType
 	LayerLimit  *int
+	// Verify is the policy the provenance of the image is verified against.
+	Verify *imageverify.Policy
+	// Verified is the result of the verification once the image is resolved.
+	Verified *imageverify.Result
 }
 
 func NewImageIdentifier(str string) (*ImageIdentifier, error) {
@@ -44,11 +49,21 @@ func (id *ImageIdentifier) Capture(c *provenance.Capture, pin string) error {
 	if err != nil {
 		return errors.Wrapf(err, "failed to parse image digest %s", pin)
 	}
-	c.AddImage(provenancetypes.ImageSource{
+	src := provenancetypes.ImageSource{
 		Ref:      id.Reference.String(),
 		Platform: id.Platform,
 		Digest:   dgst,
-	})
+	}
+	if v := id.Verified; v != nil {
+		src.Verified = &provenancetypes.ImageVerification{
+			Manifest:      v.Manifest,
+			Provenance:    v.Provenance.Digest,
+			PredicateType: v.PredicateType,
+			BuilderID:     v.BuilderID,
+			KeyIDs:        v.KeyIDs,
+		}
+	}
+	c.AddImage(src)
 	return nil
 }
 
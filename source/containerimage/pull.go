@@ -15,19 +15,24 @@ import (
 	"github.com/containerd/containerd/v2/core/snapshots"
 	"github.com/containerd/containerd/v2/pkg/snapshotters"
 	cerrdefs "github.com/containerd/errdefs"
+	"github.com/containerd/platforms"
 	"github.com/moby/buildkit/cache"
 	"github.com/moby/buildkit/client"
 	"github.com/moby/buildkit/client/llb/sourceresolver"
 	"github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/solver"
 	"github.com/moby/buildkit/solver/errdefs"
+	"github.com/moby/buildkit/source"
+	"github.com/moby/buildkit/util/bklog"
 	"github.com/moby/buildkit/util/estargz"
 	"github.com/moby/buildkit/util/flightcontrol"
 	"github.com/moby/buildkit/util/imageutil"
+	"github.com/moby/buildkit/util/imageverify"
 	"github.com/moby/buildkit/util/leaseutil"
 	"github.com/moby/buildkit/util/progress"
 	"github.com/moby/buildkit/util/progress/controller"
 	"github.com/moby/buildkit/util/pull"
+	"github.com/moby/buildkit/util/push"
 	"github.com/moby/buildkit/util/resolver"
 	digest "github.com/opencontainers/go-digest"
 	"github.com/opencontainers/image-spec/identity"
@@ -46,6 +51,8 @@ type puller struct {
 	SessionManager *session.Manager
 	layerLimit     *int
 	vtx            solver.Vertex
+	verifyID       *ImageIdentifier
+	verified       *imageverify.Result
 	ResolverType
 	store sourceresolver.ResolveImageConfigOptStore
 
@@ -129,6 +136,13 @@ func (p *puller) CacheKey(ctx context.Context, g session.Group, index int) (cach
 			return struct{}{}, err
 		}
 
+		if p.verifyID != nil {
+			p.verified, err = p.verify(ctx, g, progressFactory)
+			if err != nil {
+				return struct{}{}, err
+			}
+		}
+
 		if ll := p.layerLimit; ll != nil {
 			if *ll > len(p.manifest.Descriptors) {
 				return struct{}{}, errors.Errorf("layer limit %d is greater than the number of layers in the image %d", *ll, len(p.manifest.Descriptors))
@@ -200,6 +214,48 @@ func (p *puller) CacheKey(ctx context.Context, g session.Group, index int) (cach
 	return p.configKey, p.manifest.MainManifestDesc.Digest.String(), cacheOpts, cacheDone, nil
 }
 
+// verify checks the provenance of the image against the policy of the
+// identifier. Depending on the policy, failures are returned as an error or
+// reported as a warning on the vertex.
+func (p *puller) verify(ctx context.Context, g session.Group, progressFactory progress.WriterFactory) (*imageverify.Result, error) {
+	pol := p.verifyID.Verify
+	r := resolver.DefaultPool.GetResolver(p.RegistryHosts, p.Ref, "pull", p.SessionManager, g)
+	referrers := func(ctx context.Context, dgst digest.Digest) ([]ocispecs.Descriptor, error) {
+		return push.Referrers(ctx, r, p.Ref, dgst)
+	}
+	res, err := imageverify.Verify(ctx, p.manifest.Provider(g), p.manifest.MainManifestDesc, platforms.Only(p.Platform), pol, referrers)
+	if err == nil {
+		return res, nil
+	}
+	err = errors.Wrapf(err, "failed to verify image %s", p.Ref)
+	if pol.Mode != imageverify.ModeWarn {
+		return nil, err
+	}
+	bklog.G(ctx).Warn(err.Error())
+	if p.vtx != nil {
+		if pw, ok, _ := progressFactory(ctx); ok {
+			pw.Write("verify "+p.Ref, client.VertexWarning{
+				Vertex: p.vtx.Digest(),
+				Level:  1,
+				Short:  []byte(err.Error()),
+			})
+			pw.Close()
+		}
+	}
+	return nil, nil
+}
+
+// PinnedIdentifier returns the identifier with the result of the
+// verification of the image.
+func (p *puller) PinnedIdentifier() source.Identifier {
+	if p.verified == nil {
+		return nil
+	}
+	id := *p.verifyID
+	id.Verified = p.verified
+	return &id
+}
+
 func (p *puller) Snapshot(ctx context.Context, g session.Group) (ir cache.ImmutableRef, err error) {
 	var getResolver pull.SessionResolver
 	switch p.ResolverType {
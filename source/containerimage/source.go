@@ -24,6 +24,7 @@ import (
 	srctypes "github.com/moby/buildkit/source/types"
 	"github.com/moby/buildkit/util/flightcontrol"
 	"github.com/moby/buildkit/util/imageutil"
+	"github.com/moby/buildkit/util/imageverify"
 	"github.com/moby/buildkit/util/pull"
 	"github.com/moby/buildkit/util/resolver"
 	"github.com/moby/buildkit/util/tracing"
@@ -93,6 +94,7 @@ func (is *Source) Resolve(ctx context.Context, id source.Identifier, sm *session
 		ref        reference.Spec
 		store      sourceresolver.ResolveImageConfigOptStore
 		layerLimit *int
+		verifyID   *ImageIdentifier
 	)
 	switch is.ResolverType {
 	case ResolverTypeRegistry:
@@ -108,6 +110,7 @@ func (is *Source) Resolve(ctx context.Context, id source.Identifier, sm *session
 		recordType = imageIdentifier.RecordType
 		ref = imageIdentifier.Reference
 		layerLimit = imageIdentifier.LayerLimit
+		verifyID = imageIdentifier
 	case ResolverTypeOCILayout:
 		ociIdentifier, ok := id.(*OCIIdentifier)
 		if !ok {
@@ -147,6 +150,9 @@ func (is *Source) Resolve(ctx context.Context, id source.Identifier, sm *session
 		store:          store,
 		layerLimit:     layerLimit,
 	}
+	if verifyID != nil && verifyID.Verify != nil {
+		p.verifyID = verifyID
+	}
 	return p, nil
 }
 
@@ -248,6 +254,14 @@ func (is *Source) registryIdentifier(ref string, attrs map[string]string, platfo
 		}
 	}
 
+	if attrs[pb.AttrImageVerifyBuilderID] != "" || attrs[pb.AttrImageVerifyPublicKey] != "" {
+		pol, err := imageverify.ParsePolicy(attrs[pb.AttrImageVerifyBuilderID], attrs[pb.AttrImageVerifyPublicKey], attrs[pb.AttrImageVerifyMode])
+		if err != nil {
+			return nil, err
+		}
+		id.Verify = pol
+	}
+
 	return id, nil
 }
 
@@ -0,0 +1,51 @@
+package external
+
+import (
+	"github.com/moby/buildkit/solver/llbsolver/provenance"
+	provenancetypes "github.com/moby/buildkit/solver/llbsolver/provenance/types"
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/source"
+	sourcepb "github.com/moby/buildkit/source/external/pb"
+	digest "github.com/opencontainers/go-digest"
+)
+
+// ExternalIdentifier identifies a source handled by an external provider.
+// The ref and attrs are only interpreted by the provider.
+type ExternalIdentifier struct {
+	scheme   string
+	Ref      string
+	Attrs    map[string]string
+	Platform *pb.Platform
+	// Key is the version key returned by the provider. It is only set on
+	// the identifier pinned by the cache key.
+	Key string
+}
+
+var _ source.Identifier = (*ExternalIdentifier)(nil)
+
+func (id *ExternalIdentifier) Scheme() string {
+	return id.scheme
+}
+
+func (id *ExternalIdentifier) Capture(c *provenance.Capture, pin string) error {
+	src := provenancetypes.ExternalSource{
+		Scheme: id.scheme,
+		Ref:    id.Ref,
+		Key:    id.Key,
+	}
+	// the pin is the digest of the content if the provider returned one
+	if dgst, err := digest.Parse(pin); err == nil {
+		src.Digest = dgst
+	}
+	c.AddExternal(src)
+	return nil
+}
+
+func (id *ExternalIdentifier) info() *sourcepb.SourceInfo {
+	return &sourcepb.SourceInfo{
+		Scheme:   id.scheme,
+		Ref:      id.Ref,
+		Attrs:    id.Attrs,
+		Platform: id.Platform,
+	}
+}
@@ -0,0 +1,472 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v3.11.4
+// source: github.com/moby/buildkit/source/external/pb/source.proto
+
+package moby_buildkit_v1_source
+
+import (
+	pb "github.com/moby/buildkit/solver/pb"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// SourceInfo describes a source op of the provider.
+type SourceInfo struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Scheme string                 `protobuf:"bytes,1,opt,name=scheme,proto3" json:"scheme,omitempty"`
+	// ref is the identifier of the source op without the scheme.
+	Ref           string            `protobuf:"bytes,2,opt,name=ref,proto3" json:"ref,omitempty"`
+	Attrs         map[string]string `protobuf:"bytes,3,rep,name=attrs,proto3" json:"attrs,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Platform      *pb.Platform      `protobuf:"bytes,4,opt,name=platform,proto3" json:"platform,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SourceInfo) Reset() {
+	*x = SourceInfo{}
+	mi := &file_github_com_moby_buildkit_source_external_pb_source_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SourceInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SourceInfo) ProtoMessage() {}
+
+func (x *SourceInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_moby_buildkit_source_external_pb_source_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SourceInfo.ProtoReflect.Descriptor instead.
+func (*SourceInfo) Descriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_source_external_pb_source_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SourceInfo) GetScheme() string {
+	if x != nil {
+		return x.Scheme
+	}
+	return ""
+}
+
+func (x *SourceInfo) GetRef() string {
+	if x != nil {
+		return x.Ref
+	}
+	return ""
+}
+
+func (x *SourceInfo) GetAttrs() map[string]string {
+	if x != nil {
+		return x.Attrs
+	}
+	return nil
+}
+
+func (x *SourceInfo) GetPlatform() *pb.Platform {
+	if x != nil {
+		return x.Platform
+	}
+	return nil
+}
+
+type IdentifierRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Source        *SourceInfo            `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IdentifierRequest) Reset() {
+	*x = IdentifierRequest{}
+	mi := &file_github_com_moby_buildkit_source_external_pb_source_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IdentifierRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IdentifierRequest) ProtoMessage() {}
+
+func (x *IdentifierRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_moby_buildkit_source_external_pb_source_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IdentifierRequest.ProtoReflect.Descriptor instead.
+func (*IdentifierRequest) Descriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_source_external_pb_source_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *IdentifierRequest) GetSource() *SourceInfo {
+	if x != nil {
+		return x.Source
+	}
+	return nil
+}
+
+type IdentifierResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IdentifierResponse) Reset() {
+	*x = IdentifierResponse{}
+	mi := &file_github_com_moby_buildkit_source_external_pb_source_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IdentifierResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IdentifierResponse) ProtoMessage() {}
+
+func (x *IdentifierResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_moby_buildkit_source_external_pb_source_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IdentifierResponse.ProtoReflect.Descriptor instead.
+func (*IdentifierResponse) Descriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_source_external_pb_source_proto_rawDescGZIP(), []int{2}
+}
+
+type CacheKeyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Source        *SourceInfo            `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CacheKeyRequest) Reset() {
+	*x = CacheKeyRequest{}
+	mi := &file_github_com_moby_buildkit_source_external_pb_source_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CacheKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CacheKeyRequest) ProtoMessage() {}
+
+func (x *CacheKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_moby_buildkit_source_external_pb_source_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CacheKeyRequest.ProtoReflect.Descriptor instead.
+func (*CacheKeyRequest) Descriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_source_external_pb_source_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CacheKeyRequest) GetSource() *SourceInfo {
+	if x != nil {
+		return x.Source
+	}
+	return nil
+}
+
+type CacheKeyResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// key identifies the version of the content, e.g. the ETag of an object.
+	// The content must not change for the same key. The platform is not part
+	// of the cache key so the key needs to depend on it if the content does.
+	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// digest is the optional digest of the content recorded in the
+	// provenance of the build.
+	Digest        string `protobuf:"bytes,2,opt,name=digest,proto3" json:"digest,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CacheKeyResponse) Reset() {
+	*x = CacheKeyResponse{}
+	mi := &file_github_com_moby_buildkit_source_external_pb_source_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CacheKeyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CacheKeyResponse) ProtoMessage() {}
+
+func (x *CacheKeyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_moby_buildkit_source_external_pb_source_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CacheKeyResponse.ProtoReflect.Descriptor instead.
+func (*CacheKeyResponse) Descriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_source_external_pb_source_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CacheKeyResponse) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *CacheKeyResponse) GetDigest() string {
+	if x != nil {
+		return x.Digest
+	}
+	return ""
+}
+
+type SnapshotRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Source *SourceInfo            `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	// key is the key returned by CacheKey.
+	Key           string `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SnapshotRequest) Reset() {
+	*x = SnapshotRequest{}
+	mi := &file_github_com_moby_buildkit_source_external_pb_source_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SnapshotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotRequest) ProtoMessage() {}
+
+func (x *SnapshotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_moby_buildkit_source_external_pb_source_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotRequest.ProtoReflect.Descriptor instead.
+func (*SnapshotRequest) Descriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_source_external_pb_source_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SnapshotRequest) GetSource() *SourceInfo {
+	if x != nil {
+		return x.Source
+	}
+	return nil
+}
+
+func (x *SnapshotRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+type SnapshotResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SnapshotResponse) Reset() {
+	*x = SnapshotResponse{}
+	mi := &file_github_com_moby_buildkit_source_external_pb_source_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SnapshotResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotResponse) ProtoMessage() {}
+
+func (x *SnapshotResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_github_com_moby_buildkit_source_external_pb_source_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotResponse.ProtoReflect.Descriptor instead.
+func (*SnapshotResponse) Descriptor() ([]byte, []int) {
+	return file_github_com_moby_buildkit_source_external_pb_source_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SnapshotResponse) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+var File_github_com_moby_buildkit_source_external_pb_source_proto protoreflect.FileDescriptor
+
+const file_github_com_moby_buildkit_source_external_pb_source_proto_rawDesc = "" +
+	"\n" +
+	"8github.com/moby/buildkit/source/external/pb/source.proto\x12\x17moby.buildkit.v1.source\x1a,github.com/moby/buildkit/solver/pb/ops.proto\"\xe0\x01\n" +
+	"\n" +
+	"SourceInfo\x12\x16\n" +
+	"\x06scheme\x18\x01 \x01(\tR\x06scheme\x12\x10\n" +
+	"\x03ref\x18\x02 \x01(\tR\x03ref\x12D\n" +
+	"\x05attrs\x18\x03 \x03(\v2..moby.buildkit.v1.source.SourceInfo.AttrsEntryR\x05attrs\x12(\n" +
+	"\bplatform\x18\x04 \x01(\v2\f.pb.PlatformR\bplatform\x1a8\n" +
+	"\n" +
+	"AttrsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"P\n" +
+	"\x11IdentifierRequest\x12;\n" +
+	"\x06source\x18\x01 \x01(\v2#.moby.buildkit.v1.source.SourceInfoR\x06source\"\x14\n" +
+	"\x12IdentifierResponse\"N\n" +
+	"\x0fCacheKeyRequest\x12;\n" +
+	"\x06source\x18\x01 \x01(\v2#.moby.buildkit.v1.source.SourceInfoR\x06source\"<\n" +
+	"\x10CacheKeyResponse\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x16\n" +
+	"\x06digest\x18\x02 \x01(\tR\x06digest\"`\n" +
+	"\x0fSnapshotRequest\x12;\n" +
+	"\x06source\x18\x01 \x01(\v2#.moby.buildkit.v1.source.SourceInfoR\x06source\x12\x10\n" +
+	"\x03key\x18\x02 \x01(\tR\x03key\"&\n" +
+	"\x10SnapshotResponse\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data2\xb3\x02\n" +
+	"\x06Source\x12e\n" +
+	"\n" +
+	"Identifier\x12*.moby.buildkit.v1.source.IdentifierRequest\x1a+.moby.buildkit.v1.source.IdentifierResponse\x12_\n" +
+	"\bCacheKey\x12(.moby.buildkit.v1.source.CacheKeyRequest\x1a).moby.buildkit.v1.source.CacheKeyResponse\x12a\n" +
+	"\bSnapshot\x12(.moby.buildkit.v1.source.SnapshotRequest\x1a).moby.buildkit.v1.source.SnapshotResponse0\x01BEZCgithub.com/moby/buildkit/source/external/pb;moby_buildkit_v1_sourceb\x06proto3"
+
+var (
+	file_github_com_moby_buildkit_source_external_pb_source_proto_rawDescOnce sync.Once
+	file_github_com_moby_buildkit_source_external_pb_source_proto_rawDescData []byte
+)
+
+func file_github_com_moby_buildkit_source_external_pb_source_proto_rawDescGZIP() []byte {
+	file_github_com_moby_buildkit_source_external_pb_source_proto_rawDescOnce.Do(func() {
+		file_github_com_moby_buildkit_source_external_pb_source_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_github_com_moby_buildkit_source_external_pb_source_proto_rawDesc), len(file_github_com_moby_buildkit_source_external_pb_source_proto_rawDesc)))
+	})
+	return file_github_com_moby_buildkit_source_external_pb_source_proto_rawDescData
+}
+
+var file_github_com_moby_buildkit_source_external_pb_source_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_github_com_moby_buildkit_source_external_pb_source_proto_goTypes = []any{
+	(*SourceInfo)(nil),         // 0: moby.buildkit.v1.source.SourceInfo
+	(*IdentifierRequest)(nil),  // 1: moby.buildkit.v1.source.IdentifierRequest
+	(*IdentifierResponse)(nil), // 2: moby.buildkit.v1.source.IdentifierResponse
+	(*CacheKeyRequest)(nil),    // 3: moby.buildkit.v1.source.CacheKeyRequest
+	(*CacheKeyResponse)(nil),   // 4: moby.buildkit.v1.source.CacheKeyResponse
+	(*SnapshotRequest)(nil),    // 5: moby.buildkit.v1.source.SnapshotRequest
+	(*SnapshotResponse)(nil),   // 6: moby.buildkit.v1.source.SnapshotResponse
+	nil,                        // 7: moby.buildkit.v1.source.SourceInfo.AttrsEntry
+	(*pb.Platform)(nil),        // 8: pb.Platform
+}
+var file_github_com_moby_buildkit_source_external_pb_source_proto_depIdxs = []int32{
+	7, // 0: moby.buildkit.v1.source.SourceInfo.attrs:type_name -> moby.buildkit.v1.source.SourceInfo.AttrsEntry
+	8, // 1: moby.buildkit.v1.source.SourceInfo.platform:type_name -> pb.Platform
+	0, // 2: moby.buildkit.v1.source.IdentifierRequest.source:type_name -> moby.buildkit.v1.source.SourceInfo
+	0, // 3: moby.buildkit.v1.source.CacheKeyRequest.source:type_name -> moby.buildkit.v1.source.SourceInfo
+	0, // 4: moby.buildkit.v1.source.SnapshotRequest.source:type_name -> moby.buildkit.v1.source.SourceInfo
+	1, // 5: moby.buildkit.v1.source.Source.Identifier:input_type -> moby.buildkit.v1.source.IdentifierRequest
+	3, // 6: moby.buildkit.v1.source.Source.CacheKey:input_type -> moby.buildkit.v1.source.CacheKeyRequest
+	5, // 7: moby.buildkit.v1.source.Source.Snapshot:input_type -> moby.buildkit.v1.source.SnapshotRequest
+	2, // 8: moby.buildkit.v1.source.Source.Identifier:output_type -> moby.buildkit.v1.source.IdentifierResponse
+	4, // 9: moby.buildkit.v1.source.Source.CacheKey:output_type -> moby.buildkit.v1.source.CacheKeyResponse
+	6, // 10: moby.buildkit.v1.source.Source.Snapshot:output_type -> moby.buildkit.v1.source.SnapshotResponse
+	8, // [8:11] is the sub-list for method output_type
+	5, // [5:8] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_github_com_moby_buildkit_source_external_pb_source_proto_init() }
+func file_github_com_moby_buildkit_source_external_pb_source_proto_init() {
+	if File_github_com_moby_buildkit_source_external_pb_source_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_github_com_moby_buildkit_source_external_pb_source_proto_rawDesc), len(file_github_com_moby_buildkit_source_external_pb_source_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_github_com_moby_buildkit_source_external_pb_source_proto_goTypes,
+		DependencyIndexes: file_github_com_moby_buildkit_source_external_pb_source_proto_depIdxs,
+		MessageInfos:      file_github_com_moby_buildkit_source_external_pb_source_proto_msgTypes,
+	}.Build()
+	File_github_com_moby_buildkit_source_external_pb_source_proto = out.File
+	file_github_com_moby_buildkit_source_external_pb_source_proto_goTypes = nil
+	file_github_com_moby_buildkit_source_external_pb_source_proto_depIdxs = nil
+}
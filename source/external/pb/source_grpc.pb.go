@@ -0,0 +1,215 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v3.11.4
+// source: github.com/moby/buildkit/source/external/pb/source.proto
+
+package moby_buildkit_v1_source
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Source_Identifier_FullMethodName = "/moby.buildkit.v1.source.Source/Identifier"
+	Source_CacheKey_FullMethodName   = "/moby.buildkit.v1.source.Source/CacheKey"
+	Source_Snapshot_FullMethodName   = "/moby.buildkit.v1.source.Source/Snapshot"
+)
+
+// SourceClient is the client API for Source service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Source is implemented by out-of-process source providers. BuildKit calls
+// the provider for every source op with a scheme the provider is configured
+// for.
+type SourceClient interface {
+	// Identifier validates the source before it is resolved.
+	Identifier(ctx context.Context, in *IdentifierRequest, opts ...grpc.CallOption) (*IdentifierResponse, error)
+	// CacheKey returns the version of the content the source points to.
+	CacheKey(ctx context.Context, in *CacheKeyRequest, opts ...grpc.CallOption) (*CacheKeyResponse, error)
+	// Snapshot streams the content of the source as an uncompressed tar
+	// archive.
+	Snapshot(ctx context.Context, in *SnapshotRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SnapshotResponse], error)
+}
+
+type sourceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSourceClient(cc grpc.ClientConnInterface) SourceClient {
+	return &sourceClient{cc}
+}
+
+func (c *sourceClient) Identifier(ctx context.Context, in *IdentifierRequest, opts ...grpc.CallOption) (*IdentifierResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(IdentifierResponse)
+	err := c.cc.Invoke(ctx, Source_Identifier_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sourceClient) CacheKey(ctx context.Context, in *CacheKeyRequest, opts ...grpc.CallOption) (*CacheKeyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CacheKeyResponse)
+	err := c.cc.Invoke(ctx, Source_CacheKey_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sourceClient) Snapshot(ctx context.Context, in *SnapshotRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SnapshotResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Source_ServiceDesc.Streams[0], Source_Snapshot_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SnapshotRequest, SnapshotResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Source_SnapshotClient = grpc.ServerStreamingClient[SnapshotResponse]
+
+// SourceServer is the server API for Source service.
+// All implementations should embed UnimplementedSourceServer
+// for forward compatibility.
+//
+// Source is implemented by out-of-process source providers. BuildKit calls
+// the provider for every source op with a scheme the provider is configured
+// for.
+type SourceServer interface {
+	// Identifier validates the source before it is resolved.
+	Identifier(context.Context, *IdentifierRequest) (*IdentifierResponse, error)
+	// CacheKey returns the version of the content the source points to.
+	CacheKey(context.Context, *CacheKeyRequest) (*CacheKeyResponse, error)
+	// Snapshot streams the content of the source as an uncompressed tar
+	// archive.
+	Snapshot(*SnapshotRequest, grpc.ServerStreamingServer[SnapshotResponse]) error
+}
+
+// UnimplementedSourceServer should be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSourceServer struct{}
+
+func (UnimplementedSourceServer) Identifier(context.Context, *IdentifierRequest) (*IdentifierResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Identifier not implemented")
+}
+func (UnimplementedSourceServer) CacheKey(context.Context, *CacheKeyRequest) (*CacheKeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CacheKey not implemented")
+}
+func (UnimplementedSourceServer) Snapshot(*SnapshotRequest, grpc.ServerStreamingServer[SnapshotResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method Snapshot not implemented")
+}
+func (UnimplementedSourceServer) testEmbeddedByValue() {}
+
+// UnsafeSourceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SourceServer will
+// result in compilation errors.
+type UnsafeSourceServer interface {
+	mustEmbedUnimplementedSourceServer()
+}
+
+func RegisterSourceServer(s grpc.ServiceRegistrar, srv SourceServer) {
+	// If the following call pancis, it indicates UnimplementedSourceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Source_ServiceDesc, srv)
+}
+
+func _Source_Identifier_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IdentifierRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SourceServer).Identifier(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Source_Identifier_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SourceServer).Identifier(ctx, req.(*IdentifierRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Source_CacheKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CacheKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SourceServer).CacheKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Source_CacheKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SourceServer).CacheKey(ctx, req.(*CacheKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Source_Snapshot_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SnapshotRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SourceServer).Snapshot(m, &grpc.GenericServerStream[SnapshotRequest, SnapshotResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Source_SnapshotServer = grpc.ServerStreamingServer[SnapshotResponse]
+
+// Source_ServiceDesc is the grpc.ServiceDesc for Source service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Source_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "moby.buildkit.v1.source.Source",
+	HandlerType: (*SourceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Identifier",
+			Handler:    _Source_Identifier_Handler,
+		},
+		{
+			MethodName: "CacheKey",
+			Handler:    _Source_CacheKey_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Snapshot",
+			Handler:       _Source_Snapshot_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "github.com/moby/buildkit/source/external/pb/source.proto",
+}
@@ -0,0 +1,301 @@
+// Package external implements sources that are handled by out-of-process
+// providers over the gRPC protocol defined in the pb package.
+package external
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"maps"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/moby/buildkit/cache"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/snapshot"
+	"github.com/moby/buildkit/solver"
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/source"
+	sourcepb "github.com/moby/buildkit/source/external/pb"
+	"github.com/moby/buildkit/util/grpcerrors"
+	"github.com/moby/go-archive"
+	"github.com/moby/go-archive/chrootarchive"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// identifierTimeout limits the time the provider can take to validate a
+// source as source.Source.Identifier doesn't get a context.
+const identifierTimeout = 30 * time.Second
+
+// defaultMaxSize is the default limit for the size of the tar stream of a
+// snapshot.
+const defaultMaxSize = 10 << 30
+
+type Opt struct {
+	// Scheme is the scheme of the source ops handled by the provider.
+	Scheme string
+	// Address of the provider in the form of unix://<path> or
+	// tcp://<host>:<port>.
+	Address       string
+	CacheAccessor cache.Accessor
+	// MaxSize limits the size of the tar stream of a snapshot. Defaults to
+	// 10GiB.
+	MaxSize int64
+}
+
+type Source struct {
+	scheme  string
+	conn    *grpc.ClientConn
+	client  sourcepb.SourceClient
+	cache   cache.Accessor
+	maxSize int64
+}
+
+var _ source.Source = (*Source)(nil)
+
+func NewSource(opt Opt) (*Source, error) {
+	if opt.Scheme == "" {
+		return nil, errors.New("missing scheme for external source")
+	}
+	target, err := dialTarget(opt.Address)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid address for %s source", opt.Scheme)
+	}
+	// the connection is established lazily on the first request
+	conn, err := grpc.NewClient(target,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(grpcerrors.UnaryClientInterceptor),
+		grpc.WithStreamInterceptor(grpcerrors.StreamClientInterceptor),
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create client for %s source", opt.Scheme)
+	}
+	maxSize := opt.MaxSize
+	if maxSize <= 0 {
+		maxSize = defaultMaxSize
+	}
+	return &Source{
+		scheme:  opt.Scheme,
+		conn:    conn,
+		client:  sourcepb.NewSourceClient(conn),
+		cache:   opt.CacheAccessor,
+		maxSize: maxSize,
+	}, nil
+}
+
+// Close closes the connection to the provider.
+func (es *Source) Close() error {
+	return es.conn.Close()
+}
+
+func dialTarget(address string) (string, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	switch u.Scheme {
+	case "unix":
+		return address, nil
+	case "tcp":
+		if u.Host == "" {
+			return "", errors.Errorf("missing host in %q", address)
+		}
+		return u.Host, nil
+	default:
+		return "", errors.Errorf("unsupported address %q, expected unix://<path> or tcp://<host>:<port>", address)
+	}
+}
+
+func (es *Source) Schemes() []string {
+	return []string{es.scheme}
+}
+
+func (es *Source) Identifier(scheme, ref string, attrs map[string]string, platform *pb.Platform) (source.Identifier, error) {
+	id := &ExternalIdentifier{
+		scheme:   scheme,
+		Ref:      ref,
+		Attrs:    maps.Clone(attrs),
+		Platform: platform,
+	}
+	ctx, cancel := context.WithTimeoutCause(context.TODO(), identifierTimeout, errors.WithStack(context.DeadlineExceeded))
+	defer cancel()
+	if _, err := es.client.Identifier(ctx, &sourcepb.IdentifierRequest{Source: id.info()}); err != nil {
+		return nil, errors.Wrapf(err, "invalid %s source %s", scheme, ref)
+	}
+	return id, nil
+}
+
+type externalSourceHandler struct {
+	*Source
+	id *ExternalIdentifier
+
+	mu sync.Mutex
+	// pinned is the identifier with the version key of the first cache key
+	// so that the snapshot matches the cache key.
+	pinned *ExternalIdentifier
+}
+
+func (es *Source) Resolve(ctx context.Context, id source.Identifier, sm *session.Manager, _ solver.Vertex) (source.SourceInstance, error) {
+	eid, ok := id.(*ExternalIdentifier)
+	if !ok {
+		return nil, errors.Errorf("invalid external identifier %v", id)
+	}
+	return &externalSourceHandler{Source: es, id: eid}, nil
+}
+
+// CacheKey combines the source with the version key returned by the
+// provider. The platform is not part of the key so that the content is
+// shared between platforms unless the provider returns different keys.
+func (h *externalSourceHandler) CacheKey(ctx context.Context, g session.Group, index int) (string, string, solver.CacheOpts, bool, error) {
+	resp, err := h.client.CacheKey(ctx, &sourcepb.CacheKeyRequest{Source: h.id.info()})
+	if err != nil {
+		return "", "", nil, false, errors.Wrapf(err, "failed to get cache key for %s://%s", h.id.scheme, h.id.Ref)
+	}
+	if resp.Key == "" {
+		return "", "", nil, false, errors.Errorf("empty cache key for %s://%s", h.id.scheme, h.id.Ref)
+	}
+	pin := resp.Key
+	if resp.Digest != "" {
+		dgst, err := digest.Parse(resp.Digest)
+		if err != nil {
+			return "", "", nil, false, errors.Wrapf(err, "invalid digest for %s://%s", h.id.scheme, h.id.Ref)
+		}
+		pin = dgst.String()
+	}
+
+	h.mu.Lock()
+	if h.pinned == nil {
+		id := *h.id
+		id.Key = resp.Key
+		h.pinned = &id
+	}
+	h.mu.Unlock()
+
+	dt, err := json.Marshal(struct {
+		Scheme, Ref, Key string
+		Attrs            map[string]string
+	}{h.id.scheme, h.id.Ref, resp.Key, h.id.Attrs})
+	if err != nil {
+		return "", "", nil, false, err
+	}
+	return digest.FromBytes(dt).String(), pin, nil, true, nil
+}
+
+// PinnedIdentifier returns the identifier with the version key returned by
+// the provider.
+func (h *externalSourceHandler) PinnedIdentifier() source.Identifier {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.pinned == nil {
+		return nil
+	}
+	return h.pinned
+}
+
+func (h *externalSourceHandler) Snapshot(ctx context.Context, g session.Group) (ref cache.ImmutableRef, retErr error) {
+	h.mu.Lock()
+	pinned := h.pinned
+	h.mu.Unlock()
+	if pinned == nil {
+		if _, _, _, _, err := h.CacheKey(ctx, g, 0); err != nil {
+			return nil, err
+		}
+		h.mu.Lock()
+		pinned = h.pinned
+		h.mu.Unlock()
+	}
+
+	// the stream is canceled if the snapshot fails before it is read fully
+	streamCtx, cancel := context.WithCancelCause(ctx)
+	defer func() { cancel(errors.WithStack(context.Canceled)) }()
+
+	stream, err := h.client.Snapshot(streamCtx, &sourcepb.SnapshotRequest{
+		Source: h.id.info(),
+		Key:    pinned.Key,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to snapshot %s://%s", h.id.scheme, h.id.Ref)
+	}
+
+	newRef, err := h.cache.New(ctx, nil, g, cache.CachePolicyRetain, cache.WithDescription(h.id.scheme+"://"+h.id.Ref))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if retErr != nil && newRef != nil {
+			newRef.Release(context.WithoutCancel(ctx))
+		}
+	}()
+
+	mount, err := newRef.Mount(ctx, false, g)
+	if err != nil {
+		return nil, err
+	}
+
+	lm := snapshot.LocalMounter(mount)
+	dir, err := lm.Mount()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if lm != nil {
+			lm.Unmount()
+		}
+	}()
+
+	opts := &archive.TarOptions{
+		BestEffortXattrs: true,
+	}
+	if idmap := mount.IdentityMapping(); idmap != nil {
+		opts.IDMap = *idmap
+	}
+	// the protocol only allows uncompressed tar streams
+	sr := &streamReader{stream: stream, limit: h.maxSize}
+	if err := chrootarchive.UntarUncompressed(sr, dir, opts); err != nil {
+		return nil, errors.Wrapf(err, "failed to unpack %s://%s", h.id.scheme, h.id.Ref)
+	}
+
+	if err := lm.Unmount(); err != nil {
+		return nil, err
+	}
+	lm = nil
+
+	ref, err = newRef.Commit(ctx)
+	if err != nil {
+		return nil, err
+	}
+	newRef = nil
+	return ref, nil
+}
+
+// streamReader reads the tar stream sent by the provider up to limit bytes.
+type streamReader struct {
+	stream sourcepb.Source_SnapshotClient
+	buf    []byte
+	limit  int64
+	n      int64
+}
+
+func (r *streamReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		resp, err := r.stream.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return 0, io.EOF
+			}
+			return 0, err
+		}
+		r.n += int64(len(resp.Data))
+		if r.n > r.limit {
+			return 0, errors.Errorf("snapshot exceeds the maximum size of %d bytes", r.limit)
+		}
+		r.buf = resp.Data
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
@@ -0,0 +1,357 @@
+package external
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/containerd/containerd/v2/core/diff/apply"
+	ctdmetadata "github.com/containerd/containerd/v2/core/metadata"
+	"github.com/containerd/containerd/v2/core/snapshots"
+	"github.com/containerd/containerd/v2/plugins/content/local"
+	"github.com/containerd/containerd/v2/plugins/diff/walking"
+	"github.com/containerd/containerd/v2/plugins/snapshots/native"
+	"github.com/moby/buildkit/cache"
+	"github.com/moby/buildkit/cache/metadata"
+	"github.com/moby/buildkit/snapshot"
+	containerdsnapshot "github.com/moby/buildkit/snapshot/containerd"
+	"github.com/moby/buildkit/solver/llbsolver/provenance"
+	provenancetypes "github.com/moby/buildkit/solver/llbsolver/provenance/types"
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/source"
+	sourcepb "github.com/moby/buildkit/source/external/pb"
+	"github.com/moby/buildkit/util/grpcerrors"
+	"github.com/moby/buildkit/util/leaseutil"
+	"github.com/moby/buildkit/util/winlayers"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// testProvider serves objects of a bucket with versions as keys.
+type testProvider struct {
+	version   atomic.Int32
+	snapshots atomic.Int32
+	objects   map[string]string
+}
+
+func (p *testProvider) Identifier(ctx context.Context, req *sourcepb.IdentifierRequest) (*sourcepb.IdentifierResponse, error) {
+	if _, ok := p.objects[req.Source.Ref]; !ok {
+		return nil, status.Errorf(codes.NotFound, "object %s not found", req.Source.Ref)
+	}
+	return &sourcepb.IdentifierResponse{}, nil
+}
+
+func (p *testProvider) CacheKey(ctx context.Context, req *sourcepb.CacheKeyRequest) (*sourcepb.CacheKeyResponse, error) {
+	resp := &sourcepb.CacheKeyResponse{
+		Key: req.Source.Ref + "@" + strconv.Itoa(int(p.version.Load())),
+	}
+	if req.Source.Attrs["digest"] == "true" {
+		resp.Digest = digest.FromString(p.content(req.Source.Ref, int(p.version.Load()))).String()
+	}
+	return resp, nil
+}
+
+func (p *testProvider) Snapshot(req *sourcepb.SnapshotRequest, stream grpc.ServerStreamingServer[sourcepb.SnapshotResponse]) error {
+	p.snapshots.Add(1)
+	// the key selects the version of the object
+	ref, version, ok := strings.Cut(req.Key, "@")
+	if !ok || ref != req.Source.Ref {
+		return status.Errorf(codes.InvalidArgument, "invalid key %s", req.Key)
+	}
+	v, err := strconv.Atoi(version)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid key %s", req.Key)
+	}
+	content := p.content(req.Source.Ref, v)
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	if err := tw.WriteHeader(&tar.Header{
+		Name:     filepath.Base(req.Source.Ref),
+		Typeflag: tar.TypeReg,
+		Mode:     0644,
+		Size:     int64(len(content)),
+	}); err != nil {
+		return err
+	}
+	if _, err := tw.Write([]byte(content)); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	// send in small chunks to test reading across messages
+	dt := buf.Bytes()
+	for len(dt) > 0 {
+		n := min(len(dt), 100)
+		if err := stream.Send(&sourcepb.SnapshotResponse{Data: dt[:n]}); err != nil {
+			return err
+		}
+		dt = dt[n:]
+	}
+	return nil
+}
+
+func (p *testProvider) content(ref string, version int) string {
+	return p.objects[ref] + strconv.Itoa(version)
+}
+
+func TestExternalSource(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+
+	p := &testProvider{objects: map[string]string{
+		"bucket/dir/foo": "content",
+	}}
+	es := newExternalSource(t, p, Opt{})
+	require.Equal(t, []string{"s3"}, es.Schemes())
+
+	_, err := es.Identifier("s3", "bucket/missing", nil, nil)
+	require.ErrorContains(t, err, "object bucket/missing not found")
+
+	id, err := es.Identifier("s3", "bucket/dir/foo", map[string]string{"digest": "true"}, &pb.Platform{OS: "linux", Architecture: "amd64"})
+	require.NoError(t, err)
+
+	h, err := es.Resolve(ctx, id, nil, nil)
+	require.NoError(t, err)
+
+	require.Nil(t, h.(source.PinnedIdentifier).PinnedIdentifier())
+
+	k1, pin, _, done, err := h.CacheKey(ctx, nil, 0)
+	require.NoError(t, err)
+	require.True(t, done)
+	require.Equal(t, digest.FromString("content0").String(), pin)
+	pinned := h.(source.PinnedIdentifier).PinnedIdentifier().(*ExternalIdentifier)
+	require.Equal(t, "bucket/dir/foo@0", pinned.Key)
+	require.Empty(t, id.(*ExternalIdentifier).Key)
+
+	ref, err := h.Snapshot(ctx, nil)
+	require.NoError(t, err)
+	defer ref.Release(context.TODO())
+	require.Equal(t, "content0", readFile(ctx, t, ref, "foo"))
+
+	// the platform is not part of the cache key
+	id2, err := es.Identifier("s3", "bucket/dir/foo", map[string]string{"digest": "true"}, &pb.Platform{OS: "linux", Architecture: "arm64"})
+	require.NoError(t, err)
+	h, err = es.Resolve(ctx, id2, nil, nil)
+	require.NoError(t, err)
+	k2, _, _, _, err := h.CacheKey(ctx, nil, 0)
+	require.NoError(t, err)
+	require.Equal(t, k1, k2)
+
+	// a new version changes the cache key
+	p.version.Store(1)
+	h, err = es.Resolve(ctx, id, nil, nil)
+	require.NoError(t, err)
+	k3, pin, _, _, err := h.CacheKey(ctx, nil, 0)
+	require.NoError(t, err)
+	require.NotEqual(t, k1, k3)
+	require.Equal(t, digest.FromString("content1").String(), pin)
+
+	// the snapshot uses the version of the pinned cache key
+	p.version.Store(2)
+	ref2, err := h.Snapshot(ctx, nil)
+	require.NoError(t, err)
+	defer ref2.Release(context.TODO())
+	require.Equal(t, "content1", readFile(ctx, t, ref2, "foo"))
+	require.Equal(t, int32(2), p.snapshots.Load())
+
+	c := &provenance.Capture{}
+	require.NoError(t, h.(source.PinnedIdentifier).PinnedIdentifier().Capture(c, pin))
+	require.Equal(t, []provenancetypes.ExternalSource{{
+		Scheme: "s3",
+		Ref:    "bucket/dir/foo",
+		Key:    "bucket/dir/foo@1",
+		Digest: digest.FromString("content1"),
+	}}, c.Sources.External)
+	require.Empty(t, c.Sources.HTTP)
+}
+
+func TestExternalSourceNoDigest(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+
+	p := &testProvider{objects: map[string]string{
+		"bucket/dir/foo": "content",
+	}}
+	es := newExternalSource(t, p, Opt{})
+
+	id, err := es.Identifier("s3", "bucket/dir/foo", nil, nil)
+	require.NoError(t, err)
+	h, err := es.Resolve(ctx, id, nil, nil)
+	require.NoError(t, err)
+
+	// the snapshot gets the cache key if it wasn't requested before
+	ref, err := h.Snapshot(ctx, nil)
+	require.NoError(t, err)
+	defer ref.Release(context.TODO())
+	require.Equal(t, "content0", readFile(ctx, t, ref, "foo"))
+
+	_, pin, _, _, err := h.CacheKey(ctx, nil, 0)
+	require.NoError(t, err)
+	require.Equal(t, "bucket/dir/foo@0", pin)
+
+	// the key is recorded even without a digest
+	c := &provenance.Capture{}
+	require.NoError(t, h.(source.PinnedIdentifier).PinnedIdentifier().Capture(c, pin))
+	require.Equal(t, []provenancetypes.ExternalSource{{
+		Scheme: "s3",
+		Ref:    "bucket/dir/foo",
+		Key:    "bucket/dir/foo@0",
+	}}, c.Sources.External)
+}
+
+func TestExternalSourceMaxSize(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+
+	p := &testProvider{objects: map[string]string{
+		"bucket/dir/foo": "content",
+	}}
+	es := newExternalSource(t, p, Opt{MaxSize: 512})
+
+	id, err := es.Identifier("s3", "bucket/dir/foo", nil, nil)
+	require.NoError(t, err)
+	h, err := es.Resolve(ctx, id, nil, nil)
+	require.NoError(t, err)
+
+	_, err = h.Snapshot(ctx, nil)
+	require.ErrorContains(t, err, "snapshot exceeds the maximum size of 512 bytes")
+}
+
+func TestExternalSourceClose(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+
+	p := &testProvider{objects: map[string]string{
+		"bucket/dir/foo": "content",
+	}}
+	es := newExternalSource(t, p, Opt{})
+	require.NoError(t, es.Close())
+
+	id := &ExternalIdentifier{scheme: "s3", Ref: "bucket/dir/foo"}
+	h, err := es.Resolve(ctx, id, nil, nil)
+	require.NoError(t, err)
+	_, _, _, _, err = h.CacheKey(ctx, nil, 0)
+	require.Error(t, err)
+	require.Equal(t, codes.Canceled, status.Code(errors.Cause(err)))
+}
+
+func TestExternalSourceAddress(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		address, target, err string
+	}{
+		{"unix:///run/source.sock", "unix:///run/source.sock", ""},
+		{"tcp://localhost:1234", "localhost:1234", ""},
+		{"tcp://", "", "missing host"},
+		{"/run/source.sock", "", "unsupported address"},
+		{"", "", "unsupported address"},
+	} {
+		target, err := dialTarget(tc.address)
+		if tc.err != "" {
+			require.ErrorContains(t, err, tc.err)
+			continue
+		}
+		require.NoError(t, err)
+		require.Equal(t, tc.target, target)
+	}
+}
+
+func newExternalSource(t *testing.T, p sourcepb.SourceServer, opt Opt) *Source {
+	sock := filepath.Join(t.TempDir(), "source.sock")
+	l, err := net.Listen("unix", sock)
+	require.NoError(t, err)
+
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(grpcerrors.UnaryServerInterceptor),
+		grpc.StreamInterceptor(grpcerrors.StreamServerInterceptor),
+	)
+	sourcepb.RegisterSourceServer(server, p)
+	go server.Serve(l)
+	t.Cleanup(server.Stop)
+
+	opt.Scheme = "s3"
+	opt.Address = "unix://" + sock
+	opt.CacheAccessor = newCacheManager(t)
+	es, err := NewSource(opt)
+	require.NoError(t, err)
+	return es
+}
+
+func readFile(ctx context.Context, t *testing.T, ref cache.ImmutableRef, fp string) string {
+	mount, err := ref.Mount(ctx, true, nil)
+	require.NoError(t, err)
+
+	lm := snapshot.LocalMounter(mount)
+	dir, err := lm.Mount()
+	require.NoError(t, err)
+	defer lm.Unmount()
+
+	dt, err := os.ReadFile(filepath.Join(dir, fp))
+	require.NoError(t, err)
+	return string(dt)
+}
+
+func newCacheManager(t *testing.T) cache.Manager {
+	tmpdir := t.TempDir()
+
+	snapshotter, err := native.NewSnapshotter(filepath.Join(tmpdir, "snapshots"))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, snapshotter.Close())
+	})
+
+	store, err := local.NewStore(tmpdir)
+	require.NoError(t, err)
+
+	db, err := bolt.Open(filepath.Join(tmpdir, "containerdmeta.db"), 0644, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, db.Close())
+	})
+
+	mdb := ctdmetadata.NewDB(db, store, map[string]snapshots.Snapshotter{
+		"native": snapshotter,
+	})
+
+	md, err := metadata.NewStore(filepath.Join(tmpdir, "metadata.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, md.Close())
+	})
+
+	lm := leaseutil.WithNamespace(ctdmetadata.NewLeaseManager(mdb), "buildkit")
+	c := mdb.ContentStore()
+	applier := winlayers.NewFileSystemApplierWithWindows(c, apply.NewFileSystemApplier(c))
+	differ := winlayers.NewWalkingDiffWithWindows(c, walking.NewWalkingDiff(c))
+
+	cm, err := cache.NewManager(cache.ManagerOpt{
+		Snapshotter:    snapshot.FromContainerdSnapshotter("native", containerdsnapshot.NSSnapshotter("buildkit", mdb.Snapshotter("native")), nil),
+		MetadataStore:  md,
+		LeaseManager:   lm,
+		ContentStore:   c,
+		Applier:        applier,
+		Differ:         differ,
+		GarbageCollect: mdb.GarbageCollect,
+		Root:           tmpdir,
+		MountPoolRoot:  filepath.Join(tmpdir, "cachemounts"),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, cm.Close())
+	})
+	return cm
+}
@@ -27,6 +27,8 @@ type HTTPIdentifier struct {
 	GID              int
 	AuthHeaderSecret string
 	Header           []HeaderField
+	// Mirrors are URLs the file is fetched from if URL fails, in order.
+	Mirrors []string
 }
 
 type HeaderField struct {
@@ -0,0 +1,156 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/util/bklog"
+	"github.com/pkg/errors"
+)
+
+// maxRetries is the number of times a temporary error fetching a URL is
+// retried before the next mirror is tried.
+const maxRetries = 3
+
+// retryBackoff is the delay before the first retry. It doubles on every
+// retry. This is a variable so that it can be lowered in tests.
+var retryBackoff = time.Second
+
+type statusError struct {
+	StatusCode int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("invalid response status %d", e.StatusCode)
+}
+
+// validateMirrors checks the URL prefixes of the mirrors configured per host.
+func validateMirrors(mirrors map[string][]string) error {
+	for host, prefixes := range mirrors {
+		for _, p := range prefixes {
+			u, err := url.Parse(p)
+			if err != nil {
+				return errors.Wrapf(err, "invalid mirror for %s", host)
+			}
+			if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return errors.Errorf("invalid mirror %q for %s: expected http(s)://<host>[/<path>]", p, host)
+			}
+		}
+	}
+	return nil
+}
+
+// rewriteURL returns the URL with its scheme and host replaced by the mirror
+// prefix, and the prefix path prepended to its path.
+func rewriteURL(u *url.URL, prefix string) (string, error) {
+	m, err := url.Parse(prefix)
+	if err != nil {
+		return "", err
+	}
+	nu := *u
+	nu.Scheme = m.Scheme
+	nu.Host = m.Host
+	nu.User = nil
+	nu.Path = strings.TrimSuffix(m.Path, "/") + u.Path
+	nu.RawPath = ""
+	return nu.String(), nil
+}
+
+// urls returns the URLs the source can be fetched from in order: the source
+// URL followed by the mirrors of the source. Each of them is preceded by the
+// mirrors configured for its host.
+func (hs *httpSourceHandler) urls() []string {
+	var urls []string
+	seen := map[string]struct{}{}
+	add := func(u string) {
+		if _, ok := seen[u]; !ok {
+			seen[u] = struct{}{}
+			urls = append(urls, u)
+		}
+	}
+	for _, s := range append([]string{hs.src.URL}, hs.src.Mirrors...) {
+		if u, err := url.Parse(s); err == nil {
+			for _, prefix := range hs.mirrors[u.Host] {
+				if mu, err := rewriteURL(u, prefix); err == nil {
+					add(mu)
+				}
+			}
+		}
+		add(s)
+	}
+	return urls
+}
+
+// do sends a request to the URLs of the source in order and returns the
+// first successful response. Temporary errors are retried with backoff up to
+// retries times before the next URL is tried.
+func (hs *httpSourceHandler) do(ctx context.Context, g session.Group, client *http.Client, retries int, prepare func(*http.Request)) (*http.Response, error) {
+	urls := hs.urls()
+	if len(urls) == 1 {
+		return hs.doURL(ctx, g, client, urls[0], retries, prepare)
+	}
+	var errs error
+	for _, u := range urls {
+		resp, err := hs.doURL(ctx, g, client, u, retries, prepare)
+		if err == nil {
+			return resp, nil
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		bklog.G(ctx).WithError(err).Debugf("failed to fetch %s", u)
+		errs = multierror.Append(errs, errors.Wrapf(err, "failed to fetch %s", u))
+	}
+	return nil, errs
+}
+
+func (hs *httpSourceHandler) doURL(ctx context.Context, g session.Group, client *http.Client, u string, retries int, prepare func(*http.Request)) (*http.Response, error) {
+	backoff := retryBackoff
+	for i := 0; ; i++ {
+		req, err := hs.newHTTPRequest(ctx, g, u)
+		if err != nil {
+			return nil, err
+		}
+		if prepare != nil {
+			prepare(req)
+		}
+		resp, err := client.Do(req)
+		if err == nil {
+			if resp.StatusCode >= 200 && resp.StatusCode < 400 {
+				return resp, nil
+			}
+			resp.Body.Close()
+			err = errors.WithStack(&statusError{StatusCode: resp.StatusCode})
+		}
+		if i >= retries || !retryable(err) || ctx.Err() != nil {
+			return nil, err
+		}
+		bklog.G(ctx).WithError(err).Warnf("failed to fetch %s, retrying in %v", u, backoff)
+		select {
+		case <-ctx.Done():
+			return nil, context.Cause(ctx)
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// retryable returns true for network errors and server errors that may
+// succeed if the request is sent again.
+func retryable(err error) bool {
+	var se *statusError
+	if errors.As(err, &se) {
+		return se.StatusCode >= 500 || se.StatusCode == http.StatusTooManyRequests || se.StatusCode == http.StatusRequestTimeout
+	}
+	var ue *url.Error
+	if errors.As(err, &ue) {
+		return !errors.Is(ue.Err, context.Canceled) && !errors.Is(ue.Err, context.DeadlineExceeded)
+	}
+	return false
+}
@@ -0,0 +1,194 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/moby/buildkit/identity"
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/util/testutil/httpserver"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	retryBackoff = time.Millisecond
+}
+
+func TestHTTPMirrors(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+
+	hs, err := newHTTPSource(t)
+	require.NoError(t, err)
+
+	var failed atomic.Int32
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		failed.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer origin.Close()
+
+	server := httpserver.NewTestServer(map[string]httpserver.Response{
+		"/mirror/foo": {
+			Etag:    identity.NewID(),
+			Content: []byte("content1"),
+		},
+	})
+	defer server.Close()
+
+	id := &HTTPIdentifier{URL: origin.URL + "/foo", Mirrors: []string{server.URL + "/missing", server.URL + "/mirror/foo"}}
+
+	h, err := hs.Resolve(ctx, id, nil, nil)
+	require.NoError(t, err)
+
+	k, p, _, _, err := h.CacheKey(ctx, nil, 0)
+	require.NoError(t, err)
+
+	// same as TestHTTPSource as the file name is taken from the source URL
+	require.Equal(t, "sha256:0b1a154faa3003c1fbe7fda9c8a42d55fde2df2a2c405c32038f8ac7ed6b044a", k)
+	require.Equal(t, "sha256:d0b425e00e15a0d36b9b361f02bab63563aed6cb4665083905386c55d5b679fa", p)
+	require.Equal(t, int32(maxRetries+1), failed.Load())
+	require.Equal(t, 1, server.Stats("/mirror/foo").AllRequests)
+
+	ref, err := h.Snapshot(ctx, nil)
+	require.NoError(t, err)
+	defer ref.Release(context.TODO())
+
+	dt, err := readFile(ctx, ref, "foo")
+	require.NoError(t, err)
+	require.Equal(t, []byte("content1"), dt)
+}
+
+func TestHTTPMirrorsChecksum(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+
+	hs, err := newHTTPSource(t)
+	require.NoError(t, err)
+
+	bad := httpserver.NewTestServer(map[string]httpserver.Response{
+		"/foo": {Content: []byte("content-different")},
+	})
+	defer bad.Close()
+	good := httpserver.NewTestServer(map[string]httpserver.Response{
+		"/bar": {Content: []byte("content-correct")},
+	})
+	defer good.Close()
+
+	checksum := digest.FromBytes([]byte("content-correct"))
+
+	h, err := hs.Resolve(ctx, &HTTPIdentifier{URL: good.URL + "/foo", Checksum: checksum}, nil, nil)
+	require.NoError(t, err)
+	expectedKey, expectedPin, _, _, err := h.CacheKey(ctx, nil, 0)
+	require.NoError(t, err)
+
+	id := &HTTPIdentifier{URL: bad.URL + "/foo", Checksum: checksum, Mirrors: []string{good.URL + "/bar"}}
+	h, err = hs.Resolve(ctx, id, nil, nil)
+	require.NoError(t, err)
+
+	k, p, _, _, err := h.CacheKey(ctx, nil, 0)
+	require.NoError(t, err)
+	require.Equal(t, expectedKey, k)
+	require.Equal(t, expectedPin, p)
+
+	ref, err := h.Snapshot(ctx, nil)
+	require.NoError(t, err)
+	defer ref.Release(context.TODO())
+
+	dt, err := readFile(ctx, ref, "foo")
+	require.NoError(t, err)
+	require.Equal(t, []byte("content-correct"), dt)
+	require.Equal(t, 1, bad.Stats("/foo").AllRequests)
+	require.Equal(t, 1, good.Stats("/bar").AllRequests)
+}
+
+func TestHTTPMirrorsFail(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+
+	hs, err := newHTTPSource(t)
+	require.NoError(t, err)
+
+	server := httpserver.NewTestServer(map[string]httpserver.Response{})
+	defer server.Close()
+
+	id := &HTTPIdentifier{URL: server.URL + "/foo", Mirrors: []string{server.URL + "/bar"}}
+	h, err := hs.Resolve(ctx, id, nil, nil)
+	require.NoError(t, err)
+
+	_, _, _, _, err = h.CacheKey(ctx, nil, 0)
+	require.ErrorContains(t, err, "failed to fetch "+server.URL+"/foo: invalid response status 404")
+	require.ErrorContains(t, err, "failed to fetch "+server.URL+"/bar: invalid response status 404")
+}
+
+func TestHTTPConfigMirrors(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+
+	server := httpserver.NewTestServer(map[string]httpserver.Response{
+		"/cache/dl/foo": {Content: []byte("content1")},
+	})
+	defer server.Close()
+
+	hs, err := newHTTPSourceWithOpt(t, Opt{
+		Mirrors: map[string][]string{
+			"origin.invalid": {server.URL + "/cache/"},
+		},
+	})
+	require.NoError(t, err)
+
+	h, err := hs.Resolve(ctx, &HTTPIdentifier{URL: "http://origin.invalid/dl/foo"}, nil, nil)
+	require.NoError(t, err)
+
+	_, p, _, _, err := h.CacheKey(ctx, nil, 0)
+	require.NoError(t, err)
+	require.Equal(t, digest.FromBytes([]byte("content1")).String(), p)
+	require.Equal(t, 1, server.Stats("/cache/dl/foo").AllRequests)
+
+	_, err = newHTTPSourceWithOpt(t, Opt{
+		Mirrors: map[string][]string{
+			"origin.invalid": {"mirror.invalid"},
+		},
+	})
+	require.ErrorContains(t, err, "invalid mirror")
+}
+
+func TestHTTPMirrorsIdentifier(t *testing.T) {
+	t.Parallel()
+
+	hs := &httpSource{}
+	id, err := hs.Identifier("https", "example.com/foo", map[string]string{
+		pb.AttrHTTPMirrors: "https://mirror1.example.com/foo\nhttp://mirror2.example.com/foo\n",
+	}, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"https://mirror1.example.com/foo", "http://mirror2.example.com/foo"}, id.(*HTTPIdentifier).Mirrors)
+
+	_, err = hs.Identifier("https", "example.com/foo", map[string]string{
+		pb.AttrHTTPMirrors: "ftp://mirror.example.com/foo",
+	}, nil)
+	require.ErrorContains(t, err, "only http and https URLs are supported")
+}
+
+func TestRewriteURL(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		url, prefix, expected string
+	}{
+		{"https://example.com/a/b.tgz", "https://mirror.example.com", "https://mirror.example.com/a/b.tgz"},
+		{"https://example.com/a/b.tgz", "http://mirror.example.com/example/", "http://mirror.example.com/example/a/b.tgz"},
+		{"https://user@example.com/a%20b.tgz?x=1", "https://mirror.example.com/p", "https://mirror.example.com/p/a%20b.tgz?x=1"},
+	} {
+		u, err := url.Parse(tc.url)
+		require.NoError(t, err)
+		got, err := rewriteURL(u, tc.prefix)
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, got)
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/hashicorp/go-multierror"
 	"github.com/moby/buildkit/cache"
 	"github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/session/secrets"
@@ -49,11 +50,15 @@ var supportedUserDefinedHeaders = map[string]bool{
 type Opt struct {
 	CacheAccessor cache.Accessor
 	Transport     http.RoundTripper
+	// Mirrors maps a host to URL prefixes that replace the scheme and host of
+	// the URLs of that host. They are tried in order before the URL itself.
+	Mirrors map[string][]string
 }
 
 type httpSource struct {
 	cache     cache.Accessor
 	transport http.RoundTripper
+	mirrors   map[string][]string
 }
 
 func NewSource(opt Opt) (source.Source, error) {
@@ -61,9 +66,13 @@ func NewSource(opt Opt) (source.Source, error) {
 	if transport == nil {
 		transport = tracing.DefaultTransport
 	}
+	if err := validateMirrors(opt.Mirrors); err != nil {
+		return nil, err
+	}
 	hs := &httpSource{
 		cache:     opt.CacheAccessor,
 		transport: transport,
+		mirrors:   opt.Mirrors,
 	}
 	return hs, nil
 }
@@ -108,6 +117,20 @@ func (hs *httpSource) Identifier(scheme, ref string, attrs map[string]string, pl
 			id.GID = int(i)
 		case pb.AttrHTTPAuthHeaderSecret:
 			id.AuthHeaderSecret = v
+		case pb.AttrHTTPMirrors:
+			for _, m := range strings.Split(v, "\n") {
+				if m == "" {
+					continue
+				}
+				u, err := url.Parse(m)
+				if err != nil {
+					return nil, errors.Wrapf(err, "invalid mirror %q", m)
+				}
+				if u.Scheme != "http" && u.Scheme != "https" {
+					return nil, errors.Errorf("invalid mirror %q: only http and https URLs are supported", m)
+				}
+				id.Mirrors = append(id.Mirrors, m)
+			}
 		default:
 			if name, found := strings.CutPrefix(k, pb.AttrHTTPHeaderPrefix); found {
 				name = http.CanonicalHeaderKey(name)
@@ -218,16 +241,13 @@ func (hs *httpSourceHandler) CacheKey(ctx context.Context, g session.Group, inde
 		return "", "", nil, false, errors.Wrapf(err, "failed to search metadata for %s", uh)
 	}
 
-	req, err := hs.newHTTPRequest(ctx, g)
-	if err != nil {
-		return "", "", nil, false, err
-	}
 	m := map[string]cacheRefMetadata{}
 
 	// If we request a single ETag in 'If-None-Match', some servers omit the
 	// unambiguous ETag in their response.
 	// See: https://github.com/moby/buildkit/issues/905
 	var onlyETag string
+	var ifNoneMatch string
 
 	if len(mds) > 0 {
 		for _, md := range mds {
@@ -249,7 +269,7 @@ func (hs *httpSourceHandler) CacheKey(ctx context.Context, g session.Group, inde
 			for t := range m {
 				etags = append(etags, t)
 			}
-			req.Header.Set("If-None-Match", strings.Join(etags, ", "))
+			ifNoneMatch = strings.Join(etags, ", ")
 
 			if len(etags) == 1 {
 				onlyETag = etags[0]
@@ -263,11 +283,15 @@ func (hs *httpSourceHandler) CacheKey(ctx context.Context, g session.Group, inde
 	// though they return ETag-s. So first, optionally try a HEAD request with
 	// manual ETag value comparison.
 	if len(m) > 0 {
-		req.Method = "HEAD"
-		// we need to add accept-encoding header manually because stdlib only adds it to GET requests
-		// some servers will return different etags if Accept-Encoding header is different
-		req.Header.Set("Accept-Encoding", "gzip")
-		resp, err := client.Do(req)
+		// errors are ignored and the HEAD request isn't retried as the GET
+		// request follows
+		resp, err := hs.do(ctx, g, client, 0, func(req *http.Request) {
+			req.Method = "HEAD"
+			req.Header.Set("If-None-Match", ifNoneMatch)
+			// we need to add accept-encoding header manually because stdlib only adds it to GET requests
+			// some servers will return different etags if Accept-Encoding header is different
+			req.Header.Set("Accept-Encoding", "gzip")
+		})
 		if err == nil {
 			if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusNotModified {
 				respETag := etagValue(resp.Header.Get("ETag"))
@@ -291,20 +315,20 @@ func (hs *httpSourceHandler) CacheKey(ctx context.Context, g session.Group, inde
 			}
 			resp.Body.Close()
 		}
-		req.Method = "GET"
-		// Unset explicit Accept-Encoding for GET, otherwise the go http library will not
-		// transparently decompress the response body when it is gzipped. It will still add
-		// this header implicitly when the request is made though.
-		req.Header.Del("Accept-Encoding")
 	}
 
-	resp, err := client.Do(req)
+	// Accept-Encoding isn't set explicitly for GET, otherwise the go http
+	// library will not transparently decompress the response body when it is
+	// gzipped. It will still add this header implicitly when the request is
+	// made though.
+	resp, err := hs.do(ctx, g, client, maxRetries, func(req *http.Request) {
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+	})
 	if err != nil {
 		return "", "", nil, false, err
 	}
-	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
-		return "", "", nil, false, errors.Errorf("invalid response status %d", resp.StatusCode)
-	}
 	if resp.StatusCode == http.StatusNotModified {
 		respETag := etagValue(resp.Header.Get("ETag"))
 		if respETag == "" && onlyETag != "" {
@@ -331,6 +355,7 @@ func (hs *httpSourceHandler) CacheKey(ctx context.Context, g session.Group, inde
 	}
 
 	ref, dgst, err := hs.save(ctx, resp, g)
+	resp.Body.Close()
 	if err != nil {
 		return "", "", nil, false, err
 	}
@@ -473,35 +498,43 @@ func (hs *httpSourceHandler) Snapshot(ctx context.Context, g session.Group) (cac
 		}
 	}
 
-	req, err := hs.newHTTPRequest(ctx, g)
-	if err != nil {
-		return nil, err
-	}
-
 	client := hs.client(g)
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer func() {
-		_ = resp.Body.Close()
-	}()
-
-	ref, dgst, err := hs.save(ctx, resp, g)
-	if err != nil {
-		return nil, err
-	}
-	if dgst != hs.cacheKey {
-		ref.Release(context.TODO())
-		return nil, errors.Errorf("digest mismatch %s: %s", dgst, hs.cacheKey)
+	// a mirror serving different content is skipped
+	urls := hs.urls()
+	var errs error
+	for _, u := range urls {
+		resp, err := hs.doURL(ctx, g, client, u, maxRetries, nil)
+		if err != nil {
+			if len(urls) == 1 || ctx.Err() != nil {
+				return nil, err
+			}
+			errs = multierror.Append(errs, errors.Wrapf(err, "failed to fetch %s", u))
+			continue
+		}
+		ref, dgst, err := hs.save(ctx, resp, g)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if dgst != hs.cacheKey {
+			ref.Release(context.TODO())
+			err := errors.Errorf("digest mismatch %s: %s", dgst, hs.cacheKey)
+			if len(urls) == 1 {
+				return nil, err
+			}
+			errs = multierror.Append(errs, errors.Wrapf(err, "failed to fetch %s", u))
+			continue
+		}
+		return ref, nil
 	}
-
-	return ref, nil
+	return nil, errs
 }
 
-func (hs *httpSourceHandler) newHTTPRequest(ctx context.Context, g session.Group) (*http.Request, error) {
-	req, err := http.NewRequest(http.MethodGet, hs.src.URL, nil)
+// newHTTPRequest returns a request for u, which is either the source URL or
+// one of its mirrors.
+func (hs *httpSourceHandler) newHTTPRequest(ctx context.Context, g session.Group, u string) (*http.Request, error) {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -516,11 +549,14 @@ func (hs *httpSourceHandler) newHTTPRequest(ctx context.Context, g session.Group
 		token bool
 	}
 
+	// the secret set for the source isn't sent to the mirrors
+	explicitSecret := hs.src.AuthHeaderSecret != "" && u == hs.src.URL
+
 	var secretNames []authSecret
-	if hs.src.AuthHeaderSecret != "" {
+	if explicitSecret {
 		secretNames = append(secretNames, authSecret{name: hs.src.AuthHeaderSecret})
 	} else {
-		u, err := url.Parse(hs.src.URL)
+		u, err := url.Parse(u)
 		if err == nil {
 			secretNames = append(secretNames, authSecret{name: HTTPAuthHeaderSecretPrefix + u.Hostname()})
 			secretNames = append(secretNames, authSecret{name: HTTPAuthTokenSecretPrefix + u.Hostname(), token: true})
@@ -540,7 +576,7 @@ func (hs *httpSourceHandler) newHTTPRequest(ctx context.Context, g session.Group
 			req.Header.Set("Authorization", v)
 			return nil
 		})
-		if err != nil && hs.src.AuthHeaderSecret != "" {
+		if err != nil && explicitSecret {
 			return nil, errors.Wrapf(err, "failed to retrieve HTTP auth secret %s", hs.src.AuthHeaderSecret)
 		}
 	}
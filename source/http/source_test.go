@@ -307,6 +307,10 @@ func readFile(ctx context.Context, ref cache.ImmutableRef, fp string) ([]byte, e
 }
 
 func newHTTPSource(t *testing.T) (source.Source, error) {
+	return newHTTPSourceWithOpt(t, Opt{})
+}
+
+func newHTTPSourceWithOpt(t *testing.T, opt Opt) (source.Source, error) {
 	tmpdir := t.TempDir()
 
 	snapshotter, err := native.NewSnapshotter(filepath.Join(tmpdir, "snapshots"))
@@ -365,7 +369,6 @@ func newHTTPSource(t *testing.T) (source.Source, error) {
 		require.NoError(t, cm.Close())
 	})
 
-	return NewSource(Opt{
-		CacheAccessor: cm,
-	})
+	opt.CacheAccessor = cm
+	return NewSource(opt)
 }
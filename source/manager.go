@@ -36,6 +36,15 @@ type SourceInstance interface {
 	Snapshot(ctx context.Context, g session.Group) (cache.ImmutableRef, error)
 }
 
+// PinnedIdentifier is implemented by source instances that resolve more
+// details of their identifier together with the pin, e.g. the result of
+// verifying the source.
+type PinnedIdentifier interface {
+	// PinnedIdentifier returns the identifier with the details resolved by
+	// CacheKey, or nil if there are none.
+	PinnedIdentifier() Identifier
+}
+
 type Manager struct {
 	mu      sync.Mutex
 	schemes map[string]Source
@@ -55,6 +64,14 @@ func (sm *Manager) Register(src Source) {
 	sm.mu.Unlock()
 }
 
+// Registered returns true if a source is registered for the scheme.
+func (sm *Manager) Registered(scheme string) bool {
+	sm.mu.Lock()
+	_, ok := sm.schemes[scheme]
+	sm.mu.Unlock()
+	return ok
+}
+
 func (sm *Manager) Identifier(op *pb.Op_Source, platform *pb.Platform) (Identifier, error) {
 	scheme, ref, ok := strings.Cut(op.Source.Identifier, "://")
 	if !ok {
@@ -0,0 +1,49 @@
+package ociartifact
+
+import (
+	"github.com/containerd/containerd/v2/pkg/reference"
+	"github.com/moby/buildkit/solver/llbsolver/provenance"
+	provenancetypes "github.com/moby/buildkit/solver/llbsolver/provenance/types"
+	"github.com/moby/buildkit/source"
+	srctypes "github.com/moby/buildkit/source/types"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+type ArtifactIdentifier struct {
+	Reference reference.Spec
+	// Platform selects the manifest if the reference points to an index.
+	Platform *ocispecs.Platform
+}
+
+func NewArtifactIdentifier(str string) (*ArtifactIdentifier, error) {
+	ref, err := reference.Parse(str)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if ref.Object == "" {
+		return nil, errors.WithStack(reference.ErrObjectRequired)
+	}
+	return &ArtifactIdentifier{Reference: ref}, nil
+}
+
+var _ source.Identifier = (*ArtifactIdentifier)(nil)
+
+func (*ArtifactIdentifier) Scheme() string {
+	return srctypes.OCIArtifactScheme
+}
+
+func (id *ArtifactIdentifier) Capture(c *provenance.Capture, pin string) error {
+	dgst, err := digest.Parse(pin)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse artifact digest %s", pin)
+	}
+	c.AddArtifact(provenancetypes.ArtifactSource{
+		Ref:      id.Reference.String(),
+		Platform: id.Platform,
+		Digest:   dgst,
+	})
+	return nil
+}
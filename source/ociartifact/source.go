@@ -0,0 +1,291 @@
+package ociartifact
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/containerd/containerd/v2/core/images"
+	"github.com/containerd/containerd/v2/core/remotes"
+	"github.com/containerd/containerd/v2/core/remotes/docker"
+	"github.com/containerd/platforms"
+	"github.com/moby/buildkit/cache"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/snapshot"
+	"github.com/moby/buildkit/solver"
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/source"
+	srctypes "github.com/moby/buildkit/source/types"
+	"github.com/moby/buildkit/util/contentutil"
+	"github.com/moby/buildkit/util/imageutil"
+	"github.com/moby/buildkit/util/progress"
+	"github.com/moby/buildkit/util/resolver"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+type Opt struct {
+	CacheAccessor cache.Accessor
+	RegistryHosts docker.RegistryHosts
+}
+
+type artifactSource struct {
+	cache cache.Accessor
+	hosts docker.RegistryHosts
+}
+
+// NewSource returns a source that places the blobs of an OCI artifact as
+// files in a snapshot. Files are named by the org.opencontainers.image.title
+// annotation of the blob, or by the encoded digest if it isn't set.
+func NewSource(opt Opt) (source.Source, error) {
+	return &artifactSource{
+		cache: opt.CacheAccessor,
+		hosts: opt.RegistryHosts,
+	}, nil
+}
+
+func (as *artifactSource) Schemes() []string {
+	return []string{srctypes.OCIArtifactScheme}
+}
+
+func (as *artifactSource) Identifier(scheme, ref string, attrs map[string]string, platform *pb.Platform) (source.Identifier, error) {
+	id, err := NewArtifactIdentifier(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	if platform != nil {
+		id.Platform = &ocispecs.Platform{
+			OS:           platform.OS,
+			Architecture: platform.Architecture,
+			Variant:      platform.Variant,
+			OSVersion:    platform.OSVersion,
+		}
+		if platform.OSFeatures != nil {
+			id.Platform.OSFeatures = slices.Clone(platform.OSFeatures)
+		}
+	}
+
+	return id, nil
+}
+
+type artifactSourceHandler struct {
+	*artifactSource
+	src ArtifactIdentifier
+	sm  *session.Manager
+
+	root     ocispecs.Descriptor
+	manifest *ocispecs.Manifest
+}
+
+func (as *artifactSource) Resolve(ctx context.Context, id source.Identifier, sm *session.Manager, _ solver.Vertex) (source.SourceInstance, error) {
+	artifactIdentifier, ok := id.(*ArtifactIdentifier)
+	if !ok {
+		return nil, errors.Errorf("invalid oci artifact identifier %v", id)
+	}
+
+	return &artifactSourceHandler{
+		artifactSource: as,
+		src:            *artifactIdentifier,
+		sm:             sm,
+	}, nil
+}
+
+func (h *artifactSourceHandler) resolver(g session.Group) *resolver.Resolver {
+	return resolver.DefaultPool.GetResolver(h.hosts, h.src.Reference.String(), "pull", h.sm, g)
+}
+
+func (h *artifactSourceHandler) CacheKey(ctx context.Context, g session.Group, index int) (string, string, solver.CacheOpts, bool, error) {
+	if h.manifest == nil {
+		if err := h.resolve(ctx, g); err != nil {
+			return "", "", nil, false, err
+		}
+	}
+	dt, err := json.Marshal(struct {
+		Artifact digest.Digest
+		Layers   []ocispecs.Descriptor
+	}{
+		Artifact: h.root.Digest,
+		Layers:   h.manifest.Layers,
+	})
+	if err != nil {
+		return "", "", nil, false, err
+	}
+	return digest.FromBytes(dt).String(), h.root.Digest.String(), nil, true, nil
+}
+
+// resolve resolves the reference and reads the manifest of the artifact,
+// selecting it by platform if the reference points to an index.
+func (h *artifactSourceHandler) resolve(ctx context.Context, g session.Group) error {
+	ref := h.src.Reference.String()
+	r := h.resolver(g)
+	name, desc, err := r.Resolve(ctx, ref)
+	if err != nil {
+		return err
+	}
+	fetcher, err := r.Fetcher(ctx, name)
+	if err != nil {
+		return err
+	}
+	h.root = desc
+
+	provider := contentutil.FromFetcher(fetcher)
+
+	if images.IsIndexType(desc.MediaType) {
+		var idx ocispecs.Index
+		if err := imageutil.ReadJSON(ctx, provider, desc, &idx); err != nil {
+			return err
+		}
+		platform := platforms.Default()
+		if p := h.src.Platform; p != nil {
+			platform = platforms.Only(*p)
+		}
+		if desc, err = imageutil.SelectManifest(idx.Manifests, platform); err != nil {
+			return errors.Wrapf(err, "failed to select manifest for %s", ref)
+		}
+	}
+	if !images.IsManifestType(desc.MediaType) {
+		return errors.Errorf("unsupported media type %s for %s", desc.MediaType, ref)
+	}
+
+	var mfst ocispecs.Manifest
+	if err := imageutil.ReadJSON(ctx, provider, desc, &mfst); err != nil {
+		return err
+	}
+	h.manifest = &mfst
+	return nil
+}
+
+func (h *artifactSourceHandler) Snapshot(ctx context.Context, g session.Group) (ref cache.ImmutableRef, retErr error) {
+	if h.manifest == nil {
+		if err := h.resolve(ctx, g); err != nil {
+			return nil, err
+		}
+	}
+	files, err := layerFiles(h.manifest.Layers)
+	if err != nil {
+		return nil, err
+	}
+
+	fetcher, err := h.resolver(g).Fetcher(ctx, h.src.Reference.String())
+	if err != nil {
+		return nil, err
+	}
+
+	newRef, err := h.cache.New(ctx, nil, g, cache.CachePolicyRetain, cache.WithDescription(fmt.Sprintf("oci artifact %s", h.src.Reference.String())))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if retErr != nil && newRef != nil {
+			newRef.Release(context.WithoutCancel(ctx))
+		}
+	}()
+
+	mount, err := newRef.Mount(ctx, false, g)
+	if err != nil {
+		return nil, err
+	}
+
+	lm := snapshot.LocalMounter(mount)
+	dir, err := lm.Mount()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if lm != nil {
+			lm.Unmount()
+		}
+	}()
+
+	uid, gid := 0, 0
+	if idmap := mount.IdentityMapping(); idmap != nil {
+		if uid, gid, err = idmap.ToHost(uid, gid); err != nil {
+			return nil, err
+		}
+	}
+
+	done := progress.OneOff(ctx, fmt.Sprintf("pulling %d files from %s", len(files), h.src.Reference.String()))
+	for i, desc := range h.manifest.Layers {
+		if err := writeBlob(ctx, fetcher, desc, filepath.Join(dir, files[i]), uid, gid); err != nil {
+			return nil, done(err)
+		}
+	}
+	done(nil)
+
+	if err := lm.Unmount(); err != nil {
+		return nil, err
+	}
+	lm = nil
+
+	ref, err = newRef.Commit(ctx)
+	if err != nil {
+		return nil, err
+	}
+	newRef = nil
+	return ref, nil
+}
+
+// layerFiles returns the file names for the blobs of the artifact.
+func layerFiles(layers []ocispecs.Descriptor) ([]string, error) {
+	files := make([]string, 0, len(layers))
+	for _, desc := range layers {
+		name, ok := desc.Annotations[ocispecs.AnnotationTitle]
+		if !ok {
+			name = desc.Digest.Encoded()
+		}
+		name = filepath.FromSlash(name)
+		if !filepath.IsLocal(name) {
+			return nil, errors.Errorf("invalid file name %q for blob %s", name, desc.Digest)
+		}
+		name = filepath.Clean(name)
+		if slices.Contains(files, name) {
+			return nil, errors.Errorf("duplicate file name %q for blob %s", name, desc.Digest)
+		}
+		files = append(files, name)
+	}
+	return files, nil
+}
+
+func writeBlob(ctx context.Context, fetcher remotes.Fetcher, desc ocispecs.Descriptor, p string, uid, gid int) error {
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	rc, err := fetcher.Fetch(ctx, desc)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	f, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	verifier := desc.Digest.Verifier()
+	n, err := io.Copy(io.MultiWriter(f, verifier), io.LimitReader(rc, desc.Size))
+	if err != nil {
+		return err
+	}
+	if n != desc.Size || !verifier.Verified() {
+		return errors.Errorf("digest mismatch for blob %s", desc.Digest)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if uid != 0 || gid != 0 {
+		if err := os.Lchown(p, uid, gid); err != nil {
+			return err
+		}
+	}
+	mtime := time.Unix(0, 0)
+	return os.Chtimes(p, mtime, mtime)
+}
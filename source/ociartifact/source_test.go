@@ -0,0 +1,103 @@
+package ociartifact
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/moby/buildkit/solver/llbsolver/provenance"
+	provenancetypes "github.com/moby/buildkit/solver/llbsolver/provenance/types"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdentifier(t *testing.T) {
+	as := &artifactSource{}
+	id, err := as.Identifier("oci-artifact", "ghcr.io/foo/chart:1.0", nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, "ghcr.io/foo/chart:1.0", id.(*ArtifactIdentifier).Reference.String())
+
+	_, err = as.Identifier("oci-artifact", "ghcr.io/foo/chart", nil, nil)
+	require.Error(t, err)
+}
+
+func TestCapture(t *testing.T) {
+	id, err := NewArtifactIdentifier("ghcr.io/foo/chart:1.0")
+	require.NoError(t, err)
+	id.Platform = &ocispecs.Platform{OS: "linux", Architecture: "amd64"}
+	dgst := digest.FromString("manifest")
+
+	var c provenance.Capture
+	require.NoError(t, id.Capture(&c, dgst.String()))
+	require.Empty(t, c.Sources.Images)
+	require.Equal(t, []provenancetypes.ArtifactSource{{
+		Ref:      "ghcr.io/foo/chart:1.0",
+		Platform: id.Platform,
+		Digest:   dgst,
+	}}, c.Sources.Artifacts)
+
+	pr, err := provenance.NewPredicate(&c)
+	require.NoError(t, err)
+	require.Len(t, pr.Materials, 1)
+	require.Equal(t, "pkg:oci/chart@"+url.PathEscape(dgst.String())+"?platform=linux%2Famd64&repository_url=ghcr.io%2Ffoo%2Fchart&tag=1.0", pr.Materials[0].URI)
+	require.Equal(t, dgst.Encoded(), pr.Materials[0].Digest["sha256"])
+
+	require.Error(t, id.Capture(&c, "invalid"))
+}
+
+func TestLayerFiles(t *testing.T) {
+	blob := digest.FromString("blob")
+	files, err := layerFiles([]ocispecs.Descriptor{
+		{Digest: digest.FromString("a"), Annotations: map[string]string{ocispecs.AnnotationTitle: "chart.tgz"}},
+		{Digest: digest.FromString("b"), Annotations: map[string]string{ocispecs.AnnotationTitle: "bin/./module.wasm"}},
+		{Digest: blob},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"chart.tgz", filepath.Join("bin", "module.wasm"), blob.Encoded()}, files)
+
+	_, err = layerFiles([]ocispecs.Descriptor{{Digest: blob, Annotations: map[string]string{ocispecs.AnnotationTitle: "../escape"}}})
+	require.ErrorContains(t, err, "invalid file name")
+	_, err = layerFiles([]ocispecs.Descriptor{{Digest: blob, Annotations: map[string]string{ocispecs.AnnotationTitle: "/abs"}}})
+	require.ErrorContains(t, err, "invalid file name")
+
+	_, err = layerFiles([]ocispecs.Descriptor{
+		{Digest: digest.FromString("a"), Annotations: map[string]string{ocispecs.AnnotationTitle: "same"}},
+		{Digest: digest.FromString("b"), Annotations: map[string]string{ocispecs.AnnotationTitle: "./same"}},
+	})
+	require.ErrorContains(t, err, "duplicate file name")
+}
+
+func TestWriteBlob(t *testing.T) {
+	dt := []byte("hello")
+	desc := ocispecs.Descriptor{Digest: digest.FromBytes(dt), Size: int64(len(dt))}
+	f := fetcher{desc.Digest: dt}
+
+	p := filepath.Join(t.TempDir(), "sub", "hello.txt")
+	require.NoError(t, writeBlob(context.TODO(), f, desc, p, 0, 0))
+	got, err := os.ReadFile(p)
+	require.NoError(t, err)
+	require.Equal(t, dt, got)
+	st, err := os.Stat(p)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), st.ModTime().Unix())
+
+	bad := ocispecs.Descriptor{Digest: digest.FromString("other"), Size: int64(len(dt))}
+	f[bad.Digest] = dt
+	require.ErrorContains(t, writeBlob(context.TODO(), f, bad, filepath.Join(t.TempDir(), "bad"), 0, 0), "digest mismatch")
+}
+
+type fetcher map[digest.Digest][]byte
+
+func (f fetcher) Fetch(ctx context.Context, desc ocispecs.Descriptor) (io.ReadCloser, error) {
+	dt, ok := f[desc.Digest]
+	if !ok {
+		return nil, errors.Errorf("not found: %s", desc.Digest)
+	}
+	return io.NopCloser(bytes.NewReader(dt)), nil
+}
@@ -0,0 +1,219 @@
+package pkgregistry
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1" //nolint:gosec // used by the npm cache index
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/moby/buildkit/version"
+	"github.com/pkg/errors"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/sumdb/dirhash"
+)
+
+// fetchGoModule downloads the module from the proxy to the download cache of
+// the GOMODCACHE layout in dir, verifying it against the go.sum hashes. The
+// .info file is generated locally from the version.
+func fetchGoModule(ctx context.Context, client *http.Client, id *GoModuleIdentifier, dir string) error {
+	escPath, err := module.EscapePath(id.Path)
+	if err != nil {
+		return err
+	}
+	escVersion, err := module.EscapeVersion(id.Version)
+	if err != nil {
+		return err
+	}
+	base := filepath.Join(dir, "cache", "download", filepath.FromSlash(escPath), "@v", escVersion)
+	if err := os.MkdirAll(filepath.Dir(base), 0755); err != nil {
+		return err
+	}
+
+	u, err := id.url(".mod")
+	if err != nil {
+		return err
+	}
+	mod, err := get(ctx, client, u)
+	if err != nil {
+		return err
+	}
+	if id.ModSum != "" {
+		sum, err := dirhash.Hash1([]string{"go.mod"}, func(string) (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(mod)), nil
+		})
+		if err != nil {
+			return err
+		}
+		if sum != id.ModSum {
+			return errors.Errorf("checksum mismatch for %s@%s/go.mod: got %s, expected %s", id.Path, id.Version, sum, id.ModSum)
+		}
+	}
+	if err := os.WriteFile(base+".mod", mod, 0644); err != nil {
+		return err
+	}
+
+	// The .info file served by the proxy can't be verified against go.sum,
+	// so only the version is written. The commit time is optional.
+	info, err := json.Marshal(struct{ Version string }{id.Version})
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(base+".info", info, 0644); err != nil {
+		return err
+	}
+
+	if id.Sum == "" {
+		return nil
+	}
+	if u, err = id.url(".zip"); err != nil {
+		return err
+	}
+	if err := download(ctx, client, u, base+".zip", nil); err != nil {
+		return err
+	}
+	sum, err := dirhash.HashZip(base+".zip", dirhash.Hash1)
+	if err != nil {
+		return err
+	}
+	if sum != id.Sum {
+		return errors.Errorf("checksum mismatch for %s@%s: got %s, expected %s", id.Path, id.Version, sum, id.Sum)
+	}
+	return os.WriteFile(base+".ziphash", []byte(sum), 0644)
+}
+
+// npmCacheEntry is an entry of the index of the npm content cache
+// (_cacache/index-v5).
+type npmCacheEntry struct {
+	Key       string           `json:"key"`
+	Integrity string           `json:"integrity"`
+	Time      int64            `json:"time"`
+	Size      int64            `json:"size"`
+	Metadata  npmCacheMetadata `json:"metadata"`
+}
+
+type npmCacheMetadata struct {
+	URL        string            `json:"url"`
+	ReqHeaders map[string]string `json:"reqHeaders"`
+	ResHeaders map[string]string `json:"resHeaders"`
+}
+
+// fetchNPMPackage downloads the package tarball to the npm content cache in
+// dir, verifying it against the integrity, and adds it to the cache index
+// under its resolved URL so that it can be installed with npm ci --offline.
+func fetchNPMPackage(ctx context.Context, client *http.Client, id *NPMPackageIdentifier, dir string) error {
+	sri, err := parseIntegrity(id.Integrity)
+	if err != nil {
+		return err
+	}
+	cacheDir := filepath.Join(dir, "_cacache")
+	p := filepath.Join(cacheDir, "content-v2", sri.algorithm, splitHash(hex.EncodeToString(sri.sum)))
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+
+	h := sri.hash()
+	if err := download(ctx, client, id.fetchURL(), p, h); err != nil {
+		return err
+	}
+	if !bytes.Equal(h.Sum(nil), sri.sum) {
+		return errors.Errorf("integrity mismatch for %s@%s: expected %s", id.Name, id.Version, sri)
+	}
+	st, err := os.Stat(p)
+	if err != nil {
+		return err
+	}
+
+	key := "make-fetch-happen:request-cache:" + id.URL
+	dt, err := json.Marshal(npmCacheEntry{
+		Key:       key,
+		Integrity: sri.String(),
+		Size:      st.Size(),
+		Metadata: npmCacheMetadata{
+			URL:        id.URL,
+			ReqHeaders: map[string]string{},
+			ResHeaders: map[string]string{},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	keyHash := sha256.Sum256([]byte(key))
+	entryHash := sha1.Sum(dt) //nolint:gosec // format of the npm cache index
+	bucket := filepath.Join(cacheDir, "index-v5", splitHash(hex.EncodeToString(keyHash[:])))
+	if err := os.MkdirAll(filepath.Dir(bucket), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(bucket, []byte("\n"+hex.EncodeToString(entryHash[:])+"\t"+string(dt)), 0644)
+}
+
+// splitHash returns the path of a hash in the npm cache.
+func splitHash(h string) string {
+	return filepath.Join(h[:2], h[2:4], h[4:])
+}
+
+func newRequest(ctx context.Context, u string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", version.UserAgent())
+	return req, nil
+}
+
+func do(client *http.Client, req *http.Request) (*http.Response, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, errors.Errorf("invalid response status %d for %s", resp.StatusCode, req.URL)
+	}
+	return resp, nil
+}
+
+func get(ctx context.Context, client *http.Client, u string) ([]byte, error) {
+	req, err := newRequest(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := do(client, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+func download(ctx context.Context, client *http.Client, u, p string, w io.Writer) error {
+	req, err := newRequest(ctx, u)
+	if err != nil {
+		return err
+	}
+	resp, err := do(client, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	f, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var dest io.Writer = f
+	if w != nil {
+		dest = io.MultiWriter(f, w)
+	}
+	if _, err := io.Copy(dest, resp.Body); err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,140 @@
+package pkgregistry
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/mod/sumdb/dirhash"
+)
+
+func TestFetchGoModule(t *testing.T) {
+	mod := []byte("module example.com/Foo\n")
+	var zbuf bytes.Buffer
+	zw := zip.NewWriter(&zbuf)
+	for name, dt := range map[string][]byte{"go.mod": mod, "foo.go": []byte("package foo\n")} {
+		w, err := zw.Create("example.com/!foo@v1.0.0/" + name)
+		require.NoError(t, err)
+		_, err = w.Write(dt)
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+
+	zipFile := filepath.Join(t.TempDir(), "mod.zip")
+	require.NoError(t, os.WriteFile(zipFile, zbuf.Bytes(), 0644))
+	sum, err := dirhash.HashZip(zipFile, dirhash.Hash1)
+	require.NoError(t, err)
+	modSum, err := dirhash.Hash1([]string{"go.mod"}, func(string) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(mod)), nil
+	})
+	require.NoError(t, err)
+
+	files := map[string][]byte{
+		"/example.com/!foo/@v/v1.0.0.mod": mod,
+		"/example.com/!foo/@v/v1.0.0.zip": zbuf.Bytes(),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		dt, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(dt)
+	}))
+	defer srv.Close()
+
+	id, err := NewGoModuleIdentifier("example.com/Foo@v1.0.0")
+	require.NoError(t, err)
+	id.Proxy = srv.URL
+	id.Sum = sum
+	id.ModSum = modSum
+
+	dir := t.TempDir()
+	require.NoError(t, fetchGoModule(context.TODO(), srv.Client(), id, dir))
+	base := filepath.Join(dir, "cache", "download", "example.com", "!foo", "@v", "v1.0.0")
+	for _, ext := range []string{".mod", ".zip"} {
+		dt, err := os.ReadFile(base + ext)
+		require.NoError(t, err)
+		require.Equal(t, files["/example.com/!foo/@v/v1.0.0"+ext], dt)
+	}
+	// the .info file isn't covered by go.sum and is not fetched
+	dt, err := os.ReadFile(base + ".info")
+	require.NoError(t, err)
+	require.JSONEq(t, `{"Version":"v1.0.0"}`, string(dt))
+	dt, err = os.ReadFile(base + ".ziphash")
+	require.NoError(t, err)
+	require.Equal(t, sum, string(dt))
+
+	// go.mod only
+	id.Sum = ""
+	dir = t.TempDir()
+	require.NoError(t, fetchGoModule(context.TODO(), srv.Client(), id, dir))
+	base = filepath.Join(dir, "cache", "download", "example.com", "!foo", "@v", "v1.0.0")
+	_, err = os.Stat(base + ".mod")
+	require.NoError(t, err)
+	_, err = os.Stat(base + ".zip")
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	id.Sum = modSum
+	err = fetchGoModule(context.TODO(), srv.Client(), id, t.TempDir())
+	require.ErrorContains(t, err, "checksum mismatch for example.com/Foo@v1.0.0")
+
+	id.Sum = sum
+	id.ModSum = sum
+	err = fetchGoModule(context.TODO(), srv.Client(), id, t.TempDir())
+	require.ErrorContains(t, err, "checksum mismatch for example.com/Foo@v1.0.0/go.mod")
+}
+
+func TestFetchNPMPackage(t *testing.T) {
+	tgz := []byte("not really a tarball")
+	sum := sha512.Sum512(tgz)
+	sri := "sha512-" + base64.StdEncoding.EncodeToString(sum[:])
+
+	var requested []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		w.Write(tgz)
+	}))
+	defer srv.Close()
+
+	id, err := NewNPMPackageIdentifier("@scope/pkg@1.0.0")
+	require.NoError(t, err)
+	require.Equal(t, "@scope/pkg", id.Name)
+	id.URL = DefaultNPMRegistry + "@scope/pkg/-/pkg-1.0.0.tgz"
+	id.Integrity = sri
+	id.Registry = srv.URL + "/npm/"
+
+	dir := t.TempDir()
+	require.NoError(t, fetchNPMPackage(context.TODO(), srv.Client(), id, dir))
+	require.Equal(t, []string{"/npm/@scope/pkg/-/pkg-1.0.0.tgz"}, requested)
+
+	enc := hex.EncodeToString(sum[:])
+	dt, err := os.ReadFile(filepath.Join(dir, "_cacache", "content-v2", "sha512", enc[:2], enc[2:4], enc[4:]))
+	require.NoError(t, err)
+	require.Equal(t, tgz, dt)
+
+	key := sha256.Sum256([]byte("make-fetch-happen:request-cache:" + id.URL))
+	keyEnc := hex.EncodeToString(key[:])
+	dt, err = os.ReadFile(filepath.Join(dir, "_cacache", "index-v5", keyEnc[:2], keyEnc[2:4], keyEnc[4:]))
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(dt), "\n"))
+	require.Contains(t, string(dt), `"key":"make-fetch-happen:request-cache:`+id.URL+`"`)
+	require.Contains(t, string(dt), `"integrity":"`+sri+`"`)
+
+	other := sha512.Sum512([]byte("other"))
+	id.Integrity = "sha512-" + base64.StdEncoding.EncodeToString(other[:])
+	err = fetchNPMPackage(context.TODO(), srv.Client(), id, t.TempDir())
+	require.ErrorContains(t, err, "integrity mismatch for @scope/pkg@1.0.0")
+}
@@ -0,0 +1,204 @@
+package pkgregistry
+
+import (
+	"crypto/sha1" //nolint:gosec // used for npm integrity hashes
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"hash"
+	"strings"
+
+	"github.com/moby/buildkit/solver/llbsolver/provenance"
+	provenancetypes "github.com/moby/buildkit/solver/llbsolver/provenance/types"
+	"github.com/moby/buildkit/source"
+	srctypes "github.com/moby/buildkit/source/types"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	"golang.org/x/mod/module"
+)
+
+const (
+	DefaultGoModProxy  = "https://proxy.golang.org"
+	DefaultNPMRegistry = "https://registry.npmjs.org/"
+)
+
+type GoModuleIdentifier struct {
+	Path    string
+	Version string
+	// Sum is the go.sum hash of the module zip. Only go.mod is fetched if it
+	// is empty.
+	Sum string
+	// ModSum is the go.sum hash of go.mod.
+	ModSum string
+	Proxy  string
+}
+
+func NewGoModuleIdentifier(str string) (*GoModuleIdentifier, error) {
+	i := strings.LastIndex(str, "@")
+	if i <= 0 {
+		return nil, errors.Errorf("invalid go module %q, expected <path>@<version>", str)
+	}
+	id := &GoModuleIdentifier{Path: str[:i], Version: str[i+1:], Proxy: DefaultGoModProxy}
+	if err := module.Check(id.Path, id.Version); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return id, nil
+}
+
+var _ source.Identifier = (*GoModuleIdentifier)(nil)
+
+func (*GoModuleIdentifier) Scheme() string {
+	return srctypes.GoModScheme
+}
+
+func (id *GoModuleIdentifier) Capture(c *provenance.Capture, pin string) error {
+	dgst, err := digest.Parse(pin)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse go module digest %s", pin)
+	}
+	u, err := id.url(".zip")
+	if err != nil {
+		return err
+	}
+	c.AddHTTP(provenancetypes.HTTPSource{
+		URL:    u,
+		Digest: dgst,
+	})
+	return nil
+}
+
+// url returns the proxy URL of the module file with the suffix.
+func (id *GoModuleIdentifier) url(suffix string) (string, error) {
+	p, err := module.EscapePath(id.Path)
+	if err != nil {
+		return "", err
+	}
+	v, err := module.EscapeVersion(id.Version)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(id.Proxy, "/") + "/" + p + "/@v/" + v + suffix, nil
+}
+
+// pin returns the go.sum hash of the module as a digest. The h1 hash is a
+// SHA-256 of the summary of the module files.
+func (id *GoModuleIdentifier) pin() (digest.Digest, error) {
+	sum := id.Sum
+	if sum == "" {
+		sum = id.ModSum
+	}
+	enc, ok := strings.CutPrefix(sum, "h1:")
+	if !ok {
+		return "", errors.Errorf("unsupported go.sum hash %q for %s@%s", sum, id.Path, id.Version)
+	}
+	dt, err := base64.StdEncoding.DecodeString(enc)
+	if err != nil || len(dt) != sha256.Size {
+		return "", errors.Errorf("invalid go.sum hash %q for %s@%s", sum, id.Path, id.Version)
+	}
+	return digest.NewDigestFromEncoded(digest.SHA256, hex.EncodeToString(dt)), nil
+}
+
+type NPMPackageIdentifier struct {
+	Name    string
+	Version string
+	// URL is the resolved URL of the tarball in package-lock.json. It is also
+	// the key of the npm cache entry.
+	URL string
+	// Integrity is the subresource integrity of the tarball.
+	Integrity string
+	// Registry replaces DefaultNPMRegistry in URL when fetching.
+	Registry string
+}
+
+func NewNPMPackageIdentifier(str string) (*NPMPackageIdentifier, error) {
+	i := strings.LastIndex(str, "@")
+	if i <= 0 {
+		return nil, errors.Errorf("invalid npm package %q, expected <name>@<version>", str)
+	}
+	return &NPMPackageIdentifier{Name: str[:i], Version: str[i+1:]}, nil
+}
+
+var _ source.Identifier = (*NPMPackageIdentifier)(nil)
+
+func (*NPMPackageIdentifier) Scheme() string {
+	return srctypes.NPMScheme
+}
+
+func (id *NPMPackageIdentifier) Capture(c *provenance.Capture, pin string) error {
+	// not validated as go-digest doesn't support the sha1 hashes of older
+	// packages
+	dgst := digest.Digest(pin)
+	if _, enc, ok := strings.Cut(pin, ":"); !ok || enc == "" {
+		return errors.Errorf("failed to parse npm package digest %s", pin)
+	}
+	c.AddHTTP(provenancetypes.HTTPSource{
+		URL:    id.URL,
+		Digest: dgst,
+	})
+	return nil
+}
+
+// fetchURL returns the URL the tarball is fetched from.
+func (id *NPMPackageIdentifier) fetchURL() string {
+	if id.Registry != "" {
+		if p, ok := strings.CutPrefix(id.URL, DefaultNPMRegistry); ok {
+			return strings.TrimSuffix(id.Registry, "/") + "/" + p
+		}
+	}
+	return id.URL
+}
+
+// integrity is a parsed subresource integrity hash.
+type integrity struct {
+	algorithm string
+	sum       []byte
+}
+
+func (i integrity) hash() hash.Hash {
+	switch i.algorithm {
+	case "sha512":
+		return sha512.New()
+	case "sha384":
+		return sha512.New384()
+	case "sha256":
+		return sha256.New()
+	default:
+		return sha1.New() //nolint:gosec // used by old packages
+	}
+}
+
+func (i integrity) digest() digest.Digest {
+	return digest.NewDigestFromEncoded(digest.Algorithm(i.algorithm), hex.EncodeToString(i.sum))
+}
+
+// String returns the integrity in the format used by package-lock.json.
+func (i integrity) String() string {
+	return i.algorithm + "-" + base64.StdEncoding.EncodeToString(i.sum)
+}
+
+// parseIntegrity returns the strongest hash of a subresource integrity
+// string.
+func parseIntegrity(s string) (integrity, error) {
+	var best integrity
+	rank := map[string]int{"sha1": 1, "sha256": 2, "sha384": 3, "sha512": 4}
+	for _, f := range strings.Fields(s) {
+		alg, enc, ok := strings.Cut(f, "-")
+		if !ok || rank[alg] == 0 {
+			continue
+		}
+		// options can follow the hash after "?"
+		enc, _, _ = strings.Cut(enc, "?")
+		sum, err := base64.StdEncoding.DecodeString(enc)
+		if err != nil {
+			return integrity{}, errors.Wrapf(err, "invalid integrity %q", f)
+		}
+		if rank[alg] > rank[best.algorithm] {
+			best = integrity{algorithm: alg, sum: sum}
+		}
+	}
+	if best.algorithm == "" {
+		return integrity{}, errors.Errorf("unsupported integrity %q", s)
+	}
+	return best, nil
+}
@@ -0,0 +1,239 @@
+package pkgregistry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/moby/buildkit/cache"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/snapshot"
+	"github.com/moby/buildkit/solver"
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/source"
+	srctypes "github.com/moby/buildkit/source/types"
+	"github.com/moby/buildkit/util/tracing"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+)
+
+type Opt struct {
+	CacheAccessor cache.Accessor
+	Transport     http.RoundTripper
+}
+
+// registrySource fetches single artifacts listed in a lock file from a
+// package registry. Every artifact is a separate source so that it is cached
+// by its checksum, independent of the registry it was fetched from.
+type registrySource struct {
+	cache     cache.Accessor
+	transport http.RoundTripper
+}
+
+func NewSource(opt Opt) (source.Source, error) {
+	transport := opt.Transport
+	if transport == nil {
+		transport = tracing.DefaultTransport
+	}
+	return &registrySource{
+		cache:     opt.CacheAccessor,
+		transport: transport,
+	}, nil
+}
+
+func (rs *registrySource) Schemes() []string {
+	return []string{srctypes.GoModScheme, srctypes.NPMScheme}
+}
+
+func (rs *registrySource) Identifier(scheme, ref string, attrs map[string]string, platform *pb.Platform) (source.Identifier, error) {
+	switch scheme {
+	case srctypes.GoModScheme:
+		id, err := NewGoModuleIdentifier(ref)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range attrs {
+			switch k {
+			case pb.AttrGoModSum:
+				id.Sum = v
+			case pb.AttrGoModModSum:
+				id.ModSum = v
+			case pb.AttrGoModProxy:
+				id.Proxy = v
+			}
+		}
+		if id.Sum == "" && id.ModSum == "" {
+			return nil, errors.Errorf("missing go.sum hash for %s", ref)
+		}
+		if _, err := id.pin(); err != nil {
+			return nil, err
+		}
+		return id, nil
+	case srctypes.NPMScheme:
+		id, err := NewNPMPackageIdentifier(ref)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range attrs {
+			switch k {
+			case pb.AttrNPMURL:
+				id.URL = v
+			case pb.AttrNPMIntegrity:
+				id.Integrity = v
+			case pb.AttrNPMRegistry:
+				id.Registry = v
+			}
+		}
+		if id.URL == "" {
+			return nil, errors.Errorf("missing url for npm package %s", ref)
+		}
+		if u, err := url.Parse(id.URL); err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+			return nil, errors.Errorf("unsupported url %q for npm package %s", id.URL, ref)
+		}
+		if _, err := parseIntegrity(id.Integrity); err != nil {
+			return nil, errors.Wrapf(err, "invalid npm package %s", ref)
+		}
+		return id, nil
+	default:
+		return nil, errors.Errorf("unsupported scheme %s", scheme)
+	}
+}
+
+type registrySourceHandler struct {
+	*registrySource
+	id source.Identifier
+}
+
+func (rs *registrySource) Resolve(ctx context.Context, id source.Identifier, sm *session.Manager, _ solver.Vertex) (source.SourceInstance, error) {
+	switch id.(type) {
+	case *GoModuleIdentifier, *NPMPackageIdentifier:
+	default:
+		return nil, errors.Errorf("invalid package identifier %v", id)
+	}
+	return &registrySourceHandler{registrySource: rs, id: id}, nil
+}
+
+// CacheKey only depends on the checksums of the artifact, so that it isn't
+// fetched again if the registry changes.
+func (h *registrySourceHandler) CacheKey(ctx context.Context, g session.Group, index int) (string, string, solver.CacheOpts, bool, error) {
+	var (
+		key any
+		pin digest.Digest
+	)
+	switch id := h.id.(type) {
+	case *GoModuleIdentifier:
+		var err error
+		if pin, err = id.pin(); err != nil {
+			return "", "", nil, false, err
+		}
+		key = struct {
+			Path, Version, Sum, ModSum string
+		}{id.Path, id.Version, id.Sum, id.ModSum}
+	case *NPMPackageIdentifier:
+		sri, err := parseIntegrity(id.Integrity)
+		if err != nil {
+			return "", "", nil, false, err
+		}
+		pin = sri.digest()
+		key = struct {
+			Name, Version, URL string
+			Integrity          digest.Digest
+		}{id.Name, id.Version, id.URL, pin}
+	}
+	dt, err := json.Marshal(key)
+	if err != nil {
+		return "", "", nil, false, err
+	}
+	return digest.FromBytes(dt).String(), pin.String(), nil, true, nil
+}
+
+func (h *registrySourceHandler) Snapshot(ctx context.Context, g session.Group) (ref cache.ImmutableRef, retErr error) {
+	var desc string
+	switch id := h.id.(type) {
+	case *GoModuleIdentifier:
+		desc = fmt.Sprintf("go module %s@%s", id.Path, id.Version)
+	case *NPMPackageIdentifier:
+		desc = fmt.Sprintf("npm package %s@%s", id.Name, id.Version)
+	}
+
+	newRef, err := h.cache.New(ctx, nil, g, cache.CachePolicyRetain, cache.WithDescription(desc))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if retErr != nil && newRef != nil {
+			newRef.Release(context.WithoutCancel(ctx))
+		}
+	}()
+
+	mount, err := newRef.Mount(ctx, false, g)
+	if err != nil {
+		return nil, err
+	}
+
+	lm := snapshot.LocalMounter(mount)
+	dir, err := lm.Mount()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if lm != nil {
+			lm.Unmount()
+		}
+	}()
+
+	client := &http.Client{Transport: h.transport}
+	switch id := h.id.(type) {
+	case *GoModuleIdentifier:
+		err = fetchGoModule(ctx, client, id, dir)
+	case *NPMPackageIdentifier:
+		err = fetchNPMPackage(ctx, client, id, dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	uid, gid := 0, 0
+	if idmap := mount.IdentityMapping(); idmap != nil {
+		if uid, gid, err = idmap.ToHost(uid, gid); err != nil {
+			return nil, err
+		}
+	}
+	if err := normalize(dir, uid, gid); err != nil {
+		return nil, err
+	}
+
+	if err := lm.Unmount(); err != nil {
+		return nil, err
+	}
+	lm = nil
+
+	ref, err = newRef.Commit(ctx)
+	if err != nil {
+		return nil, err
+	}
+	newRef = nil
+	return ref, nil
+}
+
+// normalize sets the owner and resets the timestamps of the fetched files so
+// that the snapshot only depends on the content.
+func normalize(dir string, uid, gid int) error {
+	mtime := time.Unix(0, 0)
+	return filepath.WalkDir(dir, func(p string, _ os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if uid != 0 || gid != 0 {
+			if err := os.Lchown(p, uid, gid); err != nil {
+				return err
+			}
+		}
+		return os.Chtimes(p, mtime, mtime)
+	})
+}
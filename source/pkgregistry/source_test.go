@@ -0,0 +1,85 @@
+package pkgregistry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/moby/buildkit/solver/pb"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdentifier(t *testing.T) {
+	rs := &registrySource{}
+
+	id, err := rs.Identifier("gomod", "github.com/pkg/errors@v0.9.1", map[string]string{
+		pb.AttrGoModSum: "h1:FEBLx1zS214owpjy7qsBeixbURkuhQAwrK5UwLGTwt4=",
+	}, nil)
+	require.NoError(t, err)
+	gid := id.(*GoModuleIdentifier)
+	require.Equal(t, "github.com/pkg/errors", gid.Path)
+	require.Equal(t, "v0.9.1", gid.Version)
+	require.Equal(t, DefaultGoModProxy, gid.Proxy)
+	u, err := gid.url(".zip")
+	require.NoError(t, err)
+	require.Equal(t, "https://proxy.golang.org/github.com/pkg/errors/@v/v0.9.1.zip", u)
+
+	_, err = rs.Identifier("gomod", "github.com/pkg/errors@v0.9.1", nil, nil)
+	require.ErrorContains(t, err, "missing go.sum hash")
+	_, err = rs.Identifier("gomod", "github.com/pkg/errors@v0.9.1", map[string]string{pb.AttrGoModSum: "h2:abc"}, nil)
+	require.ErrorContains(t, err, "unsupported go.sum hash")
+	_, err = rs.Identifier("gomod", "github.com/pkg/errors", map[string]string{pb.AttrGoModSum: "h1:abc"}, nil)
+	require.Error(t, err)
+
+	id, err = rs.Identifier("npm", "@scope/pkg@1.0.0", map[string]string{
+		pb.AttrNPMURL:       "https://registry.npmjs.org/@scope/pkg/-/pkg-1.0.0.tgz",
+		pb.AttrNPMIntegrity: "sha1-aaaaaaaaaaaaaaaaaaaaaaaaaaa=",
+		pb.AttrNPMRegistry:  "https://npm.example",
+	}, nil)
+	require.NoError(t, err)
+	nid := id.(*NPMPackageIdentifier)
+	require.Equal(t, "@scope/pkg", nid.Name)
+	require.Equal(t, "1.0.0", nid.Version)
+	require.Equal(t, "https://npm.example/@scope/pkg/-/pkg-1.0.0.tgz", nid.fetchURL())
+
+	_, err = rs.Identifier("npm", "pkg@1.0.0", map[string]string{pb.AttrNPMIntegrity: "sha1-aaaaaaaaaaaaaaaaaaaaaaaaaaa="}, nil)
+	require.ErrorContains(t, err, "missing url")
+	_, err = rs.Identifier("npm", "pkg@1.0.0", map[string]string{pb.AttrNPMURL: "file:../pkg", pb.AttrNPMIntegrity: "sha1-aaaaaaaaaaaaaaaaaaaaaaaaaaa="}, nil)
+	require.ErrorContains(t, err, "unsupported url")
+	_, err = rs.Identifier("npm", "pkg@1.0.0", map[string]string{pb.AttrNPMURL: "https://registry.npmjs.org/pkg/-/pkg-1.0.0.tgz", pb.AttrNPMIntegrity: "md5-abc"}, nil)
+	require.ErrorContains(t, err, "unsupported integrity")
+}
+
+func TestParseIntegrity(t *testing.T) {
+	sri, err := parseIntegrity("sha1-aaaaaaaaaaaaaaaaaaaaaaaaaaa= sha512-v2kDEe57lecTulaDIuNTPy3Ry4gLGJ6Z1O3vE1krgXZNrsQ+LFTGHVxVjcXPs17LhbZVGedAJv8XZ1tvj5FvSg==?foo")
+	require.NoError(t, err)
+	require.Equal(t, "sha512", sri.algorithm)
+	require.Equal(t, "sha512-v2kDEe57lecTulaDIuNTPy3Ry4gLGJ6Z1O3vE1krgXZNrsQ+LFTGHVxVjcXPs17LhbZVGedAJv8XZ1tvj5FvSg==", sri.String())
+	require.NoError(t, sri.digest().Validate())
+
+	_, err = parseIntegrity("sha256-!!!")
+	require.ErrorContains(t, err, "invalid integrity")
+	_, err = parseIntegrity("")
+	require.ErrorContains(t, err, "unsupported integrity")
+}
+
+func TestCacheKeyIgnoresRegistry(t *testing.T) {
+	rs := &registrySource{}
+	key := func(proxy string) (string, string) {
+		id, err := rs.Identifier("gomod", "github.com/pkg/errors@v0.9.1", map[string]string{
+			pb.AttrGoModSum:   "h1:FEBLx1zS214owpjy7qsBeixbURkuhQAwrK5UwLGTwt4=",
+			pb.AttrGoModProxy: proxy,
+		}, nil)
+		require.NoError(t, err)
+		src, err := rs.Resolve(context.TODO(), id, nil, nil)
+		require.NoError(t, err)
+		k, pin, _, done, err := src.CacheKey(context.TODO(), nil, 0)
+		require.NoError(t, err)
+		require.True(t, done)
+		return k, pin
+	}
+	k1, pin := key("https://proxy.golang.org")
+	k2, _ := key("https://goproxy.example")
+	require.Equal(t, k1, k2)
+	require.Equal(t, digest.Digest("sha256:14404bc75cd2db5e28c298f2eeab017a2c5b51192e850030acae54c0b193c2de"), digest.Digest(pin))
+}
@@ -7,4 +7,7 @@ const (
 	HTTPScheme        = "http"
 	HTTPSScheme       = "https"
 	OCIScheme         = "oci-layout"
+	OCIArtifactScheme = "oci-artifact"
+	GoModScheme       = "gomod"
+	NPMScheme         = "npm"
 )
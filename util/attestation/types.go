@@ -6,4 +6,38 @@ const (
 	DockerAnnotationReferenceDescription = "vnd.docker.reference.description"
 
 	DockerAnnotationReferenceTypeDefault = "attestation-manifest"
+
+	// AnnotationPredicateType is the annotation of attestation layers that
+	// holds the predicate type of the statement.
+	AnnotationPredicateType = "in-toto.io/predicate-type"
+
+	// ArtifactTypeAttestationManifest is the artifact type of attestation
+	// manifests that are stored as referrers of the image manifest.
+	ArtifactTypeAttestationManifest = "application/vnd.docker.attestation.manifest.v1+json"
+
+	// PredicateTypeVulns and PredicateTypeOpenVEX are the predicate types of
+	// the statements accepted from vulnerability scanners by default.
+	PredicateTypeVulns   = "https://in-toto.io/attestation/vulns/v0.1"
+	PredicateTypeOpenVEX = "https://openvex.dev/ns/v0.2.0"
+
+	// MediaTypeDSSEEnvelope is the media type of attestation layers that
+	// contain a signed DSSE envelope instead of a plain in-toto statement.
+	MediaTypeDSSEEnvelope = "application/vnd.dsse.envelope.v1+json"
+
+	// MediaTypeSimpleSigning is the media type of image signature payloads in
+	// the simple signing format used by Sigstore.
+	MediaTypeSimpleSigning = "application/vnd.dev.cosign.simplesigning.v1+json"
+	// SimpleSigningType is the critical.type of simple signing payloads.
+	SimpleSigningType = "cosign container image signature"
+	// ArtifactTypeSignature is the artifact type of image signature manifests.
+	ArtifactTypeSignature = "application/vnd.dev.cosign.artifact.sig.v1+json"
+	// AnnotationSignature holds the base64 encoded signature of a simple
+	// signing payload layer.
+	AnnotationSignature = "dev.cosignproject.cosign/signature"
 )
+
+// IsVulnPredicate returns true if predicateType is the type of a
+// vulnerability report accepted by default.
+func IsVulnPredicate(predicateType string) bool {
+	return predicateType == PredicateTypeVulns || predicateType == PredicateTypeOpenVEX
+}
@@ -0,0 +1,528 @@
+package compression
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/core/images"
+	"github.com/containerd/containerd/v2/pkg/labels"
+	"github.com/klauspost/compress/zstd"
+	"github.com/moby/buildkit/util/iohelper"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+	"github.com/vbatts/tar-split/archive/tar"
+	"github.com/vbatts/tar-split/tar/asm"
+	"github.com/vbatts/tar-split/tar/storage"
+)
+
+// Annotations set on zstd:chunked layers, compatible with
+// containers/storage.
+const (
+	ZstdChunkedManifestChecksumAnnotation = "io.github.containers.zstd-chunked.manifest-checksum"
+	ZstdChunkedManifestPositionAnnotation = "io.github.containers.zstd-chunked.manifest-position"
+	ZstdChunkedTarSplitPositionAnnotation = "io.github.containers.zstd-chunked.tarsplit-position"
+)
+
+var ZstdChunkedAnnotations = []string{
+	ZstdChunkedManifestChecksumAnnotation,
+	ZstdChunkedManifestPositionAnnotation,
+	ZstdChunkedTarSplitPositionAnnotation,
+}
+
+const zstdChunkedLabel = "buildkit.io/compression/zstd-chunked"
+
+const (
+	// zstdChunkedManifestTypeCRFS is the only TOC format defined for
+	// zstd:chunked.
+	zstdChunkedManifestTypeCRFS = 1
+	// zstdChunkedFooterSize is the size of the footer data, without the
+	// skippable frame header.
+	zstdChunkedFooterSize = 64
+	// zstdSkippableFrameHeaderSize is the size of the magic number and
+	// frame size in front of the data of a skippable frame.
+	zstdSkippableFrameHeaderSize = 8
+)
+
+var (
+	zstdSkippableFrameMagic = []byte{0x50, 0x2a, 0x4d, 0x18}
+	zstdChunkedFrameMagic   = []byte("GNUlInUx")
+)
+
+// zstdChunkedTOC is the table of contents stored in a zstd:chunked layer.
+type zstdChunkedTOC struct {
+	Version        int                   `json:"version"`
+	Entries        []zstdChunkedTOCEntry `json:"entries"`
+	TarSplitDigest digest.Digest         `json:"tarSplitDigest,omitempty"`
+}
+
+type zstdChunkedTOCEntry struct {
+	Type        string            `json:"type"`
+	Name        string            `json:"name"`
+	Linkname    string            `json:"linkName,omitempty"`
+	Mode        int64             `json:"mode,omitempty"`
+	Size        int64             `json:"size,omitempty"`
+	UID         int               `json:"uid,omitempty"`
+	GID         int               `json:"gid,omitempty"`
+	ModTime     *time.Time        `json:"modtime,omitempty"`
+	AccessTime  *time.Time        `json:"accesstime,omitempty"`
+	ChangeTime  *time.Time        `json:"changetime,omitempty"`
+	Devmajor    int64             `json:"devMajor,omitempty"`
+	Devminor    int64             `json:"devMinor,omitempty"`
+	Xattrs      map[string]string `json:"xattrs,omitempty"`
+	Digest      string            `json:"digest,omitempty"`
+	Offset      int64             `json:"offset,omitempty"`
+	EndOffset   int64             `json:"endOffset,omitempty"`
+	ChunkSize   int64             `json:"chunkSize,omitempty"`
+	ChunkDigest string            `json:"chunkDigest,omitempty"`
+}
+
+// zstdChunkedFooter is the data of the skippable frame at the end of a
+// zstd:chunked layer.
+type zstdChunkedFooter struct {
+	ManifestOffset             uint64
+	ManifestLengthCompressed   uint64
+	ManifestLengthUncompressed uint64
+	ManifestType               uint64
+	TarSplitOffset             uint64
+	TarSplitLengthCompressed   uint64
+	TarSplitLengthUncompressed uint64
+}
+
+func (f zstdChunkedFooter) marshal() []byte {
+	dt := make([]byte, zstdChunkedFooterSize)
+	binary.LittleEndian.PutUint64(dt[8*0:], f.ManifestOffset)
+	binary.LittleEndian.PutUint64(dt[8*1:], f.ManifestLengthCompressed)
+	binary.LittleEndian.PutUint64(dt[8*2:], f.ManifestLengthUncompressed)
+	binary.LittleEndian.PutUint64(dt[8*3:], f.ManifestType)
+	binary.LittleEndian.PutUint64(dt[8*4:], f.TarSplitOffset)
+	binary.LittleEndian.PutUint64(dt[8*5:], f.TarSplitLengthCompressed)
+	binary.LittleEndian.PutUint64(dt[8*6:], f.TarSplitLengthUncompressed)
+	copy(dt[8*7:], zstdChunkedFrameMagic)
+	return dt
+}
+
+func (c zstdChunkedType) Compress(ctx context.Context, comp Config) (compressorFunc Compressor, finalize Finalizer) {
+	var cInfo *blobInfo
+	var annotations map[string]string
+	var writeErr error
+	var mu sync.Mutex
+	return func(dest io.Writer, requiredMediaType string) (io.WriteCloser, error) {
+			ct, err := FromMediaType(requiredMediaType)
+			if err != nil {
+				return nil, err
+			}
+			if ct != Zstd {
+				return nil, errors.Errorf("unsupported media type for zstd:chunked compressor %q", requiredMediaType)
+			}
+			level := zstd.SpeedDefault
+			if comp.Level != nil {
+				level = toZstdEncoderLevel(*comp.Level)
+			}
+			done := make(chan struct{})
+			pr, pw := io.Pipe()
+			go func() (retErr error) {
+				defer close(done)
+				defer func() {
+					if retErr != nil {
+						mu.Lock()
+						writeErr = retErr
+						mu.Unlock()
+					}
+				}()
+
+				blobInfoW, bInfoCh := calculateBlobInfo()
+				defer blobInfoW.Close()
+
+				a, err := writeZstdChunked(io.MultiWriter(dest, blobInfoW), pr, level)
+				if err != nil {
+					pr.CloseWithError(err)
+					return err
+				}
+				if err := blobInfoW.Close(); err != nil {
+					pr.CloseWithError(err)
+					return err
+				}
+				bInfo := <-bInfoCh
+				mu.Lock()
+				cInfo = &bInfo
+				annotations = a
+				mu.Unlock()
+				pr.Close()
+				return nil
+			}()
+			return &iohelper.WriteCloser{WriteCloser: pw, CloseFunc: func() error {
+				<-done // wait until the write completes
+				return nil
+			}}, nil
+		}, func(ctx context.Context, cs content.Store) (map[string]string, error) {
+			mu.Lock()
+			cInfo, annotations, writeErr := cInfo, annotations, writeErr
+			mu.Unlock()
+			if cInfo == nil {
+				if writeErr != nil {
+					return nil, errors.Wrapf(writeErr, "cannot finalize due to write error")
+				}
+				return nil, errors.Errorf("cannot finalize (reason unknown)")
+			}
+
+			// Fill necessary labels
+			info, err := cs.Info(ctx, cInfo.compressedDigest)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to get info from content store")
+			}
+			if info.Labels == nil {
+				info.Labels = make(map[string]string)
+			}
+			info.Labels[labels.LabelUncompressed] = cInfo.uncompressedDigest.String()
+			info.Labels[zstdChunkedLabel] = "true"
+			if _, err := cs.Update(ctx, info, "labels."+labels.LabelUncompressed, "labels."+zstdChunkedLabel); err != nil {
+				return nil, err
+			}
+
+			a := make(map[string]string, len(annotations)+1)
+			for k, v := range annotations {
+				a[k] = v
+			}
+			a[labels.LabelUncompressed] = cInfo.uncompressedDigest.String()
+			return a, nil
+		}
+}
+
+func (c zstdChunkedType) Decompress(ctx context.Context, cs content.Store, desc ocispecs.Descriptor) (io.ReadCloser, error) {
+	return decompress(ctx, cs, desc)
+}
+
+func (c zstdChunkedType) NeedsConversion(ctx context.Context, cs content.Store, desc ocispecs.Descriptor) (bool, error) {
+	if !images.IsLayerType(desc.MediaType) {
+		return false, nil
+	}
+	chunked, err := c.Is(ctx, cs, desc.Digest)
+	if err != nil {
+		return false, err
+	}
+	return !chunked, nil
+}
+
+func (c zstdChunkedType) NeedsComputeDiffBySelf(comp Config) bool {
+	return true
+}
+
+func (c zstdChunkedType) OnlySupportOCITypes() bool {
+	return true
+}
+
+func (c zstdChunkedType) MediaType() string {
+	return ocispecs.MediaTypeImageLayerZstd
+}
+
+func (c zstdChunkedType) String() string {
+	return "zstd:chunked"
+}
+
+// Is returns true when the specified digest of content exists in the
+// content store and it's zstd:chunked.
+func (c zstdChunkedType) Is(ctx context.Context, cs content.Store, dgst digest.Digest) (bool, error) {
+	info, err := cs.Info(ctx, dgst)
+	if err != nil {
+		return false, nil
+	}
+	if v, ok := info.Labels[zstdChunkedLabel]; ok {
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b, nil
+		}
+	}
+
+	res := func() bool {
+		ra, err := cs.ReaderAt(ctx, ocispecs.Descriptor{Digest: dgst})
+		if err != nil {
+			return false
+		}
+		defer ra.Close()
+		_, err = readZstdChunkedFooter(ra, ra.Size())
+		return err == nil
+	}()
+
+	if info.Labels == nil {
+		info.Labels = make(map[string]string)
+	}
+	info.Labels[zstdChunkedLabel] = strconv.FormatBool(res) // cache the result
+	if _, err := cs.Update(ctx, info, "labels."+zstdChunkedLabel); err != nil {
+		return false, err
+	}
+	return res, nil
+}
+
+// writeZstdChunked compresses the tar stream from r to dest. The payload of
+// each file is compressed in separate zstd frames so that it can be fetched
+// on its own. The table of contents, the tar-split data needed to recreate
+// the original tar and a footer pointing to both are appended as skippable
+// frames. Returns the annotations for the layer descriptor.
+func writeZstdChunked(dest io.Writer, r io.Reader, level zstd.EncoderLevel) (map[string]string, error) {
+	cw := &countingWriter{w: dest}
+	zw, err := zstd.NewWriter(cw, zstd.WithEncoderLevel(level))
+	if err != nil {
+		return nil, err
+	}
+	defer zw.Close()
+
+	tarSplit := &bytes.Buffer{}
+	tarSplitCounter := &countingWriter{w: io.Discard}
+	tsw, err := zstd.NewWriter(tarSplit, zstd.WithEncoderLevel(level))
+	if err != nil {
+		return nil, err
+	}
+	defer tsw.Close()
+
+	its, err := asm.NewInputTarStream(r, storage.NewJSONPacker(io.MultiWriter(tsw, tarSplitCounter)), nil)
+	if err != nil {
+		return nil, err
+	}
+	tr := tar.NewReader(its)
+	tr.RawAccounting = true
+
+	// restart ends the current zstd frame and returns the offset of the
+	// next one
+	restart := func() (int64, error) {
+		if err := zw.Close(); err != nil {
+			return 0, err
+		}
+		zw.Reset(cw)
+		return cw.n, nil
+	}
+
+	var entries []zstdChunkedTOCEntry
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		if _, err := zw.Write(tr.RawBytes()); err != nil {
+			return nil, err
+		}
+
+		typ, err := zstdChunkedEntryType(hdr.Typeflag)
+		if err != nil {
+			return nil, err
+		}
+		e := zstdChunkedTOCEntry{
+			Type:     typ,
+			Name:     hdr.Name,
+			Linkname: hdr.Linkname,
+			Mode:     hdr.Mode,
+			Size:     hdr.Size,
+			UID:      hdr.Uid,
+			GID:      hdr.Gid,
+			Devmajor: hdr.Devmajor,
+			Devminor: hdr.Devminor,
+		}
+		if !hdr.ModTime.IsZero() {
+			e.ModTime = &hdr.ModTime
+		}
+		if !hdr.AccessTime.IsZero() {
+			e.AccessTime = &hdr.AccessTime
+		}
+		if !hdr.ChangeTime.IsZero() {
+			e.ChangeTime = &hdr.ChangeTime
+		}
+		for k, v := range hdr.PAXRecords {
+			if name, ok := strings.CutPrefix(k, "SCHILY.xattr."); ok {
+				if e.Xattrs == nil {
+					e.Xattrs = map[string]string{}
+				}
+				e.Xattrs[name] = base64.StdEncoding.EncodeToString([]byte(v))
+			}
+		}
+
+		if hdr.Size > 0 && typ == "reg" {
+			if e.Offset, err = restart(); err != nil {
+				return nil, err
+			}
+			dgstr := digest.Canonical.Digester()
+			if _, err := io.Copy(io.MultiWriter(zw, dgstr.Hash()), tr); err != nil {
+				return nil, err
+			}
+			if e.EndOffset, err = restart(); err != nil {
+				return nil, err
+			}
+			e.Digest = dgstr.Digest().String()
+			e.ChunkSize = hdr.Size
+			e.ChunkDigest = e.Digest
+		}
+		entries = append(entries, e)
+	}
+	if _, err := zw.Write(tr.RawBytes()); err != nil {
+		return nil, err
+	}
+	// the tar may contain trailing padding that is part of the diffID
+	if _, err := io.Copy(zw, its); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	if err := tsw.Close(); err != nil {
+		return nil, err
+	}
+
+	toc, err := json.Marshal(zstdChunkedTOC{
+		Version:        1,
+		Entries:        entries,
+		TarSplitDigest: digest.FromBytes(tarSplit.Bytes()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	enc, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(level))
+	if err != nil {
+		return nil, err
+	}
+	compressedTOC := enc.EncodeAll(toc, nil)
+	enc.Close()
+
+	footer := zstdChunkedFooter{
+		ManifestOffset:             uint64(cw.n) + zstdSkippableFrameHeaderSize,
+		ManifestLengthCompressed:   uint64(len(compressedTOC)),
+		ManifestLengthUncompressed: uint64(len(toc)),
+		ManifestType:               zstdChunkedManifestTypeCRFS,
+		TarSplitLengthCompressed:   uint64(tarSplit.Len()),
+		TarSplitLengthUncompressed: uint64(tarSplitCounter.n),
+	}
+	footer.TarSplitOffset = footer.ManifestOffset + footer.ManifestLengthCompressed + zstdSkippableFrameHeaderSize
+
+	for _, dt := range [][]byte{compressedTOC, tarSplit.Bytes(), footer.marshal()} {
+		if err := writeZstdSkippableFrame(cw, dt); err != nil {
+			return nil, err
+		}
+	}
+
+	return map[string]string{
+		ZstdChunkedManifestChecksumAnnotation: digest.FromBytes(compressedTOC).String(),
+		ZstdChunkedManifestPositionAnnotation: fmt.Sprintf("%d:%d:%d:%d", footer.ManifestOffset, footer.ManifestLengthCompressed, footer.ManifestLengthUncompressed, footer.ManifestType),
+		ZstdChunkedTarSplitPositionAnnotation: fmt.Sprintf("%d:%d:%d", footer.TarSplitOffset, footer.TarSplitLengthCompressed, footer.TarSplitLengthUncompressed),
+	}, nil
+}
+
+func zstdChunkedEntryType(t byte) (string, error) {
+	switch t {
+	case tar.TypeReg, tar.TypeRegA: //nolint:staticcheck // ignore SA1019: TypeRegA is deprecated but still found in old tars.
+		return "reg", nil
+	case tar.TypeLink:
+		return "hardlink", nil
+	case tar.TypeSymlink:
+		return "symlink", nil
+	case tar.TypeDir:
+		return "dir", nil
+	case tar.TypeChar:
+		return "char", nil
+	case tar.TypeBlock:
+		return "block", nil
+	case tar.TypeFifo:
+		return "fifo", nil
+	default:
+		return "", errors.Errorf("unsupported tar entry type %q", t)
+	}
+}
+
+func writeZstdSkippableFrame(w io.Writer, dt []byte) error {
+	hdr := make([]byte, zstdSkippableFrameHeaderSize)
+	copy(hdr, zstdSkippableFrameMagic)
+	binary.LittleEndian.PutUint32(hdr[4:], uint32(len(dt)))
+	if _, err := w.Write(hdr); err != nil {
+		return err
+	}
+	_, err := w.Write(dt)
+	return err
+}
+
+// readZstdChunkedFooter reads the footer at the end of a zstd:chunked blob.
+func readZstdChunkedFooter(ra io.ReaderAt, size int64) (*zstdChunkedFooter, error) {
+	const frameSize = zstdSkippableFrameHeaderSize + zstdChunkedFooterSize
+	if size < frameSize {
+		return nil, errors.New("blob is too small for a zstd:chunked footer")
+	}
+	dt := make([]byte, frameSize)
+	if _, err := ra.ReadAt(dt, size-frameSize); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(dt[:4], zstdSkippableFrameMagic) || binary.LittleEndian.Uint32(dt[4:8]) != zstdChunkedFooterSize {
+		return nil, errors.New("no zstd:chunked footer frame")
+	}
+	dt = dt[zstdSkippableFrameHeaderSize:]
+	if !bytes.Equal(dt[8*7:], zstdChunkedFrameMagic) {
+		return nil, errors.New("invalid zstd:chunked footer magic")
+	}
+	f := &zstdChunkedFooter{
+		ManifestOffset:             binary.LittleEndian.Uint64(dt[8*0:]),
+		ManifestLengthCompressed:   binary.LittleEndian.Uint64(dt[8*1:]),
+		ManifestLengthUncompressed: binary.LittleEndian.Uint64(dt[8*2:]),
+		ManifestType:               binary.LittleEndian.Uint64(dt[8*3:]),
+		TarSplitOffset:             binary.LittleEndian.Uint64(dt[8*4:]),
+		TarSplitLengthCompressed:   binary.LittleEndian.Uint64(dt[8*5:]),
+		TarSplitLengthUncompressed: binary.LittleEndian.Uint64(dt[8*6:]),
+	}
+	if f.ManifestType != zstdChunkedManifestTypeCRFS {
+		return nil, errors.Errorf("unsupported zstd:chunked manifest type %d", f.ManifestType)
+	}
+	if f.ManifestOffset+f.ManifestLengthCompressed > uint64(size) || f.TarSplitOffset+f.TarSplitLengthCompressed > uint64(size) {
+		return nil, errors.New("zstd:chunked footer points outside of the blob")
+	}
+	return f, nil
+}
+
+// readZstdChunkedTOC reads the table of contents of a zstd:chunked blob.
+func readZstdChunkedTOC(ra io.ReaderAt, size int64) (*zstdChunkedTOC, error) {
+	f, err := readZstdChunkedFooter(ra, size)
+	if err != nil {
+		return nil, err
+	}
+	const maxTOCSize = 64 << 20
+	if f.ManifestLengthCompressed > maxTOCSize || f.ManifestLengthUncompressed > maxTOCSize {
+		return nil, errors.New("zstd:chunked table of contents is too large")
+	}
+	compressed := make([]byte, f.ManifestLengthCompressed)
+	if _, err := ra.ReadAt(compressed, int64(f.ManifestOffset)); err != nil {
+		return nil, err
+	}
+	dec, err := zstd.NewReader(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer dec.Close()
+	dt, err := dec.DecodeAll(compressed, make([]byte, 0, f.ManifestLengthUncompressed))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decompress zstd:chunked table of contents")
+	}
+	if uint64(len(dt)) != f.ManifestLengthUncompressed {
+		return nil, errors.New("zstd:chunked table of contents has unexpected size")
+	}
+	var toc zstdChunkedTOC
+	if err := json.Unmarshal(dt, &toc); err != nil {
+		return nil, errors.Wrap(err, "failed to parse zstd:chunked table of contents")
+	}
+	return &toc, nil
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,126 @@
+package compression
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestZstdChunkedRoundTrip(t *testing.T) {
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	files := map[string]string{
+		"foo/bar": "hello",
+		"baz":     strings.Repeat("buildkit", 1000),
+	}
+	mtime := time.Unix(1700000000, 0)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "foo/", Typeflag: tar.TypeDir, Mode: 0755, ModTime: mtime}))
+	for _, name := range []string{"foo/bar", "baz"} {
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Name:       name,
+			Typeflag:   tar.TypeReg,
+			Mode:       0644,
+			Size:       int64(len(files[name])),
+			ModTime:    mtime,
+			PAXRecords: map[string]string{"SCHILY.xattr.user.foo": "bar"},
+			Format:     tar.FormatPAX,
+		}))
+		_, err := tw.Write([]byte(files[name]))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "baz", ModTime: mtime}))
+	require.NoError(t, tw.Close())
+	orig := buf.Bytes()
+
+	out := &bytes.Buffer{}
+	annotations, err := writeZstdChunked(out, bytes.NewReader(orig), zstd.SpeedDefault)
+	require.NoError(t, err)
+	blob := out.Bytes()
+	ra := bytes.NewReader(blob)
+
+	footer, err := readZstdChunkedFooter(ra, int64(len(blob)))
+	require.NoError(t, err)
+	require.Equal(t, uint64(zstdChunkedManifestTypeCRFS), footer.ManifestType)
+	require.Equal(t, strings.Join([]string{
+		strconv.FormatUint(footer.ManifestOffset, 10),
+		strconv.FormatUint(footer.ManifestLengthCompressed, 10),
+		strconv.FormatUint(footer.ManifestLengthUncompressed, 10),
+		"1",
+	}, ":"), annotations[ZstdChunkedManifestPositionAnnotation])
+	require.Equal(t, digest.FromBytes(blob[footer.ManifestOffset:footer.ManifestOffset+footer.ManifestLengthCompressed]).String(), annotations[ZstdChunkedManifestChecksumAnnotation])
+
+	toc, err := readZstdChunkedTOC(ra, int64(len(blob)))
+	require.NoError(t, err)
+	require.Equal(t, 1, toc.Version)
+	require.Len(t, toc.Entries, 4)
+	require.Equal(t, digest.FromBytes(blob[footer.TarSplitOffset:footer.TarSplitOffset+footer.TarSplitLengthCompressed]), toc.TarSplitDigest)
+
+	dec, err := zstd.NewReader(nil)
+	require.NoError(t, err)
+	defer dec.Close()
+
+	byName := map[string]zstdChunkedTOCEntry{}
+	for _, e := range toc.Entries {
+		byName[e.Name] = e
+	}
+	require.Equal(t, "dir", byName["foo/"].Type)
+	require.Equal(t, "symlink", byName["link"].Type)
+	require.Equal(t, "baz", byName["link"].Linkname)
+	for name, data := range files {
+		e := byName[name]
+		require.Equal(t, "reg", e.Type)
+		require.Equal(t, int64(len(data)), e.Size)
+		require.Equal(t, digest.FromString(data).String(), e.Digest)
+		require.Equal(t, "YmFy", e.Xattrs["user.foo"])
+		require.True(t, mtime.Equal(*e.ModTime))
+
+		// each file payload can be fetched and decompressed on its own
+		dt, err := dec.DecodeAll(blob[e.Offset:e.EndOffset], nil)
+		require.NoError(t, err)
+		require.Equal(t, data, string(dt))
+	}
+
+	// the whole blob decompresses to the original tar, skipping the
+	// metadata frames
+	r, err := zstd.NewReader(bytes.NewReader(blob))
+	require.NoError(t, err)
+	defer r.Close()
+	dt, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, orig, dt)
+}
+
+func TestZstdChunkedFooterInvalid(t *testing.T) {
+	_, err := readZstdChunkedFooter(bytes.NewReader([]byte("short")), 5)
+	require.Error(t, err)
+
+	dt := bytes.Repeat([]byte{0}, 100)
+	_, err = readZstdChunkedFooter(bytes.NewReader(dt), int64(len(dt)))
+	require.ErrorContains(t, err, "no zstd:chunked footer")
+
+	// footer pointing outside of the blob
+	out := &bytes.Buffer{}
+	require.NoError(t, writeZstdSkippableFrame(out, zstdChunkedFooter{
+		ManifestOffset:           1000,
+		ManifestLengthCompressed: 10,
+		ManifestType:             zstdChunkedManifestTypeCRFS,
+	}.marshal()))
+	_, err = readZstdChunkedFooter(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	require.ErrorContains(t, err, "outside of the blob")
+}
+
+func TestParseZstdChunked(t *testing.T) {
+	c, err := Parse("zstd:chunked")
+	require.NoError(t, err)
+	require.Equal(t, ZstdChunked, c)
+	require.Equal(t, "zstd:chunked", c.String())
+	require.True(t, c.OnlySupportOCITypes())
+}
@@ -0,0 +1,190 @@
+package tarmerge
+
+import (
+	"archive/tar"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	whiteoutPrefix = ".wh."
+	whiteoutOpaque = whiteoutPrefix + whiteoutPrefix + ".opq"
+)
+
+// Layer opens the uncompressed tar stream of a layer. It is called twice for
+// every layer passed to Merge.
+type Layer func() (io.ReadCloser, error)
+
+// Merge writes a single tar stream to w that produces the same filesystem as
+// applying the layers in order. Entries shadowed by a later layer are
+// dropped.
+//
+// If keepWhiteouts is set, whiteout files are kept in the output so that the
+// merged layer can still be applied on top of the layers below the merged
+// ones. Otherwise the layers are assumed to start from an empty filesystem
+// and whiteouts only drop entries from the output.
+func Merge(w io.Writer, layers []Layer, keepWhiteouts bool) error {
+	t := &tree{
+		nodes:    map[string]position{},
+		children: map[string]map[string]struct{}{},
+		links:    map[position]linkTarget{},
+	}
+	for i, l := range layers {
+		if err := readLayer(l, func(idx int, hdr *tar.Header) bool {
+			t.add(position{layer: i, index: idx}, hdr, keepWhiteouts)
+			return false
+		}, nil); err != nil {
+			return errors.Wrapf(err, "failed to read layer %d", i)
+		}
+	}
+
+	final := make(map[position]struct{}, len(t.nodes))
+	for _, pos := range t.nodes {
+		final[pos] = struct{}{}
+	}
+	for pos, target := range t.links {
+		if _, ok := final[pos]; !ok {
+			continue
+		}
+		if cur, ok := t.nodes[target.name]; ok == target.exists && cur == target.pos {
+			continue
+		}
+		return errors.Errorf("cannot merge layers: target of hardlink %s was changed in a later layer", target.name)
+	}
+
+	tw := tar.NewWriter(w)
+	for i, l := range layers {
+		var werr error
+		if err := readLayer(l, func(idx int, hdr *tar.Header) bool {
+			if _, ok := final[position{layer: i, index: idx}]; !ok || werr != nil {
+				return false
+			}
+			werr = tw.WriteHeader(hdr)
+			return werr == nil
+		}, tw); err != nil {
+			return errors.Wrapf(err, "failed to read layer %d", i)
+		}
+		if werr != nil {
+			return werr
+		}
+	}
+	return tw.Close()
+}
+
+// readLayer calls fn for every entry of the layer. The content of the entry
+// is copied to w if fn returns true.
+func readLayer(l Layer, fn func(int, *tar.Header) bool, w io.Writer) error {
+	rc, err := l()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	tr := tar.NewReader(rc)
+	for idx := 0; ; idx++ {
+		hdr, err := tr.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if fn(idx, hdr) {
+			//nolint:gosec // G110: content is copied as-is from a layer
+			if _, err := io.Copy(w, tr); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+type position struct {
+	layer int
+	index int
+}
+
+type linkTarget struct {
+	name   string
+	pos    position
+	exists bool
+}
+
+// tree tracks which entry provides each path after applying the layers read
+// so far.
+type tree struct {
+	nodes    map[string]position
+	children map[string]map[string]struct{}
+	links    map[position]linkTarget
+}
+
+func (t *tree) add(pos position, hdr *tar.Header, keepWhiteouts bool) {
+	p := cleanPath(hdr.Name)
+	dir, base := path.Split(p)
+	dir = cleanPath(dir)
+
+	if base == whiteoutOpaque {
+		t.removeChildren(dir, pos.layer)
+		if keepWhiteouts {
+			t.set(p, pos)
+		}
+		return
+	}
+	if name, ok := strings.CutPrefix(base, whiteoutPrefix); ok {
+		target := path.Join(dir, name)
+		t.removeChildren(target, pos.layer)
+		if cur, ok := t.nodes[target]; ok && cur.layer < pos.layer {
+			delete(t.nodes, target)
+		}
+		if keepWhiteouts {
+			t.set(p, pos)
+		}
+		return
+	}
+
+	if hdr.Typeflag != tar.TypeDir {
+		t.removeChildren(p, pos.layer+1)
+	}
+	if hdr.Typeflag == tar.TypeLink {
+		target := cleanPath(hdr.Linkname)
+		cur, ok := t.nodes[target]
+		t.links[pos] = linkTarget{name: target, pos: cur, exists: ok}
+	}
+	t.set(p, pos)
+}
+
+func (t *tree) set(p string, pos position) {
+	t.nodes[p] = pos
+	if p == "/" {
+		return
+	}
+	dir := path.Dir(p)
+	c, ok := t.children[dir]
+	if !ok {
+		c = map[string]struct{}{}
+		t.children[dir] = c
+	}
+	c[p] = struct{}{}
+}
+
+// removeChildren drops the entries below p that come from layers before
+// layer. Entries of the same layer are kept as whiteouts only apply to lower
+// layers.
+func (t *tree) removeChildren(p string, layer int) {
+	for c := range t.children[p] {
+		t.removeChildren(c, layer)
+		if pos, ok := t.nodes[c]; ok && pos.layer < layer {
+			delete(t.nodes, c)
+		}
+		if _, ok := t.nodes[c]; !ok && len(t.children[c]) == 0 {
+			delete(t.children[p], c)
+			delete(t.children, c)
+		}
+	}
+}
+
+func cleanPath(p string) string {
+	return path.Clean("/" + p)
+}
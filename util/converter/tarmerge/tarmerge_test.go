@@ -0,0 +1,133 @@
+package tarmerge
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type entry struct {
+	name     string
+	typ      byte
+	data     string
+	linkname string
+}
+
+func createLayer(t testing.TB, entries ...entry) Layer {
+	buf := bytes.NewBuffer(nil)
+	tw := tar.NewWriter(buf)
+	for _, e := range entries {
+		hdr := tar.Header{
+			Typeflag: e.typ,
+			Name:     e.name,
+			Size:     int64(len(e.data)),
+			Mode:     0o644,
+			Linkname: e.linkname,
+		}
+		require.NoError(t, tw.WriteHeader(&hdr))
+		_, err := tw.Write([]byte(e.data))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	dt := buf.Bytes()
+	return func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(dt)), nil
+	}
+}
+
+func readEntries(t testing.TB, dt []byte) []string {
+	var out []string
+	tr := tar.NewReader(bytes.NewReader(dt))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return out
+		}
+		require.NoError(t, err)
+		content, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		s := hdr.Name
+		if len(content) > 0 {
+			s += "=" + string(content)
+		}
+		out = append(out, s)
+	}
+}
+
+func testLayers(t testing.TB) []Layer {
+	return []Layer{
+		createLayer(t,
+			entry{name: "etc/", typ: tar.TypeDir},
+			entry{name: "etc/hosts", typ: tar.TypeReg, data: "localhost"},
+			entry{name: "etc/passwd", typ: tar.TypeReg, data: "root"},
+			entry{name: "opt/", typ: tar.TypeDir},
+			entry{name: "opt/a", typ: tar.TypeReg, data: "a"},
+			entry{name: "var/", typ: tar.TypeDir},
+			entry{name: "var/lib/", typ: tar.TypeDir},
+			entry{name: "var/lib/x", typ: tar.TypeReg, data: "x"},
+		),
+		createLayer(t,
+			entry{name: "etc/.wh.hosts", typ: tar.TypeReg},
+			entry{name: "etc/passwd", typ: tar.TypeReg, data: "root:x"},
+			entry{name: "opt/.wh..wh..opq", typ: tar.TypeReg},
+			entry{name: "opt/b", typ: tar.TypeReg, data: "b"},
+			entry{name: "var", typ: tar.TypeSymlink, linkname: "/tmp"},
+		),
+	}
+}
+
+func TestMerge(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, Merge(buf, testLayers(t), false))
+	require.Equal(t, []string{
+		"etc/",
+		"opt/",
+		"etc/passwd=root:x",
+		"opt/b=b",
+		"var",
+	}, readEntries(t, buf.Bytes()))
+}
+
+func TestMergeKeepWhiteouts(t *testing.T) {
+	layers := append(testLayers(t), createLayer(t,
+		entry{name: "etc/hosts", typ: tar.TypeReg, data: "127.0.0.1"},
+		entry{name: ".wh.usr", typ: tar.TypeReg},
+	))
+	buf := &bytes.Buffer{}
+	require.NoError(t, Merge(buf, layers, true))
+	require.Equal(t, []string{
+		"etc/",
+		"opt/",
+		"etc/.wh.hosts",
+		"etc/passwd=root:x",
+		"opt/.wh..wh..opq",
+		"opt/b=b",
+		"var",
+		"etc/hosts=127.0.0.1",
+		".wh.usr",
+	}, readEntries(t, buf.Bytes()))
+}
+
+func TestMergeHardlink(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, Merge(buf, []Layer{
+		createLayer(t,
+			entry{name: "a", typ: tar.TypeReg, data: "a"},
+			entry{name: "b", typ: tar.TypeLink, linkname: "a"},
+		),
+		createLayer(t, entry{name: "c", typ: tar.TypeReg, data: "c"}),
+	}, false))
+	require.Equal(t, []string{"a=a", "b", "c=c"}, readEntries(t, buf.Bytes()))
+
+	err := Merge(io.Discard, []Layer{
+		createLayer(t,
+			entry{name: "a", typ: tar.TypeReg, data: "a"},
+			entry{name: "b", typ: tar.TypeLink, linkname: "a"},
+		),
+		createLayer(t, entry{name: "a", typ: tar.TypeReg, data: "a2"}),
+	}, false)
+	require.ErrorContains(t, err, "target of hardlink /a was changed")
+}
@@ -0,0 +1,426 @@
+// Package imagediff compares two images: their configs, layers, the files in
+// their root filesystems and their attestations.
+package imagediff
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/core/images"
+	"github.com/containerd/containerd/v2/pkg/archive/compression"
+	"github.com/containerd/platforms"
+	"github.com/moby/buildkit/util/attestation"
+	"github.com/moby/buildkit/util/imageutil"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+const (
+	ChangeAdded    = "added"
+	ChangeRemoved  = "removed"
+	ChangeModified = "modified"
+)
+
+// Image is an image in a content provider.
+type Image struct {
+	Provider content.Provider
+	Desc     ocispecs.Descriptor
+}
+
+// Result describes the differences between two images. Values from the first
+// image are always on the A side.
+type Result struct {
+	ManifestA    digest.Digest       `json:"manifestA"`
+	ManifestB    digest.Digest       `json:"manifestB"`
+	Config       []ConfigChange      `json:"config,omitempty"`
+	Layers       []LayerChange       `json:"layers,omitempty"`
+	Files        []FileChange        `json:"files,omitempty"`
+	Attestations []AttestationChange `json:"attestations,omitempty"`
+}
+
+// Empty reports if no differences were found.
+func (r *Result) Empty() bool {
+	return len(r.Config) == 0 && len(r.Layers) == 0 && len(r.Files) == 0 && len(r.Attestations) == 0
+}
+
+// ConfigChange is a field of the image config that differs. Env and labels
+// are compared per key, e.g. "env.PATH" or "labels.version".
+type ConfigChange struct {
+	Field string `json:"field"`
+	A     any    `json:"a,omitempty"`
+	B     any    `json:"b,omitempty"`
+}
+
+// LayerChange is a layer position that has a different blob in the images.
+type LayerChange struct {
+	Index int                  `json:"index"`
+	A     *ocispecs.Descriptor `json:"a,omitempty"`
+	B     *ocispecs.Descriptor `json:"b,omitempty"`
+}
+
+// FileChange is a file that was added, removed or modified in the root
+// filesystem of the second image.
+type FileChange struct {
+	Path   string `json:"path"`
+	Change string `json:"change"`
+	A      *File  `json:"a,omitempty"`
+	B      *File  `json:"b,omitempty"`
+}
+
+// File is the metadata of a file in the root filesystem of an image.
+type File struct {
+	Mode     os.FileMode   `json:"mode"`
+	Size     int64         `json:"size"`
+	UID      int           `json:"uid"`
+	GID      int           `json:"gid"`
+	Linkname string        `json:"linkname,omitempty"`
+	Digest   digest.Digest `json:"digest,omitempty"`
+
+	// layer is the index of the layer that last changed the file
+	layer int
+}
+
+func (f *File) String() string {
+	s := fmt.Sprintf("mode=%s size=%d uid=%d gid=%d", f.Mode, f.Size, f.UID, f.GID)
+	if f.Linkname != "" {
+		s += " link=" + f.Linkname
+	}
+	return s
+}
+
+func (f *File) equal(other *File) bool {
+	return f.Mode == other.Mode && f.Size == other.Size && f.UID == other.UID && f.GID == other.GID &&
+		f.Linkname == other.Linkname && f.Digest == other.Digest
+}
+
+// AttestationChange is a predicate type whose attestations differ.
+type AttestationChange struct {
+	PredicateType string          `json:"predicateType"`
+	Change        string          `json:"change"`
+	A             []digest.Digest `json:"a,omitempty"`
+	B             []digest.Digest `json:"b,omitempty"`
+}
+
+// Diff compares the manifests matching platform in images a and b.
+func Diff(ctx context.Context, a, b Image, platform platforms.MatchComparer) (*Result, error) {
+	imgA, err := load(ctx, a, platform)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load first image")
+	}
+	imgB, err := load(ctx, b, platform)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load second image")
+	}
+
+	res := &Result{
+		ManifestA: imgA.manifest.Digest,
+		ManifestB: imgB.manifest.Digest,
+		Config:    diffConfig(&imgA.config, &imgB.config),
+		Layers:    diffLayers(imgA.layers, imgB.layers),
+	}
+
+	filesA, err := readFiles(ctx, a.Provider, imgA.layers)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read layers of first image")
+	}
+	filesB, err := readFiles(ctx, b.Provider, imgB.layers)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read layers of second image")
+	}
+	res.Files = diffFiles(filesA, filesB)
+	res.Attestations = diffAttestations(imgA.attestations, imgB.attestations)
+	return res, nil
+}
+
+type image struct {
+	manifest     ocispecs.Descriptor
+	config       ocispecs.Image
+	layers       []ocispecs.Descriptor
+	attestations map[string][]digest.Digest
+}
+
+func load(ctx context.Context, img Image, platform platforms.MatchComparer) (*image, error) {
+	out := &image{manifest: img.Desc}
+
+	var idx ocispecs.Index
+	if images.IsIndexType(img.Desc.MediaType) {
+		if err := imageutil.ReadJSON(ctx, img.Provider, img.Desc, &idx); err != nil {
+			return nil, errors.Wrap(err, "failed to read image index")
+		}
+		mfst, err := imageutil.SelectManifest(idx.Manifests, platform)
+		if err != nil {
+			return nil, err
+		}
+		out.manifest = mfst
+	} else if !images.IsManifestType(img.Desc.MediaType) {
+		return nil, errors.Errorf("unsupported media type %s", img.Desc.MediaType)
+	}
+
+	var mfst ocispecs.Manifest
+	if err := imageutil.ReadJSON(ctx, img.Provider, out.manifest, &mfst); err != nil {
+		return nil, errors.Wrap(err, "failed to read image manifest")
+	}
+	if err := imageutil.ReadJSON(ctx, img.Provider, mfst.Config, &out.config); err != nil {
+		return nil, errors.Wrap(err, "failed to read image config")
+	}
+	out.layers = mfst.Layers
+
+	out.attestations = map[string][]digest.Digest{}
+	for _, d := range idx.Manifests {
+		if d.Annotations[attestation.DockerAnnotationReferenceType] != attestation.DockerAnnotationReferenceTypeDefault ||
+			d.Annotations[attestation.DockerAnnotationReferenceDigest] != out.manifest.Digest.String() {
+			continue
+		}
+		var att ocispecs.Manifest
+		if err := imageutil.ReadJSON(ctx, img.Provider, d, &att); err != nil {
+			return nil, errors.Wrap(err, "failed to read attestation manifest")
+		}
+		for _, l := range att.Layers {
+			pt := l.Annotations[attestation.AnnotationPredicateType]
+			out.attestations[pt] = append(out.attestations[pt], l.Digest)
+		}
+	}
+	return out, nil
+}
+
+func diffConfig(a, b *ocispecs.Image) []ConfigChange {
+	var changes []ConfigChange
+	field := func(name string, va, vb any) {
+		if !reflect.DeepEqual(va, vb) {
+			changes = append(changes, ConfigChange{Field: name, A: va, B: vb})
+		}
+	}
+	keyed := func(prefix string, ma, mb map[string]string) {
+		keys := map[string]string{}
+		maps.Copy(keys, ma)
+		maps.Copy(keys, mb)
+		for _, k := range slices.Sorted(maps.Keys(keys)) {
+			va, okA := ma[k]
+			vb, okB := mb[k]
+			if okA != okB || va != vb {
+				c := ConfigChange{Field: prefix + "." + k}
+				if okA {
+					c.A = va
+				}
+				if okB {
+					c.B = vb
+				}
+				changes = append(changes, c)
+			}
+		}
+	}
+
+	field("platform", platforms.Format(a.Platform), platforms.Format(b.Platform))
+	field("user", a.Config.User, b.Config.User)
+	field("workingDir", a.Config.WorkingDir, b.Config.WorkingDir)
+	field("entrypoint", a.Config.Entrypoint, b.Config.Entrypoint)
+	field("cmd", a.Config.Cmd, b.Config.Cmd)
+	field("stopSignal", a.Config.StopSignal, b.Config.StopSignal)
+	field("exposedPorts", slices.Sorted(maps.Keys(a.Config.ExposedPorts)), slices.Sorted(maps.Keys(b.Config.ExposedPorts)))
+	field("volumes", slices.Sorted(maps.Keys(a.Config.Volumes)), slices.Sorted(maps.Keys(b.Config.Volumes)))
+	keyed("env", envMap(a.Config.Env), envMap(b.Config.Env))
+	keyed("labels", a.Config.Labels, b.Config.Labels)
+
+	for i := range max(len(a.History), len(b.History)) {
+		var ha, hb *ocispecs.History
+		if i < len(a.History) {
+			ha = &a.History[i]
+		}
+		if i < len(b.History) {
+			hb = &b.History[i]
+		}
+		if ha != nil && hb != nil && historyEqual(ha, hb) {
+			continue
+		}
+		c := ConfigChange{Field: fmt.Sprintf("history[%d]", i)}
+		if ha != nil {
+			c.A = ha
+		}
+		if hb != nil {
+			c.B = hb
+		}
+		changes = append(changes, c)
+	}
+	return changes
+}
+
+func historyEqual(a, b *ocispecs.History) bool {
+	return a.CreatedBy == b.CreatedBy && a.Comment == b.Comment && a.EmptyLayer == b.EmptyLayer && a.Author == b.Author &&
+		((a.Created == nil && b.Created == nil) || (a.Created != nil && b.Created != nil && a.Created.Equal(*b.Created)))
+}
+
+func envMap(env []string) map[string]string {
+	m := make(map[string]string, len(env))
+	for _, e := range env {
+		k, v, _ := strings.Cut(e, "=")
+		m[k] = v
+	}
+	return m
+}
+
+func diffLayers(a, b []ocispecs.Descriptor) []LayerChange {
+	var changes []LayerChange
+	for i := range max(len(a), len(b)) {
+		c := LayerChange{Index: i}
+		if i < len(a) {
+			c.A = &a[i]
+		}
+		if i < len(b) {
+			c.B = &b[i]
+		}
+		if c.A != nil && c.B != nil && c.A.Digest == c.B.Digest {
+			continue
+		}
+		changes = append(changes, c)
+	}
+	return changes
+}
+
+// readFiles applies the layers in order and returns the files of the
+// resulting root filesystem.
+func readFiles(ctx context.Context, provider content.Provider, layers []ocispecs.Descriptor) (map[string]*File, error) {
+	files := map[string]*File{}
+	for i, l := range layers {
+		if err := applyLayer(ctx, provider, l, i, files); err != nil {
+			return nil, errors.Wrapf(err, "layer %s", l.Digest)
+		}
+	}
+	return files, nil
+}
+
+func applyLayer(ctx context.Context, provider content.Provider, desc ocispecs.Descriptor, idx int, files map[string]*File) error {
+	ra, err := provider.ReaderAt(ctx, desc)
+	if err != nil {
+		return err
+	}
+	defer ra.Close()
+
+	r, err := compression.DecompressStream(content.NewReader(ra))
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		p := path.Join("/", hdr.Name)
+		dir, base := path.Split(p)
+		dir = path.Clean(dir)
+
+		if base == ".wh..wh..opq" {
+			// opaque directory hides the contents from lower layers
+			for k, f := range files {
+				if f.layer < idx && strings.HasPrefix(k, strings.TrimSuffix(dir, "/")+"/") {
+					delete(files, k)
+				}
+			}
+			continue
+		}
+		if name, ok := strings.CutPrefix(base, ".wh."); ok {
+			removeTree(files, path.Join(dir, name))
+			continue
+		}
+
+		f := &File{
+			Mode:     hdr.FileInfo().Mode(),
+			Size:     hdr.Size,
+			UID:      hdr.Uid,
+			GID:      hdr.Gid,
+			Linkname: hdr.Linkname,
+			layer:    idx,
+		}
+		switch hdr.Typeflag {
+		case tar.TypeReg:
+			dgst, err := digest.Canonical.FromReader(tr)
+			if err != nil {
+				return err
+			}
+			f.Digest = dgst
+		case tar.TypeLink:
+			target, ok := files[path.Join("/", hdr.Linkname)]
+			if !ok {
+				return errors.Errorf("hardlink %s points to missing file %s", p, hdr.Linkname)
+			}
+			f = &File{}
+			*f = *target
+			f.layer = idx
+		}
+		if old, ok := files[p]; ok && old.Mode.IsDir() && !f.Mode.IsDir() {
+			removeTree(files, p)
+		}
+		files[p] = f
+	}
+}
+
+func removeTree(files map[string]*File, p string) {
+	delete(files, p)
+	for k := range files {
+		if strings.HasPrefix(k, p+"/") {
+			delete(files, k)
+		}
+	}
+}
+
+func diffFiles(a, b map[string]*File) []FileChange {
+	var changes []FileChange
+	for _, p := range slices.Sorted(maps.Keys(a)) {
+		fa := a[p]
+		fb, ok := b[p]
+		switch {
+		case !ok:
+			changes = append(changes, FileChange{Path: p, Change: ChangeRemoved, A: fa})
+		case !fa.equal(fb):
+			changes = append(changes, FileChange{Path: p, Change: ChangeModified, A: fa, B: fb})
+		}
+	}
+	for p, fb := range b {
+		if _, ok := a[p]; !ok {
+			changes = append(changes, FileChange{Path: p, Change: ChangeAdded, B: fb})
+		}
+	}
+	slices.SortFunc(changes, func(x, y FileChange) int {
+		return strings.Compare(x.Path, y.Path)
+	})
+	return changes
+}
+
+func diffAttestations(a, b map[string][]digest.Digest) []AttestationChange {
+	var changes []AttestationChange
+	types := map[string][]digest.Digest{}
+	maps.Copy(types, a)
+	maps.Copy(types, b)
+	for _, pt := range slices.Sorted(maps.Keys(types)) {
+		da, okA := a[pt]
+		db, okB := b[pt]
+		c := AttestationChange{PredicateType: pt, A: da, B: db}
+		switch {
+		case !okA:
+			c.Change = ChangeAdded
+		case !okB:
+			c.Change = ChangeRemoved
+		case !slices.Equal(slices.Sorted(slices.Values(da)), slices.Sorted(slices.Values(db))):
+			c.Change = ChangeModified
+		default:
+			continue
+		}
+		changes = append(changes, c)
+	}
+	return changes
+}
@@ -0,0 +1,169 @@
+package imagediff
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/platforms"
+	"github.com/moby/buildkit/util/attestation"
+	"github.com/moby/buildkit/util/contentutil"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+)
+
+type testEntry struct {
+	name     string
+	typ      byte
+	mode     int64
+	data     string
+	linkname string
+}
+
+func TestDiff(t *testing.T) {
+	ctx := context.TODO()
+	store := contentutil.NewBuffer()
+
+	base := []testEntry{
+		{name: "etc/", typ: tar.TypeDir, mode: 0755},
+		{name: "etc/passwd", typ: tar.TypeReg, mode: 0644, data: "root:x:0:0"},
+		{name: "etc/hosts", typ: tar.TypeReg, mode: 0644, data: "localhost"},
+		{name: "opt/", typ: tar.TypeDir, mode: 0755},
+		{name: "opt/app/", typ: tar.TypeDir, mode: 0755},
+		{name: "opt/app/a", typ: tar.TypeReg, mode: 0644, data: "a"},
+		{name: "bin/", typ: tar.TypeDir, mode: 0755},
+		{name: "bin/sh", typ: tar.TypeReg, mode: 0755, data: "elf"},
+	}
+
+	imgA := writeImage(ctx, t, store, ocispecs.ImageConfig{
+		Env:    []string{"PATH=/bin", "FOO=1"},
+		Labels: map[string]string{"version": "1"},
+		Cmd:    []string{"sh"},
+	}, [][]testEntry{base}, map[string]string{"https://spdx.dev/Document": "sbom-a"})
+
+	imgB := writeImage(ctx, t, store, ocispecs.ImageConfig{
+		Env:    []string{"PATH=/bin", "BAR=2"},
+		Labels: map[string]string{"version": "2"},
+		Cmd:    []string{"sh"},
+	}, [][]testEntry{base, {
+		{name: "etc/.wh.hosts", typ: tar.TypeReg},
+		{name: "etc/passwd", typ: tar.TypeReg, mode: 0600, data: "root:x:0:0"},
+		{name: "opt/app/.wh..wh..opq", typ: tar.TypeReg},
+		{name: "opt/app/b", typ: tar.TypeReg, mode: 0644, data: "b"},
+		{name: "bin/bash", typ: tar.TypeLink, linkname: "bin/sh"},
+	}}, map[string]string{"https://spdx.dev/Document": "sbom-b", "https://slsa.dev/provenance/v0.2": "prov"})
+
+	res, err := Diff(ctx, imgA, imgB, platforms.Only(ocispecs.Platform{OS: "linux", Architecture: "amd64"}))
+	require.NoError(t, err)
+	require.False(t, res.Empty())
+
+	fields := map[string]ConfigChange{}
+	for _, c := range res.Config {
+		fields[c.Field] = c
+	}
+	require.Len(t, fields, 4)
+	require.Equal(t, ConfigChange{Field: "env.FOO", A: "1"}, fields["env.FOO"])
+	require.Equal(t, ConfigChange{Field: "env.BAR", B: "2"}, fields["env.BAR"])
+	require.Equal(t, ConfigChange{Field: "labels.version", A: "1", B: "2"}, fields["labels.version"])
+	require.Contains(t, fields, "history[1]")
+
+	require.Len(t, res.Layers, 1)
+	require.Equal(t, 1, res.Layers[0].Index)
+	require.Nil(t, res.Layers[0].A)
+
+	var files []string
+	for _, f := range res.Files {
+		files = append(files, f.Change+" "+f.Path)
+	}
+	require.Equal(t, []string{
+		"added /bin/bash",
+		"removed /etc/hosts",
+		"modified /etc/passwd",
+		"removed /opt/app/a",
+		"added /opt/app/b",
+	}, files)
+	require.Equal(t, res.Files[0].B.Digest, digest.FromString("elf"))
+
+	require.Len(t, res.Attestations, 2)
+	require.Equal(t, "https://slsa.dev/provenance/v0.2", res.Attestations[0].PredicateType)
+	require.Equal(t, ChangeAdded, res.Attestations[0].Change)
+	require.Equal(t, ChangeModified, res.Attestations[1].Change)
+
+	res, err = Diff(ctx, imgA, imgA, platforms.Only(ocispecs.Platform{OS: "linux", Architecture: "amd64"}))
+	require.NoError(t, err)
+	require.True(t, res.Empty())
+
+	_, err = Diff(ctx, imgA, imgB, platforms.Only(ocispecs.Platform{OS: "linux", Architecture: "s390x"}))
+	require.ErrorContains(t, err, "no matching manifest for platform")
+}
+
+func writeImage(ctx context.Context, t *testing.T, store contentutil.Buffer, cfg ocispecs.ImageConfig, layers [][]testEntry, atts map[string]string) Image {
+	write := func(mt string, dt []byte) ocispecs.Descriptor {
+		desc := ocispecs.Descriptor{MediaType: mt, Digest: digest.FromBytes(dt), Size: int64(len(dt))}
+		require.NoError(t, content.WriteBlob(ctx, store, desc.Digest.String(), bytes.NewReader(dt), desc))
+		return desc
+	}
+	writeJSON := func(mt string, v any) ocispecs.Descriptor {
+		dt, err := json.Marshal(v)
+		require.NoError(t, err)
+		return write(mt, dt)
+	}
+
+	img := ocispecs.Image{
+		Platform: ocispecs.Platform{OS: "linux", Architecture: "amd64"},
+		Config:   cfg,
+	}
+	var descs []ocispecs.Descriptor
+	for _, entries := range layers {
+		buf := &bytes.Buffer{}
+		tw := tar.NewWriter(buf)
+		for _, e := range entries {
+			require.NoError(t, tw.WriteHeader(&tar.Header{
+				Name:     e.name,
+				Typeflag: e.typ,
+				Mode:     e.mode,
+				Size:     int64(len(e.data)),
+				Linkname: e.linkname,
+			}))
+			_, err := tw.Write([]byte(e.data))
+			require.NoError(t, err)
+		}
+		require.NoError(t, tw.Close())
+		descs = append(descs, write(ocispecs.MediaTypeImageLayer, buf.Bytes()))
+		img.History = append(img.History, ocispecs.History{CreatedBy: "layer"})
+	}
+
+	mfst := writeJSON(ocispecs.MediaTypeImageManifest, ocispecs.Manifest{
+		MediaType: ocispecs.MediaTypeImageManifest,
+		Config:    writeJSON(ocispecs.MediaTypeImageConfig, img),
+		Layers:    descs,
+	})
+	mfst.Platform = &img.Platform
+
+	var attLayers []ocispecs.Descriptor
+	for pt, dt := range atts {
+		l := write("application/vnd.in-toto+json", []byte(dt))
+		l.Annotations = map[string]string{attestation.AnnotationPredicateType: pt}
+		attLayers = append(attLayers, l)
+	}
+	att := writeJSON(ocispecs.MediaTypeImageManifest, ocispecs.Manifest{
+		MediaType: ocispecs.MediaTypeImageManifest,
+		Config:    write(ocispecs.MediaTypeImageConfig, []byte("{}")),
+		Layers:    attLayers,
+	})
+	att.Platform = &ocispecs.Platform{OS: "unknown", Architecture: "unknown"}
+	att.Annotations = map[string]string{
+		attestation.DockerAnnotationReferenceType:   attestation.DockerAnnotationReferenceTypeDefault,
+		attestation.DockerAnnotationReferenceDigest: mfst.Digest.String(),
+	}
+
+	idx := writeJSON(ocispecs.MediaTypeImageIndex, ocispecs.Index{
+		MediaType: ocispecs.MediaTypeImageIndex,
+		Manifests: []ocispecs.Descriptor{mfst, att},
+	})
+	return Image{Provider: store, Desc: idx}
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/containerd/platforms"
 	intoto "github.com/in-toto/in-toto-golang/in_toto"
 	srctypes "github.com/moby/buildkit/source/types"
+	"github.com/moby/buildkit/util/attestation"
 	"github.com/moby/buildkit/util/contentutil"
 	"github.com/moby/buildkit/util/leaseutil"
 	"github.com/moby/buildkit/util/resolver/limited"
@@ -189,7 +190,8 @@ func childrenConfigHandler(provider content.Provider, platform platforms.MatchCo
 				descs = append(descs, index.Manifests...)
 			}
 		case images.MediaTypeDockerSchema2Config, ocispecs.MediaTypeImageConfig, docker.LegacyConfigMediaType,
-			intoto.PayloadType:
+			intoto.PayloadType, attestation.MediaTypeDSSEEnvelope,
+			attestation.MediaTypeSimpleSigning, ocispecs.MediaTypeEmptyJSON:
 			// childless data types.
 			return nil, nil
 		default:
@@ -0,0 +1,63 @@
+package imageutil
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/platforms"
+	"github.com/moby/buildkit/util/attestation"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+// maxManifestSize limits the size of the indexes, manifests and configs read
+// with ReadJSON.
+const maxManifestSize = 16 << 20
+
+// ReadJSON reads the blob of desc, verifies its digest and decodes it into v.
+func ReadJSON(ctx context.Context, provider content.Provider, desc ocispecs.Descriptor, v any) error {
+	if desc.Size > maxManifestSize {
+		return errors.Errorf("%s size %d exceeds limit %d", desc.Digest, desc.Size, maxManifestSize)
+	}
+	if err := desc.Digest.Validate(); err != nil {
+		return err
+	}
+	dt, err := content.ReadBlob(ctx, provider, desc)
+	if err != nil {
+		return err
+	}
+	if dgst := desc.Digest.Algorithm().FromBytes(dt); dgst != desc.Digest {
+		return errors.Errorf("digest mismatch for %s: %s", desc.Digest, dgst)
+	}
+	return json.Unmarshal(dt, v)
+}
+
+// SelectManifest returns the manifest of an index that best matches platform.
+// Attestation manifests are skipped. A manifest without a platform is only
+// selected if it is the only manifest in the index.
+func SelectManifest(descs []ocispecs.Descriptor, platform platforms.MatchComparer) (ocispecs.Descriptor, error) {
+	var (
+		best       *ocispecs.Descriptor
+		candidates []ocispecs.Descriptor
+	)
+	for i, desc := range descs {
+		if _, ok := desc.Annotations[attestation.DockerAnnotationReferenceType]; ok {
+			continue
+		}
+		candidates = append(candidates, desc)
+		if desc.Platform == nil || !platform.Match(*desc.Platform) {
+			continue
+		}
+		if best == nil || platform.Less(*desc.Platform, *best.Platform) {
+			best = &descs[i]
+		}
+	}
+	if best != nil {
+		return *best, nil
+	}
+	if len(candidates) == 1 && candidates[0].Platform == nil {
+		return candidates[0], nil
+	}
+	return ocispecs.Descriptor{}, errors.New("no matching manifest for platform")
+}
@@ -0,0 +1,81 @@
+package imageutil
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/containerd/platforms"
+	"github.com/moby/buildkit/util/attestation"
+	"github.com/moby/buildkit/util/contentutil"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectManifest(t *testing.T) {
+	amd64 := ocispecs.Descriptor{Digest: digest.FromString("amd64"), Platform: &ocispecs.Platform{OS: "linux", Architecture: "amd64"}}
+	arm64 := ocispecs.Descriptor{Digest: digest.FromString("arm64"), Platform: &ocispecs.Platform{OS: "linux", Architecture: "arm64"}}
+	noPlatform := ocispecs.Descriptor{Digest: digest.FromString("noPlatform")}
+	att := ocispecs.Descriptor{
+		Digest:   digest.FromString("att"),
+		Platform: &ocispecs.Platform{OS: "unknown", Architecture: "unknown"},
+		Annotations: map[string]string{
+			attestation.DockerAnnotationReferenceType: attestation.DockerAnnotationReferenceTypeDefault,
+		},
+	}
+	arm64Only := platforms.Only(ocispecs.Platform{OS: "linux", Architecture: "arm64"})
+
+	desc, err := SelectManifest([]ocispecs.Descriptor{amd64, arm64, att}, arm64Only)
+	require.NoError(t, err)
+	require.Equal(t, arm64.Digest, desc.Digest)
+
+	desc, err = SelectManifest([]ocispecs.Descriptor{noPlatform, att}, arm64Only)
+	require.NoError(t, err)
+	require.Equal(t, noPlatform.Digest, desc.Digest)
+
+	_, err = SelectManifest([]ocispecs.Descriptor{amd64, noPlatform}, platforms.Only(ocispecs.Platform{OS: "windows", Architecture: "arm64"}))
+	require.ErrorContains(t, err, "no matching manifest")
+
+	_, err = SelectManifest([]ocispecs.Descriptor{att}, platforms.Any(*att.Platform))
+	require.ErrorContains(t, err, "no matching manifest")
+
+	p := platforms.DefaultSpec()
+	def := ocispecs.Descriptor{Digest: digest.FromString("default"), Platform: &p}
+	desc, err = SelectManifest([]ocispecs.Descriptor{noPlatform, def}, platforms.Default())
+	require.NoError(t, err)
+	require.Equal(t, def.Digest, desc.Digest)
+}
+
+func TestReadJSON(t *testing.T) {
+	ctx := context.TODO()
+	mfst := ocispecs.Manifest{ArtifactType: "application/vnd.example"}
+	dt, err := json.Marshal(mfst)
+	require.NoError(t, err)
+	desc := ocispecs.Descriptor{Digest: digest.FromBytes(dt), Size: int64(len(dt))}
+	bad := ocispecs.Descriptor{Digest: digest.FromString("other"), Size: int64(len(dt))}
+	provider := contentutil.FromFetcher(fetcher{desc.Digest: dt, bad.Digest: dt})
+
+	var out ocispecs.Manifest
+	require.NoError(t, ReadJSON(ctx, provider, desc, &out))
+	require.Equal(t, mfst.ArtifactType, out.ArtifactType)
+
+	large := desc
+	large.Size = maxManifestSize + 1
+	require.ErrorContains(t, ReadJSON(ctx, provider, large, &out), "exceeds limit")
+
+	require.ErrorContains(t, ReadJSON(ctx, provider, bad, &out), "digest mismatch")
+}
+
+type fetcher map[digest.Digest][]byte
+
+func (f fetcher) Fetch(ctx context.Context, desc ocispecs.Descriptor) (io.ReadCloser, error) {
+	dt, ok := f[desc.Digest]
+	if !ok {
+		return nil, errors.Errorf("not found: %s", desc.Digest)
+	}
+	return io.NopCloser(bytes.NewReader(dt)), nil
+}
@@ -0,0 +1,274 @@
+// Package imageverify verifies the provenance attestations attached to
+// images before they are used in a build.
+package imageverify
+
+import (
+	"context"
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/core/images"
+	"github.com/containerd/platforms"
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+	slsa02 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v0.2"
+	slsa1 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v1"
+	"github.com/moby/buildkit/session/signer"
+	"github.com/moby/buildkit/util/attestation"
+	"github.com/moby/buildkit/util/imageutil"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+	"github.com/secure-systems-lab/go-securesystemslib/dsse"
+)
+
+const (
+	ModeError = "error"
+	ModeWarn  = "warn"
+)
+
+// maxAttestationSize limits the size of attestation blobs that are read.
+const maxAttestationSize = 16 << 20
+
+// Policy defines the requirements for the provenance of an image.
+type Policy struct {
+	// BuilderIDs are the allowed builder IDs. If empty, provenance from any
+	// builder is accepted.
+	BuilderIDs []string
+	// Keys are the public keys that the provenance must be signed with. If
+	// empty, signatures are not checked.
+	Keys []crypto.PublicKey
+	// Mode is ModeError to fail the build if verification fails, or
+	// ModeWarn to only report a warning.
+	Mode string
+}
+
+// ParsePolicy creates a policy from a comma separated list of builder IDs,
+// PEM encoded public keys and a mode. All values are optional.
+func ParsePolicy(builderIDs, keys, mode string) (*Policy, error) {
+	p := &Policy{Mode: ModeError}
+	for _, id := range strings.Split(builderIDs, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			p.BuilderIDs = append(p.BuilderIDs, id)
+		}
+	}
+	if keys != "" {
+		var err error
+		p.Keys, err = signer.ParsePublicKeys([]byte(keys))
+		if err != nil {
+			return nil, err
+		}
+	}
+	switch mode {
+	case "", ModeError:
+	case ModeWarn:
+		p.Mode = ModeWarn
+	default:
+		return nil, errors.Errorf("invalid image verification mode %q", mode)
+	}
+	return p, nil
+}
+
+// Result describes the provenance that an image was verified against.
+type Result struct {
+	// Manifest is the digest of the verified platform manifest.
+	Manifest digest.Digest
+	// Provenance is the descriptor of the provenance attestation blob.
+	Provenance ocispecs.Descriptor
+	// PredicateType is the predicate type of the provenance.
+	PredicateType string
+	// BuilderID is the builder ID recorded in the provenance.
+	BuilderID string
+	// KeyIDs are the IDs of the keys that signed the provenance, if
+	// signatures were verified.
+	KeyIDs []string
+}
+
+// ReferrersFunc returns the descriptors of the manifests that refer to dgst
+// with their subject field.
+type ReferrersFunc func(ctx context.Context, dgst digest.Digest) ([]ocispecs.Descriptor, error)
+
+// Verify checks that the image at desc has a provenance attestation for the
+// manifest matching platform that satisfies the policy. Attestations are
+// looked up in the image index, as stored by BuildKit with the default
+// attestation storage, and in the referrers of the manifest if referrers is
+// not nil. Images that are a single manifest without an index can only have
+// attestations as referrers.
+func Verify(ctx context.Context, provider content.Provider, desc ocispecs.Descriptor, platform platforms.MatchComparer, pol *Policy, referrers ReferrersFunc) (*Result, error) {
+	var (
+		mfst    ocispecs.Descriptor
+		attDesc *ocispecs.Descriptor
+	)
+	switch {
+	case images.IsIndexType(desc.MediaType):
+		var idx ocispecs.Index
+		if err := imageutil.ReadJSON(ctx, provider, desc, &idx); err != nil {
+			return nil, errors.Wrap(err, "failed to read image index")
+		}
+		var err error
+		mfst, err = imageutil.SelectManifest(idx.Manifests, platform)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, d := range idx.Manifests {
+			if d.Annotations[attestation.DockerAnnotationReferenceType] == attestation.DockerAnnotationReferenceTypeDefault &&
+				d.Annotations[attestation.DockerAnnotationReferenceDigest] == mfst.Digest.String() {
+				attDesc = &d
+				break
+			}
+		}
+	case images.IsManifestType(desc.MediaType):
+		mfst = desc
+	default:
+		return nil, errors.Errorf("unsupported image media type %q", desc.MediaType)
+	}
+
+	if attDesc == nil && referrers != nil {
+		refs, err := referrers(ctx, mfst.Digest)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list referrers of %s", mfst.Digest)
+		}
+		for _, d := range refs {
+			if d.ArtifactType == attestation.ArtifactTypeAttestationManifest {
+				attDesc = &d
+				break
+			}
+		}
+	}
+	if attDesc == nil {
+		return nil, errors.Errorf("no attestations found for %s", mfst.Digest)
+	}
+	var att ocispecs.Manifest
+	if err := imageutil.ReadJSON(ctx, provider, *attDesc, &att); err != nil {
+		return nil, errors.Wrap(err, "failed to read attestation manifest")
+	}
+
+	var errs []error
+	for _, l := range att.Layers {
+		pt := l.Annotations[attestation.AnnotationPredicateType]
+		if pt != slsa02.PredicateSLSAProvenance && pt != slsa1.PredicateSLSAProvenance {
+			continue
+		}
+		res, err := verifyProvenance(ctx, provider, l, mfst.Digest, pol)
+		if err == nil {
+			return res, nil
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) == 0 {
+		return nil, errors.Errorf("no provenance attestation found for %s", mfst.Digest)
+	}
+	return nil, errors.Wrapf(errs[0], "provenance of %s", mfst.Digest)
+}
+
+func verifyProvenance(ctx context.Context, provider content.Provider, desc ocispecs.Descriptor, subject digest.Digest, pol *Policy) (*Result, error) {
+	if desc.Size > maxAttestationSize {
+		return nil, errors.Errorf("attestation %s is too large", desc.Digest)
+	}
+	dt, err := content.ReadBlob(ctx, provider, desc)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Result{
+		Manifest:      subject,
+		Provenance:    desc,
+		PredicateType: desc.Annotations[attestation.AnnotationPredicateType],
+	}
+
+	payload := dt
+	switch desc.MediaType {
+	case attestation.MediaTypeDSSEEnvelope:
+		var env dsse.Envelope
+		if err := json.Unmarshal(dt, &env); err != nil {
+			return nil, errors.Wrap(err, "failed to decode attestation envelope")
+		}
+		payload, err = base64.StdEncoding.DecodeString(env.Payload)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode attestation payload")
+		}
+		if len(pol.Keys) > 0 {
+			res.KeyIDs, err = verifyEnvelope(&env, payload, pol.Keys)
+			if err != nil {
+				return nil, err
+			}
+		}
+	case intoto.PayloadType:
+		if len(pol.Keys) > 0 {
+			return nil, errors.New("provenance is not signed")
+		}
+	default:
+		return nil, errors.Errorf("unsupported attestation media type %q", desc.MediaType)
+	}
+
+	var stmt struct {
+		intoto.StatementHeader
+		Predicate json.RawMessage `json:"predicate"`
+	}
+	if err := json.Unmarshal(payload, &stmt); err != nil {
+		return nil, errors.Wrap(err, "failed to decode provenance")
+	}
+	if stmt.PredicateType != res.PredicateType {
+		return nil, errors.Errorf("provenance predicate type %q does not match %q", stmt.PredicateType, res.PredicateType)
+	}
+	if !slices.ContainsFunc(stmt.Subject, func(s intoto.Subject) bool {
+		return s.Digest[subject.Algorithm().String()] == subject.Encoded()
+	}) {
+		return nil, errors.Errorf("provenance subject does not match %s", subject)
+	}
+
+	switch stmt.PredicateType {
+	case slsa02.PredicateSLSAProvenance:
+		var p slsa02.ProvenancePredicate
+		if err := json.Unmarshal(stmt.Predicate, &p); err != nil {
+			return nil, errors.Wrap(err, "failed to decode provenance predicate")
+		}
+		res.BuilderID = p.Builder.ID
+	case slsa1.PredicateSLSAProvenance:
+		var p slsa1.ProvenancePredicate
+		if err := json.Unmarshal(stmt.Predicate, &p); err != nil {
+			return nil, errors.Wrap(err, "failed to decode provenance predicate")
+		}
+		res.BuilderID = p.RunDetails.Builder.ID
+	}
+
+	if len(pol.BuilderIDs) > 0 && !slices.Contains(pol.BuilderIDs, res.BuilderID) {
+		if res.BuilderID == "" {
+			return nil, errors.New("provenance has no builder ID")
+		}
+		return nil, errors.Errorf("builder ID %q is not allowed", res.BuilderID)
+	}
+	return res, nil
+}
+
+// verifyEnvelope returns the IDs of the keys that signed the envelope. At
+// least one signature must be valid.
+func verifyEnvelope(env *dsse.Envelope, payload []byte, keys []crypto.PublicKey) ([]string, error) {
+	pae := dsse.PAE(env.PayloadType, payload)
+	var ids []string
+	for _, key := range keys {
+		for _, s := range env.Signatures {
+			sig, err := base64.StdEncoding.DecodeString(s.Sig)
+			if err != nil {
+				continue
+			}
+			if signer.VerifyWithKey(key, pae, sig) != nil {
+				continue
+			}
+			id, err := signer.KeyID(key)
+			if err != nil {
+				return nil, err
+			}
+			ids = append(ids, id)
+			break
+		}
+	}
+	if len(ids) == 0 {
+		return nil, errors.New("provenance is not signed by a trusted key")
+	}
+	return ids, nil
+}
@@ -0,0 +1,190 @@
+package imageverify
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/platforms"
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+	slsa02 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v0.2"
+	"github.com/moby/buildkit/exporter/attestation"
+	attestationTypes "github.com/moby/buildkit/util/attestation"
+	"github.com/moby/buildkit/util/contentutil"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify(t *testing.T) {
+	ctx := context.TODO()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	s, err := attestation.NewKeySigner(key)
+	require.NoError(t, err)
+	pubDER, err := x509.MarshalPKIXPublicKey(key.Public())
+	require.NoError(t, err)
+	pubPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}))
+
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherDER, err := x509.MarshalPKIXPublicKey(otherKey.Public())
+	require.NoError(t, err)
+	otherPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: otherDER}))
+
+	const builderID = "https://github.com/example/repo/.github/workflows/build.yml"
+	platform := platforms.Only(ocispecs.Platform{OS: "linux", Architecture: "amd64"})
+
+	plain := newImage(ctx, t, builderID, nil)
+	signed := newImage(ctx, t, builderID, func(stmt intoto.Statement) ([]byte, string) {
+		env, err := attestation.SignStatement(ctx, s, stmt)
+		require.NoError(t, err)
+		dt, err := json.Marshal(env)
+		require.NoError(t, err)
+		return dt, attestationTypes.MediaTypeDSSEEnvelope
+	})
+
+	tcs := []struct {
+		name       string
+		img        *testImage
+		builderIDs string
+		keys       string
+		err        string
+	}{
+		{name: "any builder", img: plain},
+		{name: "allowed builder", img: plain, builderIDs: "https://example.com/other," + builderID},
+		{name: "denied builder", img: plain, builderIDs: "https://example.com/other", err: "is not allowed"},
+		{name: "unsigned", img: plain, keys: pubPEM, err: "not signed"},
+		{name: "signed", img: signed, builderIDs: builderID, keys: pubPEM},
+		{name: "untrusted key", img: signed, keys: otherPEM, err: "not signed by a trusted key"},
+		{name: "signed without keys", img: signed, builderIDs: builderID},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			pol, err := ParsePolicy(tc.builderIDs, tc.keys, "")
+			require.NoError(t, err)
+			res, err := Verify(ctx, tc.img.store, tc.img.index, platform, pol, nil)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, builderID, res.BuilderID)
+			require.Equal(t, tc.img.manifest, res.Manifest)
+			require.Equal(t, slsa02.PredicateSLSAProvenance, res.PredicateType)
+			if tc.keys != "" {
+				require.Len(t, res.KeyIDs, 1)
+			} else {
+				require.Empty(t, res.KeyIDs)
+			}
+		})
+	}
+
+	pol, err := ParsePolicy("", "", ModeWarn)
+	require.NoError(t, err)
+	_, err = Verify(ctx, plain.store, plain.index, platforms.Only(ocispecs.Platform{OS: "linux", Architecture: "s390x"}), pol, nil)
+	require.ErrorContains(t, err, "no matching manifest")
+
+	// single manifest images only have attestations as referrers
+	mfst := plain.platformManifest
+	mfst.Platform = nil
+	_, err = Verify(ctx, plain.store, mfst, platform, pol, nil)
+	require.ErrorContains(t, err, "no attestations found")
+
+	referrers := func(_ context.Context, dgst digest.Digest) ([]ocispecs.Descriptor, error) {
+		require.Equal(t, mfst.Digest, dgst)
+		return []ocispecs.Descriptor{{
+			MediaType:    ocispecs.MediaTypeImageManifest,
+			ArtifactType: "application/vnd.dev.cosign.artifact.sig.v1+json",
+			Digest:       digest.FromString("sig"),
+			Size:         3,
+		}, {
+			MediaType:    plain.attestation.MediaType,
+			ArtifactType: attestationTypes.ArtifactTypeAttestationManifest,
+			Digest:       plain.attestation.Digest,
+			Size:         plain.attestation.Size,
+		}}, nil
+	}
+	res, err := Verify(ctx, plain.store, mfst, platform, pol, referrers)
+	require.NoError(t, err)
+	require.Equal(t, mfst.Digest, res.Manifest)
+	require.Equal(t, builderID, res.BuilderID)
+
+	_, err = ParsePolicy("", "", "ignore")
+	require.ErrorContains(t, err, "invalid image verification mode")
+}
+
+type testImage struct {
+	store            content.Provider
+	index            ocispecs.Descriptor
+	manifest         digest.Digest
+	platformManifest ocispecs.Descriptor
+	attestation      ocispecs.Descriptor
+}
+
+func newImage(ctx context.Context, t *testing.T, builderID string, sign func(intoto.Statement) ([]byte, string)) *testImage {
+	store := contentutil.NewBuffer()
+	write := func(mt string, dt []byte) ocispecs.Descriptor {
+		desc := ocispecs.Descriptor{MediaType: mt, Digest: digest.FromBytes(dt), Size: int64(len(dt))}
+		require.NoError(t, content.WriteBlob(ctx, store, desc.Digest.String(), bytes.NewReader(dt), desc))
+		return desc
+	}
+	writeJSON := func(mt string, v any) ocispecs.Descriptor {
+		dt, err := json.Marshal(v)
+		require.NoError(t, err)
+		return write(mt, dt)
+	}
+
+	mfst := writeJSON(ocispecs.MediaTypeImageManifest, ocispecs.Manifest{
+		MediaType: ocispecs.MediaTypeImageManifest,
+		Config:    write(ocispecs.MediaTypeImageConfig, []byte("{}")),
+	})
+	mfst.Platform = &ocispecs.Platform{OS: "linux", Architecture: "amd64"}
+
+	stmt := intoto.Statement{
+		StatementHeader: intoto.StatementHeader{
+			Type:          intoto.StatementInTotoV01,
+			PredicateType: slsa02.PredicateSLSAProvenance,
+			Subject: []intoto.Subject{{
+				Name:   "_",
+				Digest: map[string]string{"sha256": mfst.Digest.Encoded()},
+			}},
+		},
+		Predicate: slsa02.ProvenancePredicate{
+			Builder: slsa02.ProvenanceBuilder{ID: builderID},
+		},
+	}
+	var prov ocispecs.Descriptor
+	if sign != nil {
+		dt, mt := sign(stmt)
+		prov = write(mt, dt)
+	} else {
+		prov = writeJSON(intoto.PayloadType, stmt)
+	}
+	prov.Annotations = map[string]string{attestationTypes.AnnotationPredicateType: slsa02.PredicateSLSAProvenance}
+
+	att := writeJSON(ocispecs.MediaTypeImageManifest, ocispecs.Manifest{
+		MediaType: ocispecs.MediaTypeImageManifest,
+		Config:    write(ocispecs.MediaTypeImageConfig, []byte("{}")),
+		Layers:    []ocispecs.Descriptor{prov},
+	})
+	att.Platform = &ocispecs.Platform{OS: "unknown", Architecture: "unknown"}
+	att.Annotations = map[string]string{
+		attestationTypes.DockerAnnotationReferenceType:   attestationTypes.DockerAnnotationReferenceTypeDefault,
+		attestationTypes.DockerAnnotationReferenceDigest: mfst.Digest.String(),
+	}
+
+	idx := writeJSON(ocispecs.MediaTypeImageIndex, ocispecs.Index{
+		MediaType: ocispecs.MediaTypeImageIndex,
+		Manifests: []ocispecs.Descriptor{mfst, att},
+	})
+	return &testImage{store: store, index: idx, manifest: mfst.Digest, platformManifest: mfst, attestation: att}
+}
@@ -0,0 +1,285 @@
+// Package accesslog records the DNS lookups and outgoing connections made
+// from a network namespace by inspecting its packets.
+package accesslog
+
+import (
+	"cmp"
+	"encoding/binary"
+	"net/netip"
+	"slices"
+	"sync"
+
+	resourcestypes "github.com/moby/buildkit/executor/resources/types"
+)
+
+// maxRecords limits the number of DNS lookups and connections kept.
+const maxRecords = 1000
+
+const (
+	protoTCP = 6
+	protoUDP = 17
+
+	tcpFlagSYN = 0x02
+	tcpFlagACK = 0x10
+
+	dnsPort = 53
+)
+
+// Log aggregates the network access seen in packets.
+type Log struct {
+	mu        sync.Mutex
+	dns       map[string]map[netip.Addr]struct{}
+	conns     map[connKey]struct{}
+	truncated bool
+}
+
+type connKey struct {
+	proto string
+	addr  netip.AddrPort
+}
+
+// New returns an empty Log.
+func New() *Log {
+	return &Log{
+		dns:   map[string]map[netip.Addr]struct{}{},
+		conns: map[connKey]struct{}{},
+	}
+}
+
+// HandlePacket records an IPv4 or IPv6 packet without link-layer header.
+// outgoing is true for packets sent from the namespace.
+func (l *Log) HandlePacket(pkt []byte, outgoing bool) {
+	if len(pkt) < 1 {
+		return
+	}
+	var (
+		proto    byte
+		src, dst netip.Addr
+		payload  []byte
+	)
+	switch pkt[0] >> 4 {
+	case 4:
+		if len(pkt) < 20 {
+			return
+		}
+		ihl := int(pkt[0]&0x0f) * 4
+		if ihl < 20 || len(pkt) < ihl {
+			return
+		}
+		if binary.BigEndian.Uint16(pkt[6:8])&0x1fff != 0 {
+			// non-first fragment
+			return
+		}
+		proto = pkt[9]
+		src = netip.AddrFrom4([4]byte(pkt[12:16]))
+		dst = netip.AddrFrom4([4]byte(pkt[16:20]))
+		payload = pkt[ihl:]
+	case 6:
+		if len(pkt) < 40 {
+			return
+		}
+		// extension headers are not followed
+		proto = pkt[6]
+		src = netip.AddrFrom16([16]byte(pkt[8:24]))
+		dst = netip.AddrFrom16([16]byte(pkt[24:40]))
+		payload = pkt[40:]
+	default:
+		return
+	}
+	if dst.IsLoopback() || src.IsLoopback() {
+		return
+	}
+
+	switch proto {
+	case protoTCP:
+		if len(payload) < 14 || !outgoing {
+			return
+		}
+		flags := payload[13]
+		if flags&tcpFlagSYN == 0 || flags&tcpFlagACK != 0 {
+			return
+		}
+		l.addConn("tcp", netip.AddrPortFrom(dst, binary.BigEndian.Uint16(payload[2:4])))
+	case protoUDP:
+		if len(payload) < 8 {
+			return
+		}
+		sport := binary.BigEndian.Uint16(payload[0:2])
+		dport := binary.BigEndian.Uint16(payload[2:4])
+		if outgoing {
+			l.addConn("udp", netip.AddrPortFrom(dst, dport))
+		} else if sport == dnsPort {
+			if name, addrs, ok := parseDNSResponse(payload[8:]); ok {
+				l.addDNS(name, addrs)
+			}
+		}
+	}
+}
+
+func (l *Log) addConn(proto string, addr netip.AddrPort) {
+	k := connKey{proto: proto, addr: addr}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.conns[k]; ok {
+		return
+	}
+	if len(l.conns) >= maxRecords {
+		l.truncated = true
+		return
+	}
+	l.conns[k] = struct{}{}
+}
+
+func (l *Log) addDNS(name string, addrs []netip.Addr) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	m, ok := l.dns[name]
+	if !ok {
+		if len(l.dns) >= maxRecords {
+			l.truncated = true
+			return
+		}
+		m = map[netip.Addr]struct{}{}
+		l.dns[name] = m
+	}
+	for _, a := range addrs {
+		m[a] = struct{}{}
+	}
+}
+
+// Summary returns the recorded access, or nil if nothing was recorded.
+// Connections are annotated with the names that resolved to their address.
+func (l *Log) Summary() *resourcestypes.NetworkAccessLog {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.dns) == 0 && len(l.conns) == 0 {
+		return nil
+	}
+
+	out := &resourcestypes.NetworkAccessLog{Truncated: l.truncated}
+	hosts := map[netip.Addr][]string{}
+	for name, addrs := range l.dns {
+		lookup := resourcestypes.DNSLookup{Name: name}
+		for a := range addrs {
+			lookup.Addresses = append(lookup.Addresses, a.String())
+			hosts[a] = append(hosts[a], name)
+		}
+		slices.Sort(lookup.Addresses)
+		out.DNS = append(out.DNS, lookup)
+	}
+	slices.SortFunc(out.DNS, func(a, b resourcestypes.DNSLookup) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	for k := range l.conns {
+		c := resourcestypes.NetworkConnection{
+			Protocol: k.proto,
+			Address:  k.addr.String(),
+			Hosts:    slices.Clone(hosts[k.addr.Addr()]),
+		}
+		slices.Sort(c.Hosts)
+		out.Connections = append(out.Connections, c)
+	}
+	slices.SortFunc(out.Connections, func(a, b resourcestypes.NetworkConnection) int {
+		if c := cmp.Compare(a.Protocol, b.Protocol); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Address, b.Address)
+	})
+	return out
+}
+
+// parseDNSResponse returns the queried name and the A and AAAA records of a
+// DNS response message.
+func parseDNSResponse(msg []byte) (string, []netip.Addr, bool) {
+	if len(msg) < 12 {
+		return "", nil, false
+	}
+	if msg[2]&0x80 == 0 {
+		// not a response
+		return "", nil, false
+	}
+	qdcount := int(binary.BigEndian.Uint16(msg[4:6]))
+	ancount := int(binary.BigEndian.Uint16(msg[6:8]))
+	if qdcount != 1 {
+		return "", nil, false
+	}
+
+	off := 12
+	name, off, ok := readDNSName(msg, off)
+	if !ok || off+4 > len(msg) {
+		return "", nil, false
+	}
+	off += 4 // qtype, qclass
+
+	var addrs []netip.Addr
+	for range ancount {
+		var ok bool
+		_, off, ok = readDNSName(msg, off)
+		if !ok || off+10 > len(msg) {
+			break
+		}
+		typ := binary.BigEndian.Uint16(msg[off : off+2])
+		rdlen := int(binary.BigEndian.Uint16(msg[off+8 : off+10]))
+		off += 10
+		if off+rdlen > len(msg) {
+			break
+		}
+		rdata := msg[off : off+rdlen]
+		off += rdlen
+		switch {
+		case typ == 1 && rdlen == 4: // A
+			addrs = append(addrs, netip.AddrFrom4([4]byte(rdata)))
+		case typ == 28 && rdlen == 16: // AAAA
+			addrs = append(addrs, netip.AddrFrom16([16]byte(rdata)))
+		}
+	}
+	return name, addrs, true
+}
+
+// readDNSName reads a possibly compressed domain name at off and returns it
+// with the offset after the name.
+func readDNSName(msg []byte, off int) (string, int, bool) {
+	var name []byte
+	end := -1
+	for jumps := 0; ; {
+		if off >= len(msg) {
+			return "", 0, false
+		}
+		c := int(msg[off])
+		switch c & 0xc0 {
+		case 0x00:
+			if c == 0 {
+				if end < 0 {
+					end = off + 1
+				}
+				if len(name) == 0 {
+					return ".", end, true
+				}
+				return string(name), end, true
+			}
+			if off+1+c > len(msg) {
+				return "", 0, false
+			}
+			if len(name) > 0 {
+				name = append(name, '.')
+			}
+			name = append(name, msg[off+1:off+1+c]...)
+			off += 1 + c
+		case 0xc0:
+			if off+2 > len(msg) {
+				return "", 0, false
+			}
+			if end < 0 {
+				end = off + 2
+			}
+			jumps++
+			if jumps > 10 {
+				return "", 0, false
+			}
+			off = int(binary.BigEndian.Uint16(msg[off:off+2]) & 0x3fff)
+		default:
+			return "", 0, false
+		}
+	}
+}
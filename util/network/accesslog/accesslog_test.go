@@ -0,0 +1,185 @@
+package accesslog
+
+import (
+	"encoding/binary"
+	"net/netip"
+	"strings"
+	"testing"
+
+	resourcestypes "github.com/moby/buildkit/executor/resources/types"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/bpf"
+)
+
+func TestLog(t *testing.T) {
+	l := New()
+	require.Nil(t, l.Summary())
+
+	local := netip.MustParseAddr("10.10.0.2")
+	resolver := netip.MustParseAddr("10.10.0.1")
+	remote := netip.MustParseAddr("151.101.2.132")
+	remote6 := netip.MustParseAddr("2a04:4e42::644")
+
+	// dns query and response
+	l.HandlePacket(ipv4(protoUDP, local, resolver, udp(40000, 53, nil)), true)
+	l.HandlePacket(ipv4(protoUDP, resolver, local, udp(53, 40000, dnsResponse("dl-cdn.alpinelinux.org", remote, remote6))), false)
+
+	// tcp handshake, only the SYN is recorded
+	l.HandlePacket(ipv4(protoTCP, local, remote, tcp(50000, 443, tcpFlagSYN)), true)
+	l.HandlePacket(ipv4(protoTCP, remote, local, tcp(443, 50000, tcpFlagSYN|tcpFlagACK)), false)
+	l.HandlePacket(ipv4(protoTCP, local, remote, tcp(50000, 443, tcpFlagACK)), true)
+	l.HandlePacket(ipv4(protoTCP, local, remote, tcp(50001, 443, tcpFlagSYN)), true)
+	l.HandlePacket(ipv6(protoTCP, netip.MustParseAddr("fd00::2"), remote6, tcp(50002, 80, tcpFlagSYN)), true)
+
+	// loopback and truncated packets are ignored
+	l.HandlePacket(ipv4(protoTCP, netip.MustParseAddr("127.0.0.1"), netip.MustParseAddr("127.0.0.1"), tcp(50003, 8080, tcpFlagSYN)), true)
+	l.HandlePacket([]byte{0x45, 0x00}, true)
+	l.HandlePacket(nil, true)
+
+	require.Equal(t, &resourcestypes.NetworkAccessLog{
+		DNS: []resourcestypes.DNSLookup{
+			{Name: "dl-cdn.alpinelinux.org", Addresses: []string{"151.101.2.132", "2a04:4e42::644"}},
+		},
+		Connections: []resourcestypes.NetworkConnection{
+			{Protocol: "tcp", Address: "151.101.2.132:443", Hosts: []string{"dl-cdn.alpinelinux.org"}},
+			{Protocol: "tcp", Address: "[2a04:4e42::644]:80", Hosts: []string{"dl-cdn.alpinelinux.org"}},
+			{Protocol: "udp", Address: "10.10.0.1:53"},
+		},
+	}, l.Summary())
+}
+
+func TestLogTruncated(t *testing.T) {
+	l := New()
+	local := netip.MustParseAddr("10.10.0.2")
+	remote := netip.MustParseAddr("192.0.2.1")
+	for i := range maxRecords + 10 {
+		l.HandlePacket(ipv4(protoUDP, local, remote, udp(40000, uint16(1000+i), nil)), true)
+	}
+	s := l.Summary()
+	require.True(t, s.Truncated)
+	require.Len(t, s.Connections, maxRecords)
+}
+
+func TestFilter(t *testing.T) {
+	vm, err := bpf.NewVM(filter)
+	require.NoError(t, err)
+
+	local := netip.MustParseAddr("10.10.0.2")
+	remote := netip.MustParseAddr("192.0.2.1")
+	local6 := netip.MustParseAddr("fd00::2")
+	remote6 := netip.MustParseAddr("2001:db8::1")
+
+	fragment := ipv4(protoUDP, local, remote, udp(40000, 53, nil))
+	fragment[7] = 0x10
+	options := ipv4(protoTCP, local, remote, append(make([]byte, 4), tcp(50000, 443, tcpFlagSYN)...))
+	options[0] = 0x46
+
+	tcs := []struct {
+		name   string
+		pkt    []byte
+		accept bool
+	}{
+		{"tcp syn", ipv4(protoTCP, local, remote, tcp(50000, 443, tcpFlagSYN)), true},
+		{"tcp syn ack", ipv4(protoTCP, remote, local, tcp(443, 50000, tcpFlagSYN|tcpFlagACK)), false},
+		{"tcp ack", ipv4(protoTCP, local, remote, tcp(50000, 443, tcpFlagACK)), false},
+		{"tcp syn with ip options", options, true},
+		{"udp", ipv4(protoUDP, local, remote, udp(40000, 53, nil)), true},
+		{"dns response", ipv4(protoUDP, remote, local, udp(53, 40000, dnsResponse("example.com", remote))), true},
+		{"fragment", fragment, false},
+		{"icmp", ipv4(1, local, remote, make([]byte, 8)), false},
+		{"tcp6 syn", ipv6(protoTCP, local6, remote6, tcp(50000, 443, tcpFlagSYN)), true},
+		{"tcp6 ack", ipv6(protoTCP, local6, remote6, tcp(50000, 443, tcpFlagACK)), false},
+		{"udp6", ipv6(protoUDP, local6, remote6, udp(40000, 53, nil)), true},
+		{"ipv6 extension header", ipv6(0, local6, remote6, make([]byte, 8)), false},
+		{"truncated", []byte{0x45, 0x00}, false},
+		{"arp", make([]byte, 28), false},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			n, err := vm.Run(tc.pkt)
+			require.NoError(t, err)
+			require.Equal(t, tc.accept, n > 0)
+		})
+	}
+}
+
+func TestParseDNSResponse(t *testing.T) {
+	_, _, ok := parseDNSResponse(nil)
+	require.False(t, ok)
+
+	msg := dnsResponse("example.com", netip.MustParseAddr("192.0.2.1"))
+	name, addrs, ok := parseDNSResponse(msg)
+	require.True(t, ok)
+	require.Equal(t, "example.com", name)
+	require.Equal(t, []netip.Addr{netip.MustParseAddr("192.0.2.1")}, addrs)
+
+	// queries are ignored
+	msg[2] &^= 0x80
+	_, _, ok = parseDNSResponse(msg)
+	require.False(t, ok)
+
+	// compression loops are rejected
+	_, _, ok = readDNSName([]byte{0xc0, 0x00}, 0)
+	require.False(t, ok)
+}
+
+func ipv4(proto byte, src, dst netip.Addr, payload []byte) []byte {
+	pkt := make([]byte, 20, 20+len(payload))
+	pkt[0] = 0x45
+	binary.BigEndian.PutUint16(pkt[2:4], uint16(20+len(payload)))
+	pkt[8] = 64
+	pkt[9] = proto
+	copy(pkt[12:16], src.AsSlice())
+	copy(pkt[16:20], dst.AsSlice())
+	return append(pkt, payload...)
+}
+
+func ipv6(proto byte, src, dst netip.Addr, payload []byte) []byte {
+	pkt := make([]byte, 40, 40+len(payload))
+	pkt[0] = 0x60
+	binary.BigEndian.PutUint16(pkt[4:6], uint16(len(payload)))
+	pkt[6] = proto
+	pkt[7] = 64
+	copy(pkt[8:24], src.AsSlice())
+	copy(pkt[24:40], dst.AsSlice())
+	return append(pkt, payload...)
+}
+
+func tcp(sport, dport uint16, flags byte) []byte {
+	b := make([]byte, 20)
+	binary.BigEndian.PutUint16(b[0:2], sport)
+	binary.BigEndian.PutUint16(b[2:4], dport)
+	b[12] = 5 << 4
+	b[13] = flags
+	return b
+}
+
+func udp(sport, dport uint16, payload []byte) []byte {
+	b := make([]byte, 8, 8+len(payload))
+	binary.BigEndian.PutUint16(b[0:2], sport)
+	binary.BigEndian.PutUint16(b[2:4], dport)
+	binary.BigEndian.PutUint16(b[4:6], uint16(8+len(payload)))
+	return append(b, payload...)
+}
+
+func dnsResponse(name string, addrs ...netip.Addr) []byte {
+	msg := []byte{0x12, 0x34, 0x81, 0x80, 0, 1, 0, byte(len(addrs)), 0, 0, 0, 0}
+	for _, label := range strings.Split(name, ".") {
+		msg = append(msg, byte(len(label)))
+		msg = append(msg, label...)
+	}
+	msg = append(msg, 0, 0, 1, 0, 1)
+	for _, a := range addrs {
+		typ := uint16(1)
+		if a.Is6() {
+			typ = 28
+		}
+		// name is a pointer to the question
+		msg = append(msg, 0xc0, 12)
+		msg = binary.BigEndian.AppendUint16(msg, typ)
+		msg = append(msg, 0, 1, 0, 0, 0, 60)
+		msg = binary.BigEndian.AppendUint16(msg, uint16(a.BitLen()/8))
+		msg = append(msg, a.AsSlice()...)
+	}
+	return msg
+}
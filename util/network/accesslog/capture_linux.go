@@ -0,0 +1,115 @@
+package accesslog
+
+import (
+	"os"
+	"sync"
+
+	"github.com/containernetworking/plugins/pkg/ns"
+	"github.com/pkg/errors"
+	"golang.org/x/net/bpf"
+	"golang.org/x/sys/unix"
+)
+
+// Capture reads the packets of a network namespace into a Log.
+type Capture struct {
+	log  *Log
+	f    *os.File
+	done chan struct{}
+	once sync.Once
+}
+
+// Start starts capturing the packets of the network namespace at nsPath.
+func Start(nsPath string) (*Capture, error) {
+	prog, err := bpf.Assemble(filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to assemble packet filter")
+	}
+	sf := make([]unix.SockFilter, len(prog))
+	for i, ins := range prog {
+		sf[i] = unix.SockFilter{Code: ins.Op, Jt: ins.Jt, Jf: ins.Jf, K: ins.K}
+	}
+
+	var fd int
+	err = ns.WithNetNSPath(nsPath, func(_ ns.NetNS) error {
+		var err error
+		// the socket doesn't receive packets until it is bound to a
+		// protocol, so the filter applies to all the packets read
+		fd, err = unix.Socket(unix.AF_PACKET, unix.SOCK_DGRAM|unix.SOCK_NONBLOCK|unix.SOCK_CLOEXEC, 0)
+		if err != nil {
+			return err
+		}
+		if err := unix.SetsockoptSockFprog(fd, unix.SOL_SOCKET, unix.SO_ATTACH_FILTER, &unix.SockFprog{Len: uint16(len(sf)), Filter: &sf[0]}); err != nil {
+			unix.Close(fd)
+			return errors.Wrap(err, "failed to attach packet filter")
+		}
+		if err := unix.Bind(fd, &unix.SockaddrLinklayer{Protocol: htons(unix.ETH_P_ALL)}); err != nil {
+			unix.Close(fd)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create packet socket")
+	}
+
+	c := &Capture{
+		log:  New(),
+		f:    os.NewFile(uintptr(fd), "accesslog"),
+		done: make(chan struct{}),
+	}
+	go c.run()
+	return c, nil
+}
+
+func (c *Capture) run() {
+	defer close(c.done)
+	rc, err := c.f.SyscallConn()
+	if err != nil {
+		return
+	}
+	buf := make([]byte, 65536)
+	for {
+		var (
+			n    int
+			from unix.Sockaddr
+			rerr error
+		)
+		err := rc.Read(func(fd uintptr) bool {
+			n, from, rerr = unix.Recvfrom(int(fd), buf, 0)
+			return rerr != unix.EAGAIN
+		})
+		if err != nil {
+			// closed
+			return
+		}
+		if rerr != nil {
+			if rerr == unix.EINTR {
+				continue
+			}
+			return
+		}
+		ll, ok := from.(*unix.SockaddrLinklayer)
+		if !ok {
+			continue
+		}
+		switch ll.Pkttype {
+		case unix.PACKET_OUTGOING:
+			c.log.HandlePacket(buf[:n], true)
+		case unix.PACKET_HOST:
+			c.log.HandlePacket(buf[:n], false)
+		}
+	}
+}
+
+// Stop stops the capture and returns the log.
+func (c *Capture) Stop() *Log {
+	c.once.Do(func() {
+		c.f.Close()
+		<-c.done
+	})
+	return c.log
+}
+
+func htons(v uint16) uint16 {
+	return v<<8 | v>>8
+}
@@ -0,0 +1,20 @@
+//go:build !linux
+
+package accesslog
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Capture reads the packets of a network namespace into a Log.
+type Capture struct{}
+
+// Start starts capturing the packets of the network namespace at nsPath.
+func Start(_ string) (*Capture, error) {
+	return nil, errors.New("network access log is only supported on linux")
+}
+
+// Stop stops the capture and returns the log.
+func (c *Capture) Stop() *Log {
+	return New()
+}
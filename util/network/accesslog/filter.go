@@ -0,0 +1,46 @@
+package accesslog
+
+import (
+	"golang.org/x/net/bpf"
+)
+
+// filter is attached to the packet socket so that only the packets that can
+// be recorded by HandlePacket are copied to userspace: TCP packets with SYN
+// and without ACK, and UDP packets, which include the DNS responses. Packets
+// start at the network header. Like HandlePacket, the filter drops IPv4
+// fragments other than the first one and doesn't follow IPv6 extension
+// headers.
+var filter = []bpf.Instruction{
+	// 0: IP version
+	bpf.LoadAbsolute{Off: 0, Size: 1},
+	bpf.ALUOpConstant{Op: bpf.ALUOpShiftRight, Val: 4},
+	bpf.JumpIf{Cond: bpf.JumpEqual, Val: 4, SkipTrue: 7},
+	bpf.JumpIf{Cond: bpf.JumpEqual, Val: 6, SkipFalse: 16},
+
+	// 4: IPv6 next header
+	bpf.LoadAbsolute{Off: 6, Size: 1},
+	bpf.JumpIf{Cond: bpf.JumpEqual, Val: protoUDP, SkipTrue: 13},
+	bpf.JumpIf{Cond: bpf.JumpEqual, Val: protoTCP, SkipFalse: 13},
+	// TCP flags after the fixed IPv6 header
+	bpf.LoadAbsolute{Off: 40 + 13, Size: 1},
+	bpf.ALUOpConstant{Op: bpf.ALUOpAnd, Val: tcpFlagSYN | tcpFlagACK},
+	bpf.JumpIf{Cond: bpf.JumpEqual, Val: tcpFlagSYN, SkipTrue: 9, SkipFalse: 10},
+
+	// 10: IPv4 fragment offset
+	bpf.LoadAbsolute{Off: 6, Size: 2},
+	bpf.JumpIf{Cond: bpf.JumpBitsSet, Val: 0x1fff, SkipTrue: 8},
+	// IPv4 protocol
+	bpf.LoadAbsolute{Off: 9, Size: 1},
+	bpf.JumpIf{Cond: bpf.JumpEqual, Val: protoUDP, SkipTrue: 5},
+	bpf.JumpIf{Cond: bpf.JumpEqual, Val: protoTCP, SkipFalse: 5},
+	// TCP flags after the IPv4 header of variable length
+	bpf.LoadMemShift{Off: 0},
+	bpf.LoadIndirect{Off: 13, Size: 1},
+	bpf.ALUOpConstant{Op: bpf.ALUOpAnd, Val: tcpFlagSYN | tcpFlagACK},
+	bpf.JumpIf{Cond: bpf.JumpEqual, Val: tcpFlagSYN, SkipFalse: 1},
+
+	// 19: accept
+	bpf.RetConstant{Val: 0xffff},
+	// 20: drop
+	bpf.RetConstant{Val: 0},
+}
@@ -135,8 +135,9 @@ func NewBridge(opt Opt) (network.Provider, error) {
 		return nil, err
 	}
 	cp := &cniProvider{
-		CNI:  cniHandle,
-		root: opt.Root,
+		CNI:       cniHandle,
+		root:      opt.Root,
+		accessLog: opt.AccessLog,
 	}
 
 	if createBridge {
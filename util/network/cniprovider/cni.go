@@ -14,6 +14,7 @@ import (
 	"github.com/moby/buildkit/identity"
 	"github.com/moby/buildkit/util/bklog"
 	"github.com/moby/buildkit/util/network"
+	"github.com/moby/buildkit/util/network/accesslog"
 	specs "github.com/opencontainers/runtime-spec/specs-go"
 	"github.com/pkg/errors"
 	"go.opentelemetry.io/otel/trace"
@@ -28,6 +29,9 @@ type Opt struct {
 	PoolSize     int
 	BridgeName   string
 	BridgeSubnet string
+	// AccessLog allows recording the DNS lookups and connections made from
+	// namespaces requested with network.WithAccessLog.
+	AccessLog bool
 }
 
 func New(opt Opt) (network.Provider, error) {
@@ -63,8 +67,9 @@ func New(opt Opt) (network.Provider, error) {
 	}
 
 	cp := &cniProvider{
-		CNI:  cniHandle,
-		root: opt.Root,
+		CNI:       cniHandle,
+		root:      opt.Root,
+		accessLog: opt.AccessLog,
 	}
 	cleanOldNamespaces(cp)
 
@@ -78,9 +83,10 @@ func New(opt Opt) (network.Provider, error) {
 
 type cniProvider struct {
 	cni.CNI
-	root    string
-	nsPool  *cniPool
-	release func() error
+	root      string
+	nsPool    *cniPool
+	release   func() error
+	accessLog bool
 }
 
 func (c *cniProvider) initNetwork(lock bool) error {
@@ -242,17 +248,32 @@ func (c *cniProvider) New(ctx context.Context, hostname string) (network.Namespa
 	// We can't use the pool for namespaces that need a custom hostname.
 	// We also avoid using it on windows because we don't have a cleanup
 	// mechanism for Windows yet.
+	var res *cniNS
 	if hostname == "" || runtime.GOOS == "windows" {
-		return c.nsPool.get(ctx)
-	}
-	var res network.Namespace
-	fn := func(ctx context.Context) error {
 		var err error
-		res, err = c.newNS(ctx, hostname)
-		return err
+		res, err = c.nsPool.get(ctx)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		fn := func(ctx context.Context) error {
+			var err error
+			res, err = c.newNS(ctx, hostname)
+			return err
+		}
+		if err := withDetachedNetNSIfAny(ctx, fn); err != nil {
+			return nil, err
+		}
 	}
-	if err := withDetachedNetNSIfAny(ctx, fn); err != nil {
-		return nil, err
+	if c.accessLog && network.AccessLogRequested(ctx) {
+		fn := func(_ context.Context) error {
+			var err error
+			res.capture, err = accesslog.Start(res.nativeID)
+			return err
+		}
+		if err := withDetachedNetNSIfAny(ctx, fn); err != nil {
+			bklog.G(ctx).Warnf("failed to start network access log for %s: %v", res.id, err)
+		}
 	}
 	return res, nil
 }
@@ -336,6 +357,7 @@ type cniNS struct {
 	canSample    bool
 	offsetSample *resourcestypes.NetworkSample
 	prevSample   *resourcestypes.NetworkSample
+	capture      *accesslog.Capture
 }
 
 func (ns *cniNS) Set(s *specs.Spec) error {
@@ -346,6 +368,10 @@ func (ns *cniNS) Close() error {
 	if ns.prevSample != nil {
 		ns.offsetSample = ns.prevSample
 	}
+	if ns.capture != nil {
+		ns.capture.Stop()
+		ns.capture = nil
+	}
 	if ns.pool == nil {
 		return ns.release()
 	}
@@ -383,6 +409,18 @@ func (ns *cniNS) Sample() (*resourcestypes.NetworkSample, error) {
 	return s, nil
 }
 
+// AccessLog stops recording the network access of the namespace and returns
+// the DNS lookups and connections seen since it was handed out. It returns nil
+// if access logging is not enabled.
+func (ns *cniNS) AccessLog() *resourcestypes.NetworkAccessLog {
+	if ns.capture == nil {
+		return nil
+	}
+	l := ns.capture.Stop().Summary()
+	ns.capture = nil
+	return l
+}
+
 func (ns *cniNS) release() error {
 	bklog.L.Debugf("releasing cni network namespace %s", ns.id)
 	err := ns.handle.Remove(context.TODO(), ns.id, ns.nativeID, ns.opts...)
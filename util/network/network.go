@@ -22,3 +22,23 @@ type Namespace interface {
 
 	Sample() (*resourcestypes.NetworkSample, error)
 }
+
+type contextKeyT string
+
+var contextKeyAccessLog = contextKeyT("buildkit/util/network/accesslog")
+
+// WithAccessLog returns a context that requests recording the network access
+// of the namespaces created with it. Providers that don't support recording
+// the network access ignore it.
+func WithAccessLog(ctx context.Context, enabled bool) context.Context {
+	if !enabled {
+		return ctx
+	}
+	return context.WithValue(ctx, contextKeyAccessLog, true)
+}
+
+// AccessLogRequested returns true if ctx was created by WithAccessLog.
+func AccessLogRequested(ctx context.Context) bool {
+	v, _ := ctx.Value(contextKeyAccessLog).(bool)
+	return v
+}
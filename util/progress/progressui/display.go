@@ -16,6 +16,7 @@ import (
 
 	"github.com/containerd/console"
 	"github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/solver/errdefs"
 	"github.com/morikuni/aec"
 	digest "github.com/opencontainers/go-digest"
 	"github.com/pkg/errors"
@@ -363,6 +364,9 @@ type vertex struct {
 	warnings   []client.VertexWarning
 	warningIdx int
 
+	stats     *client.VertexStats
+	prevStats *client.VertexStats
+
 	jobs      []*job
 	jobCached bool
 
@@ -388,6 +392,21 @@ func (v *vertex) update(c int) {
 	v.count += c
 }
 
+// statsString returns a short summary of the most recent resource usage
+// sample. CPU usage is calculated from the last two samples.
+func (v *vertex) statsString() string {
+	if v.stats == nil {
+		return ""
+	}
+	var parts []string
+	if prev := v.prevStats; prev != nil && v.stats.Timestamp.After(prev.Timestamp) && v.stats.CPUNanos >= prev.CPUNanos {
+		cpu := float64(v.stats.CPUNanos-prev.CPUNanos) / float64(v.stats.Timestamp.Sub(prev.Timestamp).Nanoseconds()) * 100
+		parts = append(parts, fmt.Sprintf("CPU %.0f%%", cpu))
+	}
+	parts = append(parts, fmt.Sprintf("RSS %.2f", units.Bytes(v.stats.MemoryBytes)))
+	return strings.Join(parts, " ")
+}
+
 func (v *vertex) mostRecentInterval() *interval {
 	if v.isStarted() {
 		ival := v.mergedIntervals[len(v.mergedIntervals)-1]
@@ -443,6 +462,7 @@ func (vg *vertexGroup) refresh() (changed, newlyStarted, newlyRevealed bool) {
 		// Group error is set to the first error found in subvtxs, if any
 		if newVtx.Error == "" {
 			newVtx.Error = subVtx.Error
+			newVtx.ExitReason = subVtx.ExitReason
 		} else {
 			vg.hidden = false
 		}
@@ -742,6 +762,19 @@ func (t *trace) update(s *client.SolveStatus, termWidth int) {
 		v.warnings = append(v.warnings, *w)
 		v.update(1)
 	}
+	for _, st := range s.Stats {
+		v, ok := t.byDigest[st.Vertex]
+		if !ok {
+			continue // shouldn't happen
+		}
+		if v.stats != nil && v.stats.ID == st.ID {
+			v.prevStats = v.stats
+		} else {
+			v.prevStats = nil
+		}
+		v.stats = st
+		v.jobCached = false
+	}
 	for _, l := range s.Logs {
 		v, ok := t.byDigest[l.Vertex]
 		if !ok {
@@ -782,6 +815,15 @@ func (t *trace) update(s *client.SolveStatus, termWidth int) {
 	}
 }
 
+// errorLabel returns the label of a failed vertex with the reason the
+// process exited, if known, e.g. "ERROR (OOMKilled)".
+func (v *vertex) errorLabel() string {
+	if reason := errdefs.FormatExitReason(v.ExitReason); reason != "" {
+		return "ERROR (" + reason + ")"
+	}
+	return "ERROR"
+}
+
 func (t *trace) printErrorLogs(f io.Writer) {
 	for _, v := range t.vertexes {
 		if v.Error != "" && !strings.HasSuffix(v.Error, context.Canceled.Error()) {
@@ -850,12 +892,15 @@ func (t *trace) displayInfo() (d displayInfo) {
 				j.name = "CANCELED " + j.name
 			} else {
 				j.hasError = true
-				j.name = "ERROR " + j.name
+				j.name = v.errorLabel() + " " + j.name
 			}
 		}
 		if v.Cached {
 			j.name = "CACHED " + j.name
 		}
+		if !j.isCompleted {
+			j.status = v.statsString()
+		}
 		j.name = v.indent + j.name
 		jobs = append(jobs, j)
 		for _, s := range v.statuses {
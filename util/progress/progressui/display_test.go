@@ -4,6 +4,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/solver/errdefs"
+	digest "github.com/opencontainers/go-digest"
 	"github.com/stretchr/testify/require"
 )
 
@@ -166,3 +169,58 @@ func TestMergeIntervals(t *testing.T) {
 		})
 	}
 }
+
+func TestVertexStatsString(t *testing.T) {
+	tr := newTrace(nil, false)
+	started := time.Unix(100, 0)
+	dgst := digest.FromString("vtx")
+	tr.update(&client.SolveStatus{
+		Vertexes: []*client.Vertex{{Digest: dgst, Name: "exec", Started: &started}},
+	}, 80)
+
+	tr.update(&client.SolveStatus{
+		Stats: []*client.VertexStats{{ID: "s", Vertex: dgst, Timestamp: time.Unix(102, 0), CPUNanos: 1e9, MemoryBytes: 64e6}},
+	}, 80)
+	require.Equal(t, "RSS 64.00MB", tr.byDigest[dgst].statsString())
+
+	tr.update(&client.SolveStatus{
+		Stats: []*client.VertexStats{{ID: "s", Vertex: dgst, Timestamp: time.Unix(104, 0), CPUNanos: 4e9, MemoryBytes: 128e6}},
+	}, 80)
+	require.Equal(t, "CPU 150% RSS 128.00MB", tr.byDigest[dgst].statsString())
+
+	d := tr.displayInfo()
+	require.Len(t, d.jobs, 1)
+	require.Equal(t, "CPU 150% RSS 128.00MB", d.jobs[0].status)
+}
+
+func TestVertexErrorLabel(t *testing.T) {
+	tr := newTrace(nil, false)
+	started := time.Unix(100, 0)
+	completed := time.Unix(101, 0)
+	oom := digest.FromString("oom")
+	failed := digest.FromString("failed")
+	tr.update(&client.SolveStatus{
+		Vertexes: []*client.Vertex{
+			{
+				Digest:     oom,
+				Name:       "exec oom",
+				Started:    &started,
+				Completed:  &completed,
+				Error:      "exit code: 137",
+				ExitReason: &errdefs.ExitReason{Type: errdefs.ExitReasonType_OOM_KILLED},
+			},
+			{
+				Digest:    failed,
+				Name:      "exec failed",
+				Started:   &started,
+				Completed: &completed,
+				Error:     "exit code: 1",
+			},
+		},
+	}, 80)
+
+	d := tr.displayInfo()
+	require.Len(t, d.jobs, 2)
+	require.Equal(t, "ERROR (OOMKilled) exec oom", d.jobs[0].name)
+	require.Equal(t, "ERROR exec failed", d.jobs[1].name)
+}
@@ -184,7 +184,7 @@ func (p *textMux) printVtx(t *trace, dgst digest.Digest) {
 			if strings.HasSuffix(v.Error, context.Canceled.Error()) {
 				fmt.Fprintf(p.w, "#%d CANCELED\n", v.index)
 			} else {
-				fmt.Fprintf(p.w, "#%d ERROR: %s\n", v.index, v.Error)
+				fmt.Fprintf(p.w, "#%d %s: %s\n", v.index, v.errorLabel(), v.Error)
 			}
 		} else if v.Cached {
 			fmt.Fprintf(p.w, "#%d CACHED\n", v.index)
@@ -63,11 +63,19 @@ func ResetTime(in Writer) Writer {
 						logs = append(logs, &v)
 					}
 
+					stats := make([]*client.VertexStats, 0, len(st.Stats))
+					for _, v := range st.Stats {
+						v := *v
+						v.Timestamp = v.Timestamp.Add(-*w.diff)
+						stats = append(stats, &v)
+					}
+
 					st = &client.SolveStatus{
 						Vertexes: vertexes,
 						Statuses: statuses,
 						Logs:     logs,
 						Warnings: st.Warnings,
+						Stats:    stats,
 					}
 				}
 				in.Status() <- st
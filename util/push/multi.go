@@ -0,0 +1,154 @@
+package push
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/core/remotes/docker"
+	"github.com/distribution/reference"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/util/contentutil"
+	"github.com/moby/buildkit/util/resolver"
+	digest "github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Multi pushes the same content to multiple references concurrently with f.
+//
+// References in the same registry are pushed after the first one of that
+// registry has completed, so that the registry can mount the blobs from the
+// repository that was pushed first instead of receiving them again. f is
+// called with the references that were pushed successfully before it was
+// called, which can be used as sources for the blobs that are not available
+// locally.
+//
+// A failed push does not cancel the pushes to the other references. The
+// returned errors have the same order as refs.
+func Multi(ctx context.Context, refs []string, f func(ctx context.Context, ref string, pushed []string) error) []error {
+	errs := make([]error, len(refs))
+
+	var mu sync.Mutex
+	var pushed []string
+	push := func(i int) {
+		mu.Lock()
+		sources := append([]string(nil), pushed...)
+		mu.Unlock()
+
+		errs[i] = f(ctx, refs[i], sources)
+		if errs[i] == nil {
+			mu.Lock()
+			pushed = append(pushed, refs[i])
+			mu.Unlock()
+		}
+	}
+
+	var groups [][]int
+	domains := map[string]int{}
+	for i, ref := range refs {
+		domain := ref
+		if parsed, err := reference.ParseNormalizedNamed(ref); err == nil {
+			domain = reference.Domain(parsed)
+		}
+		if j, ok := domains[domain]; ok {
+			groups[j] = append(groups[j], i)
+			continue
+		}
+		domains[domain] = len(groups)
+		groups = append(groups, []int{i})
+	}
+
+	var wg sync.WaitGroup
+	for _, group := range groups {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			push(group[0])
+
+			var gwg sync.WaitGroup
+			for _, i := range group[1:] {
+				gwg.Add(1)
+				go func() {
+					defer gwg.Done()
+					push(i)
+				}()
+			}
+			gwg.Wait()
+		}()
+	}
+	wg.Wait()
+
+	return errs
+}
+
+// AddPushedSources adds the repositories of the pushed references as
+// distribution sources of the blobs in annotations, so that a registry can
+// mount them across repositories even if they are not in the content store.
+func AddPushedSources(annotations map[digest.Digest]map[string]string, dgsts []digest.Digest, pushed []string) {
+	for _, ref := range pushed {
+		parsed, err := reference.ParseNormalizedNamed(ref)
+		if err != nil {
+			continue
+		}
+		key := "containerd.io/distribution.source." + reference.Domain(parsed)
+		repo := reference.Path(parsed)
+		for _, dgst := range dgsts {
+			a := maps.Clone(annotations[dgst])
+			if a == nil {
+				a = map[string]string{}
+			}
+			if v := a[key]; v == "" {
+				a[key] = repo
+			} else if !slices.Contains(strings.Split(v, ","), repo) {
+				a[key] = v + "," + repo
+			}
+			annotations[dgst] = a
+		}
+	}
+}
+
+// WithPushedSources returns a provider that reads the blobs missing from
+// store from the references that were already pushed, before falling back to
+// provider. This avoids pulling lazy blobs from their original source again
+// when pushing to multiple registries.
+func WithPushedSources(ctx context.Context, sm *session.Manager, sid string, provider content.Provider, store content.InfoProvider, pushed []string, insecure bool, hosts docker.RegistryHosts) content.Provider {
+	if len(pushed) == 0 {
+		return provider
+	}
+	p := &pushedProvider{Provider: provider, store: store}
+	for _, ref := range pushed {
+		parsed, err := reference.ParseNormalizedNamed(ref)
+		if err != nil {
+			continue
+		}
+		hosts, scope := pushHosts(parsed, insecure, hosts)
+		r := resolver.DefaultPool.GetResolver(hosts, ref, scope, sm, session.NewGroup(sid))
+		fetcher, err := r.Fetcher(ctx, ref)
+		if err != nil {
+			continue
+		}
+		p.sources = append(p.sources, contentutil.FromFetcher(fetcher))
+	}
+	return p
+}
+
+type pushedProvider struct {
+	content.Provider
+	store   content.InfoProvider
+	sources []content.Provider
+}
+
+func (p *pushedProvider) ReaderAt(ctx context.Context, desc ocispecs.Descriptor) (content.ReaderAt, error) {
+	if _, err := p.store.Info(ctx, desc.Digest); err == nil {
+		return p.Provider.ReaderAt(ctx, desc)
+	}
+	for _, s := range p.sources {
+		if ra, err := s.ReaderAt(ctx, desc); err == nil {
+			return ra, nil
+		}
+	}
+	return p.Provider.ReaderAt(ctx, desc)
+}
@@ -0,0 +1,69 @@
+package push
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMulti(t *testing.T) {
+	refs := []string{
+		"docker.io/foo/a:latest",
+		"ghcr.io/foo/a:latest",
+		"docker.io/foo/b:latest",
+		"docker.io/foo/c:latest",
+		"quay.io/foo/a:latest",
+	}
+
+	var mu sync.Mutex
+	seen := map[string][]string{}
+	errs := Multi(context.TODO(), refs, func(ctx context.Context, ref string, pushed []string) error {
+		mu.Lock()
+		seen[ref] = pushed
+		mu.Unlock()
+		if ref == "quay.io/foo/a:latest" {
+			return errors.New("denied")
+		}
+		return nil
+	})
+	require.Len(t, errs, len(refs))
+	for i, err := range errs {
+		if i == 4 {
+			require.ErrorContains(t, err, "denied")
+		} else {
+			require.NoError(t, err)
+		}
+	}
+	require.Len(t, seen, len(refs))
+
+	// later pushes to the same registry start after the first one
+	require.Contains(t, seen["docker.io/foo/b:latest"], "docker.io/foo/a:latest")
+	require.Contains(t, seen["docker.io/foo/c:latest"], "docker.io/foo/a:latest")
+	for _, pushed := range seen {
+		require.NotContains(t, pushed, "quay.io/foo/a:latest")
+	}
+}
+
+func TestAddPushedSources(t *testing.T) {
+	dgst1 := digest.FromString("foo")
+	dgst2 := digest.FromString("bar")
+	orig := map[string]string{"containerd.io/distribution.source.docker.io": "library/alpine"}
+	annotations := map[digest.Digest]map[string]string{dgst1: orig}
+
+	AddPushedSources(annotations, []digest.Digest{dgst1, dgst2}, []string{"foo/a:latest", "ghcr.io/foo/b", "docker.io/library/alpine"})
+
+	require.Equal(t, map[string]string{
+		"containerd.io/distribution.source.docker.io": "library/alpine,foo/a",
+		"containerd.io/distribution.source.ghcr.io":   "foo/b",
+	}, annotations[dgst1])
+	require.Equal(t, map[string]string{
+		"containerd.io/distribution.source.docker.io": "foo/a,library/alpine",
+		"containerd.io/distribution.source.ghcr.io":   "foo/b",
+	}, annotations[dgst2])
+	// the original annotations are not modified
+	require.Equal(t, map[string]string{"containerd.io/distribution.source.docker.io": "library/alpine"}, orig)
+}
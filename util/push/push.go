@@ -15,6 +15,7 @@ import (
 	"github.com/distribution/reference"
 	intoto "github.com/in-toto/in-toto-golang/in_toto"
 	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/util/attestation"
 	"github.com/moby/buildkit/util/bklog"
 	"github.com/moby/buildkit/util/contentutil"
 	"github.com/moby/buildkit/util/flightcontrol"
@@ -69,19 +70,7 @@ func Push(ctx context.Context, sm *session.Manager, sid string, provider content
 		ref = r.String()
 	}
 
-	scope := "push"
-	if insecure {
-		insecureTrue := true
-		httpTrue := true
-		hosts = resolver.NewRegistryConfig(map[string]resolverconfig.RegistryConfig{
-			reference.Domain(parsed): {
-				Insecure:  &insecureTrue,
-				PlainHTTP: &httpTrue,
-			},
-		})
-		scope += ":insecure"
-	}
-
+	hosts, scope := pushHosts(parsed, insecure, hosts)
 	resolver := resolver.DefaultPool.GetResolver(hosts, ref, scope, sm, session.NewGroup(sid))
 
 	pusher, err := Pusher(ctx, resolver, ref)
@@ -127,7 +116,7 @@ func Push(ctx context.Context, sm *session.Manager, sid string, provider content
 		return err
 	}
 
-	layersDone := progress.OneOff(ctx, "pushing layers")
+	layersDone := progress.OneOff(ctx, fmt.Sprintf("pushing layers for %s", ref))
 	err = images.Dispatch(ctx, skipNonDistributableBlobs(images.Handlers(handlers...)), nil, ocispecs.Descriptor{
 		Digest:    dgst,
 		Size:      ra.Size(),
@@ -146,6 +135,24 @@ func Push(ctx context.Context, sm *session.Manager, sid string, provider content
 	return mfstDone(nil)
 }
 
+// pushHosts returns the registry hosts and resolver scope for pushing to the
+// repository of parsed.
+func pushHosts(parsed reference.Named, insecure bool, hosts docker.RegistryHosts) (docker.RegistryHosts, string) {
+	scope := "push"
+	if insecure {
+		insecureTrue := true
+		httpTrue := true
+		hosts = resolver.NewRegistryConfig(map[string]resolverconfig.RegistryConfig{
+			reference.Domain(parsed): {
+				Insecure:  &insecureTrue,
+				PlainHTTP: &httpTrue,
+			},
+		})
+		scope += ":insecure"
+	}
+	return hosts, scope
+}
+
 // TODO: the containerd function for this is filtering too much, that needs to be fixed.
 // For now we just carry this.
 func skipNonDistributableBlobs(f images.HandlerFunc) images.HandlerFunc {
@@ -251,7 +258,8 @@ func childrenHandler(provider content.Provider) images.HandlerFunc {
 		case images.MediaTypeDockerSchema2Layer, images.MediaTypeDockerSchema2LayerGzip,
 			images.MediaTypeDockerSchema2Config, ocispecs.MediaTypeImageConfig,
 			ocispecs.MediaTypeImageLayer, ocispecs.MediaTypeImageLayerGzip,
-			intoto.PayloadType:
+			intoto.PayloadType, attestation.MediaTypeDSSEEnvelope,
+			attestation.MediaTypeSimpleSigning, ocispecs.MediaTypeEmptyJSON:
 			// childless data types.
 			return nil, nil
 		default:
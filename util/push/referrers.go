@@ -0,0 +1,351 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/core/remotes/docker"
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/distribution/reference"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/util/contentutil"
+	"github.com/moby/buildkit/util/progress"
+	"github.com/moby/buildkit/util/progress/logs"
+	"github.com/moby/buildkit/util/resolver"
+	"github.com/moby/buildkit/util/resolver/limited"
+	"github.com/moby/buildkit/util/resolver/retryhandler"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+// PushReferrers pushes manifests that refer to other manifests with their
+// subject field to the repository of ref. The registry reports with the
+// OCI-Subject header of every manifest push whether it indexed the subject for
+// the referrers API. Referrers the registry didn't index are added to the
+// index tagged with the referrers tag schema so that clients can still
+// discover them.
+func PushReferrers(ctx context.Context, sm *session.Manager, sid string, cs content.Store, referrers []ocispecs.Descriptor, ref string, insecure bool, hosts docker.RegistryHosts) error {
+	if len(referrers) == 0 {
+		return nil
+	}
+	ctx = contentutil.RegisterContentPayloadTypes(ctx)
+	parsed, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return err
+	}
+	name := reference.TrimNamed(parsed)
+
+	hosts, scope := pushHosts(name, insecure, hosts)
+	r := resolver.DefaultPool.GetResolver(hosts, name.String(), scope, sm, session.NewGroup(sid))
+	p, err := Pusher(ctx, r, name.String())
+	if err != nil {
+		return err
+	}
+	pushBlob := retryhandler.New(limited.PushHandler(p, cs, name.String()), logs.LoggerFromContext(ctx))
+
+	var subjects []digest.Digest
+	unindexed := map[digest.Digest][]ocispecs.Descriptor{}
+	for _, desc := range referrers {
+		dt, err := content.ReadBlob(ctx, cs, desc)
+		if err != nil {
+			return err
+		}
+		var mfst ocispecs.Manifest
+		if err := json.Unmarshal(dt, &mfst); err != nil {
+			return errors.Wrapf(err, "failed to parse referrer manifest %s", desc.Digest)
+		}
+		if mfst.Subject == nil {
+			return errors.Errorf("referrer manifest %s has no subject", desc.Digest)
+		}
+
+		done := progress.OneOff(ctx, "pushing referrer "+desc.Digest.String())
+		for _, blob := range append([]ocispecs.Descriptor{mfst.Config}, mfst.Layers...) {
+			if _, err := pushBlob(ctx, blob); err != nil {
+				return done(err)
+			}
+		}
+		indexed, err := putManifest(ctx, r, name, desc, dt)
+		if err := done(err); err != nil {
+			return err
+		}
+		if indexed == mfst.Subject.Digest {
+			continue
+		}
+		if _, ok := unindexed[mfst.Subject.Digest]; !ok {
+			subjects = append(subjects, mfst.Subject.Digest)
+		}
+		unindexed[mfst.Subject.Digest] = append(unindexed[mfst.Subject.Digest], desc)
+	}
+
+	for _, subject := range subjects {
+		done := progress.OneOff(ctx, "pushing referrers tag for "+subject.String())
+		if err := done(pushReferrersTag(ctx, r, name, subject, unindexed[subject])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// maxIndexSize limits the size of the referrers indexes that are read.
+const maxIndexSize = 4 << 20
+
+// ReferrersTag returns the tag of the referrers index of dgst as defined by
+// the referrers tag schema of the OCI distribution spec: <alg>-<ref> with the
+// algorithm truncated to 32 and the encoded digest to 64 characters.
+func ReferrersTag(dgst digest.Digest) string {
+	alg, ref := dgst.Algorithm().String(), dgst.Encoded()
+	if len(alg) > 32 {
+		alg = alg[:32]
+	}
+	if len(ref) > 64 {
+		ref = ref[:64]
+	}
+	return alg + "-" + ref
+}
+
+// Referrers returns the descriptors of the manifests that refer to dgst in the
+// repository of ref. The referrers API is used if the registry implements it,
+// otherwise the index tagged with the referrers tag schema is read.
+func Referrers(ctx context.Context, r *resolver.Resolver, ref string, dgst digest.Digest) ([]ocispecs.Descriptor, error) {
+	parsed, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return nil, err
+	}
+	name := reference.TrimNamed(parsed)
+
+	idx, err := referrersIndex(ctx, r, name, dgst, docker.HostCapabilityPull)
+	if err != nil {
+		return nil, err
+	}
+	if idx == nil {
+		tagged, err := reference.WithTag(name, ReferrersTag(dgst))
+		if err != nil {
+			return nil, err
+		}
+		idx, err = fetchIndex(ctx, r, tagged.String())
+		if err != nil || idx == nil {
+			return nil, err
+		}
+	}
+	return idx.Manifests, nil
+}
+
+// putManifest pushes the manifest dt to the repository name by digest and
+// returns the subject digest from the OCI-Subject header of the response. The
+// header is only set by registries that implement the referrers API.
+func putManifest(ctx context.Context, r *resolver.Resolver, name reference.Named, desc ocispecs.Descriptor, dt []byte) (digest.Digest, error) {
+	hosts, err := r.HostsFunc(reference.Domain(name))
+	if err != nil {
+		return "", err
+	}
+	ctx = docker.WithScope(ctx, "repository:"+reference.Path(name)+":pull,push")
+	for _, host := range hosts {
+		if !host.Capabilities.Has(docker.HostCapabilityPush) {
+			continue
+		}
+		u := url.URL{
+			Scheme: host.Scheme,
+			Host:   host.Host,
+			Path:   path.Join(host.Path, reference.Path(name), "manifests", desc.Digest.String()),
+		}
+		resp, err := doRequest(ctx, host, http.MethodPut, u.String(), desc.MediaType, dt)
+		if err != nil {
+			return "", err
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+			return "", errors.Errorf("unexpected status pushing manifest %s to %s: %s", desc.Digest, host.Host, resp.Status)
+		}
+		subject := resp.Header.Get("OCI-Subject")
+		if subject == "" {
+			return "", nil
+		}
+		dgst, err := digest.Parse(subject)
+		if err != nil {
+			return "", errors.Wrapf(err, "invalid OCI-Subject header pushing manifest %s", desc.Digest)
+		}
+		return dgst, nil
+	}
+	return "", errors.Errorf("no registry host with push capability for %s", name)
+}
+
+// referrersIndex returns the response of the referrers API for dgst, or nil if
+// the registry doesn't implement the API.
+func referrersIndex(ctx context.Context, r *resolver.Resolver, name reference.Named, dgst digest.Digest, capability docker.HostCapabilities) (*ocispecs.Index, error) {
+	hosts, err := r.HostsFunc(reference.Domain(name))
+	if err != nil {
+		return nil, err
+	}
+	ctx = docker.WithScope(ctx, "repository:"+reference.Path(name)+":pull")
+	for _, host := range hosts {
+		if !host.Capabilities.Has(capability) {
+			continue
+		}
+		u := url.URL{
+			Scheme: host.Scheme,
+			Host:   host.Host,
+			Path:   path.Join(host.Path, reference.Path(name), "referrers", dgst.String()),
+		}
+		resp, err := doRequest(ctx, host, http.MethodGet, u.String(), "", nil)
+		if err != nil {
+			return nil, err
+		}
+		switch resp.StatusCode {
+		case http.StatusOK:
+			var idx ocispecs.Index
+			err := json.NewDecoder(io.LimitReader(resp.Body, maxIndexSize)).Decode(&idx)
+			resp.Body.Close()
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to parse referrers of %s", dgst)
+			}
+			return &idx, nil
+		case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+			resp.Body.Close()
+			return nil, nil
+		default:
+			resp.Body.Close()
+			return nil, errors.Errorf("unexpected status checking referrers API on %s: %s", host.Host, resp.Status)
+		}
+	}
+	return nil, nil
+}
+
+// fetchIndex reads the index tagged as ref, or returns nil if it doesn't
+// exist.
+func fetchIndex(ctx context.Context, r *resolver.Resolver, ref string) (*ocispecs.Index, error) {
+	_, desc, err := r.Resolve(ctx, ref)
+	if err != nil {
+		if cerrdefs.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	fetcher, err := r.Fetcher(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	rc, err := fetcher.Fetch(ctx, desc)
+	if err != nil {
+		return nil, err
+	}
+	dt, err := io.ReadAll(io.LimitReader(rc, maxIndexSize))
+	rc.Close()
+	if err != nil {
+		return nil, err
+	}
+	var idx ocispecs.Index
+	if err := json.Unmarshal(dt, &idx); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse referrers index %s", ref)
+	}
+	return &idx, nil
+}
+
+// doRequest sends a request with body of contentType to the registry host,
+// authorizing and retrying it once if the registry asks for credentials.
+func doRequest(ctx context.Context, host docker.RegistryHost, method, u, contentType string, body []byte) (*http.Response, error) {
+	var responses []*http.Response
+	for {
+		req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		if body != nil {
+			req.Header.Set("Content-Type", contentType)
+		} else {
+			req.Header.Set("Accept", ocispecs.MediaTypeImageIndex)
+		}
+		for k, v := range host.Header {
+			req.Header[k] = v
+		}
+		if host.Authorizer != nil {
+			if err := host.Authorizer.Authorize(ctx, req); err != nil {
+				return nil, err
+			}
+		}
+		client := host.Client
+		if client == nil {
+			client = http.DefaultClient
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusUnauthorized || host.Authorizer == nil || len(responses) > 0 {
+			return resp, nil
+		}
+		responses = append(responses, resp)
+		resp.Body.Close()
+		if err := host.Authorizer.AddResponses(ctx, responses); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// pushReferrersTag adds referrers to the index tagged with the referrers tag
+// of subject, creating the index if it doesn't exist yet.
+func pushReferrersTag(ctx context.Context, r *resolver.Resolver, name reference.Named, subject digest.Digest, referrers []ocispecs.Descriptor) error {
+	tagged, err := reference.WithTag(name, ReferrersTag(subject))
+	if err != nil {
+		return err
+	}
+	ref := tagged.String()
+
+	idx, err := fetchIndex(ctx, r, ref)
+	if err != nil {
+		return err
+	}
+	if idx == nil {
+		idx = &ocispecs.Index{
+			Versioned: specs.Versioned{
+				SchemaVersion: 2,
+			},
+			MediaType: ocispecs.MediaTypeImageIndex,
+		}
+	}
+
+	changed := false
+	for _, desc := range referrers {
+		if slices.ContainsFunc(idx.Manifests, func(d ocispecs.Descriptor) bool {
+			return d.Digest == desc.Digest
+		}) {
+			continue
+		}
+		idx.Manifests = append(idx.Manifests, desc)
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+
+	dt, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return err
+	}
+	idxDesc := ocispecs.Descriptor{
+		MediaType: ocispecs.MediaTypeImageIndex,
+		Digest:    digest.FromBytes(dt),
+		Size:      int64(len(dt)),
+	}
+	p, err := Pusher(ctx, r, ref)
+	if err != nil {
+		return err
+	}
+	cw, err := p.Push(ctx, idxDesc)
+	if err != nil {
+		if cerrdefs.IsAlreadyExists(err) {
+			return nil
+		}
+		return err
+	}
+	defer cw.Close()
+	return content.Copy(ctx, cw, bytes.NewReader(dt), idxDesc.Size, idxDesc.Digest)
+}
@@ -0,0 +1,341 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/core/remotes/docker"
+	"github.com/containerd/containerd/v2/plugins/content/local"
+	"github.com/distribution/reference"
+	"github.com/moby/buildkit/util/resolver"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReferrersTag(t *testing.T) {
+	dgst := digest.FromString("foo")
+	require.Equal(t, "sha256-"+dgst.Encoded(), ReferrersTag(dgst))
+
+	dgst = digest.SHA512.FromString("foo")
+	require.Equal(t, "sha512-"+dgst.Encoded()[:64], ReferrersTag(dgst))
+
+	// algorithms are truncated to 32 characters
+	require.Equal(t, strings.Repeat("a", 32)+"-abcd", ReferrersTag(digest.Digest(strings.Repeat("a", 40)+":abcd")))
+}
+
+func TestPushReferrers(t *testing.T) {
+	ctx := context.TODO()
+	cs, err := local.NewStore(t.TempDir())
+	require.NoError(t, err)
+
+	subject1 := ocispecs.Descriptor{MediaType: ocispecs.MediaTypeImageManifest, Digest: digest.FromString("subject1"), Size: 8}
+	subject2 := ocispecs.Descriptor{MediaType: ocispecs.MediaTypeImageManifest, Digest: digest.FromString("subject2"), Size: 8}
+	referrers := []ocispecs.Descriptor{
+		writeReferrer(ctx, t, cs, subject1, "att1"),
+		writeReferrer(ctx, t, cs, subject2, "att2"),
+		writeReferrer(ctx, t, cs, subject1, "sig1"),
+	}
+
+	for _, tc := range []struct {
+		name string
+		// indexed lists the subjects the registry reports in OCI-Subject
+		indexed []digest.Digest
+		tags    map[digest.Digest][]ocispecs.Descriptor
+	}{
+		{
+			name:    "supported",
+			indexed: []digest.Digest{subject1.Digest, subject2.Digest},
+		},
+		{
+			name: "unsupported",
+			tags: map[digest.Digest][]ocispecs.Descriptor{
+				subject1.Digest: {referrers[0], referrers[2]},
+				subject2.Digest: {referrers[1]},
+			},
+		},
+		{
+			// only the subject the registry didn't index gets a referrers tag
+			name:    "partial",
+			indexed: []digest.Digest{subject1.Digest},
+			tags: map[digest.Digest][]ocispecs.Descriptor{
+				subject2.Digest: {referrers[1]},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			reg := newTestRegistry()
+			reg.indexed = tc.indexed
+			srv := httptest.NewServer(reg)
+			defer srv.Close()
+
+			u, err := url.Parse(srv.URL)
+			require.NoError(t, err)
+			hosts := func(string) ([]docker.RegistryHost, error) {
+				return []docker.RegistryHost{{
+					Client:       srv.Client(),
+					Host:         u.Host,
+					Scheme:       "http",
+					Path:         "/v2",
+					Capabilities: docker.HostCapabilityPull | docker.HostCapabilityResolve | docker.HostCapabilityPush,
+				}}, nil
+			}
+
+			require.NoError(t, PushReferrers(ctx, nil, "", cs, referrers, u.Host+"/library/foo", false, hosts))
+
+			for _, desc := range referrers {
+				dt, err := content.ReadBlob(ctx, cs, desc)
+				require.NoError(t, err)
+				require.Equal(t, dt, reg.manifest(t, desc.Digest.String()))
+
+				var mfst ocispecs.Manifest
+				require.NoError(t, json.Unmarshal(dt, &mfst))
+				require.True(t, reg.hasBlob(mfst.Config.Digest))
+				for _, l := range mfst.Layers {
+					require.True(t, reg.hasBlob(l.Digest))
+				}
+			}
+			for _, subject := range []digest.Digest{subject1.Digest, subject2.Digest} {
+				expected, ok := tc.tags[subject]
+				if !ok {
+					require.False(t, reg.hasManifest(ReferrersTag(subject)))
+					continue
+				}
+				idx := reg.index(t, ReferrersTag(subject))
+				require.Equal(t, expected, idx.Manifests)
+			}
+		})
+	}
+}
+
+func TestPushReferrersTag(t *testing.T) {
+	ctx := context.TODO()
+	reg := newTestRegistry()
+	srv := httptest.NewServer(reg)
+	defer srv.Close()
+
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	hosts := func(string) ([]docker.RegistryHost, error) {
+		return []docker.RegistryHost{{
+			Client:       srv.Client(),
+			Host:         u.Host,
+			Scheme:       "http",
+			Path:         "/v2",
+			Capabilities: docker.HostCapabilityPull | docker.HostCapabilityResolve | docker.HostCapabilityPush,
+		}}, nil
+	}
+	name, err := reference.ParseNormalizedNamed(u.Host + "/library/foo")
+	require.NoError(t, err)
+	r := resolver.DefaultPool.GetResolver(hosts, name.String(), "push:"+strings.ToLower(t.Name()), nil, nil)
+
+	subject := digest.FromString("subject")
+	sig := ocispecs.Descriptor{
+		MediaType:    ocispecs.MediaTypeImageManifest,
+		ArtifactType: "application/vnd.dev.cosign.artifact.sig.v1+json",
+		Digest:       digest.FromString("sig"),
+		Size:         3,
+	}
+	require.NoError(t, pushReferrersTag(ctx, r, name, subject, []ocispecs.Descriptor{sig}))
+	idx := reg.index(t, ReferrersTag(subject))
+	require.Equal(t, ocispecs.MediaTypeImageIndex, idx.MediaType)
+	require.Equal(t, []ocispecs.Descriptor{sig}, idx.Manifests)
+
+	// referrers are added to the existing index
+	att := ocispecs.Descriptor{
+		MediaType:    ocispecs.MediaTypeImageManifest,
+		ArtifactType: "application/vnd.in-toto+json",
+		Digest:       digest.FromString("att"),
+		Size:         3,
+	}
+	require.NoError(t, pushReferrersTag(ctx, r, name, subject, []ocispecs.Descriptor{sig, att}))
+	idx = reg.index(t, ReferrersTag(subject))
+	require.Equal(t, []ocispecs.Descriptor{sig, att}, idx.Manifests)
+
+	// the index isn't pushed again if nothing changed
+	puts := reg.puts
+	require.NoError(t, pushReferrersTag(ctx, r, name, subject, []ocispecs.Descriptor{att}))
+	require.Equal(t, puts, reg.puts)
+
+	// referrers are read back from the referrers tag
+	refs, err := Referrers(ctx, r, name.String(), subject)
+	require.NoError(t, err)
+	require.Equal(t, []ocispecs.Descriptor{sig, att}, refs)
+
+	refs, err = Referrers(ctx, r, name.String(), digest.FromString("other"))
+	require.NoError(t, err)
+	require.Empty(t, refs)
+}
+
+// writeReferrer writes a manifest referring to subject with a single layer of
+// data to cs.
+func writeReferrer(ctx context.Context, t *testing.T, cs content.Store, subject ocispecs.Descriptor, data string) ocispecs.Descriptor {
+	config := ocispecs.DescriptorEmptyJSON
+	require.NoError(t, content.WriteBlob(ctx, cs, config.Digest.String(), bytes.NewReader(config.Data), config))
+	layer := ocispecs.Descriptor{
+		MediaType: "application/vnd.in-toto+json",
+		Digest:    digest.FromString(data),
+		Size:      int64(len(data)),
+	}
+	require.NoError(t, content.WriteBlob(ctx, cs, layer.Digest.String(), strings.NewReader(data), layer))
+
+	dt, err := json.Marshal(ocispecs.Manifest{
+		Versioned:    specs.Versioned{SchemaVersion: 2},
+		MediaType:    ocispecs.MediaTypeImageManifest,
+		ArtifactType: "application/vnd.in-toto+json",
+		Config:       config,
+		Layers:       []ocispecs.Descriptor{layer},
+		Subject:      &subject,
+	})
+	require.NoError(t, err)
+	desc := ocispecs.Descriptor{
+		MediaType:    ocispecs.MediaTypeImageManifest,
+		ArtifactType: "application/vnd.in-toto+json",
+		Digest:       digest.FromBytes(dt),
+		Size:         int64(len(dt)),
+	}
+	require.NoError(t, content.WriteBlob(ctx, cs, desc.Digest.String(), bytes.NewReader(dt), desc))
+	return desc
+}
+
+// testRegistry is a registry that stores manifests and blobs of a single
+// repository. It only reports the subjects listed in indexed in the
+// OCI-Subject header and doesn't serve the referrers API.
+type testRegistry struct {
+	mu        sync.Mutex
+	manifests map[string][]byte
+	blobs     map[digest.Digest][]byte
+	indexed   []digest.Digest
+	uploads   int
+	puts      int
+}
+
+func newTestRegistry() *testRegistry {
+	return &testRegistry{manifests: map[string][]byte{}, blobs: map[digest.Digest][]byte{}}
+}
+
+func (reg *testRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	if upload, ok := strings.CutPrefix(r.URL.Path, "/v2/library/foo/blobs/uploads/"); ok {
+		reg.serveUpload(w, r, upload)
+		return
+	}
+	if dgst, ok := strings.CutPrefix(r.URL.Path, "/v2/library/foo/blobs/"); ok {
+		dt, ok := reg.blobs[digest.Digest(dgst)]
+		if !ok || r.Method != http.MethodHead {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(dt)))
+		w.Header().Set("Docker-Content-Digest", dgst)
+		return
+	}
+
+	ref, ok := strings.CutPrefix(r.URL.Path, "/v2/library/foo/manifests/")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	switch r.Method {
+	case http.MethodHead, http.MethodGet:
+		dt, ok := reg.manifests[ref]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", ocispecs.MediaTypeImageIndex)
+		w.Header().Set("Docker-Content-Digest", digest.FromBytes(dt).String())
+		w.Header().Set("Content-Length", strconv.Itoa(len(dt)))
+		if r.Method == http.MethodGet {
+			w.Write(dt)
+		}
+	case http.MethodPut:
+		dt, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var mfst ocispecs.Manifest
+		if err := json.Unmarshal(dt, &mfst); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if mfst.Subject != nil && slices.Contains(reg.indexed, mfst.Subject.Digest) {
+			w.Header().Set("OCI-Subject", mfst.Subject.Digest.String())
+		}
+		reg.puts++
+		reg.manifests[ref] = dt
+		reg.manifests[digest.FromBytes(dt).String()] = dt
+		w.Header().Set("Docker-Content-Digest", digest.FromBytes(dt).String())
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (reg *testRegistry) serveUpload(w http.ResponseWriter, r *http.Request, upload string) {
+	switch {
+	case r.Method == http.MethodPost && upload == "":
+		reg.uploads++
+		w.Header().Set("Location", "/v2/library/foo/blobs/uploads/"+strconv.Itoa(reg.uploads))
+		w.WriteHeader(http.StatusAccepted)
+	case r.Method == http.MethodPut && upload != "":
+		dt, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		dgst := digest.FromBytes(dt)
+		if dgst.String() != r.URL.Query().Get("digest") {
+			http.Error(w, "digest mismatch", http.StatusBadRequest)
+			return
+		}
+		reg.blobs[dgst] = dt
+		w.Header().Set("Docker-Content-Digest", dgst.String())
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (reg *testRegistry) hasBlob(dgst digest.Digest) bool {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	_, ok := reg.blobs[dgst]
+	return ok
+}
+
+func (reg *testRegistry) hasManifest(ref string) bool {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	_, ok := reg.manifests[ref]
+	return ok
+}
+
+func (reg *testRegistry) manifest(t *testing.T, ref string) []byte {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	dt, ok := reg.manifests[ref]
+	require.True(t, ok, "missing %s", ref)
+	return dt
+}
+
+func (reg *testRegistry) index(t *testing.T, tag string) ocispecs.Index {
+	var idx ocispecs.Index
+	require.NoError(t, json.Unmarshal(reg.manifest(t, tag), &idx))
+	return idx
+}
@@ -0,0 +1,223 @@
+package sbomutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+	"github.com/moby/buildkit/util/sbomutil/cyclonedx"
+	"github.com/moby/buildkit/version"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	spdx_json "github.com/spdx/tools-golang/json"
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+const spdxNoAssertion = "NOASSERTION"
+
+// defaultDocumentName is the name of converted SPDX documents if the
+// CycloneDX BOM doesn't describe a component.
+const defaultDocumentName = "sbom"
+
+// Convert converts an SBOM predicate between the SPDX and CycloneDX formats,
+// identified by their in-toto predicate types. Only package information is
+// converted, files and relationships between packages are dropped.
+func Convert(dt []byte, from, to string) ([]byte, error) {
+	if from == to {
+		return dt, nil
+	}
+	switch {
+	case from == intoto.PredicateSPDX && to == intoto.PredicateCycloneDX:
+		return spdxToCycloneDX(dt)
+	case from == intoto.PredicateCycloneDX && to == intoto.PredicateSPDX:
+		return cycloneDXToSPDX(dt)
+	default:
+		return nil, errors.Errorf("cannot convert sbom from %s to %s", from, to)
+	}
+}
+
+func spdxToCycloneDX(dt []byte) ([]byte, error) {
+	doc, err := spdx_json.Read(bytes.NewReader(dt))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to decode spdx")
+	}
+
+	bom := cyclonedx.BOM{
+		BOMFormat:    cyclonedx.BOMFormat,
+		SpecVersion:  cyclonedx.SpecVersion,
+		SerialNumber: uuid.NewSHA1(uuid.NameSpaceURL, []byte(doc.DocumentNamespace)).URN(),
+		Version:      1,
+		Metadata: &cyclonedx.Metadata{
+			Component: &cyclonedx.Component{
+				Type: cyclonedx.ComponentTypeContainer,
+				Name: doc.DocumentName,
+			},
+		},
+	}
+	if ci := doc.CreationInfo; ci != nil {
+		bom.Metadata.Timestamp = ci.Created
+		for _, c := range ci.Creators {
+			if c.CreatorType != "Tool" {
+				continue
+			}
+			if bom.Metadata.Tools == nil {
+				bom.Metadata.Tools = &cyclonedx.Tools{}
+			}
+			bom.Metadata.Tools.Components = append(bom.Metadata.Tools.Components, cyclonedx.Component{
+				Type: cyclonedx.ComponentTypeApplication,
+				Name: c.Creator,
+			})
+		}
+	}
+
+	for _, p := range doc.Packages {
+		if p == nil {
+			continue
+		}
+		c := cyclonedx.Component{
+			BOMRef:  string(p.PackageSPDXIdentifier),
+			Type:    cyclonedx.ComponentTypeLibrary,
+			Name:    p.PackageName,
+			Version: p.PackageVersion,
+		}
+		switch p.PrimaryPackagePurpose {
+		case "OPERATING-SYSTEM":
+			c.Type = cyclonedx.ComponentTypeOperatingSystem
+		case "APPLICATION":
+			c.Type = cyclonedx.ComponentTypeApplication
+		case "CONTAINER":
+			c.Type = cyclonedx.ComponentTypeContainer
+		}
+		for _, ref := range p.PackageExternalReferences {
+			if ref != nil && ref.RefType == common.TypePackageManagerPURL {
+				c.PURL = ref.Locator
+				break
+			}
+		}
+		for _, l := range []string{p.PackageLicenseDeclared, p.PackageLicenseConcluded} {
+			if l != "" && l != spdxNoAssertion && l != "NONE" {
+				c.Licenses = []cyclonedx.License{{Expression: l}}
+				break
+			}
+		}
+		bom.Components = append(bom.Components, c)
+	}
+
+	return json.MarshalIndent(bom, "", "  ")
+}
+
+func cycloneDXToSPDX(dt []byte) ([]byte, error) {
+	var bom cyclonedx.BOM
+	if err := json.Unmarshal(dt, &bom); err != nil {
+		return nil, errors.Wrap(err, "unable to decode cyclonedx")
+	}
+	if bom.BOMFormat != cyclonedx.BOMFormat {
+		return nil, errors.Errorf("invalid cyclonedx bom format %q", bom.BOMFormat)
+	}
+
+	name := defaultDocumentName
+	created := time.Unix(0, 0).UTC().Format(time.RFC3339)
+	creators := []common.Creator{
+		{CreatorType: "Tool", Creator: "buildkit-" + version.Version},
+	}
+	if md := bom.Metadata; md != nil {
+		if md.Component != nil && md.Component.Name != "" {
+			name = md.Component.Name
+		}
+		if md.Timestamp != "" {
+			created = md.Timestamp
+		}
+		if md.Tools != nil {
+			for _, t := range md.Tools.Components {
+				creator := t.Name
+				if t.Version != "" {
+					creator += "-" + t.Version
+				}
+				creators = append(creators, common.Creator{CreatorType: "Tool", Creator: creator})
+			}
+		}
+	}
+
+	doc := &spdx.Document{
+		SPDXVersion:       spdx.Version,
+		DataLicense:       spdx.DataLicense,
+		SPDXIdentifier:    "DOCUMENT",
+		DocumentName:      name,
+		DocumentNamespace: fmt.Sprintf("https://mobyproject.org/buildkit/sbom/%s-%s", name, digest.FromBytes(dt).Encoded()),
+		CreationInfo: &spdx.CreationInfo{
+			Creators: creators,
+			Created:  created,
+		},
+	}
+
+	var add func(cs []cyclonedx.Component)
+	add = func(cs []cyclonedx.Component) {
+		for _, c := range cs {
+			p := &spdx.Package{
+				PackageName:             c.Name,
+				PackageSPDXIdentifier:   common.ElementID(fmt.Sprintf("Package-%d", len(doc.Packages))),
+				PackageVersion:          c.Version,
+				PackageDownloadLocation: spdxNoAssertion,
+				PackageLicenseConcluded: spdxNoAssertion,
+				PackageLicenseDeclared:  cycloneDXLicense(c.Licenses),
+				PackageCopyrightText:    spdxNoAssertion,
+			}
+			switch c.Type {
+			case cyclonedx.ComponentTypeOperatingSystem:
+				p.PrimaryPackagePurpose = "OPERATING-SYSTEM"
+			case cyclonedx.ComponentTypeApplication:
+				p.PrimaryPackagePurpose = "APPLICATION"
+			case cyclonedx.ComponentTypeContainer:
+				p.PrimaryPackagePurpose = "CONTAINER"
+			case cyclonedx.ComponentTypeLibrary:
+				p.PrimaryPackagePurpose = "LIBRARY"
+			}
+			if c.PURL != "" {
+				p.PackageExternalReferences = []*spdx.PackageExternalReference{
+					{
+						Category: common.CategoryPackageManager,
+						RefType:  common.TypePackageManagerPURL,
+						Locator:  c.PURL,
+					},
+				}
+			}
+			doc.Packages = append(doc.Packages, p)
+			doc.Relationships = append(doc.Relationships, &spdx.Relationship{
+				RefA:         common.MakeDocElementID("", "DOCUMENT"),
+				RefB:         common.MakeDocElementID("", string(p.PackageSPDXIdentifier)),
+				Relationship: common.TypeRelationshipDescribe,
+			})
+			add(c.Components)
+		}
+	}
+	add(bom.Components)
+
+	var buf bytes.Buffer
+	if err := spdx_json.Write(doc, &buf); err != nil {
+		return nil, errors.Wrap(err, "unable to encode spdx")
+	}
+	return buf.Bytes(), nil
+}
+
+// cycloneDXLicense returns the SPDX license expression for ls. Licenses that
+// are only known by name can't be expressed and are left as NOASSERTION.
+func cycloneDXLicense(ls []cyclonedx.License) string {
+	var ids []string
+	for _, l := range ls {
+		switch {
+		case l.Expression != "":
+			return l.Expression
+		case l.License != nil && l.License.ID != "":
+			ids = append(ids, l.License.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return spdxNoAssertion
+	}
+	return strings.Join(ids, " AND ")
+}
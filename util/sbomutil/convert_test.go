@@ -0,0 +1,66 @@
+package sbomutil
+
+import (
+	"encoding/json"
+	"testing"
+
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+	"github.com/moby/buildkit/util/sbomutil/cyclonedx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvert(t *testing.T) {
+	t.Parallel()
+
+	dt := []byte(`{"spdxVersion":"SPDX-2.3","dataLicense":"CC0-1.0","SPDXID":"SPDXRef-DOCUMENT","name":"sbom","documentNamespace":"https://mobyproject.org/buildkit/sbom/sbom-2070b7b505dc65efc31e234af4d1302fe63fbd3ef0112cedfe38837143fa7821","creationInfo":{"creators":["Tool: buildkit-v0.0.0+unknown"],"created":"2024-01-01T00:00:00Z"},` +
+		`"packages":[` +
+		`{"name":"musl","SPDXID":"SPDXRef-Package-0","versionInfo":"1.2.5-r0","downloadLocation":"NOASSERTION","filesAnalyzed":false,"licenseConcluded":"NOASSERTION","licenseDeclared":"MIT","copyrightText":"NOASSERTION","externalRefs":[{"referenceCategory":"PACKAGE-MANAGER","referenceType":"purl","referenceLocator":"pkg:apk/alpine/musl@1.2.5-r0?distro=alpine-3.20.0"}]},` +
+		`{"name":"buildkit","SPDXID":"SPDXRef-Package-1","versionInfo":"v0.20.0","downloadLocation":"NOASSERTION","filesAnalyzed":false,"licenseConcluded":"NOASSERTION","licenseDeclared":"NOASSERTION","copyrightText":"NOASSERTION","externalRefs":[{"referenceCategory":"PACKAGE-MANAGER","referenceType":"purl","referenceLocator":"pkg:golang/github.com/moby/buildkit@v0.20.0"}]}],` +
+		`"relationships":[{"spdxElementId":"SPDXRef-DOCUMENT","relatedSpdxElement":"SPDXRef-Package-0","relationshipType":"DESCRIBES"},{"spdxElementId":"SPDXRef-DOCUMENT","relatedSpdxElement":"SPDXRef-Package-1","relationshipType":"DESCRIBES"}]}`)
+
+	cdx, err := Convert(dt, intoto.PredicateSPDX, intoto.PredicateCycloneDX)
+	require.NoError(t, err)
+
+	var bom cyclonedx.BOM
+	require.NoError(t, json.Unmarshal(cdx, &bom))
+	require.Equal(t, cyclonedx.BOMFormat, bom.BOMFormat)
+	require.Equal(t, "2024-01-01T00:00:00Z", bom.Metadata.Timestamp)
+	require.Equal(t, "sbom", bom.Metadata.Component.Name)
+	require.Len(t, bom.Components, 2)
+	require.Equal(t, "musl", bom.Components[0].Name)
+	require.Equal(t, "pkg:apk/alpine/musl@1.2.5-r0?distro=alpine-3.20.0", bom.Components[0].PURL)
+	require.Equal(t, []cyclonedx.License{{Expression: "MIT"}}, bom.Components[0].Licenses)
+	require.Equal(t, "pkg:golang/github.com/moby/buildkit@v0.20.0", bom.Components[1].PURL)
+	require.Empty(t, bom.Components[1].Licenses)
+
+	back, err := Convert(cdx, intoto.PredicateCycloneDX, intoto.PredicateSPDX)
+	require.NoError(t, err)
+
+	var doc struct {
+		SPDXVersion  string `json:"spdxVersion"`
+		Name         string `json:"name"`
+		CreationInfo struct {
+			Created string `json:"created"`
+		} `json:"creationInfo"`
+		Packages []struct {
+			Name            string `json:"name"`
+			Version         string `json:"versionInfo"`
+			LicenseDeclared string `json:"licenseDeclared"`
+			ExternalRefs    []struct {
+				Locator string `json:"referenceLocator"`
+			} `json:"externalRefs"`
+		} `json:"packages"`
+	}
+	require.NoError(t, json.Unmarshal(back, &doc))
+	require.Equal(t, "SPDX-2.3", doc.SPDXVersion)
+	require.Equal(t, "sbom", doc.Name)
+	require.Equal(t, "2024-01-01T00:00:00Z", doc.CreationInfo.Created)
+	require.Len(t, doc.Packages, 2)
+	require.Equal(t, "musl", doc.Packages[0].Name)
+	require.Equal(t, "MIT", doc.Packages[0].LicenseDeclared)
+	require.Equal(t, "pkg:apk/alpine/musl@1.2.5-r0?distro=alpine-3.20.0", doc.Packages[0].ExternalRefs[0].Locator)
+	require.Equal(t, "NOASSERTION", doc.Packages[1].LicenseDeclared)
+
+	_, err = Convert(dt, intoto.PredicateSPDX, "https://example.com/unknown")
+	require.ErrorContains(t, err, "cannot convert")
+}
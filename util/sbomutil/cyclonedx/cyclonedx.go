@@ -0,0 +1,64 @@
+// Package cyclonedx defines the subset of the CycloneDX JSON format that
+// BuildKit produces and converts.
+//
+// https://cyclonedx.org/docs/1.5/json/
+package cyclonedx
+
+const (
+	BOMFormat   = "CycloneDX"
+	SpecVersion = "1.5"
+)
+
+// Component types.
+const (
+	ComponentTypeApplication     = "application"
+	ComponentTypeContainer       = "container"
+	ComponentTypeLibrary         = "library"
+	ComponentTypeOperatingSystem = "operating-system"
+)
+
+type BOM struct {
+	BOMFormat    string      `json:"bomFormat"`
+	SpecVersion  string      `json:"specVersion"`
+	SerialNumber string      `json:"serialNumber,omitempty"`
+	Version      int         `json:"version"`
+	Metadata     *Metadata   `json:"metadata,omitempty"`
+	Components   []Component `json:"components,omitempty"`
+}
+
+type Metadata struct {
+	Timestamp string     `json:"timestamp,omitempty"`
+	Tools     *Tools     `json:"tools,omitempty"`
+	Component *Component `json:"component,omitempty"`
+}
+
+type Tools struct {
+	Components []Component `json:"components,omitempty"`
+}
+
+type Component struct {
+	BOMRef     string      `json:"bom-ref,omitempty"`
+	Type       string      `json:"type"`
+	Name       string      `json:"name"`
+	Version    string      `json:"version,omitempty"`
+	PURL       string      `json:"purl,omitempty"`
+	Licenses   []License   `json:"licenses,omitempty"`
+	Properties []Property  `json:"properties,omitempty"`
+	Components []Component `json:"components,omitempty"`
+}
+
+// License is either a single license or an SPDX license expression.
+type License struct {
+	License    *LicenseID `json:"license,omitempty"`
+	Expression string     `json:"expression,omitempty"`
+}
+
+type LicenseID struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+type Property struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
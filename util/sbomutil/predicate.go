@@ -0,0 +1,11 @@
+// Package sbomutil provides helpers for the SBOM formats produced by the
+// frontends that are also needed by the exporters.
+package sbomutil
+
+import intoto "github.com/in-toto/in-toto-golang/in_toto"
+
+// IsSBOMPredicate returns true if predicateType is the type of a supported
+// SBOM format.
+func IsSBOMPredicate(predicateType string) bool {
+	return predicateType == intoto.PredicateSPDX || predicateType == intoto.PredicateCycloneDX
+}
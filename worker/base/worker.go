@@ -3,8 +3,10 @@ package base
 import (
 	"context"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"time"
 
 	"github.com/containerd/containerd/v2/core/content"
@@ -21,7 +23,9 @@ import (
 	"github.com/moby/buildkit/executor"
 	"github.com/moby/buildkit/executor/resources"
 	"github.com/moby/buildkit/exporter"
+	"github.com/moby/buildkit/exporter/attestation"
 	imageexporter "github.com/moby/buildkit/exporter/containerimage"
+	fsimageexporter "github.com/moby/buildkit/exporter/fsimage"
 	localexporter "github.com/moby/buildkit/exporter/local"
 	ociexporter "github.com/moby/buildkit/exporter/oci"
 	tarexporter "github.com/moby/buildkit/exporter/tar"
@@ -38,9 +42,12 @@ import (
 	"github.com/moby/buildkit/solver/pb"
 	"github.com/moby/buildkit/source"
 	"github.com/moby/buildkit/source/containerimage"
+	"github.com/moby/buildkit/source/external"
 	"github.com/moby/buildkit/source/git"
 	"github.com/moby/buildkit/source/http"
 	"github.com/moby/buildkit/source/local"
+	"github.com/moby/buildkit/source/ociartifact"
+	"github.com/moby/buildkit/source/pkgregistry"
 	"github.com/moby/buildkit/util/archutil"
 	"github.com/moby/buildkit/util/bklog"
 	"github.com/moby/buildkit/util/leaseutil"
@@ -76,6 +83,8 @@ type WorkerOpt struct {
 	Differ           diff.Comparer
 	ImageStore       images.Store // optional
 	RegistryHosts    docker.RegistryHosts
+	HTTPMirrors      map[string][]string // optional, see http.Opt
+	SourceProviders  map[string]string   // optional, addresses of external source providers by scheme
 	IdentityMapping  *user.IdentityMapping
 	LeaseManager     *leaseutil.Manager
 	GarbageCollect   func(context.Context) (gc.Stats, error)
@@ -84,6 +93,9 @@ type WorkerOpt struct {
 	MountPoolRoot    string
 	ResourceMonitor  *resources.Monitor
 	CDIManager       *cdidevices.Manager
+	// AttestationSigner signs attestations if requested by the exporter and
+	// the client doesn't provide a signer.
+	AttestationSigner attestation.Signer
 }
 
 // Worker is a local worker instance with dedicated snapshotter, cache, and so on.
@@ -95,10 +107,11 @@ type Worker struct {
 	imageWriter     *imageexporter.ImageWriter
 	ImageSource     *containerimage.Source
 	OCILayoutSource *containerimage.Source
+	externalSources []*external.Source
 }
 
 // NewWorker instantiates a local worker
-func NewWorker(ctx context.Context, opt WorkerOpt) (*Worker, error) {
+func NewWorker(ctx context.Context, opt WorkerOpt) (_ *Worker, retErr error) {
 	imageRefChecker := imagerefchecker.New(imagerefchecker.Opt{
 		ImageStore:   opt.ImageStore,
 		ContentStore: opt.ContentStore,
@@ -155,6 +168,7 @@ func NewWorker(ctx context.Context, opt WorkerOpt) (*Worker, error) {
 
 	hs, err := http.NewSource(http.Opt{
 		CacheAccessor: cm,
+		Mirrors:       opt.HTTPMirrors,
 	})
 	if err != nil {
 		return nil, err
@@ -162,6 +176,23 @@ func NewWorker(ctx context.Context, opt WorkerOpt) (*Worker, error) {
 
 	sm.Register(hs)
 
+	as, err := ociartifact.NewSource(ociartifact.Opt{
+		CacheAccessor: cm,
+		RegistryHosts: opt.RegistryHosts,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sm.Register(as)
+
+	ps, err := pkgregistry.NewSource(pkgregistry.Opt{
+		CacheAccessor: cm,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sm.Register(ps)
+
 	ss, err := local.NewSource(local.Opt{
 		CacheAccessor: cm,
 	})
@@ -185,6 +216,30 @@ func NewWorker(ctx context.Context, opt WorkerOpt) (*Worker, error) {
 
 	sm.Register(os)
 
+	var externalSources []*external.Source
+	defer func() {
+		if retErr != nil {
+			for _, es := range externalSources {
+				es.Close()
+			}
+		}
+	}()
+	for _, scheme := range slices.Sorted(maps.Keys(opt.SourceProviders)) {
+		if sm.Registered(scheme) {
+			return nil, errors.Errorf("external source provider can't override builtin %s source", scheme)
+		}
+		es, err := external.NewSource(external.Opt{
+			Scheme:        scheme,
+			Address:       opt.SourceProviders[scheme],
+			CacheAccessor: cm,
+		})
+		if err != nil {
+			return nil, err
+		}
+		externalSources = append(externalSources, es)
+		sm.Register(es)
+	}
+
 	iw, err := imageexporter.NewImageWriter(imageexporter.WriterOpt{
 		Snapshotter:  opt.Snapshotter,
 		ContentStore: opt.ContentStore,
@@ -210,6 +265,7 @@ func NewWorker(ctx context.Context, opt WorkerOpt) (*Worker, error) {
 		imageWriter:     iw,
 		ImageSource:     is,
 		OCILayoutSource: os,
+		externalSources: externalSources,
 	}, nil
 }
 
@@ -236,6 +292,11 @@ func (w *Worker) Close() error {
 			rerr = multierror.Append(rerr, err)
 		}
 	}
+	for _, es := range w.externalSources {
+		if err := es.Close(); err != nil {
+			rerr = multierror.Append(rerr, err)
+		}
+	}
 	return rerr
 }
 
@@ -458,11 +519,12 @@ func (w *Worker) Exporter(name string, sm *session.Manager) (exporter.Exporter,
 	switch name {
 	case client.ExporterImage:
 		return imageexporter.New(imageexporter.Opt{
-			Images:         w.ImageStore,
-			SessionManager: sm,
-			ImageWriter:    w.imageWriter,
-			RegistryHosts:  w.RegistryHosts,
-			LeaseManager:   w.LeaseManager(),
+			Images:            w.ImageStore,
+			SessionManager:    sm,
+			ImageWriter:       w.imageWriter,
+			RegistryHosts:     w.RegistryHosts,
+			LeaseManager:      w.LeaseManager(),
+			AttestationSigner: w.AttestationSigner,
 		})
 	case client.ExporterLocal:
 		return localexporter.New(localexporter.Opt{
@@ -472,19 +534,27 @@ func (w *Worker) Exporter(name string, sm *session.Manager) (exporter.Exporter,
 		return tarexporter.New(tarexporter.Opt{
 			SessionManager: sm,
 		})
+	case client.ExporterSquashFS, client.ExporterEROFS, client.ExporterExt4:
+		return fsimageexporter.New(fsimageexporter.Opt{
+			SessionManager: sm,
+			Root:           w.WorkerOpt.Root,
+			Type:           name,
+		})
 	case client.ExporterOCI:
 		return ociexporter.New(ociexporter.Opt{
-			SessionManager: sm,
-			ImageWriter:    w.imageWriter,
-			Variant:        ociexporter.VariantOCI,
-			LeaseManager:   w.LeaseManager(),
+			SessionManager:    sm,
+			ImageWriter:       w.imageWriter,
+			Variant:           ociexporter.VariantOCI,
+			LeaseManager:      w.LeaseManager(),
+			AttestationSigner: w.AttestationSigner,
 		})
 	case client.ExporterDocker:
 		return ociexporter.New(ociexporter.Opt{
-			SessionManager: sm,
-			ImageWriter:    w.imageWriter,
-			Variant:        ociexporter.VariantDocker,
-			LeaseManager:   w.LeaseManager(),
+			SessionManager:    sm,
+			ImageWriter:       w.imageWriter,
+			Variant:           ociexporter.VariantDocker,
+			LeaseManager:      w.LeaseManager(),
+			AttestationSigner: w.AttestationSigner,
 		})
 	default:
 		return nil, errors.Errorf("exporter %q could not be found", name)
@@ -18,6 +18,7 @@ import (
 	"github.com/moby/buildkit/executor/oci"
 	containerdsnapshot "github.com/moby/buildkit/snapshot/containerd"
 	"github.com/moby/buildkit/solver/llbsolver/cdidevices"
+	"github.com/moby/buildkit/solver/pb"
 	"github.com/moby/buildkit/util/leaseutil"
 	"github.com/moby/buildkit/util/network/netproviders"
 	"github.com/moby/buildkit/util/winlayers"
@@ -36,6 +37,7 @@ type WorkerOptions struct {
 	SnapshotterName string
 	Namespace       string
 	CgroupParent    string
+	ResourceLimits  *pb.ResourceLimits
 	Rootless        bool
 	Labels          map[string]string
 	DNS             *oci.DNSConfig
@@ -157,6 +159,7 @@ func newContainerd(client *ctd.Client, workerOpts WorkerOptions) (base.WorkerOpt
 		Client:           client,
 		Root:             root,
 		CgroupParent:     workerOpts.CgroupParent,
+		ResourceLimits:   workerOpts.ResourceLimits,
 		ApparmorProfile:  workerOpts.ApparmorProfile,
 		DNSConfig:        workerOpts.DNS,
 		Selinux:          workerOpts.Selinux,
@@ -22,6 +22,7 @@ import (
 	"github.com/moby/buildkit/executor/runcexecutor"
 	containerdsnapshot "github.com/moby/buildkit/snapshot/containerd"
 	"github.com/moby/buildkit/solver/llbsolver/cdidevices"
+	"github.com/moby/buildkit/solver/pb"
 	"github.com/moby/buildkit/util/leaseutil"
 	"github.com/moby/buildkit/util/network/netproviders"
 	"github.com/moby/buildkit/util/winlayers"
@@ -40,7 +41,7 @@ type SnapshotterFactory struct {
 }
 
 // NewWorkerOpt creates a WorkerOpt.
-func NewWorkerOpt(root string, snFactory SnapshotterFactory, rootless bool, processMode oci.ProcessMode, labels map[string]string, idmap *user.IdentityMapping, nopt netproviders.Opt, dns *oci.DNSConfig, binary, apparmorProfile string, selinux bool, parallelismSem *semaphore.Weighted, traceSocket, defaultCgroupParent string, cdiManager *cdidevices.Manager) (base.WorkerOpt, error) {
+func NewWorkerOpt(root string, snFactory SnapshotterFactory, rootless bool, processMode oci.ProcessMode, labels map[string]string, idmap *user.IdentityMapping, nopt netproviders.Opt, dns *oci.DNSConfig, binary, apparmorProfile string, selinux bool, parallelismSem *semaphore.Weighted, traceSocket, defaultCgroupParent string, defaultResourceLimits *pb.ResourceLimits, cdiManager *cdidevices.Manager) (base.WorkerOpt, error) {
 	var opt base.WorkerOpt
 	name := "runc-" + snFactory.Name
 	root = filepath.Join(root, name)
@@ -71,16 +72,17 @@ func NewWorkerOpt(root string, snFactory SnapshotterFactory, rootless bool, proc
 		// Otherwise, a nil array will be sent and the default OCI worker binary will be used
 		CommandCandidates: cmds,
 		// without root privileges
-		Rootless:            rootless,
-		ProcessMode:         processMode,
-		IdentityMapping:     idmap,
-		DNS:                 dns,
-		ApparmorProfile:     apparmorProfile,
-		SELinux:             selinux,
-		TracingSocket:       traceSocket,
-		DefaultCgroupParent: defaultCgroupParent,
-		ResourceMonitor:     rm,
-		CDIManager:          cdiManager,
+		Rootless:              rootless,
+		ProcessMode:           processMode,
+		IdentityMapping:       idmap,
+		DNS:                   dns,
+		ApparmorProfile:       apparmorProfile,
+		SELinux:               selinux,
+		TracingSocket:         traceSocket,
+		DefaultCgroupParent:   defaultCgroupParent,
+		DefaultResourceLimits: defaultResourceLimits,
+		ResourceMonitor:       rm,
+		CDIManager:            cdiManager,
 	}, np)
 	if err != nil {
 		return opt, err